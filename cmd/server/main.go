@@ -47,10 +47,15 @@ func main() {
 
 	cont := container.New(conf)
 
+	// Background jobs
+	go cont.OfferPriceService.Run(ctx, conf.SchedulerInterval)
+	go cont.WebhookService.Run(ctx, conf.WebhookInterval)
+	go cont.NotificationService.Run(ctx, conf.SchedulerInterval)
+
 	// HTTP Server
 	err = http.Server(
 		ctx,
-		http.Router(cont),
+		http.Router(cont, conf),
 	)
 
 	if err != nil {
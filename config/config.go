@@ -3,6 +3,8 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,9 +17,36 @@ type Configuration struct {
 	MigrateToVersion    string
 	MigrationLocation   string
 	FileStorageLocation string
+	FilesUrl            string // Адреса, за якою API віддає файли зі сховища
+	StorageBackend      string // filesystem - локальна тека FILES_LOCATION, s3 - S3-сумісне сховище (AWS S3, MinIO), memory - у пам'яті, для тестів
+	S3Endpoint          string // host[:port], напр. localhost:9000 для MinIO
+	S3Region            string
+	S3Bucket            string
+	S3AccessKey         string
+	S3SecretKey         string
+	S3UseSsl            bool
+	S3PresignTtl        time.Duration // Час життя підписаних посилань на файли, 0 - файли віддаються через API
+	ImageUploadMaxSize  int64         // Найбільший розмір зображення, що завантажується через multipart/form-data, байт
 	JwtSecret           string
-	JwtTTL              time.Duration
+	JwtTTL              time.Duration // Час життя access токена
+	RefreshTokenTTL     time.Duration // Час життя сесії без оновлення токена
+	LoginMaxAttempts    int           // Кількість невдалих спроб входу в акаунт до блокування
+	LoginIpMaxAttempts  int           // Кількість невдалих спроб входу з однієї IP до блокування
+	LoginLockout        time.Duration // Тривалість першого блокування, кожне наступне вдвічі довше
+	TrustedProxies      []string      // Адреси або мережі (CIDR) балансувальників, лише їм вірять у X-Forwarded-For
+	TotpIssuer          string        // Назва сервісу в застосунку-автентифікаторі
+	SchedulerInterval   time.Duration
+	WebhookInterval     time.Duration
 	MonobankPrivateKey  string // Токен з особистого кабінету https://web.monobank.ua/ або тестовий токен з https://api.monobank.ua/
+	SmtpHost            string // Порожній хост - листи лише пишуться в лог. Для розробки підходить MailHog (localhost:1025)
+	SmtpPort            string
+	SmtpUser            string
+	SmtpPassword        string
+	SmtpFrom            string
+	SmsGatewayUrl       string // Порожня адреса - SMS лише пишуться в лог
+	SmsGatewayToken     string
+	DefaultLanguage     string
+	Timezone            string // Часовий пояс, в якому рахуються тихі години сповіщень
 }
 
 func GetConfiguration() Configuration {
@@ -29,9 +58,36 @@ func GetConfiguration() Configuration {
 		MigrateToVersion:    getOrDefault("MIGRATE", "latest"),
 		MigrationLocation:   getOrDefault("MIGRATION_LOCATION", "internal/infra/database/migrations"),
 		FileStorageLocation: getOrDefault("FILES_LOCATION", "file_storage"),
+		FilesUrl:            getOrDefault("FILES_URL", "/static/"),
+		StorageBackend:      getOrDefault("STORAGE_BACKEND", "filesystem"),
+		S3Endpoint:          getOrDefault("S3_ENDPOINT", ""),
+		S3Region:            getOrDefault("S3_REGION", "us-east-1"),
+		S3Bucket:            getOrDefault("S3_BUCKET", ""),
+		S3AccessKey:         getOrDefault("S3_ACCESS_KEY", ""),
+		S3SecretKey:         getOrDefault("S3_SECRET_KEY", ""),
+		S3UseSsl:            getBoolOrDefault("S3_USE_SSL", true),
+		S3PresignTtl:        getDurationOrDefault("S3_PRESIGN_TTL", time.Hour),
+		ImageUploadMaxSize:  int64(getIntOrDefault("IMAGE_UPLOAD_MAX_SIZE", 10<<20)),
 		JwtSecret:           getOrDefault("JWT_SECRET", "1234567890"),
-		JwtTTL:              72 * time.Hour,
+		JwtTTL:              getDurationOrDefault("JWT_TTL", 15*time.Minute),
+		RefreshTokenTTL:     getDurationOrDefault("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		LoginMaxAttempts:    getIntOrDefault("LOGIN_MAX_ATTEMPTS", 5),
+		LoginIpMaxAttempts:  getIntOrDefault("LOGIN_IP_MAX_ATTEMPTS", 20),
+		LoginLockout:        getDurationOrDefault("LOGIN_LOCKOUT", time.Minute),
+		TrustedProxies:      getListOrDefault("TRUSTED_PROXIES", nil),
+		TotpIssuer:          getOrDefault("TOTP_ISSUER", "Horodyna"),
+		SchedulerInterval:   time.Minute,
+		WebhookInterval:     10 * time.Second,
 		MonobankPrivateKey:  getOrDefault("MONOBANK_PRIVATE_KEY", "uES2_x-N_rd3eysY_-SXsoqBAIgmK4lLnqZpRZMKdAM4"),
+		SmtpHost:            getOrDefault("SMTP_HOST", ""),
+		SmtpPort:            getOrDefault("SMTP_PORT", "1025"),
+		SmtpUser:            getOrDefault("SMTP_USER", ""),
+		SmtpPassword:        getOrDefault("SMTP_PASSWORD", ""),
+		SmtpFrom:            getOrDefault("SMTP_FROM", "no-reply@localhost"),
+		SmsGatewayUrl:       getOrDefault("SMS_GATEWAY_URL", ""),
+		SmsGatewayToken:     getOrDefault("SMS_GATEWAY_TOKEN", ""),
+		DefaultLanguage:     getOrDefault("DEFAULT_LANGUAGE", "uk"),
+		Timezone:            getOrDefault("TIMEZONE", "Europe/Kyiv"),
 	}
 }
 
@@ -51,3 +107,54 @@ func getOrDefault(key, defaultVal string) string {
 	}
 	return env
 }
+
+func getIntOrDefault(key string, defaultVal int) int {
+	env, set := os.LookupEnv(key)
+	if !set || env == "" {
+		return defaultVal
+	}
+	i, err := strconv.Atoi(env)
+	if err != nil {
+		log.Fatalf("%s env var is not a valid number: %s", key, err)
+	}
+	return i
+}
+
+func getDurationOrDefault(key string, defaultVal time.Duration) time.Duration {
+	env, set := os.LookupEnv(key)
+	if !set || env == "" {
+		return defaultVal
+	}
+	d, err := time.ParseDuration(env)
+	if err != nil {
+		log.Fatalf("%s env var is not a valid duration: %s", key, err)
+	}
+	return d
+}
+
+// getListOrDefault splits a comma separated value, e.g. "10.0.0.0/8,127.0.0.1".
+func getListOrDefault(key string, defaultVal []string) []string {
+	env, set := os.LookupEnv(key)
+	if !set || env == "" {
+		return defaultVal
+	}
+	var list []string
+	for _, item := range strings.Split(env, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func getBoolOrDefault(key string, defaultVal bool) bool {
+	env, set := os.LookupEnv(key)
+	if !set || env == "" {
+		return defaultVal
+	}
+	b, err := strconv.ParseBool(env)
+	if err != nil {
+		log.Fatalf("%s env var is not a valid boolean: %s", key, err)
+	}
+	return b
+}
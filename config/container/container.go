@@ -3,6 +3,9 @@ package container
 import (
 	"boilerplate/config"
 	"boilerplate/internal/app"
+	"boilerplate/internal/app/events"
+	"boilerplate/internal/app/notification"
+	"boilerplate/internal/app/ratelimit"
 	"boilerplate/internal/filesystem"
 	"boilerplate/internal/infra/database"
 	"boilerplate/internal/infra/http/controllers"
@@ -21,8 +24,13 @@ type Container struct {
 	Controllers
 }
 
+// NotificationService is aliased so the embedded field has a descriptive name.
+type NotificationService = notification.Service
+
 type Middlewares struct {
-	AuthMw func(http.Handler) http.Handler
+	AuthMw            func(http.Handler) http.Handler
+	AuthRateLimitMw   func(http.Handler) http.Handler
+	SearchRateLimitMw func(http.Handler) http.Handler
 }
 
 type Services struct {
@@ -37,6 +45,21 @@ type Services struct {
 	app.AddressService
 	app.InvoiceService
 	app.MonobankService
+	app.OfferPriceService
+	app.PromotionService
+	app.ReviewService
+	app.FavoriteService
+	app.OfferAlertService
+	NotificationService
+	app.WebhookService
+	app.OrderMessageService
+	app.AuditService
+	app.AdminService
+	app.TwoFactorService
+	app.AccountService
+	app.ApiKeyService
+	app.FarmMemberService
+	app.FarmInvitationService
 }
 
 type Controllers struct {
@@ -51,6 +74,19 @@ type Controllers struct {
 	controllers.AddressController
 	controllers.InvoiceController
 	controllers.MonobankController
+	controllers.OfferPriceController
+	controllers.PromotionController
+	controllers.ReviewController
+	controllers.FavoriteController
+	controllers.OfferAlertController
+	controllers.NotificationController
+	controllers.OrderEventController
+	controllers.WebhookController
+	controllers.OrderMessageController
+	controllers.AdminController
+	controllers.ApiKeyController
+	controllers.FarmMemberController
+	controllers.FileController
 }
 
 func New(conf config.Configuration) Container {
@@ -62,41 +98,94 @@ func New(conf config.Configuration) Container {
 	offerRepository := database.NewOfferRepository(sess)
 	farmRepository := database.NewFarmRepository(sess, offerRepository)
 	orderItemRepository := database.NewOrderItemRepository(sess, offerRepository, farmRepository)
-	orderRepository := database.NewOrderRepository(sess, orderItemRepository)
+	promotionRepository := database.NewPromotionRepository(sess)
+	reviewRepository := database.NewReviewRepository(sess)
+	favoriteRepository := database.NewFavoriteRepository(sess, offerRepository, farmRepository)
+	offerAlertRepository := database.NewOfferAlertRepository(sess)
+	orderRepository := database.NewOrderRepository(sess, orderItemRepository, promotionRepository)
 	ImageRepository := database.NewImageModelRepository(sess)
 	addressRepository := database.NewAddressRepository(sess)
 	invoiceRepository := database.NewInvoiceRepository(sess)
+	offerPriceRepository := database.NewOfferPriceRepository(sess)
+	notificationRepository := database.NewNotificationRepository(sess)
+	notificationPreferenceRepository := database.NewNotificationPreferenceRepository(sess)
+	queuedNotificationRepository := database.NewQueuedNotificationRepository(sess)
+	webhookRepository := database.NewWebhookRepository(sess)
+	orderMessageRepository := database.NewOrderMessageRepository(sess)
+	oneTimeCodeRepository := database.NewOneTimeCodeRepository(sess)
+	auditEventRepository := database.NewAuditEventRepository(sess)
+	twoFactorRepository := database.NewTwoFactorRepository(sess)
+	apiKeyRepository := database.NewApiKeyRepository(sess)
+	farmMemberRepository := database.NewFarmMemberRepository(sess)
+	accountRepository := database.NewAccountRepository(sess)
 
 	userService := app.NewUserService(userRepository)
-	authService := app.NewAuthService(sessionRepository, userService, conf, tknAuth)
-	farmService := app.NewFarmService(farmRepository, offerRepository, orderRepository)
+	notificationService := notification.NewService(notificationRepository, notificationPreferenceRepository, queuedNotificationRepository, userRepository, conf)
+	oneTimeCodeService := app.NewOneTimeCodeService(oneTimeCodeRepository, notificationService)
+	twoFactorService := app.NewTwoFactorService(twoFactorRepository, userService, conf)
+	authService := app.NewAuthService(sessionRepository, userService, oneTimeCodeService, twoFactorService, conf, tknAuth)
+	auditService := app.NewAuditService(auditEventRepository)
+	farmService := app.NewFarmService(farmRepository, offerRepository, orderRepository, farmMemberRepository, auditService)
 	catService := app.NewCategoryService()
-	imageStorageService := filesystem.NewImageStorageService(conf.FileStorageLocation)
-	imageService := app.NewImageModelService(ImageRepository, imageStorageService)
-	offerService := app.NewOfferService(offerRepository, imageStorageService, imageService)
-	orderService := app.NewOrderService(orderRepository, orderItemRepository, addressRepository)
-	orderItemService := app.NewOrderItemsService(orderItemRepository, orderRepository)
+	imageStorageService := getImageStorage(conf)
+	imageProcessor := filesystem.NewImageProcessor()
+	imageService := app.NewImageModelService(ImageRepository, imageStorageService, imageProcessor)
+	eventBroker := events.NewMemoryBroker()
+	webhookService := app.NewWebhookService(webhookRepository, orderItemRepository)
+	offerAlertService := app.NewOfferAlertService(offerAlertRepository, notificationService)
+	offerService := app.NewOfferService(offerRepository, offerPriceRepository, imageService, offerAlertService, webhookService, auditService)
+	offerPriceService := app.NewOfferPriceService(offerPriceRepository, offerRepository, offerService)
+	orderService := app.NewOrderService(orderRepository, orderItemRepository, farmMemberRepository, addressRepository, notificationService, eventBroker, webhookService, auditService)
+	orderItemService := app.NewOrderItemsService(orderItemRepository, orderRepository, farmMemberRepository, eventBroker, webhookService)
 	addressService := app.NewAddressService(addressRepository)
 	invoiceService := app.NewInvoiceService(invoiceRepository)
 	monobankService := app.NewMonobankService(conf.MonobankPrivateKey, invoiceService)
+	promotionService := app.NewPromotionService(promotionRepository, orderRepository, orderItemRepository, offerRepository)
+	reviewService := app.NewReviewService(reviewRepository, imageService)
+	favoriteService := app.NewFavoriteService(favoriteRepository)
+	orderMessageService := app.NewOrderMessageService(orderMessageRepository, orderItemRepository, farmMemberRepository, imageService, notificationService)
+	accountService := app.NewAccountService(accountRepository, addressRepository, orderRepository, farmRepository, offerRepository, reviewRepository, imageStorageService, imageService, auditService)
+	apiKeyService := app.NewApiKeyService(apiKeyRepository, farmRepository)
+	farmMemberService := app.NewFarmMemberService(farmMemberRepository)
+	farmInvitationService := app.NewFarmInvitationService(farmMemberRepository, userRepository, notificationService)
+	adminService := app.NewAdminService(userRepository, sessionRepository, farmRepository, offerRepository, orderRepository, invoiceRepository, orderService, webhookService, auditService)
 
-	authController := controllers.NewAuthController(authService, userService)
-	userController := controllers.NewUserController(userService)
-	farmController := controllers.NewFarmController(farmService)
+	authController := controllers.NewAuthController(authService, userService, twoFactorService)
+	userController := controllers.NewUserController(userService, authService, accountService, imageService)
+	farmController := controllers.NewFarmController(farmService, imageService)
 	categoryController := controllers.NewCategoryController(catService)
-	offerController := controllers.NewOfferController(offerService, farmService, imageService)
-	orderController := controllers.NewOrderController(orderService, orderItemService, imageService)
+	offerController := controllers.NewOfferController(offerService, farmService, imageService, favoriteService, farmMemberService, conf.ImageUploadMaxSize)
+	orderController := controllers.NewOrderController(orderService, orderItemService, imageService, orderMessageService)
 	orderItemController := controllers.NewOrderItemController(orderItemService, imageService)
-	imageController := controllers.NewImageModelController(imageService)
+	imageController := controllers.NewImageModelController(imageService, offerService, reviewService, farmMemberService, conf.ImageUploadMaxSize)
 	addressController := controllers.NewAddressController(addressService)
 	invoiceController := controllers.NewInvoiceController(invoiceService)
 	monobankController := controllers.NewMonobankController(monobankService)
+	offerPriceController := controllers.NewOfferPriceController(offerPriceService)
+	promotionController := controllers.NewPromotionController(promotionService, farmService, orderItemService, imageService)
+	reviewController := controllers.NewReviewController(reviewService, farmService, imageService)
+	favoriteController := controllers.NewFavoriteController(favoriteService, imageService)
+	offerAlertController := controllers.NewOfferAlertController(offerAlertService, offerService)
+	notificationController := controllers.NewNotificationController(notificationService)
+	orderEventController := controllers.NewOrderEventController(eventBroker, authService, userService)
+	webhookController := controllers.NewWebhookController(webhookService, farmService)
+	orderMessageController := controllers.NewOrderMessageController(orderMessageService, imageService)
+	adminController := controllers.NewAdminController(adminService, auditService, imageService)
+	apiKeyController := controllers.NewApiKeyController(apiKeyService, farmService, farmMemberService)
+	farmMemberController := controllers.NewFarmMemberController(farmMemberService, farmInvitationService)
+	fileController := controllers.NewFileController(imageStorageService)
 
-	authMiddleware := middlewares.AuthMiddleware(tknAuth, authService, userService)
+	authMiddleware := middlewares.AuthMiddleware(tknAuth, authService, userService, apiKeyService)
+	// Sign in and sign up requests check passwords and send codes, searches
+	// by coordinates are the heaviest queries.
+	authRateLimitMiddleware := middlewares.RateLimit(ratelimit.NewTokenBucket(ratelimit.PerMinute(10), 10))
+	searchRateLimitMiddleware := middlewares.RateLimit(ratelimit.NewTokenBucket(1, 10))
 
 	return Container{
 		Middlewares: Middlewares{
-			AuthMw: authMiddleware,
+			AuthMw:            authMiddleware,
+			AuthRateLimitMw:   authRateLimitMiddleware,
+			SearchRateLimitMw: searchRateLimitMiddleware,
 		},
 		Services: Services{
 			authService,
@@ -110,6 +199,21 @@ func New(conf config.Configuration) Container {
 			addressService,
 			invoiceService,
 			monobankService,
+			offerPriceService,
+			promotionService,
+			reviewService,
+			favoriteService,
+			offerAlertService,
+			notificationService,
+			webhookService,
+			orderMessageService,
+			auditService,
+			adminService,
+			twoFactorService,
+			accountService,
+			apiKeyService,
+			farmMemberService,
+			farmInvitationService,
 		},
 		Controllers: Controllers{
 			authController,
@@ -123,6 +227,19 @@ func New(conf config.Configuration) Container {
 			addressController,
 			invoiceController,
 			monobankController,
+			offerPriceController,
+			promotionController,
+			reviewController,
+			favoriteController,
+			offerAlertController,
+			notificationController,
+			orderEventController,
+			webhookController,
+			orderMessageController,
+			adminController,
+			apiKeyController,
+			farmMemberController,
+			fileController,
 		},
 	}
 }
@@ -144,3 +261,26 @@ func getDbSess(conf config.Configuration) db.Session {
 	}
 	return sess
 }
+
+func getImageStorage(conf config.Configuration) filesystem.ImageStorageService {
+	if conf.StorageBackend == "memory" {
+		return filesystem.NewMemoryStorageService(conf.FilesUrl)
+	}
+	if conf.StorageBackend != "s3" {
+		return filesystem.NewImageStorageService(conf.FileStorageLocation, conf.FilesUrl)
+	}
+
+	storage, err := filesystem.NewS3StorageService(filesystem.S3Config{
+		Endpoint:   conf.S3Endpoint,
+		Region:     conf.S3Region,
+		Bucket:     conf.S3Bucket,
+		AccessKey:  conf.S3AccessKey,
+		SecretKey:  conf.S3SecretKey,
+		UseSsl:     conf.S3UseSsl,
+		PresignTtl: conf.S3PresignTtl,
+	}, conf.FilesUrl)
+	if err != nil {
+		log.Fatalf("Unable to connect to S3 storage: %q\n", err)
+	}
+	return storage
+}
@@ -0,0 +1,247 @@
+package app
+
+import (
+	"boilerplate/internal/domain"
+	"boilerplate/internal/filesystem"
+	"boilerplate/internal/infra/database"
+	"context"
+	"errors"
+	"io"
+	"log"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const exportPageSize = 100
+
+type AccountService interface {
+	Export(user domain.User) (domain.AccountExport, error)
+	ReadImage(ctx context.Context, image domain.Image) (io.ReadCloser, error)
+	Delete(user domain.User, req domain.DeleteAccount, actor domain.Actor) error
+}
+
+type accountService struct {
+	accountRepo       database.AccountRepository
+	addressRepo       database.AddressRepository
+	orderRepo         database.OrderRepository
+	farmRepo          database.FarmRepository
+	offerRepo         database.OfferRepository
+	reviewRepo        database.ReviewRepository
+	imageStorage      filesystem.ImageStorageService
+	imageModelService ImageModelService
+	audit             AuditService
+}
+
+func NewAccountService(acr database.AccountRepository, ar database.AddressRepository, or database.OrderRepository, fr database.FarmRepository, ofr database.OfferRepository, rr database.ReviewRepository, is filesystem.ImageStorageService, ims ImageModelService, as AuditService) AccountService {
+	return accountService{
+		accountRepo:       acr,
+		addressRepo:       ar,
+		orderRepo:         or,
+		farmRepo:          fr,
+		offerRepo:         ofr,
+		reviewRepo:        rr,
+		imageStorage:      is,
+		imageModelService: ims,
+		audit:             as,
+	}
+}
+
+func (s accountService) Export(user domain.User) (domain.AccountExport, error) {
+	export := domain.AccountExport{User: user}
+
+	addresses, err := s.addressRepo.FindAllByUserId(user.Id)
+	if err != nil {
+		log.Printf("AccountService: %s", err)
+		return domain.AccountExport{}, err
+	}
+	export.Addresses = addresses
+
+	export.Orders, err = s.findOrders(user.Id)
+	if err != nil {
+		log.Printf("AccountService: %s", err)
+		return domain.AccountExport{}, err
+	}
+
+	export.Farms, err = s.findFarms(user.Id)
+	if err != nil {
+		log.Printf("AccountService: %s", err)
+		return domain.AccountExport{}, err
+	}
+
+	export.Offers, err = s.findOffers(user.Id)
+	if err != nil {
+		log.Printf("AccountService: %s", err)
+		return domain.AccountExport{}, err
+	}
+
+	for _, farm := range export.Farms {
+		images, err := s.imageModelService.FindAll("farms", farm.Id)
+		if err != nil {
+			log.Printf("AccountService: %s", err)
+			return domain.AccountExport{}, err
+		}
+		export.Images = append(export.Images, images...)
+	}
+
+	for _, offer := range export.Offers {
+		if offer.Cover.Name != "" {
+			export.Images = append(export.Images, offer.Cover)
+		}
+		images, err := s.imageModelService.FindAll("offers", offer.Id)
+		if err != nil {
+			log.Printf("AccountService: %s", err)
+			return domain.AccountExport{}, err
+		}
+		export.Images = append(export.Images, images...)
+	}
+
+	reviews, err := s.reviewRepo.FindAllByUserId(user.Id)
+	if err != nil {
+		log.Printf("AccountService: %s", err)
+		return domain.AccountExport{}, err
+	}
+	for _, review := range reviews {
+		images, err := s.imageModelService.FindAll("reviews", review.Id)
+		if err != nil {
+			log.Printf("AccountService: %s", err)
+			return domain.AccountExport{}, err
+		}
+		export.Images = append(export.Images, images...)
+	}
+
+	return export, nil
+}
+
+func (s accountService) ReadImage(ctx context.Context, image domain.Image) (io.ReadCloser, error) {
+	return s.imageStorage.ReadImage(ctx, image.Name)
+}
+
+// Delete closes the account. Farms and addresses go away, the orders stay
+// for the farmers and the accounting but lose everything personal.
+func (s accountService) Delete(user domain.User, req domain.DeleteAccount, actor domain.Actor) error {
+	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+	if err != nil {
+		return errors.New("invalid password")
+	}
+
+	farms, err := s.findFarms(user.Id)
+	if err != nil {
+		log.Printf("AccountService: %s", err)
+		return err
+	}
+
+	err = s.checkNoActiveOrders(user, farms)
+	if err != nil {
+		return err
+	}
+
+	// The photos of the farms are not referenced by anything once the farms
+	// are gone. They are collected now and removed only after the commit, a
+	// rolled back deletion must not lose them.
+	farmIds := make([]uint64, len(farms))
+	var farmImages []domain.Image
+	for i, farm := range farms {
+		farmIds[i] = farm.Id
+		images, err := s.imageModelService.FindAll("farms", farm.Id)
+		if err != nil {
+			log.Printf("AccountService: %s", err)
+			return err
+		}
+		farmImages = append(farmImages, images...)
+	}
+
+	err = s.accountRepo.Delete(user.Id, farmIds)
+	if err != nil {
+		log.Printf("AccountService: %s", err)
+		return err
+	}
+
+	for _, image := range farmImages {
+		err = s.imageModelService.Delete(image.Id)
+		if err != nil {
+			log.Printf("AccountService: image %d: %s", image.Id, err)
+		}
+	}
+
+	for _, farm := range farms {
+		_ = s.audit.Record(domain.NewAuditEvent(actor, domain.AUDIT_FARM_DELETED, domain.AUDIT_ENTITY_FARM, farm.Id).
+			WithChanges(
+				map[string]interface{}{"name": farm.Name, "user_id": farm.User.Id, "city": farm.City, "address": farm.Address},
+				map[string]interface{}{},
+			))
+	}
+	_ = s.audit.Record(domain.NewAuditEvent(actor, domain.AUDIT_USER_DELETED, domain.AUDIT_ENTITY_USER, user.Id).
+		WithChanges(
+			map[string]interface{}{"farms": len(farms), "role": user.Role},
+			map[string]interface{}{},
+		))
+	return nil
+}
+
+func (s accountService) checkNoActiveOrders(user domain.User, farms []domain.Farm) error {
+	for _, status := range domain.GetActiveOrderStatuses() {
+		orders, err := s.orderRepo.FindAllByFilter(domain.OrderFilter{UserId: user.Id, Status: status}, domain.Pagination{Page: 1, CountPerPage: 1})
+		if err != nil {
+			log.Printf("AccountService: %s", err)
+			return err
+		}
+		if orders.Total > 0 {
+			return errors.New("you can`t delete account if there is still active orders")
+		}
+	}
+
+	for _, farm := range farms {
+		orders, err := s.orderRepo.GetActiveOrdersByFarmId(farm.Id)
+		if err != nil {
+			log.Printf("AccountService: %s", err)
+			return err
+		}
+		if len(orders) > 0 {
+			return errors.New("you can`t delete account if there is still active orders")
+		}
+	}
+
+	return nil
+}
+
+func (s accountService) findOrders(userId uint64) ([]domain.Order, error) {
+	var result []domain.Order
+	for page := 1; ; page++ {
+		orders, err := s.orderRepo.FindAllByUserId(userId, domain.Pagination{Page: uint64(page), CountPerPage: exportPageSize})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, orders.Items...)
+		if uint(page) >= orders.Pages {
+			return result, nil
+		}
+	}
+}
+
+func (s accountService) findFarms(userId uint64) ([]domain.Farm, error) {
+	var result []domain.Farm
+	for page := 1; ; page++ {
+		farms, err := s.farmRepo.FindAllByFilter(domain.FarmFilter{UserId: userId}, domain.Pagination{Page: uint64(page), CountPerPage: exportPageSize})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, farms.Items...)
+		if uint(page) >= farms.Pages {
+			return result, nil
+		}
+	}
+}
+
+func (s accountService) findOffers(userId uint64) ([]domain.Offer, error) {
+	var result []domain.Offer
+	for page := 1; ; page++ {
+		offers, err := s.offerRepo.FindAllByFilter(domain.OfferFilter{UserId: userId}, domain.Pagination{Page: uint64(page), CountPerPage: exportPageSize})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, offers.Items...)
+		if uint(page) >= offers.Pages {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package app
+
+import (
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/database"
+	"errors"
+	"log"
+	"time"
+)
+
+type AdminService interface {
+	FindUsers(filter domain.UserFilter, p domain.Pagination) (domain.Users, error)
+	BlockUser(actor domain.Actor, user domain.User, reason string) (domain.User, error)
+	UnblockUser(actor domain.Actor, user domain.User) (domain.User, error)
+	SetUserRole(actor domain.Actor, user domain.User, role domain.Role) (domain.User, error)
+	FindFarms(filter domain.FarmFilter, p domain.Pagination) (domain.Farms, error)
+	FindOffers(filter domain.OfferFilter, p domain.Pagination) (domain.Offers, error)
+	UnpublishOffer(actor domain.Actor, offer domain.Offer, reason string) (domain.Offer, error)
+	FindOrders(filter domain.OrderFilter, p domain.Pagination) (domain.Orders, error)
+	ForceOrderStatus(actor domain.Actor, order domain.Order, status domain.OrderStatus, reason string) (domain.Order, error)
+	FindPayments(filter domain.PaymentFilter, p domain.Pagination) (domain.Invoices, error)
+}
+
+type adminService struct {
+	userRepo     database.UserRepository
+	sessionRepo  database.SessionRepository
+	farmRepo     database.FarmRepository
+	offerRepo    database.OfferRepository
+	orderRepo    database.OrderRepository
+	invoiceRepo  database.InvoiceRepository
+	orderService OrderService
+	webhooks     WebhookService
+	audit        AuditService
+}
+
+func NewAdminService(
+	ur database.UserRepository,
+	sr database.SessionRepository,
+	fr database.FarmRepository,
+	ofr database.OfferRepository,
+	or database.OrderRepository,
+	ir database.InvoiceRepository,
+	os OrderService,
+	ws WebhookService,
+	as AuditService,
+) AdminService {
+	return adminService{
+		userRepo:     ur,
+		sessionRepo:  sr,
+		farmRepo:     fr,
+		offerRepo:    ofr,
+		orderRepo:    or,
+		invoiceRepo:  ir,
+		orderService: os,
+		webhooks:     ws,
+		audit:        as,
+	}
+}
+
+func (s adminService) FindUsers(filter domain.UserFilter, p domain.Pagination) (domain.Users, error) {
+	users, err := s.userRepo.FindAll(filter, p)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.Users{}, err
+	}
+
+	return users, nil
+}
+
+// BlockUser stops the user from signing in and ends all of their sessions.
+func (s adminService) BlockUser(actor domain.Actor, user domain.User, reason string) (domain.User, error) {
+	if user.Id == actor.UserId {
+		return domain.User{}, errors.New("you can not block yourself")
+	}
+	if user.IsBlocked() {
+		return domain.User{}, errors.New("user is already blocked")
+	}
+
+	now := time.Now()
+	user, err := s.userRepo.SetBlocked(user.Id, &now, &reason)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.User{}, err
+	}
+
+	err = s.sessionRepo.DeleteAllByUserId(user.Id)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.User{}, err
+	}
+
+	s.record(domain.NewAuditEvent(actor, domain.AUDIT_USER_BLOCKED, domain.AUDIT_ENTITY_USER, user.Id).
+		WithChanges(map[string]interface{}{"blocked": false}, map[string]interface{}{"blocked": true}).
+		WithReason(reason))
+	return user, nil
+}
+
+func (s adminService) UnblockUser(actor domain.Actor, user domain.User) (domain.User, error) {
+	if !user.IsBlocked() {
+		return user, nil
+	}
+
+	user, err := s.userRepo.SetBlocked(user.Id, nil, nil)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.User{}, err
+	}
+
+	s.record(domain.NewAuditEvent(actor, domain.AUDIT_USER_UNBLOCKED, domain.AUDIT_ENTITY_USER, user.Id).
+		WithChanges(map[string]interface{}{"blocked": true}, map[string]interface{}{"blocked": false}))
+	return user, nil
+}
+
+func (s adminService) SetUserRole(actor domain.Actor, user domain.User, role domain.Role) (domain.User, error) {
+	if user.Id == actor.UserId {
+		return domain.User{}, errors.New("you can not change your own role")
+	}
+	if user.Role == role {
+		return user, nil
+	}
+
+	previous := user.Role
+	user, err := s.userRepo.SetRole(user.Id, role)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.User{}, err
+	}
+
+	s.record(domain.NewAuditEvent(actor, domain.AUDIT_USER_ROLE_CHANGED, domain.AUDIT_ENTITY_USER, user.Id).
+		WithChanges(map[string]interface{}{"role": previous}, map[string]interface{}{"role": role}))
+	return user, nil
+}
+
+func (s adminService) FindFarms(filter domain.FarmFilter, p domain.Pagination) (domain.Farms, error) {
+	farms, err := s.farmRepo.FindAllByFilter(filter, p)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.Farms{}, err
+	}
+
+	return farms, nil
+}
+
+func (s adminService) FindOffers(filter domain.OfferFilter, p domain.Pagination) (domain.Offers, error) {
+	offers, err := s.offerRepo.FindAllByFilter(filter, p)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.Offers{}, err
+	}
+
+	return offers, nil
+}
+
+// UnpublishOffer hides the offer from the buyers. The farmer can publish
+// it again after fixing it.
+func (s adminService) UnpublishOffer(actor domain.Actor, offer domain.Offer, reason string) (domain.Offer, error) {
+	if !offer.Status {
+		return domain.Offer{}, errors.New("offer is not published")
+	}
+
+	offer.Status = false
+	offer, err := s.offerRepo.Update(offer)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.Offer{}, err
+	}
+
+	s.webhooks.DispatchOffer(domain.WEBHOOK_OFFER_UPDATED, offer)
+	s.record(domain.NewAuditEvent(actor, domain.AUDIT_OFFER_UNPUBLISHED, domain.AUDIT_ENTITY_OFFER, offer.Id).
+		WithChanges(map[string]interface{}{"status": true}, map[string]interface{}{"status": false}).
+		WithReason(reason))
+	return offer, nil
+}
+
+func (s adminService) FindOrders(filter domain.OrderFilter, p domain.Pagination) (domain.Orders, error) {
+	orders, err := s.orderRepo.FindAllByFilter(filter, p)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.Orders{}, err
+	}
+
+	return orders, nil
+}
+
+// ForceOrderStatus moves the order to any status, bypassing the rules of
+// who may set which status. Buyers and farmers are notified as usual.
+func (s adminService) ForceOrderStatus(actor domain.Actor, order domain.Order, status domain.OrderStatus, reason string) (domain.Order, error) {
+	if order.Status == domain.DRAFT {
+		return domain.Order{}, errors.New("draft orders can not change status")
+	}
+	if order.Status == status {
+		return order, nil
+	}
+
+	// The order service logs the status change itself, this event keeps
+	// the reason of the override.
+	previous := order.Status
+	order, err := s.orderService.ChangeStatus(order, status, actor)
+	if err != nil {
+		return domain.Order{}, err
+	}
+
+	s.record(domain.NewAuditEvent(actor, domain.AUDIT_ORDER_STATUS_FORCED, domain.AUDIT_ENTITY_ORDER, order.Id).
+		WithChanges(map[string]interface{}{"status": previous}, map[string]interface{}{"status": status}).
+		WithReason(reason))
+	return order, nil
+}
+
+func (s adminService) FindPayments(filter domain.PaymentFilter, p domain.Pagination) (domain.Invoices, error) {
+	invoices, err := s.invoiceRepo.FindAllByFilter(filter, p)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.Invoices{}, err
+	}
+
+	return invoices, nil
+}
+
+// record writes the action to the audit log. A failed write is logged but
+// does not undo the action, which has already been applied.
+func (s adminService) record(event domain.AuditEvent) {
+	_ = s.audit.Record(event)
+}
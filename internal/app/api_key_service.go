@@ -0,0 +1,138 @@
+package app
+
+import (
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/database"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+const (
+	// apiKeyPrefixLength is how much of the key is kept in clear to tell
+	// the keys apart in the list.
+	apiKeyPrefixLength = 12
+	// apiKeyTouchInterval limits the writes of the last use date, a sync
+	// script can call the API many times a minute.
+	apiKeyTouchInterval = time.Minute
+)
+
+var errInvalidApiKey = errors.New("api key is invalid")
+
+type ApiKeyService interface {
+	Find(uint64) (interface{}, error)
+	Save(key domain.ApiKey) (domain.ApiKey, error)
+	FindAllByFarmId(farmId uint64) ([]domain.ApiKey, error)
+	Revoke(key domain.ApiKey) (domain.ApiKey, error)
+	Authenticate(rawKey string) (domain.ApiKey, error)
+}
+
+type apiKeyService struct {
+	apiKeyRepo database.ApiKeyRepository
+	farmRepo   database.FarmRepository
+}
+
+func NewApiKeyService(akr database.ApiKeyRepository, fr database.FarmRepository) ApiKeyService {
+	return apiKeyService{
+		apiKeyRepo: akr,
+		farmRepo:   fr,
+	}
+}
+
+func (s apiKeyService) Find(id uint64) (interface{}, error) {
+	key, err := s.apiKeyRepo.FindById(id)
+	if err != nil {
+		log.Printf("ApiKeyService -> Find: %s", err)
+		return domain.ApiKey{}, err
+	}
+	return key, nil
+}
+
+// Save generates the key. The returned value is the only one carrying the
+// key itself, later on the key can't be shown again.
+func (s apiKeyService) Save(key domain.ApiKey) (domain.ApiKey, error) {
+	secret, err := generateRefreshToken()
+	if err != nil {
+		log.Printf("ApiKeyService: %s", err)
+		return domain.ApiKey{}, err
+	}
+
+	rawKey := domain.API_KEY_PREFIX + secret
+	key.Prefix = rawKey[:apiKeyPrefixLength]
+	key.KeyHash = hashRefreshToken(rawKey)
+	key, err = s.apiKeyRepo.Save(key)
+	if err != nil {
+		log.Printf("ApiKeyService: %s", err)
+		return domain.ApiKey{}, err
+	}
+
+	key.Key = rawKey
+	return key, nil
+}
+
+func (s apiKeyService) FindAllByFarmId(farmId uint64) ([]domain.ApiKey, error) {
+	keys, err := s.apiKeyRepo.FindAllByFarmId(farmId)
+	if err != nil {
+		log.Printf("ApiKeyService: %s", err)
+		return []domain.ApiKey{}, err
+	}
+	return keys, nil
+}
+
+func (s apiKeyService) Revoke(key domain.ApiKey) (domain.ApiKey, error) {
+	if key.RevokedDate != nil {
+		return key, nil
+	}
+
+	err := s.apiKeyRepo.Revoke(key.Id)
+	if err != nil {
+		log.Printf("ApiKeyService: %s", err)
+		return domain.ApiKey{}, err
+	}
+
+	return s.apiKeyRepo.FindById(key.Id)
+}
+
+func (s apiKeyService) Authenticate(rawKey string) (domain.ApiKey, error) {
+	if !strings.HasPrefix(rawKey, domain.API_KEY_PREFIX) {
+		return domain.ApiKey{}, errInvalidApiKey
+	}
+
+	key, err := s.apiKeyRepo.FindByHash(hashRefreshToken(rawKey))
+	if err != nil {
+		if errors.Is(err, db.ErrNoMoreRows) {
+			return domain.ApiKey{}, errInvalidApiKey
+		}
+		log.Printf("ApiKeyService: %s", err)
+		return domain.ApiKey{}, err
+	}
+
+	now := time.Now()
+	if !key.IsActive(now) {
+		return domain.ApiKey{}, errors.New("api key is revoked or expired")
+	}
+
+	// The keys go with the farm, a key left over from a deleted farm
+	// must not reach the farm's orders.
+	_, err = s.farmRepo.FindById(key.FarmId)
+	if err != nil {
+		if errors.Is(err, db.ErrNoMoreRows) {
+			return domain.ApiKey{}, errInvalidApiKey
+		}
+		log.Printf("ApiKeyService: %s", err)
+		return domain.ApiKey{}, err
+	}
+
+	if key.LastUsedDate == nil || now.Sub(*key.LastUsedDate) > apiKeyTouchInterval {
+		err = s.apiKeyRepo.Touch(key.Id, now)
+		if err != nil {
+			log.Printf("ApiKeyService: %s", err)
+		}
+		key.LastUsedDate = &now
+	}
+
+	return key, nil
+}
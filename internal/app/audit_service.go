@@ -0,0 +1,45 @@
+package app
+
+import (
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/database"
+	"log"
+)
+
+type AuditService interface {
+	Record(event domain.AuditEvent) error
+	FindAll(filter domain.AuditEventFilter, p domain.Pagination) (domain.AuditEvents, error)
+}
+
+type auditService struct {
+	auditRepo database.AuditEventRepository
+}
+
+func NewAuditService(ar database.AuditEventRepository) AuditService {
+	return auditService{
+		auditRepo: ar,
+	}
+}
+
+// Record writes the event to the audit log. Callers record events after
+// the action has been applied, so a failed write is only logged and does
+// not undo the action.
+func (s auditService) Record(event domain.AuditEvent) error {
+	_, err := s.auditRepo.Save(event)
+	if err != nil {
+		log.Printf("AuditService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s auditService) FindAll(filter domain.AuditEventFilter, p domain.Pagination) (domain.AuditEvents, error) {
+	events, err := s.auditRepo.FindAll(filter, p)
+	if err != nil {
+		log.Printf("AuditService: %s", err)
+		return domain.AuditEvents{}, err
+	}
+
+	return events, nil
+}
@@ -2,10 +2,16 @@ package app
 
 import (
 	"boilerplate/config"
+	"boilerplate/internal/app/ratelimit"
 	"boilerplate/internal/domain"
 	"boilerplate/internal/infra/database"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"log"
+	"time"
 
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/google/uuid"
@@ -13,86 +19,206 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	errInvalidCredentials  = errors.New("invalid credentials")
+	errInvalidRefreshToken = errors.New("invalid refresh token")
+	errUserBlocked         = errors.New("user is blocked")
+	errPhoneNumberTaken    = errors.New("phone number is already in use")
+	errEmailTaken          = errors.New("email is already in use")
+)
+
+// maxLoginLockout caps the progressive lockout of an account or an IP.
+const maxLoginLockout = 24 * time.Hour
+
+// refreshesPerMinute limits the refreshes of one session, a client needs
+// one per JWT_TTL. Sessions are counted apart, so refreshes behind a shared
+// address don't compete with each other or with the logins.
+const refreshesPerMinute = 5
+
+// dummyPasswordHash is compared against when the account does not exist.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
 type AuthService interface {
-	Register(user domain.User) (domain.User, string, error)
-	Login(user domain.User) (domain.User, string, error)
-	LoginWithEmail(user domain.User) (domain.User, string, error)
+	Register(user domain.User, client domain.Session) (domain.User, domain.AuthTokens, error)
+	Login(user domain.User, client domain.Session) (domain.User, domain.AuthTokens, error)
+	LoginWithEmail(user domain.User, client domain.Session) (domain.User, domain.AuthTokens, error)
+	CompleteTwoFactor(req domain.TwoFactorLogin, client domain.Session) (domain.User, domain.AuthTokens, error)
+	Refresh(refreshToken string) (domain.User, domain.AuthTokens, error)
 	ChangePassword(user domain.User, req domain.ChangePassword, sess domain.Session) error
+	ForgotPassword(user domain.User) error
+	SetPhoneNumber(user domain.User, phoneNumber string) (domain.User, error)
+	SendPhoneCode(user domain.User) error
+	VerifyPhone(user domain.User, code string) (domain.User, error)
+	ChangeEmail(user domain.User, req domain.ChangeEmail) (domain.User, error)
+	SendEmailCode(user domain.User) error
+	VerifyEmail(user domain.User, code string) (domain.User, error)
+	ResetPassword(req domain.ResetPassword) error
 	Logout(sess domain.Session) error
 	Check(sess domain.Session) error
-	GenerateJwt(user domain.User) (string, error)
+	GenerateJwt(user domain.User, client domain.Session) (domain.AuthTokens, error)
+	FindSessions(userId uint64) ([]domain.Session, error)
+	RevokeSession(userId uint64, id uuid.UUID) error
+	RevokeOtherSessions(sess domain.Session) error
 }
 
 type authService struct {
 	authRepo    database.SessionRepository
 	userService UserService
+	codeService OneTimeCodeService
+	twoFactor   TwoFactorService
+	lockouts    ratelimit.Lockout
+	ipLockouts  ratelimit.Lockout
+	refreshes   ratelimit.Limiter
 	config      config.Configuration
 	tokenAuth   *jwtauth.JWTAuth
 }
 
-func NewAuthService(ar database.SessionRepository, us UserService, cf config.Configuration, ta *jwtauth.JWTAuth) AuthService {
+func NewAuthService(ar database.SessionRepository, us UserService, ocs OneTimeCodeService, tfs TwoFactorService, cf config.Configuration, ta *jwtauth.JWTAuth) AuthService {
 	return authService{
 		authRepo:    ar,
 		userService: us,
+		codeService: ocs,
+		twoFactor:   tfs,
+		lockouts:    ratelimit.NewLockout(cf.LoginMaxAttempts, cf.LoginLockout, maxLoginLockout),
+		ipLockouts:  ratelimit.NewLockout(cf.LoginIpMaxAttempts, cf.LoginLockout, maxLoginLockout),
+		refreshes:   ratelimit.NewTokenBucket(ratelimit.PerMinute(refreshesPerMinute), refreshesPerMinute),
 		config:      cf,
 		tokenAuth:   ta,
 	}
 }
 
-func (s authService) Register(user domain.User) (domain.User, string, error) {
+func (s authService) Register(user domain.User, client domain.Session) (domain.User, domain.AuthTokens, error) {
 	_, err := s.userService.FindByPhoneNumber(*user.PhoneNumber)
 	if err == nil {
 		log.Printf("invalid credentials")
-		return domain.User{}, "", errors.New("invalid credentials")
+		return domain.User{}, domain.AuthTokens{}, errInvalidCredentials
 	} else if !errors.Is(err, db.ErrNoMoreRows) {
 		log.Print(err)
-		return domain.User{}, "", err
+		return domain.User{}, domain.AuthTokens{}, err
+	}
+	err = s.checkEmailIsFree(user, user.Email)
+	if err != nil {
+		return domain.User{}, domain.AuthTokens{}, err
 	}
 	user, err = s.userService.Save(user)
 	if err != nil {
 		log.Print(err)
-		return domain.User{}, "", err
+		return domain.User{}, domain.AuthTokens{}, err
 	}
-	token, err := s.GenerateJwt(user)
-	return user, token, err
-}
 
-func (s authService) Login(user domain.User) (domain.User, string, error) {
-	u, err := s.userService.FindByPhoneNumber(*user.PhoneNumber)
+	// The account works right away, but the number can't be used to log
+	// in until it is verified.
+	err = s.SendPhoneCode(user)
 	if err != nil {
-		if errors.Is(err, db.ErrNoMoreRows) {
-			log.Printf("AuthService: failed to find user %s", err)
-		}
+		log.Printf("AuthService: failed to send phone code %s", err)
+	}
+	err = s.SendEmailCode(user)
+	if err != nil {
+		log.Printf("AuthService: failed to send email code %s", err)
+	}
+	tokens, err := s.GenerateJwt(user, client)
+	return user, tokens, err
+}
+
+func (s authService) Login(user domain.User, client domain.Session) (domain.User, domain.AuthTokens, error) {
+	return s.login("phone:"+*user.PhoneNumber, user.Password, client, func() (domain.User, error) {
+		return s.userService.FindByPhoneNumber(*user.PhoneNumber)
+	})
+}
+
+func (s authService) LoginWithEmail(user domain.User, client domain.Session) (domain.User, domain.AuthTokens, error) {
+	return s.login("email:"+user.Email, user.Password, client, func() (domain.User, error) {
+		return s.userService.FindByEmail(user.Email)
+	})
+}
+
+// login checks the password of the account found by find. Unknown accounts
+// and wrong passwords look the same to the client, and failures lock out
+// both the account and the IP the attempts come from.
+func (s authService) login(account string, password string, client domain.Session, find func() (domain.User, error)) (domain.User, domain.AuthTokens, error) {
+	ipKey := "ip:" + client.Ip
+	if err := s.ipLockouts.Check(ipKey); err != nil {
+		return domain.User{}, domain.AuthTokens{}, err
+	}
+	if err := s.lockouts.Check(account); err != nil {
+		return domain.User{}, domain.AuthTokens{}, err
+	}
+
+	u, err := find()
+	if err != nil && !errors.Is(err, db.ErrNoMoreRows) {
 		log.Printf("AuthService: login error %s", err)
-		return domain.User{}, "", err
+		return domain.User{}, domain.AuthTokens{}, err
 	}
 
-	valid := s.checkPasswordHash(user.Password, u.Password)
-	if !valid {
-		return domain.User{}, "", errors.New("invalid credentials")
+	// An unknown account still costs a hash comparison, so the response
+	// time does not tell whether the account exists.
+	hash := u.Password
+	if err != nil {
+		hash = string(dummyPasswordHash)
+	}
+	if !s.checkPasswordHash(password, hash) || err != nil {
+		s.lockouts.Fail(account)
+		s.ipLockouts.Fail(ipKey)
+		return domain.User{}, domain.AuthTokens{}, errInvalidCredentials
+	}
+
+	if u.IsBlocked() {
+		return domain.User{}, domain.AuthTokens{}, errUserBlocked
+	}
+
+	// With 2FA the password alone gives only a challenge, the session is
+	// started by CompleteTwoFactor. The lockout stays as it is until the
+	// second factor is passed too, otherwise the password would be enough
+	// to keep guessing codes.
+	if u.IsTwoFactorEnabled() {
+		challenge, err := s.twoFactor.CreateChallenge(u)
+		if err != nil {
+			return domain.User{}, domain.AuthTokens{}, err
+		}
+		return u, domain.AuthTokens{ChallengeToken: challenge}, nil
 	}
 
-	token, err := s.GenerateJwt(u)
-	return u, token, err
+	s.lockouts.Reset(account)
+
+	tokens, err := s.GenerateJwt(u, client)
+	return u, tokens, err
 }
 
-func (s authService) LoginWithEmail(user domain.User) (domain.User, string, error) {
-	u, err := s.userService.FindByEmail(user.Email)
-	if err != nil {
-		if errors.Is(err, db.ErrNoMoreRows) {
-			log.Printf("AuthService: failed to find user %s", err)
+// CompleteTwoFactor starts the session once the second factor is passed.
+// Wrong codes count against the same lockouts as wrong passwords.
+func (s authService) CompleteTwoFactor(req domain.TwoFactorLogin, client domain.Session) (domain.User, domain.AuthTokens, error) {
+	ipKey := "ip:" + client.Ip
+	if err := s.ipLockouts.Check(ipKey); err != nil {
+		return domain.User{}, domain.AuthTokens{}, err
+	}
+
+	u, err := s.twoFactor.CompleteChallenge(req, func(u domain.User) error {
+		for _, account := range accountKeys(u) {
+			if err := s.lockouts.Check(account); err != nil {
+				return err
+			}
 		}
-		log.Printf("AuthService: login error %s", err)
-		return domain.User{}, "", err
+		return nil
+	})
+	if errors.Is(err, errInvalidTwoFactor) {
+		for _, account := range accountKeys(u) {
+			s.lockouts.Fail(account)
+		}
+		s.ipLockouts.Fail(ipKey)
+		return domain.User{}, domain.AuthTokens{}, err
+	} else if err != nil {
+		return domain.User{}, domain.AuthTokens{}, err
+	}
+	for _, account := range accountKeys(u) {
+		s.lockouts.Reset(account)
 	}
 
-	valid := s.checkPasswordHash(user.Password, u.Password)
-	if !valid {
-		return domain.User{}, "", errors.New("invalid credentials")
+	if u.IsBlocked() {
+		return domain.User{}, domain.AuthTokens{}, errUserBlocked
 	}
 
-	token, err := s.GenerateJwt(u)
-	return u, token, err
+	tokens, err := s.GenerateJwt(u, client)
+	return u, tokens, err
 }
 
 func (s authService) Logout(sess domain.Session) error {
@@ -102,8 +228,7 @@ func (s authService) Logout(sess domain.Session) error {
 func (s authService) ChangePassword(user domain.User, req domain.ChangePassword, sess domain.Session) error {
 	var err error
 	if !s.checkPasswordHash(req.OldPassword, user.Password) {
-		err = errors.New("invalid credentials")
-		return err
+		return errInvalidCredentials
 	}
 
 	if s.checkPasswordHash(req.NewPassword, user.Password) {
@@ -129,31 +254,435 @@ func (s authService) ChangePassword(user domain.User, req domain.ChangePassword,
 	return nil
 }
 
-func (s authService) GenerateJwt(user domain.User) (string, error) {
-	sess := domain.Session{UserId: user.Id, UUID: uuid.New()}
+// ForgotPassword sends a password reset code by email or SMS, depending on
+// whether the user is identified by email or phone number. Unknown users
+// get no error, so the endpoint can't be used to probe for accounts. For
+// the same reason a request repeated within the resend delay gets no error
+// either, only an existing account could run into it.
+func (s authService) ForgotPassword(user domain.User) error {
+	u, channel, err := s.findForReset(user.Email, user.PhoneNumber)
+	if err != nil {
+		if errors.Is(err, db.ErrNoMoreRows) {
+			return nil
+		}
+		log.Printf("AuthService: %s", err)
+		return err
+	}
+
+	err = s.codeService.Send(u.Id, domain.CODE_PASSWORD_RESET, channel, domain.EVENT_PASSWORD_RESET)
+	if errors.Is(err, errCodeTooEarly) {
+		log.Printf("AuthService: password reset for user %d: %s", u.Id, err)
+		return nil
+	}
+	return err
+}
+
+// ResetPassword sets a new password if the reset code is valid and signs
+// the user out everywhere.
+func (s authService) ResetPassword(req domain.ResetPassword) error {
+	user, _, err := s.findForReset(req.Email, &req.PhoneNumber)
+	if err != nil {
+		if errors.Is(err, db.ErrNoMoreRows) {
+			return errInvalidCode
+		}
+		log.Printf("AuthService: %s", err)
+		return err
+	}
+
+	err = s.codeService.Verify(user.Id, domain.CODE_PASSWORD_RESET, req.Code)
+	if err != nil {
+		return err
+	}
+
+	user.Password, err = s.userService.GeneratePasswordHash(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.userService.Update(user)
+	if err != nil {
+		return err
+	}
+
+	err = s.authRepo.DeleteAllByUserId(user.Id)
+	if err != nil {
+		log.Printf("AuthService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// SetPhoneNumber replaces a missing or unverified phone number of the user
+// and sends a code to verify the new one.
+func (s authService) SetPhoneNumber(user domain.User, phoneNumber string) (domain.User, error) {
+	if user.IsPhoneVerified() {
+		return domain.User{}, errors.New("user already have a phone number")
+	}
+
+	err := s.checkPhoneNumberIsFree(user, phoneNumber)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	user.PhoneNumber = &phoneNumber
+	user.PhoneVerifiedDate = nil
+	user, err = s.userService.Update(user)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	err = s.SendPhoneCode(user)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+func (s authService) SendPhoneCode(user domain.User) error {
+	if user.PhoneNumber == nil {
+		return errors.New("user has no phone number")
+	}
+	if user.IsPhoneVerified() {
+		return errors.New("phone number is already verified")
+	}
+
+	return s.codeService.Send(user.Id, domain.CODE_PHONE_VERIFICATION, domain.CHANNEL_SMS, domain.EVENT_PHONE_VERIFICATION)
+}
+
+func (s authService) VerifyPhone(user domain.User, code string) (domain.User, error) {
+	if user.PhoneNumber == nil {
+		return domain.User{}, errors.New("user has no phone number")
+	}
+	if user.IsPhoneVerified() {
+		return user, nil
+	}
+
+	err := s.codeService.Verify(user.Id, domain.CODE_PHONE_VERIFICATION, code)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	// Someone else could have verified the same number in the meantime.
+	err = s.checkPhoneNumberIsFree(user, *user.PhoneNumber)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	// The check above can still race with another verification, the
+	// unique index on verified numbers decides then.
+	now := time.Now()
+	user.PhoneVerifiedDate = &now
+	user, err = s.userService.Update(user)
+	if database.IsUniqueViolation(err) {
+		return domain.User{}, errPhoneNumberTaken
+	}
+	return user, err
+}
+
+// ChangeEmail replaces an unverified address right away. A verified one
+// stays in use until the new address is confirmed with the code sent to
+// it.
+func (s authService) ChangeEmail(user domain.User, req domain.ChangeEmail) (domain.User, error) {
+	if user.Email == req.Email && user.IsEmailVerified() {
+		return domain.User{}, errors.New("this email is already in use by you")
+	}
+	if user.IsEmailVerified() && !s.checkPasswordHash(req.Password, user.Password) {
+		return domain.User{}, errInvalidCredentials
+	}
+
+	err := s.checkEmailIsFree(user, req.Email)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if user.IsEmailVerified() {
+		user.NewEmail = &req.Email
+	} else {
+		user.Email = req.Email
+		user.NewEmail = nil
+	}
+	user, err = s.userService.Update(user)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	err = s.SendEmailCode(user)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+func (s authService) SendEmailCode(user domain.User) error {
+	if user.NewEmail == nil {
+		if user.Email == "" {
+			return errors.New("user has no email")
+		}
+		if user.IsEmailVerified() {
+			return errors.New("email is already verified")
+		}
+	}
+
+	return s.codeService.Send(user.Id, domain.CODE_EMAIL_VERIFICATION, domain.CHANNEL_EMAIL, domain.EVENT_EMAIL_VERIFICATION)
+}
+
+// VerifyEmail confirms the pending new address, or the current one if no
+// change was requested.
+func (s authService) VerifyEmail(user domain.User, code string) (domain.User, error) {
+	if user.NewEmail == nil && user.IsEmailVerified() {
+		return user, nil
+	}
+
+	err := s.codeService.Verify(user.Id, domain.CODE_EMAIL_VERIFICATION, code)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	email := user.Email
+	if user.NewEmail != nil {
+		email = *user.NewEmail
+	}
+
+	// Someone else could have verified the same address in the meantime.
+	err = s.checkEmailIsFree(user, email)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	now := time.Now()
+	user.Email = email
+	user.EmailVerifiedDate = &now
+	user.NewEmail = nil
+	user, err = s.userService.Update(user)
+	if database.IsUniqueViolation(err) {
+		return domain.User{}, errEmailTaken
+	}
+	return user, err
+}
+
+func (s authService) checkEmailIsFree(user domain.User, email string) error {
+	owner, err := s.userService.FindByEmail(email)
+	if err == nil && owner.Id != user.Id {
+		return errEmailTaken
+	} else if err != nil && !errors.Is(err, db.ErrNoMoreRows) {
+		log.Printf("AuthService: %s", err)
+		return err
+	}
+	return nil
+}
+
+func (s authService) checkPhoneNumberIsFree(user domain.User, phoneNumber string) error {
+	owner, err := s.userService.FindByPhoneNumber(phoneNumber)
+	if err == nil && owner.Id != user.Id {
+		return errPhoneNumberTaken
+	} else if err != nil && !errors.Is(err, db.ErrNoMoreRows) {
+		log.Printf("AuthService: %s", err)
+		return err
+	}
+	return nil
+}
+
+// accountKeys returns the lockout keys of every identifier the user can
+// log in with.
+func accountKeys(u domain.User) []string {
+	keys := []string{"email:" + u.Email}
+	if u.PhoneNumber != nil {
+		keys = append(keys, "phone:"+*u.PhoneNumber)
+	}
+	return keys
+}
+
+func (s authService) findForReset(email string, phoneNumber *string) (domain.User, domain.NotificationChannel, error) {
+	if email != "" {
+		user, err := s.userService.FindByEmail(email)
+		return user, domain.CHANNEL_EMAIL, err
+	}
+	if phoneNumber != nil && *phoneNumber != "" {
+		user, err := s.userService.FindByPhoneNumber(*phoneNumber)
+		return user, domain.CHANNEL_SMS, err
+	}
+	return domain.User{}, "", db.ErrNoMoreRows
+}
+
+// GenerateJwt starts a new session for the client and issues the first
+// pair of tokens for it.
+func (s authService) GenerateJwt(user domain.User, client domain.Session) (domain.AuthTokens, error) {
+	sess := domain.Session{
+		UserId:      user.Id,
+		UUID:        uuid.New(),
+		Device:      client.Device,
+		Ip:          client.Ip,
+		UserAgent:   client.UserAgent,
+		ExpiresDate: time.Now().Add(s.config.RefreshTokenTTL),
+	}
 	err := s.authRepo.Save(sess)
 	if err != nil {
 		log.Printf("AuthService: failed to save session %s", err)
-		return "", err
+		return domain.AuthTokens{}, err
+	}
+
+	return s.issueTokens(sess)
+}
+
+// Refresh rotates the refresh token: the presented token is spent and a
+// new pair is issued for the same session. A token that was already spent
+// means it has leaked, so the whole session is revoked.
+func (s authService) Refresh(refreshToken string) (domain.User, domain.AuthTokens, error) {
+	token, err := s.authRepo.FindRefreshToken(hashRefreshToken(refreshToken))
+	if err != nil {
+		if !errors.Is(err, db.ErrNoMoreRows) {
+			log.Printf("AuthService: %s", err)
+		}
+		return domain.User{}, domain.AuthTokens{}, errInvalidRefreshToken
+	}
+
+	sess := domain.Session{UserId: token.UserId, UUID: token.SessionUUID}
+	if allowed, retryAfter := s.refreshes.Allow("session:" + sess.UUID.String()); !allowed {
+		return domain.User{}, domain.AuthTokens{}, ratelimit.LockedError{RetryAfter: retryAfter}
+	}
+	if token.IsUsed() {
+		return domain.User{}, domain.AuthTokens{}, s.revokeReused(sess)
+	}
+	if token.ExpiresDate.Before(time.Now()) {
+		return domain.User{}, domain.AuthTokens{}, errInvalidRefreshToken
+	}
+
+	spent, err := s.authRepo.MarkRefreshTokenUsed(token)
+	if err != nil {
+		log.Printf("AuthService: %s", err)
+		return domain.User{}, domain.AuthTokens{}, err
+	}
+	if !spent {
+		return domain.User{}, domain.AuthTokens{}, s.revokeReused(sess)
 	}
 
+	sess, err = s.authRepo.Find(sess.UserId, sess.UUID)
+	if err != nil || sess.IsExpired(time.Now()) {
+		return domain.User{}, domain.AuthTokens{}, errInvalidRefreshToken
+	}
+
+	user, err := s.userService.FindById(sess.UserId)
+	if err != nil {
+		log.Printf("AuthService: %s", err)
+		return domain.User{}, domain.AuthTokens{}, errInvalidRefreshToken
+	}
+	if user.IsBlocked() {
+		return domain.User{}, domain.AuthTokens{}, errUserBlocked
+	}
+
+	err = s.authRepo.Touch(sess, time.Now().Add(s.config.RefreshTokenTTL))
+	if err != nil {
+		log.Printf("AuthService: %s", err)
+		return domain.User{}, domain.AuthTokens{}, err
+	}
+
+	tokens, err := s.issueTokens(sess)
+	return user, tokens, err
+}
+
+func (s authService) Check(sess domain.Session) error {
+	err := s.authRepo.Exists(sess)
+	if err != nil {
+		return err
+	}
+
+	err = s.authRepo.Touch(sess, time.Time{})
+	if err != nil {
+		log.Printf("AuthService: failed to update session %s", err)
+	}
+	return nil
+}
+
+func (s authService) FindSessions(userId uint64) ([]domain.Session, error) {
+	sessions, err := s.authRepo.FindAllByUserId(userId)
+	if err != nil {
+		log.Printf("AuthService: %s", err)
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (s authService) RevokeSession(userId uint64, id uuid.UUID) error {
+	sess, err := s.authRepo.Find(userId, id)
+	if err != nil {
+		return err
+	}
+
+	return s.authRepo.Delete(sess)
+}
+
+func (s authService) RevokeOtherSessions(sess domain.Session) error {
+	err := s.authRepo.DeleteAllByUserIdExcept(sess.UserId, sess.UUID)
+	if err != nil {
+		log.Printf("AuthService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s authService) issueTokens(sess domain.Session) (domain.AuthTokens, error) {
 	claims := map[string]interface{}{
 		"user_id": sess.UserId,
 		"uuid":    sess.UUID,
 	}
-	jwtauth.SetExpiryIn(claims, s.config.JwtTTL)
-	_, tokenString, err := s.tokenAuth.Encode(claims)
+	expires := time.Now().Add(s.config.JwtTTL)
+	jwtauth.SetExpiry(claims, expires)
+	_, accessToken, err := s.tokenAuth.Encode(claims)
 	if err != nil {
-		return "", err
+		return domain.AuthTokens{}, err
+	}
+
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return domain.AuthTokens{}, err
+	}
+
+	_, err = s.authRepo.SaveRefreshToken(domain.RefreshToken{
+		UserId:      sess.UserId,
+		SessionUUID: sess.UUID,
+		TokenHash:   hashRefreshToken(refreshToken),
+		ExpiresDate: time.Now().Add(s.config.RefreshTokenTTL),
+	})
+	if err != nil {
+		log.Printf("AuthService: failed to save refresh token %s", err)
+		return domain.AuthTokens{}, err
 	}
 
-	return tokenString, nil
+	return domain.AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresDate:  expires,
+	}, nil
 }
 
-func (s authService) Check(sess domain.Session) error {
-	return s.authRepo.Exists(sess)
+func (s authService) revokeReused(sess domain.Session) error {
+	log.Printf("AuthService: refresh token reuse detected, revoking session %s of user %d", sess.UUID, sess.UserId)
+	err := s.authRepo.Delete(sess)
+	if err != nil {
+		log.Printf("AuthService: %s", err)
+	}
+	return errInvalidRefreshToken
 }
 
 func (s authService) checkPasswordHash(password, hash string) bool {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
 }
+
+func generateRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,84 @@
+package events
+
+import (
+	"boilerplate/internal/domain"
+	"log"
+	"sync"
+)
+
+// subscriptionBuffer is the number of events kept for a slow subscriber.
+// Events beyond it are dropped for that subscriber only.
+const subscriptionBuffer = 32
+
+// Broker delivers order events to the connected users. The in-memory
+// implementation only reaches users connected to the same instance; a
+// broker on top of Postgres LISTEN/NOTIFY can replace it without changes
+// to publishers or subscribers.
+type Broker interface {
+	Publish(event domain.OrderEvent)
+	Subscribe(userId uint64) Subscription
+}
+
+type Subscription struct {
+	Events <-chan domain.OrderEvent
+	Close  func()
+}
+
+type memoryBroker struct {
+	mu          sync.RWMutex
+	subscribers map[uint64]map[chan domain.OrderEvent]struct{}
+}
+
+func NewMemoryBroker() Broker {
+	return &memoryBroker{
+		subscribers: make(map[uint64]map[chan domain.OrderEvent]struct{}),
+	}
+}
+
+func (b *memoryBroker) Publish(event domain.OrderEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	sent := make(map[uint64]bool, len(event.Recipients))
+	for _, userId := range event.Recipients {
+		if sent[userId] {
+			continue
+		}
+		sent[userId] = true
+
+		for ch := range b.subscribers[userId] {
+			select {
+			case ch <- event:
+			default:
+				log.Printf("EventBroker: subscriber of user %d is too slow, %s of order %d dropped", userId, event.Type, event.OrderId)
+			}
+		}
+	}
+}
+
+func (b *memoryBroker) Subscribe(userId uint64) Subscription {
+	ch := make(chan domain.OrderEvent, subscriptionBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[userId] == nil {
+		b.subscribers[userId] = make(map[chan domain.OrderEvent]struct{})
+	}
+	b.subscribers[userId][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return Subscription{
+		Events: ch,
+		Close: func() {
+			once.Do(func() {
+				b.mu.Lock()
+				delete(b.subscribers[userId], ch)
+				if len(b.subscribers[userId]) == 0 {
+					delete(b.subscribers, userId)
+				}
+				b.mu.Unlock()
+				close(ch)
+			})
+		},
+	}
+}
@@ -0,0 +1,214 @@
+package app
+
+import (
+	"boilerplate/internal/app/notification"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/database"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+type FarmInvitationService interface {
+	Find(uint64) (interface{}, error)
+	Invite(farm domain.Farm, invitation domain.FarmInvitation, inviter domain.User) (domain.FarmInvitation, error)
+	FindAllByFarmId(farmId uint64) ([]domain.FarmInvitation, error)
+	FindAllForUser(user domain.User) ([]domain.FarmInvitation, error)
+	Accept(invitation domain.FarmInvitation, user domain.User) (domain.FarmMember, error)
+	Decline(invitation domain.FarmInvitation, user domain.User) (domain.FarmInvitation, error)
+	Revoke(invitation domain.FarmInvitation) (domain.FarmInvitation, error)
+}
+
+type farmInvitationService struct {
+	memberRepo    database.FarmMemberRepository
+	userRepo      database.UserRepository
+	notifications notification.Service
+}
+
+func NewFarmInvitationService(fmr database.FarmMemberRepository, ur database.UserRepository, ns notification.Service) FarmInvitationService {
+	return farmInvitationService{
+		memberRepo:    fmr,
+		userRepo:      ur,
+		notifications: ns,
+	}
+}
+
+func (s farmInvitationService) Find(id uint64) (interface{}, error) {
+	invitation, err := s.memberRepo.FindInvitationById(id)
+	if err != nil {
+		log.Printf("FarmInvitationService -> Find: %s", err)
+		return domain.FarmInvitation{}, err
+	}
+	return invitation, nil
+}
+
+// Invite records the invitation and lets the person know if they already
+// have an account with the address. Otherwise the invitation waits until
+// someone signs up and verifies it.
+func (s farmInvitationService) Invite(farm domain.Farm, invitation domain.FarmInvitation, inviter domain.User) (domain.FarmInvitation, error) {
+	if invitation.Role == domain.FARM_ROLE_OWNER {
+		return domain.FarmInvitation{}, errors.New("a farm can have only one owner")
+	}
+
+	invitee, err := s.findInvitee(invitation)
+	if err != nil {
+		log.Printf("FarmInvitationService: %s", err)
+		return domain.FarmInvitation{}, err
+	}
+	if invitee != nil {
+		_, err = s.memberRepo.FindMember(farm.Id, invitee.Id)
+		if err == nil {
+			return domain.FarmInvitation{}, errors.New("user is already a member of the farm")
+		} else if !errors.Is(err, db.ErrNoMoreRows) {
+			log.Printf("FarmInvitationService: %s", err)
+			return domain.FarmInvitation{}, err
+		}
+	}
+
+	invitation.FarmId = farm.Id
+	invitation.InvitedBy = inviter
+	invitation.ExpiresDate = time.Now().Add(domain.FarmInvitationTtl)
+	invitation, err = s.memberRepo.SaveInvitation(invitation)
+	if err != nil {
+		log.Printf("FarmInvitationService: %s", err)
+		return domain.FarmInvitation{}, err
+	}
+
+	if invitee != nil {
+		farmName := ""
+		if farm.Name != nil {
+			farmName = *farm.Name
+		}
+		s.notifications.Send(domain.EVENT_FARM_INVITATION, invitee.Id, map[string]interface{}{
+			"Farm":    farmName,
+			"Inviter": inviter.Name,
+			"Role":    string(invitation.Role),
+		})
+	}
+
+	return invitation, nil
+}
+
+func (s farmInvitationService) findInvitee(invitation domain.FarmInvitation) (*domain.User, error) {
+	var (
+		user domain.User
+		err  error
+	)
+	if invitation.Email != nil {
+		user, err = s.userRepo.FindByEmail(*invitation.Email)
+	} else {
+		user, err = s.userRepo.FindByPhoneNumber(*invitation.PhoneNumber)
+	}
+	if errors.Is(err, db.ErrNoMoreRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s farmInvitationService) FindAllByFarmId(farmId uint64) ([]domain.FarmInvitation, error) {
+	invitations, err := s.memberRepo.FindInvitationsByFarmId(farmId)
+	if err != nil {
+		log.Printf("FarmInvitationService: %s", err)
+		return []domain.FarmInvitation{}, err
+	}
+	return invitations, nil
+}
+
+// FindAllForUser returns the pending invitations to the verified addresses
+// of the user.
+func (s farmInvitationService) FindAllForUser(user domain.User) ([]domain.FarmInvitation, error) {
+	var email, phoneNumber *string
+	if user.IsEmailVerified() {
+		email = &user.Email
+	}
+	if user.IsPhoneVerified() {
+		phoneNumber = user.PhoneNumber
+	}
+
+	invitations, err := s.memberRepo.FindPendingInvitations(email, phoneNumber, time.Now())
+	if err != nil {
+		log.Printf("FarmInvitationService: %s", err)
+		return []domain.FarmInvitation{}, err
+	}
+	return invitations, nil
+}
+
+func (s farmInvitationService) Accept(invitation domain.FarmInvitation, user domain.User) (domain.FarmMember, error) {
+	err := s.checkAnswerable(invitation, user)
+	if err != nil {
+		return domain.FarmMember{}, err
+	}
+
+	_, err = s.memberRepo.FindMember(invitation.FarmId, user.Id)
+	if err == nil {
+		return domain.FarmMember{}, errors.New("you are already a member of the farm")
+	} else if !errors.Is(err, db.ErrNoMoreRows) {
+		log.Printf("FarmInvitationService: %s", err)
+		return domain.FarmMember{}, err
+	}
+
+	member, err := s.memberRepo.SaveMember(domain.FarmMember{
+		FarmId: invitation.FarmId,
+		User:   user,
+		Role:   invitation.Role,
+	})
+	if err != nil {
+		log.Printf("FarmInvitationService: %s", err)
+		return domain.FarmMember{}, err
+	}
+
+	now := time.Now()
+	invitation.AcceptedDate = &now
+	_, err = s.memberRepo.UpdateInvitation(invitation)
+	if err != nil {
+		log.Printf("FarmInvitationService: %s", err)
+		return domain.FarmMember{}, err
+	}
+
+	return member, nil
+}
+
+func (s farmInvitationService) Decline(invitation domain.FarmInvitation, user domain.User) (domain.FarmInvitation, error) {
+	err := s.checkAnswerable(invitation, user)
+	if err != nil {
+		return domain.FarmInvitation{}, err
+	}
+
+	now := time.Now()
+	invitation.DeclinedDate = &now
+	invitation, err = s.memberRepo.UpdateInvitation(invitation)
+	if err != nil {
+		log.Printf("FarmInvitationService: %s", err)
+		return domain.FarmInvitation{}, err
+	}
+	return invitation, nil
+}
+
+func (s farmInvitationService) Revoke(invitation domain.FarmInvitation) (domain.FarmInvitation, error) {
+	if !invitation.IsPending(time.Now()) {
+		return domain.FarmInvitation{}, errors.New("invitation is no longer pending")
+	}
+
+	now := time.Now()
+	invitation.RevokedDate = &now
+	invitation, err := s.memberRepo.UpdateInvitation(invitation)
+	if err != nil {
+		log.Printf("FarmInvitationService: %s", err)
+		return domain.FarmInvitation{}, err
+	}
+	return invitation, nil
+}
+
+func (s farmInvitationService) checkAnswerable(invitation domain.FarmInvitation, user domain.User) error {
+	if !invitation.IsFor(user) {
+		return errors.New("invitation was sent to someone else")
+	}
+	if !invitation.IsPending(time.Now()) {
+		return errors.New("invitation is no longer pending")
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/database"
+	"errors"
+	"log"
+
+	"github.com/upper/db/v4"
+)
+
+type FarmMemberService interface {
+	Find(uint64) (interface{}, error)
+	Can(farmId uint64, userId uint64, permission domain.FarmPermission) (bool, error)
+	FindAllByFarmId(farmId uint64) ([]domain.FarmMember, error)
+	ChangeRole(member domain.FarmMember, role domain.FarmRole) (domain.FarmMember, error)
+	Remove(member domain.FarmMember) error
+}
+
+type farmMemberService struct {
+	memberRepo database.FarmMemberRepository
+}
+
+func NewFarmMemberService(fmr database.FarmMemberRepository) FarmMemberService {
+	return farmMemberService{
+		memberRepo: fmr,
+	}
+}
+
+func (s farmMemberService) Find(id uint64) (interface{}, error) {
+	member, err := s.memberRepo.FindMemberById(id)
+	if err != nil {
+		log.Printf("FarmMemberService -> Find: %s", err)
+		return domain.FarmMember{}, err
+	}
+	return member, nil
+}
+
+// Can tells whether the user works at the farm in a role that grants the
+// permission.
+func (s farmMemberService) Can(farmId uint64, userId uint64, permission domain.FarmPermission) (bool, error) {
+	member, err := s.memberRepo.FindMember(farmId, userId)
+	if errors.Is(err, db.ErrNoMoreRows) {
+		return false, nil
+	} else if err != nil {
+		log.Printf("FarmMemberService: %s", err)
+		return false, err
+	}
+
+	return member.Role.Can(permission), nil
+}
+
+func (s farmMemberService) FindAllByFarmId(farmId uint64) ([]domain.FarmMember, error) {
+	members, err := s.memberRepo.FindMembersByFarmId(farmId)
+	if err != nil {
+		log.Printf("FarmMemberService: %s", err)
+		return []domain.FarmMember{}, err
+	}
+	return members, nil
+}
+
+func (s farmMemberService) ChangeRole(member domain.FarmMember, role domain.FarmRole) (domain.FarmMember, error) {
+	if member.Role == domain.FARM_ROLE_OWNER || role == domain.FARM_ROLE_OWNER {
+		return domain.FarmMember{}, errors.New("the owner of the farm can't be changed")
+	}
+	if member.Role == role {
+		return member, nil
+	}
+
+	member.Role = role
+	member, err := s.memberRepo.UpdateMember(member)
+	if err != nil {
+		log.Printf("FarmMemberService: %s", err)
+		return domain.FarmMember{}, err
+	}
+	return member, nil
+}
+
+func (s farmMemberService) Remove(member domain.FarmMember) error {
+	if member.Role == domain.FARM_ROLE_OWNER {
+		return errors.New("the owner can't leave the farm")
+	}
+
+	err := s.memberRepo.DeleteMember(member.Id)
+	if err != nil {
+		log.Printf("FarmMemberService: %s", err)
+		return err
+	}
+	return nil
+}
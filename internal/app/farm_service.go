@@ -11,24 +11,29 @@ type FarmService interface {
 	Save(farm domain.Farm) (domain.Farm, error)
 	FindById(id uint64) (domain.Farm, error)
 	Update(farm domain.Farm, req domain.Farm) (domain.Farm, error)
-	Delete(id uint64) error
+	Delete(farm domain.Farm, actor domain.Actor) error
 	Find(uint64) (interface{}, error)
-	FindAll(p domain.Pagination) (domain.Farms, error)
+	FindAll(user domain.User, p domain.Pagination) (domain.Farms, error)
+	FindAllByMember(user domain.User, p domain.Pagination) (domain.Farms, error)
 	FindAllByCoords(points domain.Points, p domain.Pagination) (domain.Farms, error)
 }
 
-func NewFarmService(fr database.FarmRepository, or database.OfferRepository, orr database.OrderRepository) FarmService {
+func NewFarmService(fr database.FarmRepository, or database.OfferRepository, orr database.OrderRepository, fmr database.FarmMemberRepository, as AuditService) FarmService {
 	return farmService{
-		farmRepo:  fr,
-		offerRepo: or,
-		orderRepo: orr,
+		farmRepo:   fr,
+		offerRepo:  or,
+		orderRepo:  orr,
+		memberRepo: fmr,
+		audit:      as,
 	}
 }
 
 type farmService struct {
-	farmRepo  database.FarmRepository
-	offerRepo database.OfferRepository
-	orderRepo database.OrderRepository
+	farmRepo   database.FarmRepository
+	offerRepo  database.OfferRepository
+	orderRepo  database.OrderRepository
+	memberRepo database.FarmMemberRepository
+	audit      AuditService
 }
 
 func (s farmService) Find(id uint64) (interface{}, error) {
@@ -47,6 +52,16 @@ func (s farmService) Save(farm domain.Farm) (domain.Farm, error) {
 		return domain.Farm{}, err
 	}
 
+	_, err = s.memberRepo.SaveMember(domain.FarmMember{
+		FarmId: u.Id,
+		User:   farm.User,
+		Role:   domain.FARM_ROLE_OWNER,
+	})
+	if err != nil {
+		log.Printf("FarmService: %s", err)
+		return domain.Farm{}, err
+	}
+
 	return u, err
 }
 
@@ -71,8 +86,8 @@ func (s farmService) Update(farm domain.Farm, req domain.Farm) (domain.Farm, err
 	return farm, nil
 }
 
-func (s farmService) Delete(id uint64) error {
-	activeOrders, err := s.orderRepo.GetActiveOrdersByFarmId(id)
+func (s farmService) Delete(farm domain.Farm, actor domain.Actor) error {
+	activeOrders, err := s.orderRepo.GetActiveOrdersByFarmId(farm.Id)
 	if err != nil {
 		log.Printf("FarmService: %s", err)
 		return err
@@ -82,7 +97,7 @@ func (s farmService) Delete(id uint64) error {
 		return errors.New("you can`t delete farm if there is still acitive orders")
 	}
 
-	offers, err := s.offerRepo.FindOnlyOffersByFarmId(id)
+	offers, err := s.offerRepo.FindOnlyOffersByFarmId(farm.Id)
 	if err != nil {
 		log.Printf("FarmService: %s", err)
 		return err
@@ -96,17 +111,33 @@ func (s farmService) Delete(id uint64) error {
 		}
 	}
 
-	err = s.farmRepo.Delete(id)
+	err = s.farmRepo.Delete(farm.Id)
 	if err != nil {
 		log.Printf("FarmService: %s", err)
 		return err
 	}
 
+	_ = s.audit.Record(domain.NewAuditEvent(actor, domain.AUDIT_FARM_DELETED, domain.AUDIT_ENTITY_FARM, farm.Id).
+		WithChanges(
+			map[string]interface{}{"name": farm.Name, "user_id": farm.User.Id, "city": farm.City, "address": farm.Address, "offers": len(offers)},
+			map[string]interface{}{},
+		))
 	return nil
 }
 
-func (s farmService) FindAll(p domain.Pagination) (domain.Farms, error) {
-	farms, err := s.farmRepo.FindAll(p)
+func (s farmService) FindAll(user domain.User, p domain.Pagination) (domain.Farms, error) {
+	farms, err := s.farmRepo.FindAll(user.Id, p)
+	if err != nil {
+		log.Printf("FarmService: %s", err)
+		return domain.Farms{}, err
+	}
+
+	return farms, nil
+}
+
+// FindAllByMember lists the farms the user owns or works at.
+func (s farmService) FindAllByMember(user domain.User, p domain.Pagination) (domain.Farms, error) {
+	farms, err := s.farmRepo.FindAllByFilter(domain.FarmFilter{MemberId: user.Id}, p)
 	if err != nil {
 		log.Printf("FarmService: %s", err)
 		return domain.Farms{}, err
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/database"
+	"log"
+)
+
+type FavoriteService interface {
+	AddOffer(user domain.User, offer domain.Offer) error
+	RemoveOffer(user domain.User, offer domain.Offer) error
+	FindOffers(user domain.User, p domain.Pagination) (domain.Offers, error)
+	MarkFavorites(user domain.User, offers []domain.Offer) []domain.Offer
+	FollowFarm(user domain.User, farm domain.Farm) error
+	UnfollowFarm(user domain.User, farm domain.Farm) error
+	FindFollowedFarms(user domain.User, p domain.Pagination) (domain.Farms, error)
+}
+
+type favoriteService struct {
+	favoriteRepo database.FavoriteRepository
+}
+
+func NewFavoriteService(fr database.FavoriteRepository) FavoriteService {
+	return favoriteService{
+		favoriteRepo: fr,
+	}
+}
+
+func (s favoriteService) AddOffer(user domain.User, offer domain.Offer) error {
+	err := s.favoriteRepo.AddOffer(user.Id, offer.Id)
+	if err != nil {
+		log.Printf("FavoriteService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s favoriteService) RemoveOffer(user domain.User, offer domain.Offer) error {
+	err := s.favoriteRepo.RemoveOffer(user.Id, offer.Id)
+	if err != nil {
+		log.Printf("FavoriteService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s favoriteService) FindOffers(user domain.User, p domain.Pagination) (domain.Offers, error) {
+	offers, err := s.favoriteRepo.FindOffers(user.Id, p)
+	if err != nil {
+		log.Printf("FavoriteService: %s", err)
+		return domain.Offers{}, err
+	}
+
+	return offers, nil
+}
+
+// MarkFavorites sets IsFavorite on the offers the user has added to favorites.
+// Errors are only logged, the offers are returned unmarked in that case.
+func (s favoriteService) MarkFavorites(user domain.User, offers []domain.Offer) []domain.Offer {
+	ids := make([]uint64, len(offers))
+	for i, offer := range offers {
+		ids[i] = offer.Id
+	}
+
+	favoriteIds, err := s.favoriteRepo.FindFavoriteOfferIds(user.Id, ids)
+	if err != nil {
+		log.Printf("FavoriteService: %s", err)
+		return offers
+	}
+
+	favorites := make(map[uint64]bool, len(favoriteIds))
+	for _, id := range favoriteIds {
+		favorites[id] = true
+	}
+	for i := range offers {
+		offers[i].IsFavorite = favorites[offers[i].Id]
+	}
+
+	return offers
+}
+
+func (s favoriteService) FollowFarm(user domain.User, farm domain.Farm) error {
+	err := s.favoriteRepo.FollowFarm(user.Id, farm.Id)
+	if err != nil {
+		log.Printf("FavoriteService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s favoriteService) UnfollowFarm(user domain.User, farm domain.Farm) error {
+	err := s.favoriteRepo.UnfollowFarm(user.Id, farm.Id)
+	if err != nil {
+		log.Printf("FavoriteService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s favoriteService) FindFollowedFarms(user domain.User, p domain.Pagination) (domain.Farms, error) {
+	farms, err := s.favoriteRepo.FindFollowedFarms(user.Id, p)
+	if err != nil {
+		log.Printf("FavoriteService: %s", err)
+		return domain.Farms{}, err
+	}
+
+	return farms, nil
+}
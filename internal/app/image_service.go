@@ -4,27 +4,37 @@ import (
 	"boilerplate/internal/domain"
 	"boilerplate/internal/filesystem"
 	"boilerplate/internal/infra/database"
+	"bytes"
+	"crypto/rand"
 	"encoding/base64"
+	"encoding/hex"
+	"io"
 	"log"
 )
 
 type ImageModelService interface {
 	Find(id uint64) (interface{}, error)
 	Save(imageM domain.Image) (domain.Image, error)
+	Upload(imageM domain.Image, upload domain.ImageUpload) (domain.Image, error)
 	FindAll(entity string, id uint64) ([]domain.Image, error)
 	FindById(id uint64) (domain.Image, error)
 	Delete(id uint64) error
+	StoreFile(name string, content io.Reader) (string, error)
+	RemoveFile(name string) error
+	ImageUrl(name string) string
 }
 
 type imageModelService struct {
 	imageMRepo database.ImageRepository
 	imageServ  filesystem.ImageStorageService
+	processor  filesystem.ImageProcessor
 }
 
-func NewImageModelService(ir database.ImageRepository, is filesystem.ImageStorageService) ImageModelService {
+func NewImageModelService(ir database.ImageRepository, is filesystem.ImageStorageService, ip filesystem.ImageProcessor) ImageModelService {
 	return imageModelService{
 		imageMRepo: ir,
 		imageServ:  is,
+		processor:  ip,
 	}
 }
 
@@ -35,7 +45,7 @@ func (s imageModelService) Save(image domain.Image) (domain.Image, error) {
 		return domain.Image{}, err
 	}
 
-	name, err := s.imageServ.SaveImage(image.Name, decodedBytes)
+	name, err := s.StoreFile(image.Name, bytes.NewReader(decodedBytes))
 	if err != nil {
 		log.Printf("ImageModelService: %s", err)
 		return domain.Image{}, err
@@ -45,6 +55,7 @@ func (s imageModelService) Save(image domain.Image) (domain.Image, error) {
 	savedim, err := s.imageMRepo.Save(image)
 	if err != nil {
 		log.Printf("ImageModelService: %s", err)
+		_ = s.RemoveFile(name)
 		return domain.Image{}, err
 	}
 
@@ -52,6 +63,24 @@ func (s imageModelService) Save(image domain.Image) (domain.Image, error) {
 
 }
 
+func (s imageModelService) Upload(image domain.Image, upload domain.ImageUpload) (domain.Image, error) {
+	name, err := s.StoreFile(upload.Name, upload.Content)
+	if err != nil {
+		log.Printf("ImageModelService: %s", err)
+		return domain.Image{}, err
+	}
+
+	image.Name = name
+	savedim, err := s.imageMRepo.Save(image)
+	if err != nil {
+		log.Printf("ImageModelService: %s", err)
+		_ = s.RemoveFile(name)
+		return domain.Image{}, err
+	}
+
+	return savedim, nil
+}
+
 func (s imageModelService) Find(id uint64) (interface{}, error) {
 	i, err := s.imageMRepo.FindById(id)
 	if err != nil {
@@ -88,9 +117,9 @@ func (s imageModelService) Delete(id uint64) error {
 		return err
 	}
 
-	err = s.imageServ.RemoveImage(image.Name)
+	err = s.RemoveFile(image.Name)
 	if err != nil {
-		log.Printf("OfferService: %s", err)
+		log.Printf("ImageModelService: %s", err)
 	}
 
 	err = s.imageMRepo.Delete(id)
@@ -101,3 +130,74 @@ func (s imageModelService) Delete(id uint64) error {
 
 	return nil
 }
+
+// StoreFile processes the image and stores every variant of it. The name
+// returned is the one of the full JPEG, the original file is not kept.
+func (s imageModelService) StoreFile(name string, content io.Reader) (string, error) {
+	processed, err := s.processor.Process(content)
+	if err != nil {
+		log.Printf("ImageModelService: %s", err)
+		return "", err
+	}
+
+	base, err := variantBase(name)
+	if err != nil {
+		log.Printf("ImageModelService: %s", err)
+		return "", err
+	}
+
+	stored := make([]string, 0, len(processed))
+	for _, p := range processed {
+		variantName := domain.ImageVariantName(base, p.Size, p.Format)
+		err = s.imageServ.SaveImage(variantName, bytes.NewReader(p.Content), int64(len(p.Content)), p.Format.MimeType())
+		if err != nil {
+			log.Printf("ImageModelService: %s", err)
+			for _, n := range stored {
+				_ = s.imageServ.RemoveImage(n)
+			}
+			return "", err
+		}
+		stored = append(stored, variantName)
+	}
+
+	return domain.ImageVariantName(base, domain.IMAGE_SIZE_FULL, domain.IMAGE_FORMAT_JPEG), nil
+}
+
+// RemoveFile removes the image together with its variants.
+func (s imageModelService) RemoveFile(name string) error {
+	variants := domain.Image{Name: name}.Variants()
+	if len(variants) == 0 {
+		return s.imageServ.RemoveImage(name)
+	}
+
+	var result error
+	for _, v := range variants {
+		err := s.imageServ.RemoveImage(v.Name)
+		if err != nil {
+			result = err
+		}
+	}
+	return result
+}
+
+// variantBase keeps the readable part of the file name and makes it
+// unique, all the variants share it. The name comes from the client, see
+// filesystem.SafeName.
+func variantBase(name string) (string, error) {
+	suffix := make([]byte, 4)
+	_, err := rand.Read(suffix)
+	if err != nil {
+		return "", err
+	}
+
+	return filesystem.SafeName(name) + "_" + hex.EncodeToString(suffix), nil
+}
+
+// ImageUrl is where clients fetch the stored file from, an image without a
+// file has no URL.
+func (s imageModelService) ImageUrl(name string) string {
+	if name == "" {
+		return ""
+	}
+	return s.imageServ.ImageUrl(name)
+}
@@ -9,7 +9,9 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -23,6 +25,7 @@ const (
 
 type MonobankService interface {
 	CreateInvoice(request monobank.CreateInvoiceRequest) (monobank.CreateInvoiceResponse, error)
+	CreateOrderInvoice(order domain.Order) (monobank.CreateInvoiceResponse, error)
 	GetInvoiceData(invoiceId string) (monobank.GetInvoiceDataResponse, error)
 	CancelSuccessfulInvoice(request monobank.CancelSuccessfulInvoiceRequest) (monobank.CancelSuccessfulInvoiceResponse, error)
 }
@@ -89,6 +92,26 @@ func (s monobankService) CreateInvoice(request monobank.CreateInvoiceRequest) (m
 	return createInvoiceResponse, nil
 }
 
+// CreateOrderInvoice creates an invoice for the order total, so all the
+// discounts applied to the order are reflected in the amount to pay.
+func (s monobankService) CreateOrderInvoice(order domain.Order) (monobank.CreateInvoiceResponse, error) {
+	if order.TotalPrice <= 0 {
+		return monobank.CreateInvoiceResponse{}, errors.New("order total price must be positive")
+	}
+
+	reference := strconv.FormatUint(order.Id, 10)
+	destination := fmt.Sprintf("Order #%d", order.Id)
+	request := monobank.CreateInvoiceRequest{
+		Amount: int64(math.Round(order.TotalPrice * 100)),
+		MerchantPaymInfo: &monobank.MerchantPaymInfoItem{
+			Reference:   &reference,
+			Destination: &destination,
+		},
+	}
+
+	return s.CreateInvoice(request)
+}
+
 func (s monobankService) GetInvoiceData(invoiceId string) (monobank.GetInvoiceDataResponse, error) {
 	resp, err := s.makeHttpRequest(http.MethodGet, fmt.Sprintf(apiGetInvoiceDataUrl, invoiceId), nil)
 	if err != nil {
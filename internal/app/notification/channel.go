@@ -0,0 +1,33 @@
+package notification
+
+import (
+	"boilerplate/internal/domain"
+	"log"
+)
+
+// Message is a rendered notification ready to be delivered to a user.
+type Message struct {
+	Event   domain.NotificationEvent
+	Subject string
+	Body    string
+}
+
+// Channel delivers messages to users over a single medium.
+type Channel interface {
+	Send(user domain.User, message Message) error
+}
+
+type logChannel struct {
+	channel domain.NotificationChannel
+}
+
+// NewLogChannel returns a Channel which only writes messages to the log.
+// It stands in for email and SMS delivery when they are not configured.
+func NewLogChannel(channel domain.NotificationChannel) Channel {
+	return logChannel{channel: channel}
+}
+
+func (c logChannel) Send(user domain.User, message Message) error {
+	log.Printf("Notification: %s to user %d: %s: %s", c.channel, user.Id, message.Subject, message.Body)
+	return nil
+}
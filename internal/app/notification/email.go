@@ -0,0 +1,110 @@
+package notification
+
+import (
+	"boilerplate/internal/domain"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+const smtpTimeout = 10 * time.Second
+
+type emailChannel struct {
+	addr     string
+	host     string
+	user     string
+	password string
+	from     string
+}
+
+// NewEmailChannel returns a Channel which sends emails over SMTP.
+// Authentication is skipped when the user is empty, which is how
+// local catchers like MailHog are used.
+func NewEmailChannel(host string, port string, user string, password string, from string) Channel {
+	return emailChannel{
+		addr:     net.JoinHostPort(host, port),
+		host:     host,
+		user:     user,
+		password: password,
+		from:     from,
+	}
+}
+
+func (c emailChannel) Send(user domain.User, message Message) error {
+	if user.Email == "" {
+		return errors.New("user has no email")
+	}
+
+	conn, err := net.DialTimeout("tcp", c.addr, smtpTimeout)
+	if err != nil {
+		return err
+	}
+	err = conn.SetDeadline(time.Now().Add(smtpTimeout))
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, c.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err = client.StartTLS(nil)
+		if err != nil {
+			return err
+		}
+	}
+	if c.user != "" {
+		err = client.Auth(smtp.PlainAuth("", c.user, c.password, c.host))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = client.Mail(c.from)
+	if err != nil {
+		return err
+	}
+	err = client.Rcpt(user.Email)
+	if err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(c.compose(user.Email, message))
+	if err != nil {
+		return err
+	}
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (c emailChannel) compose(to string, message Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", c.from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,26 @@
+package notification
+
+import (
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/database"
+)
+
+type inAppChannel struct {
+	notificationRepo database.NotificationRepository
+}
+
+// NewInAppChannel returns a Channel which stores messages in the
+// notifications feed of the user.
+func NewInAppChannel(nr database.NotificationRepository) Channel {
+	return inAppChannel{notificationRepo: nr}
+}
+
+func (c inAppChannel) Send(user domain.User, message Message) error {
+	_, err := c.notificationRepo.Save(domain.Notification{
+		User:    user,
+		Event:   message.Event,
+		Subject: message.Subject,
+		Body:    message.Body,
+	})
+	return err
+}
@@ -0,0 +1,140 @@
+package notification
+
+import (
+	"boilerplate/internal/domain"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// FindPreferences returns the preferences of the user for every event and
+// channel. Events without a stored preference show their defaults.
+func (s service) FindPreferences(userId uint64) ([]domain.NotificationPreference, error) {
+	stored, err := s.preferenceRepo.FindAllByUserId(userId)
+	if err != nil {
+		log.Printf("NotificationService: %s", err)
+		return []domain.NotificationPreference{}, err
+	}
+
+	return s.mergeDefaults(userId, stored), nil
+}
+
+func (s service) SavePreferences(userId uint64, preferences []domain.NotificationPreference) ([]domain.NotificationPreference, error) {
+	for _, preference := range preferences {
+		err := validatePreference(preference)
+		if err != nil {
+			return []domain.NotificationPreference{}, err
+		}
+	}
+
+	for _, preference := range preferences {
+		preference.User = domain.User{Id: userId}
+		_, err := s.preferenceRepo.Upsert(preference)
+		if err != nil {
+			log.Printf("NotificationService: %s", err)
+			return []domain.NotificationPreference{}, err
+		}
+	}
+
+	return s.FindPreferences(userId)
+}
+
+// enabledPreferences returns the preferences of the user that let the
+// event through, one for each channel it goes over.
+func (s service) enabledPreferences(event domain.NotificationEvent, userId uint64) ([]domain.NotificationPreference, error) {
+	stored, err := s.preferenceRepo.FindAllByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var preferences []domain.NotificationPreference
+	for _, preference := range s.mergeDefaults(userId, stored) {
+		if preference.Event == event && preference.Enabled {
+			preferences = append(preferences, preference)
+		}
+	}
+	return preferences, nil
+}
+
+// sendDate tells when a message let through by the preference can go out,
+// which is after the quiet hours. Quiet hours do not apply to the in-app
+// feed as it never disturbs anyone.
+func (s service) sendDate(preference domain.NotificationPreference, now time.Time) time.Time {
+	if preference.Channel == domain.CHANNEL_IN_APP {
+		return now
+	}
+	// Back in the zone of now, the dates are stored without one.
+	return preference.QuietUntil(now.In(s.location)).In(now.Location())
+}
+
+func (s service) mergeDefaults(userId uint64, stored []domain.NotificationPreference) []domain.NotificationPreference {
+	type key struct {
+		event   domain.NotificationEvent
+		channel domain.NotificationChannel
+	}
+	byKey := make(map[key]domain.NotificationPreference, len(stored))
+	for _, preference := range stored {
+		byKey[key{preference.Event, preference.Channel}] = preference
+	}
+
+	var result []domain.NotificationPreference
+	for _, event := range domain.GetNotificationEvents() {
+		for _, channel := range domain.GetNotificationChannels() {
+			preference, ok := byKey[key{event, channel}]
+			if !ok {
+				preference = domain.NotificationPreference{
+					User:    domain.User{Id: userId},
+					Event:   event,
+					Channel: channel,
+					Enabled: isDefaultChannel(event, channel),
+				}
+			}
+			result = append(result, preference)
+		}
+	}
+	return result
+}
+
+func isDefaultChannel(event domain.NotificationEvent, channel domain.NotificationChannel) bool {
+	for _, c := range eventTemplates[event].Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+func validatePreference(preference domain.NotificationPreference) error {
+	if !isConfigurableEvent(preference.Event) {
+		return fmt.Errorf("unknown notification event %s", preference.Event)
+	}
+	if !isKnownChannel(preference.Channel) {
+		return fmt.Errorf("unknown notification channel %s", preference.Channel)
+	}
+	if (preference.QuietHoursStart == nil) != (preference.QuietHoursEnd == nil) {
+		return errors.New("both start and end of quiet hours are required")
+	}
+	if preference.QuietHoursStart != nil && (*preference.QuietHoursStart >= 24*60 || *preference.QuietHoursEnd >= 24*60) {
+		return errors.New("quiet hours must be within a day")
+	}
+	return nil
+}
+
+func isConfigurableEvent(event domain.NotificationEvent) bool {
+	for _, e := range domain.GetNotificationEvents() {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func isKnownChannel(channel domain.NotificationChannel) bool {
+	for _, c := range domain.GetNotificationChannels() {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,339 @@
+package notification
+
+import (
+	"boilerplate/config"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/database"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+type Service interface {
+	Find(uint64) (interface{}, error)
+	Send(event domain.NotificationEvent, userId uint64, data map[string]interface{})
+	Enqueue(channel domain.NotificationChannel, event domain.NotificationEvent, userId uint64, data map[string]interface{}) error
+	Deliver(channel domain.NotificationChannel, event domain.NotificationEvent, userId uint64, data map[string]interface{}) error
+	FindAllByUserId(userId uint64, unreadOnly bool, p domain.Pagination) (domain.Notifications, error)
+	CountUnread(userId uint64) (uint64, error)
+	MarkAsRead(n domain.Notification) (domain.Notification, error)
+	MarkAllAsRead(userId uint64) error
+	FindPreferences(userId uint64) ([]domain.NotificationPreference, error)
+	SavePreferences(userId uint64, preferences []domain.NotificationPreference) ([]domain.NotificationPreference, error)
+	ProcessQueue(now time.Time) error
+	Run(ctx context.Context, interval time.Duration)
+}
+
+const (
+	queueBatchSize  = 50
+	queueClaimLease = 15 * time.Minute // outlasts sending a whole batch
+)
+
+type service struct {
+	notificationRepo database.NotificationRepository
+	preferenceRepo   database.NotificationPreferenceRepository
+	queueRepo        database.QueuedNotificationRepository
+	userRepo         database.UserRepository
+	channels         map[domain.NotificationChannel]Channel
+	defaultLanguage  string
+	location         *time.Location
+}
+
+// NewService wires the delivery channels from the configuration. Email and
+// SMS fall back to the log when their servers are not configured.
+func NewService(nr database.NotificationRepository, npr database.NotificationPreferenceRepository, qnr database.QueuedNotificationRepository, ur database.UserRepository, conf config.Configuration) Service {
+	channels := map[domain.NotificationChannel]Channel{
+		domain.CHANNEL_EMAIL:  NewLogChannel(domain.CHANNEL_EMAIL),
+		domain.CHANNEL_SMS:    NewLogChannel(domain.CHANNEL_SMS),
+		domain.CHANNEL_IN_APP: NewInAppChannel(nr),
+	}
+	if conf.SmtpHost != "" {
+		channels[domain.CHANNEL_EMAIL] = NewEmailChannel(conf.SmtpHost, conf.SmtpPort, conf.SmtpUser, conf.SmtpPassword, conf.SmtpFrom)
+	}
+	if conf.SmsGatewayUrl != "" {
+		channels[domain.CHANNEL_SMS] = NewSmsChannel(conf.SmsGatewayUrl, conf.SmsGatewayToken)
+	}
+
+	location, err := time.LoadLocation(conf.Timezone)
+	if err != nil {
+		log.Printf("NotificationService: %s, using local time for quiet hours", err)
+		location = time.Local
+	}
+
+	return service{
+		notificationRepo: nr,
+		preferenceRepo:   npr,
+		queueRepo:        qnr,
+		userRepo:         ur,
+		channels:         channels,
+		defaultLanguage:  conf.DefaultLanguage,
+		location:         location,
+	}
+}
+
+func (s service) Find(id uint64) (interface{}, error) {
+	n, err := s.notificationRepo.FindById(id)
+	if err != nil {
+		log.Printf("NotificationService -> Find: %s", err)
+		return domain.Notification{}, err
+	}
+	return n, err
+}
+
+// Send delivers the event to the user over the channels allowed by the
+// preferences of the user, or the default channels of the event.
+// Email and SMS are sent in the background so slow servers do not hold
+// up the request; delivery errors are only logged. During the quiet hours
+// of a channel they are queued until the quiet hours end.
+func (s service) Send(event domain.NotificationEvent, userId uint64, data map[string]interface{}) {
+	preferences, err := s.enabledPreferences(event, userId)
+	if err != nil {
+		log.Printf("NotificationService: %s", err)
+		return
+	}
+	if len(preferences) == 0 {
+		return
+	}
+
+	user, message, err := s.prepare(event, userId, data)
+	if err != nil {
+		log.Printf("NotificationService: %s", err)
+		return
+	}
+
+	now := time.Now()
+	for _, preference := range preferences {
+		channel := preference.Channel
+		if channel == domain.CHANNEL_EMAIL && !user.IsEmailVerified() {
+			continue
+		}
+		if sendDate := s.sendDate(preference, now); sendDate.After(now) {
+			_ = s.queue(channel, user, message, sendDate)
+			continue
+		}
+		if channel == domain.CHANNEL_IN_APP {
+			s.deliver(channel, user, message)
+			continue
+		}
+		go s.deliver(channel, user, message)
+	}
+}
+
+// Enqueue puts the event for the user into the queue over a single channel
+// chosen by the user, e.g. the channel of an offer alert. The queue is
+// processed by Run, so a fan-out to many users costs the caller one insert
+// per user and not a round trip to the mail server.
+// Nothing is sent when the user has muted the event on that channel, and
+// the message waits in the queue during its quiet hours.
+func (s service) Enqueue(channel domain.NotificationChannel, event domain.NotificationEvent, userId uint64, data map[string]interface{}) error {
+	preference, err := s.preferenceRepo.FindByUserEventAndChannel(userId, event, channel)
+	if errors.Is(err, db.ErrNoMoreRows) {
+		preference = domain.NotificationPreference{Channel: channel, Enabled: true}
+	} else if err != nil {
+		log.Printf("NotificationService: %s", err)
+		return err
+	}
+	if !preference.Enabled {
+		return nil
+	}
+
+	user, message, err := s.prepare(event, userId, data)
+	if err != nil {
+		log.Printf("NotificationService: %s", err)
+		return err
+	}
+	if channel == domain.CHANNEL_EMAIL && !user.IsEmailVerified() {
+		return nil
+	}
+
+	return s.queue(channel, user, message, s.sendDate(preference, time.Now()))
+}
+
+// Deliver sends the event over the channel right away, ignoring the
+// preferences of the user. It is meant for security messages such as
+// one-time codes, which must not be muted or delayed.
+func (s service) Deliver(channel domain.NotificationChannel, event domain.NotificationEvent, userId uint64, data map[string]interface{}) error {
+	user, message, err := s.prepare(event, userId, data)
+	if err != nil {
+		log.Printf("NotificationService: %s", err)
+		return err
+	}
+
+	// The verification code goes to the address being verified, which is
+	// the new one during an email change. Anything else is emailed only
+	// to a verified address.
+	if channel == domain.CHANNEL_EMAIL {
+		if event == domain.EVENT_EMAIL_VERIFICATION && user.NewEmail != nil {
+			user.Email = *user.NewEmail
+		} else if event != domain.EVENT_EMAIL_VERIFICATION && !user.IsEmailVerified() {
+			return errors.New("email is not verified")
+		}
+	}
+
+	return s.deliver(channel, user, message)
+}
+
+func (s service) FindAllByUserId(userId uint64, unreadOnly bool, p domain.Pagination) (domain.Notifications, error) {
+	notifications, err := s.notificationRepo.FindAllByUserId(userId, unreadOnly, p)
+	if err != nil {
+		log.Printf("NotificationService: %s", err)
+		return domain.Notifications{}, err
+	}
+
+	return notifications, nil
+}
+
+func (s service) CountUnread(userId uint64) (uint64, error) {
+	count, err := s.notificationRepo.CountUnread(userId)
+	if err != nil {
+		log.Printf("NotificationService: %s", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (s service) MarkAsRead(n domain.Notification) (domain.Notification, error) {
+	if n.IsRead() {
+		return n, nil
+	}
+
+	err := s.notificationRepo.MarkAsRead(n.Id)
+	if err != nil {
+		log.Printf("NotificationService: %s", err)
+		return domain.Notification{}, err
+	}
+
+	n, err = s.notificationRepo.FindById(n.Id)
+	if err != nil {
+		log.Printf("NotificationService: %s", err)
+		return domain.Notification{}, err
+	}
+
+	return n, nil
+}
+
+func (s service) MarkAllAsRead(userId uint64) error {
+	err := s.notificationRepo.MarkAllAsRead(userId)
+	if err != nil {
+		log.Printf("NotificationService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// ProcessQueue sends the queued messages that are due: the offer alerts
+// and the messages whose quiet hours are over. Each batch is claimed
+// first, so several instances can process at once.
+func (s service) ProcessQueue(now time.Time) error {
+	for {
+		queued, err := s.queueRepo.ClaimDue(now, now.Add(queueClaimLease), queueBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, n := range queued {
+			s.sendQueued(n)
+		}
+
+		if len(queued) < queueBatchSize {
+			return nil
+		}
+	}
+}
+
+// Run processes the queue every interval until ctx is cancelled.
+func (s service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			err := s.ProcessQueue(now)
+			if err != nil {
+				log.Printf("NotificationService: %s", err)
+			}
+		}
+	}
+}
+
+// sendQueued is sent once, as the messages sent right away are. The user
+// is read again since the address could have changed in the meantime.
+func (s service) sendQueued(n domain.QueuedNotification) {
+	user, err := s.userRepo.FindById(n.User.Id)
+	if err != nil && !errors.Is(err, db.ErrNoMoreRows) {
+		// Claimed again after the lease.
+		log.Printf("NotificationService: %s", err)
+		return
+	}
+
+	if err == nil && (n.Channel != domain.CHANNEL_EMAIL || user.IsEmailVerified()) {
+		_ = s.deliver(n.Channel, user, Message{Event: n.Event, Subject: n.Subject, Body: n.Body})
+	}
+
+	err = s.queueRepo.Delete(n.Id)
+	if err != nil {
+		log.Printf("NotificationService: %s", err)
+	}
+}
+
+func (s service) queue(channel domain.NotificationChannel, user domain.User, message Message, sendDate time.Time) error {
+	_, err := s.queueRepo.Save(domain.QueuedNotification{
+		User:     user,
+		Event:    message.Event,
+		Channel:  channel,
+		Subject:  message.Subject,
+		Body:     message.Body,
+		SendDate: sendDate,
+	})
+	if err != nil {
+		log.Printf("NotificationService: %s", err)
+		return err
+	}
+	return nil
+}
+
+func (s service) prepare(event domain.NotificationEvent, userId uint64, data map[string]interface{}) (domain.User, Message, error) {
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return domain.User{}, Message{}, err
+	}
+
+	values := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		values[k] = v
+	}
+	values["Name"] = user.Name
+
+	message, err := render(event, user.Language, s.defaultLanguage, values)
+	if err != nil {
+		return domain.User{}, Message{}, err
+	}
+
+	return user, message, nil
+}
+
+func (s service) deliver(channel domain.NotificationChannel, user domain.User, message Message) error {
+	c, ok := s.channels[channel]
+	if !ok {
+		err := fmt.Errorf("unknown notification channel %s", channel)
+		log.Printf("NotificationService: %s", err)
+		return err
+	}
+
+	err := c.Send(user, message)
+	if err != nil {
+		log.Printf("NotificationService: %s to user %d: %s", channel, user.Id, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package notification
+
+import (
+	"boilerplate/internal/domain"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type smsChannel struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+type smsRequest struct {
+	Phone string `json:"phone"`
+	Text  string `json:"text"`
+}
+
+// NewSmsChannel returns a Channel which posts messages to an HTTP SMS
+// gateway as JSON {"phone": ..., "text": ...} with a bearer token.
+func NewSmsChannel(url string, token string) Channel {
+	return smsChannel{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (c smsChannel) Send(user domain.User, message Message) error {
+	if user.PhoneNumber == nil || *user.PhoneNumber == "" {
+		return errors.New("user has no phone number")
+	}
+
+	body, err := json.Marshal(smsRequest{Phone: *user.PhoneNumber, Text: message.Body})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("sms gateway responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
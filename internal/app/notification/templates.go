@@ -0,0 +1,209 @@
+package notification
+
+import (
+	"boilerplate/internal/domain"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+type eventTemplate struct {
+	// Channels the event is delivered to by default.
+	Channels []domain.NotificationChannel
+	Subjects map[string]string
+	Bodies   map[string]string
+}
+
+// Templates are rendered with text/template. The data passed to Send is
+// available in them, and .Name always holds the name of the recipient.
+var eventTemplates = map[domain.NotificationEvent]eventTemplate{
+	domain.EVENT_ORDER_SUBMITTED: {
+		Channels: []domain.NotificationChannel{domain.CHANNEL_EMAIL, domain.CHANNEL_IN_APP},
+		Subjects: map[string]string{
+			domain.LANGUAGE_UK: "Нове замовлення №{{.OrderId}}",
+			domain.LANGUAGE_EN: "New order #{{.OrderId}}",
+		},
+		Bodies: map[string]string{
+			domain.LANGUAGE_UK: "{{.Name}}, ви отримали нове замовлення №{{.OrderId}} на суму {{printf \"%.2f\" .TotalPrice}} грн. Підтвердіть або відхиліть його.",
+			domain.LANGUAGE_EN: "{{.Name}}, you have received a new order #{{.OrderId}} for {{printf \"%.2f\" .TotalPrice}} UAH. Please approve or decline it.",
+		},
+	},
+	domain.EVENT_ORDER_APPROVED: {
+		Channels: []domain.NotificationChannel{domain.CHANNEL_EMAIL, domain.CHANNEL_IN_APP},
+		Subjects: map[string]string{
+			domain.LANGUAGE_UK: "Замовлення №{{.OrderId}} підтверджено",
+			domain.LANGUAGE_EN: "Order #{{.OrderId}} approved",
+		},
+		Bodies: map[string]string{
+			domain.LANGUAGE_UK: "{{.Name}}, фермер підтвердив ваше замовлення №{{.OrderId}}.",
+			domain.LANGUAGE_EN: "{{.Name}}, the farmer has approved your order #{{.OrderId}}.",
+		},
+	},
+	domain.EVENT_ORDER_DECLINED: {
+		Channels: []domain.NotificationChannel{domain.CHANNEL_EMAIL, domain.CHANNEL_SMS, domain.CHANNEL_IN_APP},
+		Subjects: map[string]string{
+			domain.LANGUAGE_UK: "Замовлення №{{.OrderId}} відхилено",
+			domain.LANGUAGE_EN: "Order #{{.OrderId}} declined",
+		},
+		Bodies: map[string]string{
+			domain.LANGUAGE_UK: "{{.Name}}, на жаль, фермер відхилив ваше замовлення №{{.OrderId}}.",
+			domain.LANGUAGE_EN: "{{.Name}}, unfortunately the farmer has declined your order #{{.OrderId}}.",
+		},
+	},
+	domain.EVENT_ORDER_SHIPPING: {
+		Channels: []domain.NotificationChannel{domain.CHANNEL_EMAIL, domain.CHANNEL_SMS, domain.CHANNEL_IN_APP},
+		Subjects: map[string]string{
+			domain.LANGUAGE_UK: "Замовлення №{{.OrderId}} відправлено",
+			domain.LANGUAGE_EN: "Order #{{.OrderId}} shipped",
+		},
+		Bodies: map[string]string{
+			domain.LANGUAGE_UK: "{{.Name}}, ваше замовлення №{{.OrderId}} відправлено.{{if .Ttn}} ТТН: {{.Ttn}}.{{end}}",
+			domain.LANGUAGE_EN: "{{.Name}}, your order #{{.OrderId}} has been shipped.{{if .Ttn}} Tracking number: {{.Ttn}}.{{end}}",
+		},
+	},
+	domain.EVENT_ORDER_COMPLETED: {
+		Channels: []domain.NotificationChannel{domain.CHANNEL_EMAIL, domain.CHANNEL_IN_APP},
+		Subjects: map[string]string{
+			domain.LANGUAGE_UK: "Замовлення №{{.OrderId}} отримано",
+			domain.LANGUAGE_EN: "Order #{{.OrderId}} received",
+		},
+		Bodies: map[string]string{
+			domain.LANGUAGE_UK: "{{.Name}}, покупець отримав замовлення №{{.OrderId}}.",
+			domain.LANGUAGE_EN: "{{.Name}}, the buyer has received order #{{.OrderId}}.",
+		},
+	},
+	domain.EVENT_ORDER_MESSAGE: {
+		Channels: []domain.NotificationChannel{domain.CHANNEL_EMAIL, domain.CHANNEL_IN_APP},
+		Subjects: map[string]string{
+			domain.LANGUAGE_UK: "Нове повідомлення щодо замовлення №{{.OrderId}}",
+			domain.LANGUAGE_EN: "New message about order #{{.OrderId}}",
+		},
+		Bodies: map[string]string{
+			domain.LANGUAGE_UK: "{{.Name}}, {{.Sender}} пише щодо замовлення №{{.OrderId}}: {{.Text}}",
+			domain.LANGUAGE_EN: "{{.Name}}, {{.Sender}} wrote about order #{{.OrderId}}: {{.Text}}",
+		},
+	},
+	domain.EVENT_PASSWORD_RESET: {
+		Channels: []domain.NotificationChannel{domain.CHANNEL_EMAIL, domain.CHANNEL_SMS},
+		Subjects: map[string]string{
+			domain.LANGUAGE_UK: "Відновлення пароля",
+			domain.LANGUAGE_EN: "Password reset",
+		},
+		Bodies: map[string]string{
+			domain.LANGUAGE_UK: "{{.Name}}, ваш код для відновлення пароля: {{.Code}}. Він дійсний {{.Minutes}} хв. Якщо ви не запитували відновлення, просто проігноруйте це повідомлення.",
+			domain.LANGUAGE_EN: "{{.Name}}, your password reset code is {{.Code}}. It is valid for {{.Minutes}} min. If you did not request a reset, just ignore this message.",
+		},
+	},
+	domain.EVENT_PHONE_VERIFICATION: {
+		Channels: []domain.NotificationChannel{domain.CHANNEL_SMS},
+		Subjects: map[string]string{
+			domain.LANGUAGE_UK: "Підтвердження номера телефону",
+			domain.LANGUAGE_EN: "Phone number verification",
+		},
+		Bodies: map[string]string{
+			domain.LANGUAGE_UK: "Код підтвердження номера: {{.Code}}. Він дійсний {{.Minutes}} хв.",
+			domain.LANGUAGE_EN: "Your verification code is {{.Code}}. It is valid for {{.Minutes}} min.",
+		},
+	},
+	domain.EVENT_EMAIL_VERIFICATION: {
+		Channels: []domain.NotificationChannel{domain.CHANNEL_EMAIL},
+		Subjects: map[string]string{
+			domain.LANGUAGE_UK: "Підтвердження електронної пошти",
+			domain.LANGUAGE_EN: "Email verification",
+		},
+		Bodies: map[string]string{
+			domain.LANGUAGE_UK: "{{.Name}}, ваш код підтвердження електронної пошти: {{.Code}}. Він дійсний {{.Minutes}} хв. Якщо ви не реєструвалися, просто проігноруйте цей лист.",
+			domain.LANGUAGE_EN: "{{.Name}}, your email verification code is {{.Code}}. It is valid for {{.Minutes}} min. If you did not sign up, just ignore this email.",
+		},
+	},
+	domain.EVENT_OFFER_BACK_IN_STOCK: {
+		Channels: []domain.NotificationChannel{domain.CHANNEL_IN_APP},
+		Subjects: map[string]string{
+			domain.LANGUAGE_UK: "Знову в наявності",
+			domain.LANGUAGE_EN: "Back in stock",
+		},
+		Bodies: map[string]string{
+			domain.LANGUAGE_UK: "{{.Name}}, \"{{.Title}}\" знову в наявності.",
+			domain.LANGUAGE_EN: "{{.Name}}, \"{{.Title}}\" is back in stock.",
+		},
+	},
+	domain.EVENT_OFFER_PRICE_DROP: {
+		Channels: []domain.NotificationChannel{domain.CHANNEL_IN_APP},
+		Subjects: map[string]string{
+			domain.LANGUAGE_UK: "Ціна знизилась",
+			domain.LANGUAGE_EN: "Price drop",
+		},
+		Bodies: map[string]string{
+			domain.LANGUAGE_UK: "{{.Name}}, ціна на \"{{.Title}}\" знизилась до {{printf \"%.2f\" .Price}} грн.",
+			domain.LANGUAGE_EN: "{{.Name}}, the price of \"{{.Title}}\" dropped to {{printf \"%.2f\" .Price}} UAH.",
+		},
+	},
+	domain.EVENT_FARM_INVITATION: {
+		Channels: []domain.NotificationChannel{domain.CHANNEL_EMAIL, domain.CHANNEL_SMS, domain.CHANNEL_IN_APP},
+		Subjects: map[string]string{
+			domain.LANGUAGE_UK: "Запрошення до господарства",
+			domain.LANGUAGE_EN: "Farm invitation",
+		},
+		Bodies: map[string]string{
+			domain.LANGUAGE_UK: "{{.Name}}, {{.Inviter}} запрошує вас до господарства \"{{.Farm}}\". Прийміть запрошення в застосунку.",
+			domain.LANGUAGE_EN: "{{.Name}}, {{.Inviter}} invites you to join the farm \"{{.Farm}}\". Accept the invitation in the app.",
+		},
+	},
+}
+
+var parsedTemplates = parseTemplates()
+
+func parseTemplates() map[string]*template.Template {
+	result := make(map[string]*template.Template)
+	for event, t := range eventTemplates {
+		for lang, text := range t.Subjects {
+			name := templateName(event, lang, "subject")
+			result[name] = template.Must(template.New(name).Option("missingkey=zero").Parse(text))
+		}
+		for lang, text := range t.Bodies {
+			name := templateName(event, lang, "body")
+			result[name] = template.Must(template.New(name).Option("missingkey=zero").Parse(text))
+		}
+	}
+	return result
+}
+
+func templateName(event domain.NotificationEvent, lang string, part string) string {
+	return fmt.Sprintf("%s.%s.%s", event, lang, part)
+}
+
+// render builds the message of the event in the given language, falling
+// back to the default language when there is no translation.
+func render(event domain.NotificationEvent, lang string, defaultLang string, data map[string]interface{}) (Message, error) {
+	if _, ok := eventTemplates[event]; !ok {
+		return Message{}, fmt.Errorf("no templates for event %s", event)
+	}
+	if _, ok := parsedTemplates[templateName(event, lang, "body")]; !ok {
+		lang = defaultLang
+	}
+
+	subject, err := execute(templateName(event, lang, "subject"), data)
+	if err != nil {
+		return Message{}, err
+	}
+	body, err := execute(templateName(event, lang, "body"), data)
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{Event: event, Subject: subject, Body: body}, nil
+}
+
+func execute(name string, data map[string]interface{}) (string, error) {
+	t, ok := parsedTemplates[name]
+	if !ok {
+		return "", fmt.Errorf("template %s not found", name)
+	}
+
+	var b strings.Builder
+	err := t.Execute(&b, data)
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
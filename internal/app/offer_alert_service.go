@@ -0,0 +1,177 @@
+package app
+
+import (
+	"boilerplate/internal/app/notification"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/database"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+type OfferAlertService interface {
+	Find(uint64) (interface{}, error)
+	Subscribe(alert domain.OfferAlert) (domain.OfferAlert, error)
+	Unsubscribe(alert domain.OfferAlert) error
+	FindAllByUserId(userId uint64) ([]domain.OfferAlert, error)
+	Evaluate(before domain.Offer, after domain.Offer)
+}
+
+type offerAlertService struct {
+	offerAlertRepo database.OfferAlertRepository
+	notifications  notification.Service
+}
+
+func NewOfferAlertService(oar database.OfferAlertRepository, ns notification.Service) OfferAlertService {
+	return offerAlertService{
+		offerAlertRepo: oar,
+		notifications:  ns,
+	}
+}
+
+func (s offerAlertService) Find(id uint64) (interface{}, error) {
+	alert, err := s.offerAlertRepo.FindById(id)
+	if err != nil {
+		log.Printf("OfferAlertService -> Find: %s", err)
+		return domain.OfferAlert{}, err
+	}
+	return alert, err
+}
+
+// Subscribe creates an alert or updates the existing alert of the same type,
+// so a user has at most one alert of each type per offer.
+func (s offerAlertService) Subscribe(alert domain.OfferAlert) (domain.OfferAlert, error) {
+	if alert.Type == domain.ALERT_PRICE_DROP && (alert.TargetPrice == nil || *alert.TargetPrice <= 0) {
+		return domain.OfferAlert{}, errors.New("target price is required for price drop alerts")
+	}
+	if alert.Type == domain.ALERT_BACK_IN_STOCK {
+		alert.TargetPrice = nil
+	}
+
+	existing, err := s.offerAlertRepo.FindByUserOfferAndType(alert.User.Id, alert.OfferId, alert.Type)
+	if err != nil && !errors.Is(err, db.ErrNoMoreRows) {
+		log.Printf("OfferAlertService: %s", err)
+		return domain.OfferAlert{}, err
+	}
+
+	alert.Notified, alert.NotifiedDate = false, nil
+	if err == nil {
+		alert.Id = existing.Id
+		alert.CreatedDate = existing.CreatedDate
+		alert, err = s.offerAlertRepo.Update(alert)
+	} else {
+		alert, err = s.offerAlertRepo.Save(alert)
+	}
+	if err != nil {
+		log.Printf("OfferAlertService: %s", err)
+		return domain.OfferAlert{}, err
+	}
+
+	return alert, nil
+}
+
+func (s offerAlertService) Unsubscribe(alert domain.OfferAlert) error {
+	err := s.offerAlertRepo.Delete(alert.Id)
+	if err != nil {
+		log.Printf("OfferAlertService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s offerAlertService) FindAllByUserId(userId uint64) ([]domain.OfferAlert, error) {
+	alerts, err := s.offerAlertRepo.FindAllByUserId(userId)
+	if err != nil {
+		log.Printf("OfferAlertService: %s", err)
+		return []domain.OfferAlert{}, err
+	}
+
+	return alerts, nil
+}
+
+// Evaluate notifies the subscribers of the offer about the changes between
+// the two versions of it. Every alert fires once and is armed again only
+// after its condition stops holding, so repeated updates do not spam users.
+// The notifications are queued and sent in the background by the
+// notification service.
+func (s offerAlertService) Evaluate(before domain.Offer, after domain.Offer) {
+	if before.IsAvailable() != after.IsAvailable() {
+		s.evaluateBackInStock(after)
+	}
+	if before.Price != after.Price {
+		s.evaluatePriceDrop(after)
+	}
+}
+
+func (s offerAlertService) evaluateBackInStock(offer domain.Offer) {
+	alerts, err := s.offerAlertRepo.FindAllByOfferId(offer.Id, domain.ALERT_BACK_IN_STOCK)
+	if err != nil {
+		log.Printf("OfferAlertService: %s", err)
+		return
+	}
+
+	for _, alert := range alerts {
+		if !offer.IsAvailable() {
+			s.rearm(alert)
+			continue
+		}
+		if alert.Notified {
+			continue
+		}
+
+		s.notify(alert, domain.EVENT_OFFER_BACK_IN_STOCK, map[string]interface{}{"Title": offer.Title})
+	}
+}
+
+func (s offerAlertService) evaluatePriceDrop(offer domain.Offer) {
+	alerts, err := s.offerAlertRepo.FindAllByOfferId(offer.Id, domain.ALERT_PRICE_DROP)
+	if err != nil {
+		log.Printf("OfferAlertService: %s", err)
+		return
+	}
+
+	for _, alert := range alerts {
+		if alert.TargetPrice == nil {
+			continue
+		}
+		if offer.Price > *alert.TargetPrice {
+			s.rearm(alert)
+			continue
+		}
+		if alert.Notified {
+			continue
+		}
+
+		s.notify(alert, domain.EVENT_OFFER_PRICE_DROP, map[string]interface{}{"Title": offer.Title, "Price": offer.Price})
+	}
+}
+
+func (s offerAlertService) notify(alert domain.OfferAlert, event domain.NotificationEvent, data map[string]interface{}) {
+	err := s.notifications.Enqueue(alert.Channel, event, alert.User.Id, data)
+	if err != nil {
+		log.Printf("OfferAlertService: alert %d: %s", alert.Id, err)
+		return
+	}
+
+	now := time.Now()
+	alert.Notified, alert.NotifiedDate = true, &now
+	_, err = s.offerAlertRepo.Update(alert)
+	if err != nil {
+		log.Printf("OfferAlertService: alert %d: %s", alert.Id, err)
+	}
+}
+
+func (s offerAlertService) rearm(alert domain.OfferAlert) {
+	if !alert.Notified {
+		return
+	}
+
+	alert.Notified = false
+	_, err := s.offerAlertRepo.Update(alert)
+	if err != nil {
+		log.Printf("OfferAlertService: alert %d: %s", alert.Id, err)
+	}
+}
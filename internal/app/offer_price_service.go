@@ -0,0 +1,220 @@
+package app
+
+import (
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/database"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+type OfferPriceService interface {
+	Find(uint64) (interface{}, error)
+	FindHistory(offerId uint64, p domain.Pagination) (domain.OfferPrices, error)
+	FindSchedules(offerId uint64) ([]domain.OfferPriceSchedule, error)
+	Schedule(schedule domain.OfferPriceSchedule) (domain.OfferPriceSchedule, error)
+	CancelSchedule(schedule domain.OfferPriceSchedule, actor domain.Actor) (domain.OfferPriceSchedule, error)
+	ApplySchedules(now time.Time) error
+	Run(ctx context.Context, interval time.Duration)
+}
+
+type offerPriceService struct {
+	offerPriceRepo database.OfferPriceRepository
+	offerRepo      database.OfferRepository
+	offerService   OfferService
+}
+
+func NewOfferPriceService(opr database.OfferPriceRepository, or database.OfferRepository, os OfferService) OfferPriceService {
+	return offerPriceService{
+		offerPriceRepo: opr,
+		offerRepo:      or,
+		offerService:   os,
+	}
+}
+
+func (s offerPriceService) Find(id uint64) (interface{}, error) {
+	schedule, err := s.offerPriceRepo.FindScheduleById(id)
+	if err != nil {
+		log.Printf("OfferPriceService -> Find: %s", err)
+		return domain.OfferPriceSchedule{}, err
+	}
+	return schedule, err
+}
+
+func (s offerPriceService) FindHistory(offerId uint64, p domain.Pagination) (domain.OfferPrices, error) {
+	prices, err := s.offerPriceRepo.FindAllByOfferId(offerId, p)
+	if err != nil {
+		log.Printf("OfferPriceService: %s", err)
+		return domain.OfferPrices{}, err
+	}
+
+	return prices, nil
+}
+
+func (s offerPriceService) FindSchedules(offerId uint64) ([]domain.OfferPriceSchedule, error) {
+	schedules, err := s.offerPriceRepo.FindSchedulesByOfferId(offerId)
+	if err != nil {
+		log.Printf("OfferPriceService: %s", err)
+		return []domain.OfferPriceSchedule{}, err
+	}
+
+	return schedules, nil
+}
+
+func (s offerPriceService) Schedule(schedule domain.OfferPriceSchedule) (domain.OfferPriceSchedule, error) {
+	if schedule.EndDate != nil && !schedule.EndDate.After(schedule.StartDate) {
+		return domain.OfferPriceSchedule{}, errors.New("end date must be after start date")
+	}
+	if schedule.EndDate != nil && !schedule.EndDate.After(time.Now()) {
+		return domain.OfferPriceSchedule{}, errors.New("end date must be in the future")
+	}
+
+	schedules, err := s.offerPriceRepo.FindSchedulesByOfferId(schedule.OfferId)
+	if err != nil {
+		log.Printf("OfferPriceService: %s", err)
+		return domain.OfferPriceSchedule{}, err
+	}
+
+	for _, existing := range schedules {
+		if existing.IsOpen() && existing.Overlaps(schedule) {
+			return domain.OfferPriceSchedule{}, errors.New("the offer already has a scheduled price for this period")
+		}
+	}
+
+	schedule.Status = domain.PRICE_SCHEDULE_PENDING
+	schedule.PreviousPrice = nil
+	schedule, err = s.offerPriceRepo.SaveSchedule(schedule)
+	if err != nil {
+		log.Printf("OfferPriceService: %s", err)
+		return domain.OfferPriceSchedule{}, err
+	}
+
+	return schedule, nil
+}
+
+func (s offerPriceService) CancelSchedule(schedule domain.OfferPriceSchedule, actor domain.Actor) (domain.OfferPriceSchedule, error) {
+	if !schedule.IsOpen() {
+		return domain.OfferPriceSchedule{}, errors.New("the price schedule is already closed")
+	}
+
+	if schedule.Status == domain.PRICE_SCHEDULE_ACTIVE {
+		err := s.restorePrice(schedule, actor)
+		if err != nil {
+			log.Printf("OfferPriceService: %s", err)
+			return domain.OfferPriceSchedule{}, err
+		}
+	}
+
+	schedule.Status = domain.PRICE_SCHEDULE_CANCELLED
+	schedule, err := s.offerPriceRepo.UpdateSchedule(schedule)
+	if err != nil {
+		log.Printf("OfferPriceService: %s", err)
+		return domain.OfferPriceSchedule{}, err
+	}
+
+	return schedule, nil
+}
+
+// ApplySchedules finishes expired schedules first, so that a new schedule
+// starting at the same moment is not overwritten by the restored price.
+func (s offerPriceService) ApplySchedules(now time.Time) error {
+	toFinish, err := s.offerPriceRepo.FindSchedulesToFinish(now)
+	if err != nil {
+		return err
+	}
+
+	for _, schedule := range toFinish {
+		err = s.restorePrice(schedule, domain.Actor{})
+		if err != nil {
+			log.Printf("OfferPriceService: schedule %d: %s", schedule.Id, err)
+			continue
+		}
+
+		schedule.Status = domain.PRICE_SCHEDULE_FINISHED
+		_, err = s.offerPriceRepo.UpdateSchedule(schedule)
+		if err != nil {
+			log.Printf("OfferPriceService: schedule %d: %s", schedule.Id, err)
+		}
+	}
+
+	toStart, err := s.offerPriceRepo.FindSchedulesToStart(now)
+	if err != nil {
+		return err
+	}
+
+	for _, schedule := range toStart {
+		offer, err := s.offerRepo.FindById(schedule.OfferId)
+		if err != nil && !errors.Is(err, db.ErrNoMoreRows) {
+			log.Printf("OfferPriceService: schedule %d: %s", schedule.Id, err)
+			continue
+		} else if err != nil {
+			schedule.Status = domain.PRICE_SCHEDULE_CANCELLED
+			_, err = s.offerPriceRepo.UpdateSchedule(schedule)
+			if err != nil {
+				log.Printf("OfferPriceService: schedule %d: %s", schedule.Id, err)
+			}
+			continue
+		}
+
+		previousPrice := offer.Price
+		_, err = s.offerService.ChangePrice(offer, schedule.Price, domain.Actor{})
+		if err != nil {
+			log.Printf("OfferPriceService: schedule %d: %s", schedule.Id, err)
+			continue
+		}
+
+		schedule.PreviousPrice = &previousPrice
+		schedule.Status = domain.PRICE_SCHEDULE_ACTIVE
+		if schedule.EndDate == nil {
+			schedule.Status = domain.PRICE_SCHEDULE_FINISHED
+		}
+		_, err = s.offerPriceRepo.UpdateSchedule(schedule)
+		if err != nil {
+			log.Printf("OfferPriceService: schedule %d: %s", schedule.Id, err)
+		}
+	}
+
+	return nil
+}
+
+func (s offerPriceService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			err := s.ApplySchedules(now)
+			if err != nil {
+				log.Printf("OfferPriceService: %s", err)
+			}
+		}
+	}
+}
+
+// restorePrice returns the offer to its price before the schedule started,
+// unless the farmer has already changed the price manually in the meantime.
+func (s offerPriceService) restorePrice(schedule domain.OfferPriceSchedule, actor domain.Actor) error {
+	if schedule.PreviousPrice == nil {
+		return nil
+	}
+
+	offer, err := s.offerRepo.FindById(schedule.OfferId)
+	if errors.Is(err, db.ErrNoMoreRows) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if offer.Price != schedule.Price {
+		return nil
+	}
+
+	_, err = s.offerService.ChangePrice(offer, *schedule.PreviousPrice, actor)
+	return err
+}
@@ -2,8 +2,8 @@ package app
 
 import (
 	"boilerplate/internal/domain"
-	"boilerplate/internal/filesystem"
 	"boilerplate/internal/infra/database"
+	"bytes"
 	"encoding/base64"
 	"log"
 )
@@ -11,25 +11,33 @@ import (
 type OfferService interface {
 	Save(offer domain.Offer) (domain.Offer, error)
 	FindById(id uint64) (domain.Offer, error)
-	Update(off domain.Offer, req domain.Offer) (domain.Offer, error)
+	Update(off domain.Offer, req domain.Offer, actor domain.Actor) (domain.Offer, error)
 	Delete(offer domain.Offer) error
 	Find(uint64) (interface{}, error)
 	FindAll(user domain.User, p domain.Pagination) (domain.Offers, error)
 	FindAllByFarmId(farmId uint64, p domain.Pagination) (domain.Offers, error)
+	ChangePrice(offer domain.Offer, price float64, actor domain.Actor) (domain.Offer, error)
+	UpdateCover(offer domain.Offer, upload domain.ImageUpload) (domain.Offer, error)
 }
 
-func NewOfferService(or database.OfferRepository, fs filesystem.ImageStorageService, ims ImageModelService) OfferService {
+func NewOfferService(or database.OfferRepository, opr database.OfferPriceRepository, ims ImageModelService, oas OfferAlertService, ws WebhookService, as AuditService) OfferService {
 	return offerService{
 		offerRepo:         or,
-		imageService:      fs,
+		offerPriceRepo:    opr,
 		imageModelService: ims,
+		offerAlertService: oas,
+		webhooks:          ws,
+		audit:             as,
 	}
 }
 
 type offerService struct {
 	offerRepo         database.OfferRepository
-	imageService      filesystem.ImageStorageService
+	offerPriceRepo    database.OfferPriceRepository
 	imageModelService ImageModelService
+	offerAlertService OfferAlertService
+	webhooks          WebhookService
+	audit             AuditService
 }
 
 func (s offerService) Find(id uint64) (interface{}, error) {
@@ -42,25 +50,35 @@ func (s offerService) Find(id uint64) (interface{}, error) {
 }
 
 func (s offerService) Save(offer domain.Offer) (domain.Offer, error) {
-	decodedBytes, err := base64.StdEncoding.DecodeString(offer.Cover.Data)
-	if err != nil {
-		log.Printf("OfferService: %s", err)
-		return domain.Offer{}, err
+	// The cover can also be uploaded afterwards as a file.
+	if offer.Cover.Data != "" {
+		decodedBytes, err := base64.StdEncoding.DecodeString(offer.Cover.Data)
+		if err != nil {
+			log.Printf("OfferService: %s", err)
+			return domain.Offer{}, err
+		}
+
+		name, err := s.imageModelService.StoreFile(offer.Cover.Name, bytes.NewReader(decodedBytes))
+		if err != nil {
+			log.Printf("OfferService: %s", err)
+			return domain.Offer{}, err
+		}
+		offer.Cover.Name = name
 	}
 
-	name, err := s.imageService.SaveImage(offer.Cover.Name, decodedBytes)
+	o, err := s.offerRepo.Save(offer)
 	if err != nil {
 		log.Printf("OfferService: %s", err)
 		return domain.Offer{}, err
 	}
 
-	offer.Cover.Name = name
-	o, err := s.offerRepo.Save(offer)
+	err = s.recordPrice(o)
 	if err != nil {
 		log.Printf("OfferService: %s", err)
 		return domain.Offer{}, err
 	}
 
+	s.webhooks.DispatchOffer(domain.WEBHOOK_OFFER_CREATED, o)
 	return o, err
 }
 
@@ -84,7 +102,7 @@ func (s offerService) FindAllByFarmId(farmId uint64, p domain.Pagination) (domai
 	return offers, nil
 }
 
-func (s offerService) Update(off domain.Offer, req domain.Offer) (domain.Offer, error) {
+func (s offerService) Update(off domain.Offer, req domain.Offer, actor domain.Actor) (domain.Offer, error) {
 	if req.Cover.Name != "" {
 		decodedBytes, err := base64.StdEncoding.DecodeString(req.Cover.Data)
 		if err != nil {
@@ -92,11 +110,17 @@ func (s offerService) Update(off domain.Offer, req domain.Offer) (domain.Offer,
 			return domain.Offer{}, err
 		}
 
-		name, err := s.imageService.UpdateImage(off.Cover.Name, req.Cover.Name, decodedBytes)
+		name, err := s.imageModelService.StoreFile(req.Cover.Name, bytes.NewReader(decodedBytes))
 		if err != nil {
 			log.Printf("OfferService: %s", err)
 			return domain.Offer{}, err
 		}
+		if off.Cover.Name != "" {
+			err = s.imageModelService.RemoveFile(off.Cover.Name)
+			if err != nil {
+				log.Printf("OfferService: %s", err)
+			}
+		}
 		req.Cover.Name = name
 	} else {
 		req.Cover.Name = off.Cover.Name
@@ -110,20 +134,95 @@ func (s offerService) Update(off domain.Offer, req domain.Offer) (domain.Offer,
 		return domain.Offer{}, err
 	}
 
+	if offer.Price != off.Price {
+		err = s.recordPrice(offer)
+		if err != nil {
+			log.Printf("OfferService: %s", err)
+			return domain.Offer{}, err
+		}
+		s.auditPrice(off, offer, actor)
+	}
+
+	s.offerAlertService.Evaluate(off, offer)
+	s.webhooks.DispatchOffer(domain.WEBHOOK_OFFER_UPDATED, offer)
 	return offer, nil
 }
 
-func (s offerService) Delete(offer domain.Offer) error {
-	err := s.imageService.RemoveImage(offer.Cover.Name)
+func (s offerService) ChangePrice(offer domain.Offer, price float64, actor domain.Actor) (domain.Offer, error) {
+	if offer.Price == price {
+		return offer, nil
+	}
+
+	before := offer
+	o, err := s.offerRepo.UpdatePrice(offer.Id, price)
 	if err != nil {
 		log.Printf("OfferService: %s", err)
+		return domain.Offer{}, err
+	}
+
+	err = s.recordPrice(o)
+	if err != nil {
+		log.Printf("OfferService: %s", err)
+		return domain.Offer{}, err
+	}
+
+	s.auditPrice(before, o, actor)
+	s.offerAlertService.Evaluate(before, o)
+	s.webhooks.DispatchOffer(domain.WEBHOOK_OFFER_UPDATED, o)
+	return o, nil
+}
+
+func (s offerService) UpdateCover(offer domain.Offer, upload domain.ImageUpload) (domain.Offer, error) {
+	name, err := s.imageModelService.StoreFile(upload.Name, upload.Content)
+	if err != nil {
+		log.Printf("OfferService: %s", err)
+		return domain.Offer{}, err
+	}
+
+	oldCover := offer.Cover.Name
+	offer.Cover = domain.Image{Name: name}
+	o, err := s.offerRepo.Update(offer)
+	if err != nil {
+		log.Printf("OfferService: %s", err)
+		_ = s.imageModelService.RemoveFile(name)
+		return domain.Offer{}, err
+	}
+
+	if oldCover != "" {
+		err = s.imageModelService.RemoveFile(oldCover)
+		if err != nil {
+			log.Printf("OfferService: %s", err)
+		}
+	}
+
+	s.webhooks.DispatchOffer(domain.WEBHOOK_OFFER_UPDATED, o)
+	return o, nil
+}
+
+func (s offerService) recordPrice(offer domain.Offer) error {
+	_, err := s.offerPriceRepo.Save(domain.OfferPrice{OfferId: offer.Id, Price: offer.Price})
+	return err
+}
+
+func (s offerService) auditPrice(before domain.Offer, after domain.Offer, actor domain.Actor) {
+	_ = s.audit.Record(domain.NewAuditEvent(actor, domain.AUDIT_OFFER_PRICE_CHANGED, domain.AUDIT_ENTITY_OFFER, after.Id).
+		WithChanges(map[string]interface{}{"price": before.Price}, map[string]interface{}{"price": after.Price}))
+}
+
+func (s offerService) Delete(offer domain.Offer) error {
+	if offer.Cover.Name != "" {
+		err := s.imageModelService.RemoveFile(offer.Cover.Name)
+		if err != nil {
+			log.Printf("OfferService: %s", err)
+		}
 	}
-	err = s.offerRepo.Delete(offer.Id)
+	err := s.offerRepo.Delete(offer.Id)
 	if err != nil {
 		log.Printf("OfferService: %s", err)
 		return err
 	}
 
+	s.webhooks.DispatchOffer(domain.WEBHOOK_OFFER_DELETED, offer)
 	return nil
 }
 
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"boilerplate/internal/app/notification"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/database"
+	"crypto/rand"
+	"errors"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/upper/db/v4"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	errInvalidCode  = errors.New("invalid or expired code")
+	errCodeTooEarly = errors.New("code was sent recently, try again later")
+)
+
+type OneTimeCodeService interface {
+	Send(userId uint64, purpose domain.OneTimeCodePurpose, channel domain.NotificationChannel, event domain.NotificationEvent) error
+	Verify(userId uint64, purpose domain.OneTimeCodePurpose, code string) error
+}
+
+type oneTimeCodeService struct {
+	codeRepo      database.OneTimeCodeRepository
+	notifications notification.Service
+}
+
+func NewOneTimeCodeService(ocr database.OneTimeCodeRepository, ns notification.Service) OneTimeCodeService {
+	return oneTimeCodeService{
+		codeRepo:      ocr,
+		notifications: ns,
+	}
+}
+
+// Send generates a new code for the purpose and delivers it to the user
+// over the channel. Earlier codes for the same purpose stop working.
+func (s oneTimeCodeService) Send(userId uint64, purpose domain.OneTimeCodePurpose, channel domain.NotificationChannel, event domain.NotificationEvent) error {
+	last, err := s.codeRepo.FindLast(userId, purpose)
+	if err == nil && time.Since(last.CreatedDate) < domain.OneTimeCodeResendDelay {
+		return errCodeTooEarly
+	} else if err != nil && !errors.Is(err, db.ErrNoMoreRows) {
+		log.Printf("OneTimeCodeService: %s", err)
+		return err
+	}
+
+	code, err := generateCode(domain.OneTimeCodeLength)
+	if err != nil {
+		log.Printf("OneTimeCodeService: %s", err)
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("OneTimeCodeService: %s", err)
+		return err
+	}
+
+	err = s.codeRepo.ExpireAll(userId, purpose)
+	if err != nil {
+		log.Printf("OneTimeCodeService: %s", err)
+		return err
+	}
+
+	_, err = s.codeRepo.Save(domain.OneTimeCode{
+		UserId:      userId,
+		Purpose:     purpose,
+		Channel:     channel,
+		CodeHash:    string(hash),
+		ExpiresDate: time.Now().Add(domain.OneTimeCodeTTL),
+	})
+	if err != nil {
+		log.Printf("OneTimeCodeService: %s", err)
+		return err
+	}
+
+	data := map[string]interface{}{"Code": code, "Minutes": int(domain.OneTimeCodeTTL.Minutes())}
+	return s.notifications.Deliver(channel, event, userId, data)
+}
+
+// Verify spends the last code sent for the purpose if it matches. Every
+// wrong guess counts, and the code stops working after
+// domain.OneTimeCodeMaxAttempts of them.
+func (s oneTimeCodeService) Verify(userId uint64, purpose domain.OneTimeCodePurpose, code string) error {
+	c, err := s.codeRepo.FindLast(userId, purpose)
+	if err != nil {
+		if !errors.Is(err, db.ErrNoMoreRows) {
+			log.Printf("OneTimeCodeService: %s", err)
+		}
+		return errInvalidCode
+	}
+	if !c.IsActive(time.Now()) {
+		return errInvalidCode
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(c.CodeHash), []byte(code)) != nil {
+		err = s.codeRepo.IncrementAttempts(c.Id)
+		if err != nil {
+			log.Printf("OneTimeCodeService: %s", err)
+		}
+		return errInvalidCode
+	}
+
+	used, err := s.codeRepo.MarkAsUsed(c.Id)
+	if err != nil {
+		log.Printf("OneTimeCodeService: %s", err)
+		return err
+	}
+	if !used {
+		return errInvalidCode
+	}
+
+	return nil
+}
+
+func generateCode(length int) (string, error) {
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		code[i] = byte('0' + n.Int64())
+	}
+	return string(code), nil
+}
@@ -1,10 +1,12 @@
 package app
 
 import (
+	"boilerplate/internal/app/events"
 	"boilerplate/internal/domain"
 	"boilerplate/internal/infra/database"
 	"log"
 	"math"
+	"time"
 )
 
 type OrderItemsService interface {
@@ -15,16 +17,22 @@ type OrderItemsService interface {
 	Find(uint64) (interface{}, error)
 }
 
-func NewOrderItemsService(or database.OrderItemRepository, order database.OrderRepository) orderItemsService {
+func NewOrderItemsService(or database.OrderItemRepository, order database.OrderRepository, fmr database.FarmMemberRepository, eb events.Broker, ws WebhookService) orderItemsService {
 	return orderItemsService{
 		orderItemsRepo: or,
 		orderRepo:      order,
+		farmMemberRepo: fmr,
+		eventBroker:    eb,
+		webhooks:       ws,
 	}
 }
 
 type orderItemsService struct {
 	orderItemsRepo database.OrderItemRepository
 	orderRepo      database.OrderRepository
+	farmMemberRepo database.FarmMemberRepository
+	eventBroker    events.Broker
+	webhooks       WebhookService
 }
 
 func (s orderItemsService) FindAll(orderId uint64) ([]domain.OrderItem, error) {
@@ -54,7 +62,13 @@ func (s orderItemsService) Save(ord domain.OrderItem, orderId uint64) (domain.Or
 		return domain.OrderItem{}, err
 	}
 	err = s.orderRepo.Recalculate(orderId)
-	return o, err
+	if err != nil {
+		log.Printf("OrderItemService: %s", err)
+		return o, err
+	}
+
+	s.publish(orderId, o.Id)
+	return o, nil
 }
 
 func (s orderItemsService) Update(ord domain.OrderItem, req domain.OrderItem) (domain.OrderItem, error) {
@@ -73,6 +87,7 @@ func (s orderItemsService) Update(ord domain.OrderItem, req domain.OrderItem) (d
 		return domain.OrderItem{}, err
 	}
 
+	s.publish(ord.Order.Id, ord.Id)
 	return order_item, nil
 }
 
@@ -89,5 +104,33 @@ func (s orderItemsService) Delete(order domain.OrderItem) error {
 		return err
 	}
 
+	s.publish(order.Order.Id, order.Id)
 	return nil
 }
+
+func (s orderItemsService) publish(orderId uint64, orderItemId uint64) {
+	order, err := s.orderRepo.FindById(orderId)
+	if err != nil {
+		log.Printf("OrderItemService: %s", err)
+		return
+	}
+
+	recipients := []uint64{order.User.Id}
+	if order.Status != domain.DRAFT {
+		farmerIds, err := orderFarmerIds(s.orderItemsRepo, s.farmMemberRepo, order)
+		if err != nil {
+			log.Printf("OrderItemService: %s", err)
+		}
+		recipients = append(recipients, farmerIds...)
+	}
+
+	s.eventBroker.Publish(domain.OrderEvent{
+		Type:        domain.ORDER_ITEM_CHANGED,
+		OrderId:     order.Id,
+		OrderItemId: &orderItemId,
+		Status:      order.Status,
+		Recipients:  recipients,
+		CreatedDate: time.Now(),
+	})
+	s.webhooks.DispatchOrder(domain.WEBHOOK_ORDER_ITEM_CHANGED, order)
+}
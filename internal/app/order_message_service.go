@@ -0,0 +1,161 @@
+package app
+
+import (
+	"boilerplate/internal/app/notification"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/database"
+	"errors"
+	"log"
+)
+
+type OrderMessageService interface {
+	Send(order domain.Order, message domain.OrderMessage) (domain.OrderMessage, error)
+	FindAll(order domain.Order, reader domain.User, p domain.Pagination) (domain.OrderMessages, error)
+	MarkAsRead(order domain.Order, reader domain.User) error
+	CountUnread(orders []domain.Order, reader domain.User) ([]domain.Order, error)
+}
+
+type orderMessageService struct {
+	orderMessageRepo database.OrderMessageRepository
+	orderItemRepo    database.OrderItemRepository
+	farmMemberRepo   database.FarmMemberRepository
+	imageService     ImageModelService
+	notifications    notification.Service
+}
+
+func NewOrderMessageService(omr database.OrderMessageRepository, oir database.OrderItemRepository, fmr database.FarmMemberRepository, ims ImageModelService, ns notification.Service) OrderMessageService {
+	return orderMessageService{
+		orderMessageRepo: omr,
+		orderItemRepo:    oir,
+		farmMemberRepo:   fmr,
+		imageService:     ims,
+		notifications:    ns,
+	}
+}
+
+// Send adds a message to the conversation of a submitted order and lets
+// the other party know about it. Only the buyer and the farm staff of
+// the order take part in the conversation.
+func (s orderMessageService) Send(order domain.Order, message domain.OrderMessage) (domain.OrderMessage, error) {
+	if order.Status == domain.DRAFT {
+		return domain.OrderMessage{}, errors.New("messages can be sent only about a submitted order")
+	}
+
+	participants, err := s.participants(order)
+	if err != nil {
+		log.Printf("OrderMessageService: %s", err)
+		return domain.OrderMessage{}, err
+	}
+	if !containsId(participants, message.User.Id) {
+		return domain.OrderMessage{}, errors.New("you are not a participant of this order")
+	}
+
+	images := message.Images
+	sender := message.User
+	message.OrderId = order.Id
+	message.ReadDate = nil
+	message, err = s.orderMessageRepo.Save(message)
+	if err != nil {
+		log.Printf("OrderMessageService: %s", err)
+		return domain.OrderMessage{}, err
+	}
+
+	for _, image := range images {
+		image.Entity = "order_messages"
+		image.EntityId = message.Id
+		_, err = s.imageService.Save(image)
+		if err != nil {
+			log.Printf("OrderMessageService: %s", err)
+			return domain.OrderMessage{}, err
+		}
+	}
+	if len(images) > 0 {
+		message, err = s.orderMessageRepo.FindById(message.Id)
+		if err != nil {
+			log.Printf("OrderMessageService: %s", err)
+			return domain.OrderMessage{}, err
+		}
+	}
+
+	data := map[string]interface{}{"OrderId": order.Id, "Sender": sender.Name, "Text": message.Text}
+	for _, userId := range participants {
+		if userId != sender.Id {
+			s.notifications.Send(domain.EVENT_ORDER_MESSAGE, userId, data)
+		}
+	}
+
+	return message, nil
+}
+
+// FindAll returns the conversation of the order and marks the messages of
+// the other party as read by the reader.
+func (s orderMessageService) FindAll(order domain.Order, reader domain.User, p domain.Pagination) (domain.OrderMessages, error) {
+	err := s.MarkAsRead(order, reader)
+	if err != nil {
+		return domain.OrderMessages{}, err
+	}
+
+	messages, err := s.orderMessageRepo.FindAllByOrderId(order.Id, p)
+	if err != nil {
+		log.Printf("OrderMessageService: %s", err)
+		return domain.OrderMessages{}, err
+	}
+
+	return messages, nil
+}
+
+func (s orderMessageService) MarkAsRead(order domain.Order, reader domain.User) error {
+	participants, err := s.participants(order)
+	if err != nil {
+		log.Printf("OrderMessageService: %s", err)
+		return err
+	}
+	if !containsId(participants, reader.Id) {
+		return errors.New("you are not a participant of this order")
+	}
+
+	err = s.orderMessageRepo.MarkAsRead(order.Id, reader.Id)
+	if err != nil {
+		log.Printf("OrderMessageService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// CountUnread fills UnreadMessages of the orders for the reader.
+func (s orderMessageService) CountUnread(orders []domain.Order, reader domain.User) ([]domain.Order, error) {
+	orderIds := make([]uint64, len(orders))
+	for i, order := range orders {
+		orderIds[i] = order.Id
+	}
+
+	counts, err := s.orderMessageRepo.CountUnread(orderIds, reader.Id)
+	if err != nil {
+		log.Printf("OrderMessageService: %s", err)
+		return nil, err
+	}
+
+	for i := range orders {
+		orders[i].UnreadMessages = counts[orders[i].Id]
+	}
+	return orders, nil
+}
+
+func (s orderMessageService) participants(order domain.Order) ([]uint64, error) {
+	farmerIds, err := orderFarmerIds(s.orderItemRepo, s.farmMemberRepo, order)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]uint64{order.User.Id}, farmerIds...), nil
+}
+
+func containsId(ids []uint64, id uint64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
@@ -1,38 +1,54 @@
 package app
 
 import (
+	"boilerplate/internal/app/events"
+	"boilerplate/internal/app/notification"
 	"boilerplate/internal/domain"
 	"boilerplate/internal/infra/database"
+	"errors"
 	"log"
+	"time"
 )
 
 type OrderService interface {
 	Save(o domain.Order) (domain.Order, error)
 	FindById(id uint64) (domain.Order, error)
 	Update(o domain.Order, req domain.Order) (domain.Order, error)
-	NoRequestUpdate(o domain.Order) (domain.Order, error)
+	ChangeStatus(o domain.Order, status domain.OrderStatus, actor domain.Actor) (domain.Order, error)
 	FindAllByUserId(userId uint64, p domain.Pagination) (domain.Orders, error)
 	Delete(o domain.Order) error
 	Find(uint64) (interface{}, error)
 	FindByFarmerId(farmUserId uint64, p domain.Pagination) (domain.Orders, error)
+	FindAllByFarmId(farmId uint64, p domain.Pagination) (domain.Orders, error)
 	SplitOrderByFarms(order domain.Order) (map[uint64]domain.Order, error)
-	SubmitSplitedOrder(order domain.Order, farmId uint64) (domain.Order, error)
+	SubmitSplitedOrder(order domain.Order, farmId uint64, actor domain.Actor) (domain.Order, error)
 	DeleteSplitedOrder(order domain.Order, farmId uint64) error
 	GetFarmerOrdersPercentage(farmUserId uint64) ([]domain.Order, float64, error)
+	MarkPercentageAsPaid(order domain.Order) (domain.Order, error)
 }
 
-func NewOrderService(or database.OrderRepository, oir database.OrderItemRepository, ar database.AddressRepository) OrderService {
+func NewOrderService(or database.OrderRepository, oir database.OrderItemRepository, fmr database.FarmMemberRepository, ar database.AddressRepository, ns notification.Service, eb events.Broker, ws WebhookService, as AuditService) OrderService {
 	return orderService{
-		orderRepo:     or,
-		orderItemRepo: oir,
-		addressRepo:   ar,
+		orderRepo:      or,
+		orderItemRepo:  oir,
+		farmMemberRepo: fmr,
+		addressRepo:    ar,
+		notifications:  ns,
+		eventBroker:    eb,
+		webhooks:       ws,
+		audit:          as,
 	}
 }
 
 type orderService struct {
-	orderRepo     database.OrderRepository
-	orderItemRepo database.OrderItemRepository
-	addressRepo   database.AddressRepository
+	orderRepo      database.OrderRepository
+	orderItemRepo  database.OrderItemRepository
+	farmMemberRepo database.FarmMemberRepository
+	addressRepo    database.AddressRepository
+	notifications  notification.Service
+	eventBroker    events.Broker
+	webhooks       WebhookService
+	audit          AuditService
 }
 
 func (s orderService) Find(id uint64) (interface{}, error) {
@@ -58,6 +74,7 @@ func (s orderService) Save(ord domain.Order) (domain.Order, error) {
 		return domain.Order{}, err
 	}
 
+	s.publish(domain.ORDER_CREATED, o)
 	return o, err
 }
 
@@ -88,9 +105,7 @@ func (s orderService) Update(ord domain.Order, req domain.Order) (domain.Order,
 	ord.PostOfficeCity = req.PostOfficeCity
 	ord.Ttn = req.Ttn
 	ord.Comment = req.Comment
-	if ord.ShippingPrice != req.ShippingPrice {
-		ord.TotalPrice = req.ShippingPrice + ord.ProductsPrice
-	}
+	shippingChanged := ord.ShippingPrice != req.ShippingPrice
 
 	ord.ShippingPrice = req.ShippingPrice
 	order, err := s.orderRepo.Update(ord)
@@ -99,16 +114,36 @@ func (s orderService) Update(ord domain.Order, req domain.Order) (domain.Order,
 		return domain.Order{}, err
 	}
 
+	if shippingChanged {
+		err = s.orderRepo.Recalculate(order.Id)
+		if err != nil {
+			log.Printf("OrderService: %s", err)
+			return domain.Order{}, err
+		}
+
+		order, err = s.orderRepo.FindById(order.Id)
+		if err != nil {
+			log.Printf("OrderService: %s", err)
+			return domain.Order{}, err
+		}
+	}
+
 	return order, nil
 }
 
-func (s orderService) NoRequestUpdate(order domain.Order) (domain.Order, error) {
+func (s orderService) ChangeStatus(order domain.Order, status domain.OrderStatus, actor domain.Actor) (domain.Order, error) {
+	previous := order.Status
+	order.Status = status
 	order, err := s.orderRepo.Update(order)
 	if err != nil {
 		log.Printf("OrderService: %s", err)
 		return domain.Order{}, err
 	}
 
+	_ = s.audit.Record(domain.NewAuditEvent(actor, domain.AUDIT_ORDER_STATUS_CHANGED, domain.AUDIT_ENTITY_ORDER, order.Id).
+		WithChanges(map[string]interface{}{"status": previous}, map[string]interface{}{"status": status}))
+	s.notifyStatus(order)
+	s.publish(domain.ORDER_STATUS_CHANGED, order)
 	return order, nil
 }
 
@@ -132,6 +167,16 @@ func (s orderService) FindByFarmerId(farmUserId uint64, p domain.Pagination) (do
 	return orders, nil
 }
 
+func (s orderService) FindAllByFarmId(farmId uint64, p domain.Pagination) (domain.Orders, error) {
+	orders, err := s.orderRepo.FindAllByFilter(domain.OrderFilter{FarmId: farmId, ExcludeDrafts: true}, p)
+	if err != nil {
+		log.Printf("OrderService: %s", err)
+		return domain.Orders{}, err
+	}
+
+	return orders, nil
+}
+
 func (s orderService) SplitOrderByFarms(order domain.Order) (map[uint64]domain.Order, error) {
 	orderItems, err := s.orderItemRepo.FindAllWithoutPagination(order.Id)
 	if err != nil {
@@ -149,13 +194,18 @@ func (s orderService) SplitOrderByFarms(order domain.Order) (map[uint64]domain.O
 	return orders, nil
 }
 
-func (s orderService) SubmitSplitedOrder(order domain.Order, farmId uint64) (domain.Order, error) {
+func (s orderService) SubmitSplitedOrder(order domain.Order, farmId uint64, actor domain.Actor) (domain.Order, error) {
 	splitedOrder, err := s.orderRepo.SubmitSplitedOrder(order, farmId)
 	if err != nil {
 		log.Printf("OrderService: %s", err)
 		return domain.Order{}, err
 	}
 
+	_ = s.audit.Record(domain.NewAuditEvent(actor, domain.AUDIT_ORDER_STATUS_CHANGED, domain.AUDIT_ENTITY_ORDER, splitedOrder.Id).
+		WithChanges(map[string]interface{}{"status": domain.DRAFT}, map[string]interface{}{"status": splitedOrder.Status}))
+
+	s.notifyStatus(splitedOrder)
+	s.publish(domain.ORDER_CREATED, splitedOrder)
 	return splitedOrder, nil
 }
 
@@ -169,6 +219,24 @@ func (s orderService) DeleteSplitedOrder(order domain.Order, farmId uint64) erro
 	return nil
 }
 
+// MarkPercentageAsPaid records that the farmer has paid the platform
+// commission for a completed order.
+func (s orderService) MarkPercentageAsPaid(order domain.Order) (domain.Order, error) {
+	if order.Status != domain.COMPLETED {
+		return domain.Order{}, errors.New("commission is charged only for completed orders")
+	}
+
+	paid := true
+	order.IsPercentagePaid = &paid
+	order, err := s.orderRepo.Update(order)
+	if err != nil {
+		log.Printf("OrderService: %s", err)
+		return domain.Order{}, err
+	}
+
+	return order, nil
+}
+
 func (s orderService) GetFarmerOrdersPercentage(farmUserId uint64) ([]domain.Order, float64, error) {
 	orders, total, err := s.orderRepo.GetFarmerOrdersPercentage(farmUserId)
 	if err != nil {
@@ -178,3 +246,101 @@ func (s orderService) GetFarmerOrdersPercentage(farmUserId uint64) ([]domain.Ord
 
 	return orders, total, nil
 }
+
+// notifyStatus tells the other side of the order about its new status:
+// farmers learn about submitted and completed orders, buyers about
+// everything the farmer does with their order.
+func (s orderService) notifyStatus(order domain.Order) {
+	event, ok := domain.OrderStatusEvent(order.Status)
+	if !ok {
+		return
+	}
+
+	data := map[string]interface{}{
+		"OrderId":    order.Id,
+		"TotalPrice": order.TotalPrice,
+		"Ttn":        "",
+	}
+	if order.Ttn != nil {
+		data["Ttn"] = *order.Ttn
+	}
+
+	if order.IsFarmerStatus(order.Status) {
+		s.notifications.Send(event, order.User.Id, data)
+		return
+	}
+
+	farmerIds, err := orderFarmerIds(s.orderItemRepo, s.farmMemberRepo, order)
+	if err != nil {
+		log.Printf("OrderService: %s", err)
+		return
+	}
+	for _, farmerId := range farmerIds {
+		s.notifications.Send(event, farmerId, data)
+	}
+}
+
+// publish pushes the order event to the buyer and, once the order is
+// submitted, to the staff of the farms in it and their webhooks.
+func (s orderService) publish(eventType domain.OrderEventType, order domain.Order) {
+	recipients := []uint64{order.User.Id}
+	if order.Status != domain.DRAFT {
+		farmerIds, err := orderFarmerIds(s.orderItemRepo, s.farmMemberRepo, order)
+		if err != nil {
+			log.Printf("OrderService: %s", err)
+		}
+		recipients = append(recipients, farmerIds...)
+	}
+
+	s.eventBroker.Publish(domain.OrderEvent{
+		Type:        eventType,
+		OrderId:     order.Id,
+		Status:      order.Status,
+		Recipients:  recipients,
+		CreatedDate: time.Now(),
+	})
+	s.webhooks.DispatchOrder(domain.OrderWebhookEvent(eventType), order)
+}
+
+// orderFarmerIds returns the user ids of the members of the farms whose
+// products are in the order who may read or process its orders: the owner
+// and the staff working on the orders.
+func orderFarmerIds(orderItemRepo database.OrderItemRepository, farmMemberRepo database.FarmMemberRepository, order domain.Order) ([]uint64, error) {
+	orderItems := order.OrderItems
+	if len(orderItems) == 0 {
+		var err error
+		orderItems, err = orderItemRepo.FindAllWithoutPagination(order.Id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var farmIds []uint64
+	seenFarms := make(map[uint64]bool)
+	for _, item := range orderItems {
+		if item.Farm.Id == 0 || seenFarms[item.Farm.Id] {
+			continue
+		}
+		seenFarms[item.Farm.Id] = true
+		farmIds = append(farmIds, item.Farm.Id)
+	}
+
+	members, err := farmMemberRepo.FindMembersByFarmIds(farmIds)
+	if err != nil {
+		return nil, err
+	}
+
+	var farmerIds []uint64
+	seen := make(map[uint64]bool)
+	for _, member := range members {
+		if !member.Role.Can(domain.FARM_PERMISSION_ORDERS_READ) && !member.Role.Can(domain.FARM_PERMISSION_ORDERS_PROCESS) {
+			continue
+		}
+		if seen[member.User.Id] {
+			continue
+		}
+		seen[member.User.Id] = true
+		farmerIds = append(farmerIds, member.User.Id)
+	}
+	return farmerIds, nil
+}
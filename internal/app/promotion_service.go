@@ -0,0 +1,283 @@
+package app
+
+import (
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/database"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+type PromotionService interface {
+	Find(uint64) (interface{}, error)
+	Save(promotion domain.Promotion) (domain.Promotion, error)
+	Update(promotion domain.Promotion, req domain.Promotion) (domain.Promotion, error)
+	Delete(promotion domain.Promotion) error
+	FindAllByFarmId(farmId uint64, p domain.Pagination) (domain.Promotions, error)
+	FindAllPlatform(p domain.Pagination) (domain.Promotions, error)
+	ApplyToOrder(order domain.Order, code string) (domain.Order, error)
+	RemoveFromOrder(order domain.Order, promotionId uint64) (domain.Order, error)
+}
+
+type promotionService struct {
+	promotionRepo database.PromotionRepository
+	orderRepo     database.OrderRepository
+	orderItemRepo database.OrderItemRepository
+	offerRepo     database.OfferRepository
+}
+
+func NewPromotionService(pr database.PromotionRepository, or database.OrderRepository, oir database.OrderItemRepository, ofr database.OfferRepository) PromotionService {
+	return promotionService{
+		promotionRepo: pr,
+		orderRepo:     or,
+		orderItemRepo: oir,
+		offerRepo:     ofr,
+	}
+}
+
+func (s promotionService) Find(id uint64) (interface{}, error) {
+	p, err := s.promotionRepo.FindById(id)
+	if err != nil {
+		log.Printf("PromotionService -> Find: %s", err)
+		return domain.Promotion{}, err
+	}
+	return p, err
+}
+
+func (s promotionService) Save(promotion domain.Promotion) (domain.Promotion, error) {
+	promotion.Code = normalizePromotionCode(promotion.Code)
+	err := s.validate(promotion)
+	if err != nil {
+		log.Printf("PromotionService: %s", err)
+		return domain.Promotion{}, err
+	}
+
+	_, err = s.promotionRepo.FindByCode(promotion.Code)
+	if err == nil {
+		return domain.Promotion{}, errors.New("promotion with such code already exists")
+	} else if !errors.Is(err, db.ErrNoMoreRows) {
+		log.Printf("PromotionService: %s", err)
+		return domain.Promotion{}, err
+	}
+
+	promotion, err = s.promotionRepo.Save(promotion)
+	if err != nil {
+		log.Printf("PromotionService: %s", err)
+		return domain.Promotion{}, err
+	}
+
+	return promotion, nil
+}
+
+// Update changes the conditions of a promotion. The code, owner and scope of
+// the promotion are fixed at creation and can not be changed.
+func (s promotionService) Update(promotion domain.Promotion, req domain.Promotion) (domain.Promotion, error) {
+	promotion.Title = req.Title
+	promotion.Type = req.Type
+	promotion.Value = req.Value
+	promotion.BuyAmount = req.BuyAmount
+	promotion.GetAmount = req.GetAmount
+	promotion.MinOrderPrice = req.MinOrderPrice
+	promotion.UsageLimit = req.UsageLimit
+	promotion.UsageLimitPerUser = req.UsageLimitPerUser
+	promotion.StartDate = req.StartDate
+	promotion.EndDate = req.EndDate
+	promotion.IsActive = req.IsActive
+	promotion.OfferId = req.OfferId
+
+	err := s.validate(promotion)
+	if err != nil {
+		log.Printf("PromotionService: %s", err)
+		return domain.Promotion{}, err
+	}
+
+	promotion, err = s.promotionRepo.Update(promotion)
+	if err != nil {
+		log.Printf("PromotionService: %s", err)
+		return domain.Promotion{}, err
+	}
+
+	return promotion, nil
+}
+
+func (s promotionService) Delete(promotion domain.Promotion) error {
+	err := s.promotionRepo.Delete(promotion.Id)
+	if err != nil {
+		log.Printf("PromotionService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s promotionService) FindAllByFarmId(farmId uint64, p domain.Pagination) (domain.Promotions, error) {
+	promotions, err := s.promotionRepo.FindAllByFarmId(farmId, p)
+	if err != nil {
+		log.Printf("PromotionService: %s", err)
+		return domain.Promotions{}, err
+	}
+
+	return promotions, nil
+}
+
+func (s promotionService) FindAllPlatform(p domain.Pagination) (domain.Promotions, error) {
+	promotions, err := s.promotionRepo.FindAllPlatform(p)
+	if err != nil {
+		log.Printf("PromotionService: %s", err)
+		return domain.Promotions{}, err
+	}
+
+	return promotions, nil
+}
+
+func (s promotionService) ApplyToOrder(order domain.Order, code string) (domain.Order, error) {
+	if order.Status != domain.DRAFT {
+		return domain.Order{}, errors.New("promotions can be applied only to an order in DRAFT status")
+	}
+
+	promotion, err := s.promotionRepo.FindByCode(normalizePromotionCode(code))
+	if errors.Is(err, db.ErrNoMoreRows) {
+		return domain.Order{}, errors.New("promotion not found")
+	} else if err != nil {
+		log.Printf("PromotionService: %s", err)
+		return domain.Order{}, err
+	}
+
+	if !promotion.IsValidAt(time.Now()) {
+		return domain.Order{}, errors.New("promotion is not active")
+	}
+
+	for _, discount := range order.Discounts {
+		if discount.Promotion.Id == promotion.Id {
+			return domain.Order{}, errors.New("promotion is already applied to this order")
+		}
+		if discount.Promotion.Type == domain.PROMOTION_FREE_SHIPPING && promotion.Type == domain.PROMOTION_FREE_SHIPPING {
+			return domain.Order{}, errors.New("the order already has free shipping")
+		}
+	}
+
+	if promotion.UsageLimit != nil {
+		usages, err := s.promotionRepo.CountUsages(promotion.Id)
+		if err != nil {
+			log.Printf("PromotionService: %s", err)
+			return domain.Order{}, err
+		}
+		if usages >= *promotion.UsageLimit {
+			return domain.Order{}, errors.New("promotion usage limit is reached")
+		}
+	}
+
+	if promotion.UsageLimitPerUser != nil {
+		usages, err := s.promotionRepo.CountUserUsages(promotion.Id, order.User.Id)
+		if err != nil {
+			log.Printf("PromotionService: %s", err)
+			return domain.Order{}, err
+		}
+		if usages >= *promotion.UsageLimitPerUser {
+			return domain.Order{}, errors.New("you have already used this promotion")
+		}
+	}
+
+	orderItems, err := s.orderItemRepo.FindAllWithoutPagination(order.Id)
+	if err != nil {
+		log.Printf("PromotionService: %s", err)
+		return domain.Order{}, err
+	}
+
+	if len(promotion.EligibleItems(orderItems)) == 0 {
+		return domain.Order{}, errors.New("the order has no products this promotion applies to")
+	}
+
+	amount := promotion.DiscountFor(orderItems, order.ShippingPrice)
+	if amount <= 0 {
+		return domain.Order{}, errors.New("the order does not meet the promotion conditions")
+	}
+
+	_, err = s.promotionRepo.SaveDiscount(domain.OrderDiscount{OrderId: order.Id, Promotion: promotion, Amount: amount})
+	if err != nil {
+		log.Printf("PromotionService: %s", err)
+		return domain.Order{}, err
+	}
+
+	return s.recalculate(order.Id)
+}
+
+func (s promotionService) RemoveFromOrder(order domain.Order, promotionId uint64) (domain.Order, error) {
+	if order.Status != domain.DRAFT {
+		return domain.Order{}, errors.New("promotions can be removed only from an order in DRAFT status")
+	}
+
+	err := s.promotionRepo.DeleteDiscount(order.Id, promotionId)
+	if err != nil {
+		log.Printf("PromotionService: %s", err)
+		return domain.Order{}, err
+	}
+
+	return s.recalculate(order.Id)
+}
+
+func (s promotionService) recalculate(orderId uint64) (domain.Order, error) {
+	err := s.orderRepo.Recalculate(orderId)
+	if err != nil {
+		log.Printf("PromotionService: %s", err)
+		return domain.Order{}, err
+	}
+
+	order, err := s.orderRepo.FindById(orderId)
+	if err != nil {
+		log.Printf("PromotionService: %s", err)
+		return domain.Order{}, err
+	}
+
+	return order, nil
+}
+
+func (s promotionService) validate(promotion domain.Promotion) error {
+	if promotion.Code == "" {
+		return errors.New("promotion code is required")
+	}
+
+	switch promotion.Type {
+	case domain.PROMOTION_PERCENTAGE:
+		if promotion.Value <= 0 || promotion.Value > 100 {
+			return errors.New("percentage must be between 0 and 100")
+		}
+	case domain.PROMOTION_FIXED:
+		if promotion.Value <= 0 {
+			return errors.New("discount value must be positive")
+		}
+	case domain.PROMOTION_FREE_SHIPPING:
+	case domain.PROMOTION_BUY_X_GET_Y:
+		if promotion.BuyAmount == 0 || promotion.GetAmount == 0 {
+			return errors.New("buy and get amounts are required")
+		}
+		if promotion.OfferId == nil {
+			return errors.New("offer is required for buy X get Y promotions")
+		}
+	default:
+		return errors.New("unknown promotion type")
+	}
+
+	if promotion.EndDate != nil && !promotion.EndDate.After(promotion.StartDate) {
+		return errors.New("end date must be after start date")
+	}
+
+	if promotion.OfferId != nil {
+		offer, err := s.offerRepo.FindById(*promotion.OfferId)
+		if err != nil {
+			return err
+		}
+		if promotion.FarmId != nil && offer.Farm.Id != *promotion.FarmId {
+			return errors.New("offer does not belong to the promotion farm")
+		}
+	}
+
+	return nil
+}
+
+func normalizePromotionCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets which have refilled completely are
+// dropped, so that one-off clients do not pile up in memory.
+const sweepInterval = 10 * time.Minute
+
+// Limiter decides whether a request identified by the key may go on. The
+// in-memory implementation counts requests per instance only.
+type Limiter interface {
+	// Allow takes a token from the bucket of the key. When the bucket is
+	// empty it returns false and the time until the next token.
+	Allow(key string) (bool, time.Duration)
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+type tokenBucket struct {
+	mu        sync.Mutex
+	rate      float64 // tokens per second
+	burst     float64
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewTokenBucket allows bursts of up to burst requests per key, refilled
+// at rate requests per second.
+func NewTokenBucket(rate float64, burst int) Limiter {
+	return &tokenBucket{
+		rate:      rate,
+		burst:     float64(burst),
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// PerMinute converts a number of requests per minute into a rate for
+// NewTokenBucket.
+func PerMinute(n int) float64 {
+	return float64(n) / 60
+}
+
+func (l *tokenBucket) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.updated).Seconds()*l.rate)
+	b.updated = now
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+
+	b.tokens--
+	return true, 0
+}
+
+func (l *tokenBucket) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
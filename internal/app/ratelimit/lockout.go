@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LockedError is returned for a key which is locked out. RetryAfter tells
+// the client when to try again.
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e LockedError) Error() string {
+	return fmt.Sprintf("too many attempts, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+// Lockout counts failed attempts per key, e.g. per account or per IP. Once
+// the key has failed maxAttempts times in a row it is locked, and every
+// next lock lasts twice as long as the previous one.
+type Lockout interface {
+	// Check returns a LockedError while the key is locked.
+	Check(key string) error
+	Fail(key string)
+	Reset(key string)
+}
+
+type attempts struct {
+	failures    int
+	lockouts    int
+	lockedUntil time.Time
+	updated     time.Time
+}
+
+type memoryLockout struct {
+	mu          sync.Mutex
+	maxAttempts int
+	duration    time.Duration
+	maxDuration time.Duration
+	keys        map[string]*attempts
+	lastSweep   time.Time
+	now         func() time.Time
+}
+
+// NewLockout locks the key for duration after maxAttempts failures in a
+// row, doubling the lock on each next series up to maxDuration. A key
+// which has not failed for maxDuration starts from scratch.
+func NewLockout(maxAttempts int, duration time.Duration, maxDuration time.Duration) Lockout {
+	return &memoryLockout{
+		maxAttempts: maxAttempts,
+		duration:    duration,
+		maxDuration: maxDuration,
+		keys:        make(map[string]*attempts),
+		lastSweep:   time.Now(),
+		now:         time.Now,
+	}
+}
+
+func (l *memoryLockout) Check(key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	a, ok := l.keys[key]
+	if !ok {
+		return nil
+	}
+
+	now := l.now()
+	if now.Before(a.lockedUntil) {
+		return LockedError{RetryAfter: a.lockedUntil.Sub(now)}
+	}
+	return nil
+}
+
+func (l *memoryLockout) Fail(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	a := l.keys[key]
+	if a == nil || now.Sub(a.updated) > l.maxDuration {
+		a = &attempts{}
+		l.keys[key] = a
+	}
+
+	a.failures++
+	a.updated = now
+	if a.failures < l.maxAttempts {
+		return
+	}
+
+	lock := l.duration << a.lockouts
+	if lock > l.maxDuration || lock <= 0 {
+		lock = l.maxDuration
+	}
+	a.lockedUntil = now.Add(lock)
+	a.lockouts++
+	a.failures = 0
+}
+
+func (l *memoryLockout) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.keys, key)
+}
+
+func (l *memoryLockout) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, a := range l.keys {
+		if now.Sub(a.updated) > l.maxDuration && now.After(a.lockedUntil) {
+			delete(l.keys, key)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package app
+
+import (
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/database"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+type ReviewService interface {
+	Find(uint64) (interface{}, error)
+	Save(review domain.Review) (domain.Review, error)
+	Update(review domain.Review, req domain.Review) (domain.Review, error)
+	Delete(review domain.Review) error
+	FindAllByOfferId(offerId uint64, p domain.Pagination) (domain.Reviews, error)
+	FindAllByFarmId(farmId uint64, p domain.Pagination) (domain.Reviews, error)
+	Reply(review domain.Review, reply string) (domain.Review, error)
+	Report(review domain.Review, report domain.ReviewReport) error
+	Moderate(review domain.Review, status domain.ReviewStatus) (domain.Review, error)
+}
+
+type reviewService struct {
+	reviewRepo   database.ReviewRepository
+	imageService ImageModelService
+}
+
+func NewReviewService(rr database.ReviewRepository, ims ImageModelService) ReviewService {
+	return reviewService{
+		reviewRepo:   rr,
+		imageService: ims,
+	}
+}
+
+func (s reviewService) Find(id uint64) (interface{}, error) {
+	review, err := s.reviewRepo.FindById(id)
+	if err != nil {
+		log.Printf("ReviewService -> Find: %s", err)
+		return domain.Review{}, err
+	}
+	return review, err
+}
+
+// Save creates a review of an offer. Only buyers who have received the offer
+// in a COMPLETED order can write a review, and only one review per offer.
+func (s reviewService) Save(review domain.Review) (domain.Review, error) {
+	orderItem, err := s.reviewRepo.FindCompletedOrderItem(review.User.Id, review.OfferId)
+	if errors.Is(err, db.ErrNoMoreRows) {
+		return domain.Review{}, errors.New("only buyers with a completed order of this offer can review it")
+	} else if err != nil {
+		log.Printf("ReviewService: %s", err)
+		return domain.Review{}, err
+	}
+
+	_, err = s.reviewRepo.FindByUserAndOffer(review.User.Id, review.OfferId)
+	if err == nil {
+		return domain.Review{}, errors.New("you have already reviewed this offer")
+	} else if !errors.Is(err, db.ErrNoMoreRows) {
+		log.Printf("ReviewService: %s", err)
+		return domain.Review{}, err
+	}
+
+	images := review.Images
+	review.OrderItemId = orderItem.Id
+	review.FarmId = orderItem.Farm.Id
+	review.Status = domain.REVIEW_PUBLISHED
+	review.Reply, review.ReplyDate = nil, nil
+	review, err = s.reviewRepo.Save(review)
+	if err != nil {
+		log.Printf("ReviewService: %s", err)
+		return domain.Review{}, err
+	}
+
+	for _, image := range images {
+		image.Entity = "reviews"
+		image.EntityId = review.Id
+		_, err = s.imageService.Save(image)
+		if err != nil {
+			log.Printf("ReviewService: %s", err)
+			return domain.Review{}, err
+		}
+	}
+
+	return s.recalculate(review)
+}
+
+func (s reviewService) Update(review domain.Review, req domain.Review) (domain.Review, error) {
+	review.Rating = req.Rating
+	review.Text = req.Text
+	review, err := s.reviewRepo.Update(review)
+	if err != nil {
+		log.Printf("ReviewService: %s", err)
+		return domain.Review{}, err
+	}
+
+	for _, image := range req.Images {
+		image.Entity = "reviews"
+		image.EntityId = review.Id
+		_, err = s.imageService.Save(image)
+		if err != nil {
+			log.Printf("ReviewService: %s", err)
+			return domain.Review{}, err
+		}
+	}
+
+	return s.recalculate(review)
+}
+
+func (s reviewService) Delete(review domain.Review) error {
+	err := s.reviewRepo.Delete(review.Id)
+	if err != nil {
+		log.Printf("ReviewService: %s", err)
+		return err
+	}
+
+	err = s.reviewRepo.RecalculateRatings(review.OfferId, review.FarmId)
+	if err != nil {
+		log.Printf("ReviewService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s reviewService) FindAllByOfferId(offerId uint64, p domain.Pagination) (domain.Reviews, error) {
+	reviews, err := s.reviewRepo.FindAllByOfferId(offerId, p)
+	if err != nil {
+		log.Printf("ReviewService: %s", err)
+		return domain.Reviews{}, err
+	}
+
+	return reviews, nil
+}
+
+func (s reviewService) FindAllByFarmId(farmId uint64, p domain.Pagination) (domain.Reviews, error) {
+	reviews, err := s.reviewRepo.FindAllByFarmId(farmId, p)
+	if err != nil {
+		log.Printf("ReviewService: %s", err)
+		return domain.Reviews{}, err
+	}
+
+	return reviews, nil
+}
+
+func (s reviewService) Reply(review domain.Review, reply string) (domain.Review, error) {
+	now := time.Now()
+	review.Reply = &reply
+	review.ReplyDate = &now
+	review, err := s.reviewRepo.Update(review)
+	if err != nil {
+		log.Printf("ReviewService: %s", err)
+		return domain.Review{}, err
+	}
+
+	return review, nil
+}
+
+// Report saves a complaint about the review. The report that brings the
+// open reports up to ReviewReportsToHide hides the review, it no longer
+// affects the ratings. Reports after that wait for the moderator.
+func (s reviewService) Report(review domain.Review, report domain.ReviewReport) error {
+	if review.User.Id == report.User.Id {
+		return domain.ErrOwnReviewReport
+	}
+
+	report.ReviewId = review.Id
+	_, err := s.reviewRepo.SaveReport(report)
+	if database.IsUniqueViolation(err) {
+		return domain.ErrReviewAlreadyReported
+	} else if err != nil {
+		log.Printf("ReviewService: %s", err)
+		return err
+	}
+
+	count, err := s.reviewRepo.CountReports(review.Id)
+	if err != nil {
+		log.Printf("ReviewService: %s", err)
+		return err
+	}
+
+	if count == domain.ReviewReportsToHide && review.Status != domain.REVIEW_HIDDEN {
+		review.Status = domain.REVIEW_HIDDEN
+		review, err = s.reviewRepo.Update(review)
+		if err != nil {
+			log.Printf("ReviewService: %s", err)
+			return err
+		}
+
+		_, err = s.recalculate(review)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Moderate lets a moderator hide a review or bring back one hidden by
+// reports. The decision resolves the open reports, so a restored review
+// is hidden again only by ReviewReportsToHide new ones.
+func (s reviewService) Moderate(review domain.Review, status domain.ReviewStatus) (domain.Review, error) {
+	err := s.reviewRepo.ResolveReports(review.Id)
+	if err != nil {
+		log.Printf("ReviewService: %s", err)
+		return domain.Review{}, err
+	}
+
+	if review.Status == status {
+		return review, nil
+	}
+
+	review.Status = status
+	review, err = s.reviewRepo.Update(review)
+	if err != nil {
+		log.Printf("ReviewService: %s", err)
+		return domain.Review{}, err
+	}
+
+	return s.recalculate(review)
+}
+
+func (s reviewService) recalculate(review domain.Review) (domain.Review, error) {
+	err := s.reviewRepo.RecalculateRatings(review.OfferId, review.FarmId)
+	if err != nil {
+		log.Printf("ReviewService: %s", err)
+		return domain.Review{}, err
+	}
+
+	review, err = s.reviewRepo.FindById(review.Id)
+	if err != nil {
+		log.Printf("ReviewService: %s", err)
+		return domain.Review{}, err
+	}
+
+	return review, nil
+}
@@ -0,0 +1,94 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 seed of RFC 6238 Appendix B, "12345678901234567890"
+// in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// TestValidateRfc6238 checks the SHA-1 test vectors of RFC 6238 Appendix B.
+// The RFC lists 8 digit codes, the last 6 digits are the 6 digit ones.
+func TestValidateRfc6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		at := time.Unix(tt.unix, 0)
+		step, ok := Validate(rfcSecret, tt.code, at)
+		if !ok {
+			t.Errorf("Validate(%q) at %d = false, want true", tt.code, tt.unix)
+			continue
+		}
+		if step != Step(at) {
+			t.Errorf("Validate(%q) at %d matched step %d, want %d", tt.code, tt.unix, step, Step(at))
+		}
+	}
+}
+
+func TestValidateWindow(t *testing.T) {
+	// 1111111111 falls into step 37037037.
+	const code = "050471"
+	at := time.Unix(1111111111, 0)
+	codeStep := Step(at)
+
+	tests := []struct {
+		name  string
+		shift int64
+		want  bool
+	}{
+		{"two steps behind", -2, false},
+		{"one step behind", -1, true},
+		{"same step", 0, true},
+		{"one step ahead", 1, true},
+		{"two steps ahead", 2, false},
+	}
+
+	for _, tt := range tests {
+		step, ok := Validate(rfcSecret, code, at.Add(time.Duration(tt.shift)*Period))
+		if ok != tt.want {
+			t.Errorf("%s: Validate = %v, want %v", tt.name, ok, tt.want)
+			continue
+		}
+		if ok && step != codeStep {
+			t.Errorf("%s: matched step %d, want %d", tt.name, step, codeStep)
+		}
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	at := time.Unix(59, 0)
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+	}{
+		{"wrong code", rfcSecret, "287083"},
+		{"8 digits", rfcSecret, "94287082"},
+		{"empty code", rfcSecret, ""},
+		{"invalid secret", "not base32!", "287082"},
+	}
+
+	for _, tt := range tests {
+		if _, ok := Validate(tt.secret, tt.code, at); ok {
+			t.Errorf("%s: Validate = true, want false", tt.name)
+		}
+	}
+}
+
+func TestValidateLowercaseSecret(t *testing.T) {
+	if _, ok := Validate("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "287082", time.Unix(59, 0)); !ok {
+		t.Error("Validate with a lowercase secret = false, want true")
+	}
+}
@@ -0,0 +1,310 @@
+package app
+
+import (
+	"boilerplate/config"
+	"boilerplate/internal/app/totp"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/database"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/upper/db/v4"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// recoveryCodeSize is the number of random bytes in a recovery code, which
+// makes 8 characters in base32.
+const recoveryCodeSize = 5
+
+var (
+	errTwoFactorEnabled  = errors.New("two-factor authentication is already enabled")
+	errTwoFactorDisabled = errors.New("two-factor authentication is not enabled")
+	errInvalidTwoFactor  = errors.New("invalid two-factor code")
+	errInvalidChallenge  = errors.New("two-factor challenge is invalid or expired, log in again")
+
+	recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+type TwoFactorService interface {
+	Enroll(user domain.User) (domain.TwoFactorEnrollment, error)
+	Confirm(user domain.User, code string) (domain.User, []string, error)
+	RegenerateRecoveryCodes(user domain.User, code string) ([]string, error)
+	Disable(user domain.User, req domain.DisableTwoFactor) (domain.User, error)
+	CreateChallenge(user domain.User) (string, error)
+	CompleteChallenge(req domain.TwoFactorLogin, check func(domain.User) error) (domain.User, error)
+}
+
+type twoFactorService struct {
+	twoFactorRepo database.TwoFactorRepository
+	userService   UserService
+	config        config.Configuration
+}
+
+func NewTwoFactorService(tfr database.TwoFactorRepository, us UserService, cf config.Configuration) TwoFactorService {
+	return twoFactorService{
+		twoFactorRepo: tfr,
+		userService:   us,
+		config:        cf,
+	}
+}
+
+// Enroll generates a new secret for the authenticator app. 2FA is not on
+// until the user confirms it with the first code.
+func (s twoFactorService) Enroll(user domain.User) (domain.TwoFactorEnrollment, error) {
+	if user.IsTwoFactorEnabled() {
+		return domain.TwoFactorEnrollment{}, errTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		log.Printf("TwoFactorService: %s", err)
+		return domain.TwoFactorEnrollment{}, err
+	}
+
+	err = s.twoFactorRepo.SetTotp(user.Id, &secret, nil)
+	if err != nil {
+		log.Printf("TwoFactorService: %s", err)
+		return domain.TwoFactorEnrollment{}, err
+	}
+
+	return domain.TwoFactorEnrollment{
+		Secret: secret,
+		Url:    totp.Url(s.config.TotpIssuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm turns 2FA on and returns the recovery codes. They are shown only
+// once, only their hashes are kept.
+func (s twoFactorService) Confirm(user domain.User, code string) (domain.User, []string, error) {
+	if user.IsTwoFactorEnabled() {
+		return domain.User{}, nil, errTwoFactorEnabled
+	}
+	if user.TotpSecret == nil {
+		return domain.User{}, nil, errors.New("two-factor authentication was not requested")
+	}
+
+	ok, err := s.checkTotp(user, code)
+	if err != nil {
+		return domain.User{}, nil, err
+	}
+	if !ok {
+		return domain.User{}, nil, errInvalidTwoFactor
+	}
+
+	now := time.Now()
+	err = s.twoFactorRepo.SetTotp(user.Id, user.TotpSecret, &now)
+	if err != nil {
+		log.Printf("TwoFactorService: %s", err)
+		return domain.User{}, nil, err
+	}
+	user, err = s.userService.FindById(user.Id)
+	if err != nil {
+		log.Printf("TwoFactorService: %s", err)
+		return domain.User{}, nil, err
+	}
+
+	codes, err := s.generateRecoveryCodes(user.Id)
+	if err != nil {
+		return domain.User{}, nil, err
+	}
+
+	return user, codes, nil
+}
+
+func (s twoFactorService) RegenerateRecoveryCodes(user domain.User, code string) ([]string, error) {
+	if !user.IsTwoFactorEnabled() {
+		return nil, errTwoFactorDisabled
+	}
+
+	ok, err := s.checkTotp(user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errInvalidTwoFactor
+	}
+
+	return s.generateRecoveryCodes(user.Id)
+}
+
+// Disable needs both the password and a code, so a stolen session alone
+// can't turn 2FA off.
+func (s twoFactorService) Disable(user domain.User, req domain.DisableTwoFactor) (domain.User, error) {
+	if !user.IsTwoFactorEnabled() {
+		return domain.User{}, errTwoFactorDisabled
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)) != nil {
+		return domain.User{}, errInvalidCredentials
+	}
+
+	ok, err := s.checkCode(user, req.Code)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if !ok {
+		return domain.User{}, errInvalidTwoFactor
+	}
+
+	err = s.twoFactorRepo.SetTotp(user.Id, nil, nil)
+	if err != nil {
+		log.Printf("TwoFactorService: %s", err)
+		return domain.User{}, err
+	}
+	user, err = s.userService.FindById(user.Id)
+	if err != nil {
+		log.Printf("TwoFactorService: %s", err)
+		return domain.User{}, err
+	}
+
+	err = s.twoFactorRepo.DeleteRecoveryCodes(user.Id)
+	if err != nil {
+		log.Printf("TwoFactorService: %s", err)
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+func (s twoFactorService) CreateChallenge(user domain.User) (string, error) {
+	token, err := generateRefreshToken()
+	if err != nil {
+		log.Printf("TwoFactorService: %s", err)
+		return "", err
+	}
+
+	_, err = s.twoFactorRepo.SaveChallenge(domain.TwoFactorChallenge{
+		UserId:      user.Id,
+		TokenHash:   hashRefreshToken(token),
+		ExpiresDate: time.Now().Add(domain.TwoFactorChallengeTTL),
+	})
+	if err != nil {
+		log.Printf("TwoFactorService: %s", err)
+		return "", err
+	}
+
+	return token, nil
+}
+
+// CompleteChallenge accepts a code from the authenticator app or one of
+// the recovery codes and returns the user to start the session for. check
+// runs before the code is looked at and can refuse the attempt, e.g. for a
+// locked out account. A wrong code returns the user of the challenge along
+// with errInvalidTwoFactor.
+func (s twoFactorService) CompleteChallenge(req domain.TwoFactorLogin, check func(domain.User) error) (domain.User, error) {
+	challenge, err := s.twoFactorRepo.FindChallenge(hashRefreshToken(req.ChallengeToken))
+	if errors.Is(err, db.ErrNoMoreRows) {
+		return domain.User{}, errInvalidChallenge
+	} else if err != nil {
+		log.Printf("TwoFactorService: %s", err)
+		return domain.User{}, err
+	}
+	if !challenge.IsActive(time.Now()) {
+		return domain.User{}, errInvalidChallenge
+	}
+
+	user, err := s.userService.FindById(challenge.UserId)
+	if err != nil {
+		log.Printf("TwoFactorService: %s", err)
+		return domain.User{}, err
+	}
+	if !user.IsTwoFactorEnabled() {
+		return domain.User{}, errInvalidChallenge
+	}
+	err = check(user)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	claimed, err := s.twoFactorRepo.ClaimChallengeAttempt(challenge.Id, domain.TwoFactorChallengeMaxAttempts, time.Now())
+	if err != nil {
+		log.Printf("TwoFactorService: %s", err)
+		return domain.User{}, err
+	}
+	if !claimed {
+		return domain.User{}, errInvalidChallenge
+	}
+
+	ok, err := s.checkCode(user, req.Code)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if !ok {
+		return user, errInvalidTwoFactor
+	}
+
+	err = s.twoFactorRepo.DeleteChallenge(challenge.Id)
+	if err != nil {
+		log.Printf("TwoFactorService: %s", err)
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+// checkCode accepts either a code from the app or a recovery code.
+func (s twoFactorService) checkCode(user domain.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return s.checkTotp(user, code)
+	}
+
+	ok, err := s.twoFactorRepo.UseRecoveryCode(user.Id, hashRefreshToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		log.Printf("TwoFactorService: %s", err)
+		return false, err
+	}
+	return ok, nil
+}
+
+func (s twoFactorService) checkTotp(user domain.User, code string) (bool, error) {
+	if user.TotpSecret == nil {
+		return false, nil
+	}
+
+	step, ok := totp.Validate(*user.TotpSecret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	ok, err := s.twoFactorRepo.UseTotpStep(user.Id, step)
+	if err != nil {
+		log.Printf("TwoFactorService: %s", err)
+		return false, err
+	}
+	return ok, nil
+}
+
+func (s twoFactorService) generateRecoveryCodes(userId uint64) ([]string, error) {
+	codes := make([]string, domain.RecoveryCodesCount)
+	hashes := make([]string, domain.RecoveryCodesCount)
+	for i := range codes {
+		b := make([]byte, recoveryCodeSize)
+		_, err := rand.Read(b)
+		if err != nil {
+			log.Printf("TwoFactorService: %s", err)
+			return nil, err
+		}
+
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+		codes[i] = code[:len(code)/2] + "-" + code[len(code)/2:]
+		hashes[i] = hashRefreshToken(code)
+	}
+
+	err := s.twoFactorRepo.ReplaceRecoveryCodes(userId, hashes)
+	if err != nil {
+		log.Printf("TwoFactorService: %s", err)
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// normalizeRecoveryCode lets users type the code with or without the dash
+// and in any case.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
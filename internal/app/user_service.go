@@ -9,6 +9,7 @@ import (
 )
 
 type UserService interface {
+	Find(uint64) (interface{}, error)
 	FindByEmail(email string) (domain.User, error)
 	FindByPhoneNumber(phoneNumber string) (domain.User, error)
 	Save(user domain.User) (domain.User, error)
@@ -50,6 +51,9 @@ func (s userService) FindByPhoneNumber(phoneNumber string) (domain.User, error)
 
 func (s userService) Save(user domain.User) (domain.User, error) {
 	var err error
+	if user.Role == "" {
+		user.Role = domain.ROLE_BUYER
+	}
 
 	user.Password, err = s.GeneratePasswordHash(user.Password)
 	if err != nil {
@@ -66,6 +70,16 @@ func (s userService) Save(user domain.User) (domain.User, error) {
 	return u, err
 }
 
+func (s userService) Find(id uint64) (interface{}, error) {
+	user, err := s.userRepo.FindById(id)
+	if err != nil {
+		log.Printf("UserService: %s", err)
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
 func (s userService) FindById(id uint64) (domain.User, error) {
 	user, err := s.userRepo.FindById(id)
 	if err != nil {
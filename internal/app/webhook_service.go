@@ -0,0 +1,571 @@
+package app
+
+import (
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/database"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+const (
+	webhookTimeout           = 10 * time.Second
+	webhookRetryBase         = 30 * time.Second
+	webhookBatchSize         = 50
+	webhookClaimLease        = 15 * time.Minute // outlasts sending a whole batch
+	webhookResponseDrainSize = 4096
+)
+
+// errWebhookAddress is returned for webhook hosts that resolve to an
+// address of the server's own network. The server must not be usable to
+// reach its internal services.
+var errWebhookAddress = errors.New("webhook url must point to a public address")
+
+type WebhookService interface {
+	Find(uint64) (interface{}, error)
+	Save(webhook domain.Webhook) (domain.Webhook, error)
+	Update(webhook domain.Webhook, req domain.Webhook) (domain.Webhook, error)
+	RotateSecret(webhook domain.Webhook) (domain.Webhook, error)
+	Delete(webhook domain.Webhook) error
+	FindAllByFarmId(farmId uint64) ([]domain.Webhook, error)
+	FindDeliveries(webhook domain.Webhook, p domain.Pagination) (domain.WebhookDeliveries, error)
+	Redeliver(webhook domain.Webhook, deliveryId uint64) (domain.WebhookDelivery, error)
+	DispatchOrder(event domain.WebhookEvent, order domain.Order)
+	DispatchOffer(event domain.WebhookEvent, offer domain.Offer)
+	ProcessDeliveries(now time.Time) error
+	Run(ctx context.Context, interval time.Duration)
+}
+
+type webhookService struct {
+	webhookRepo   database.WebhookRepository
+	orderItemRepo database.OrderItemRepository
+	client        *http.Client
+}
+
+func NewWebhookService(wr database.WebhookRepository, oir database.OrderItemRepository) WebhookService {
+	return webhookService{
+		webhookRepo:   wr,
+		orderItemRepo: oir,
+		client:        newWebhookClient(),
+	}
+}
+
+// newWebhookClient returns a client that refuses to connect to
+// non-public addresses. The check runs on the resolved address of every
+// connection, redirects included, so a host re-pointed in DNS after
+// registration is refused as well.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(network string, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !isPublicIp(ip) {
+				return errWebhookAddress
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: webhookTimeout, Transport: transport}
+}
+
+type webhookOrderItemPayload struct {
+	Id         uint64  `json:"id"`
+	OfferId    uint64  `json:"offer_id"`
+	Title      string  `json:"title"`
+	Amount     uint32  `json:"amount"`
+	Price      float64 `json:"price"`
+	TotalPrice float64 `json:"total_price"`
+}
+
+type webhookOrderPayload struct {
+	Id             uint64                    `json:"id"`
+	Status         string                    `json:"status"`
+	Comment        string                    `json:"comment"`
+	Address        *string                   `json:"address"`
+	PostOffice     *string                   `json:"post_office"`
+	PostOfficeCity *string                   `json:"post_office_city"`
+	Ttn            *string                   `json:"ttn"`
+	ProductsPrice  float64                   `json:"products_price"`
+	ShippingPrice  float64                   `json:"shipping_price"`
+	DiscountPrice  float64                   `json:"discount_price"`
+	TotalPrice     float64                   `json:"total_price"`
+	Items          []webhookOrderItemPayload `json:"items"`
+	CreatedDate    time.Time                 `json:"created_date"`
+}
+
+type webhookOfferPayload struct {
+	Id       uint64  `json:"id"`
+	FarmId   uint64  `json:"farm_id"`
+	Title    string  `json:"title"`
+	Category string  `json:"category"`
+	Price    float64 `json:"price"`
+	Unit     string  `json:"unit"`
+	Stock    uint    `json:"stock"`
+	Status   bool    `json:"status"`
+}
+
+type webhookEnvelope struct {
+	Id          uint64          `json:"id"`
+	Event       string          `json:"event"`
+	CreatedDate time.Time       `json:"created_date"`
+	Data        json.RawMessage `json:"data"`
+}
+
+func (s webhookService) Find(id uint64) (interface{}, error) {
+	webhook, err := s.webhookRepo.FindById(id)
+	if err != nil {
+		log.Printf("WebhookService -> Find: %s", err)
+		return domain.Webhook{}, err
+	}
+	return webhook, err
+}
+
+// Save registers a webhook with a freshly generated signing secret.
+func (s webhookService) Save(webhook domain.Webhook) (domain.Webhook, error) {
+	err := validateWebhook(webhook)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	webhook.Secret, err = generateWebhookSecret()
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return domain.Webhook{}, err
+	}
+
+	webhook.IsActive = true
+	webhook, err = s.webhookRepo.Save(webhook)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return domain.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+func (s webhookService) Update(webhook domain.Webhook, req domain.Webhook) (domain.Webhook, error) {
+	webhook.Url = req.Url
+	webhook.Events = req.Events
+	webhook.IsActive = req.IsActive
+
+	err := validateWebhook(webhook)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	webhook, err = s.webhookRepo.Update(webhook)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return domain.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+// RotateSecret replaces the signing secret. Deliveries sent from now on are
+// signed with the new one.
+func (s webhookService) RotateSecret(webhook domain.Webhook) (domain.Webhook, error) {
+	var err error
+	webhook.Secret, err = generateWebhookSecret()
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return domain.Webhook{}, err
+	}
+
+	webhook, err = s.webhookRepo.Update(webhook)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return domain.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+func (s webhookService) Delete(webhook domain.Webhook) error {
+	err := s.webhookRepo.Delete(webhook.Id)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s webhookService) FindAllByFarmId(farmId uint64) ([]domain.Webhook, error) {
+	webhooks, err := s.webhookRepo.FindAllByFarmId(farmId)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return []domain.Webhook{}, err
+	}
+
+	return webhooks, nil
+}
+
+func (s webhookService) FindDeliveries(webhook domain.Webhook, p domain.Pagination) (domain.WebhookDeliveries, error) {
+	deliveries, err := s.webhookRepo.FindDeliveriesByWebhookId(webhook.Id, p)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return domain.WebhookDeliveries{}, err
+	}
+
+	return deliveries, nil
+}
+
+// Redeliver queues a new delivery with the same payload as the given one.
+// The original delivery is kept in the log untouched.
+func (s webhookService) Redeliver(webhook domain.Webhook, deliveryId uint64) (domain.WebhookDelivery, error) {
+	delivery, err := s.webhookRepo.FindDeliveryById(deliveryId)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return domain.WebhookDelivery{}, err
+	}
+	if delivery.WebhookId != webhook.Id {
+		return domain.WebhookDelivery{}, errors.New("delivery does not belong to this webhook")
+	}
+
+	return s.queue(webhook.Id, delivery.Event, delivery.Payload)
+}
+
+// DispatchOrder queues the event for the webhooks of the farms in the order.
+// Draft orders are private to the buyer and are never sent.
+func (s webhookService) DispatchOrder(event domain.WebhookEvent, order domain.Order) {
+	if order.Status == domain.DRAFT {
+		return
+	}
+
+	orderItems := order.OrderItems
+	if len(orderItems) == 0 {
+		var err error
+		orderItems, err = s.orderItemRepo.FindAllWithoutPagination(order.Id)
+		if err != nil {
+			log.Printf("WebhookService: %s", err)
+			return
+		}
+	}
+
+	byFarm := make(map[uint64][]webhookOrderItemPayload)
+	for _, item := range orderItems {
+		byFarm[item.Farm.Id] = append(byFarm[item.Farm.Id], webhookOrderItemPayload{
+			Id:         item.Id,
+			OfferId:    item.Offer.Id,
+			Title:      item.Title,
+			Amount:     item.Amount,
+			Price:      item.Price,
+			TotalPrice: item.TotalPrice,
+		})
+	}
+
+	for farmId, items := range byFarm {
+		s.dispatch(farmId, event, webhookOrderPayload{
+			Id:             order.Id,
+			Status:         string(order.Status),
+			Comment:        order.Comment,
+			Address:        order.Address,
+			PostOffice:     order.PostOffice,
+			PostOfficeCity: order.PostOfficeCity,
+			Ttn:            order.Ttn,
+			ProductsPrice:  order.ProductsPrice,
+			ShippingPrice:  order.ShippingPrice,
+			DiscountPrice:  order.DiscountPrice,
+			TotalPrice:     order.TotalPrice,
+			Items:          items,
+			CreatedDate:    order.CreatedDate,
+		})
+	}
+}
+
+func (s webhookService) DispatchOffer(event domain.WebhookEvent, offer domain.Offer) {
+	s.dispatch(offer.Farm.Id, event, webhookOfferPayload{
+		Id:       offer.Id,
+		FarmId:   offer.Farm.Id,
+		Title:    offer.Title,
+		Category: offer.Category,
+		Price:    offer.Price,
+		Unit:     offer.Unit,
+		Stock:    offer.Stock,
+		Status:   offer.Status,
+	})
+}
+
+// ProcessDeliveries sends all deliveries whose next attempt is due. Each
+// batch is claimed first, so several instances can process at once.
+func (s webhookService) ProcessDeliveries(now time.Time) error {
+	for {
+		deliveries, err := s.webhookRepo.ClaimDueDeliveries(now, now.Add(webhookClaimLease), webhookBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, delivery := range deliveries {
+			s.attempt(delivery)
+		}
+
+		if len(deliveries) < webhookBatchSize {
+			return nil
+		}
+	}
+}
+
+// Run processes due deliveries every interval until ctx is cancelled.
+func (s webhookService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			err := s.ProcessDeliveries(now)
+			if err != nil {
+				log.Printf("WebhookService: %s", err)
+			}
+		}
+	}
+}
+
+func (s webhookService) dispatch(farmId uint64, event domain.WebhookEvent, data interface{}) {
+	if farmId == 0 {
+		return
+	}
+
+	webhooks, err := s.webhookRepo.FindAllByFarmId(farmId)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return
+	}
+
+	var payload []byte
+	for _, webhook := range webhooks {
+		if !webhook.IsActive || !webhook.Subscribes(event) {
+			continue
+		}
+
+		if payload == nil {
+			payload, err = json.Marshal(data)
+			if err != nil {
+				log.Printf("WebhookService: %s", err)
+				return
+			}
+		}
+
+		_, err = s.queue(webhook.Id, event, string(payload))
+		if err != nil {
+			log.Printf("WebhookService: %s", err)
+		}
+	}
+}
+
+func (s webhookService) queue(webhookId uint64, event domain.WebhookEvent, payload string) (domain.WebhookDelivery, error) {
+	now := time.Now()
+	delivery, err := s.webhookRepo.SaveDelivery(domain.WebhookDelivery{
+		WebhookId:       webhookId,
+		Event:           event,
+		Payload:         payload,
+		Status:          domain.DELIVERY_PENDING,
+		NextAttemptDate: &now,
+	})
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return domain.WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
+
+// attempt sends the delivery once and schedules the next attempt with
+// exponential backoff when it fails.
+func (s webhookService) attempt(delivery domain.WebhookDelivery) {
+	webhook, err := s.webhookRepo.FindById(delivery.WebhookId)
+	if err != nil && !errors.Is(err, db.ErrNoMoreRows) {
+		// The webhook could not be read, not the endpoint. Try again later
+		// without spending an attempt.
+		log.Printf("WebhookService: delivery %d: %s", delivery.Id, err)
+		next := time.Now().Add(webhookRetryBase)
+		delivery.Status, delivery.NextAttemptDate = domain.DELIVERY_PENDING, &next
+		s.saveAttempt(delivery)
+		return
+	}
+	if err != nil || !webhook.IsActive {
+		msg := "webhook is deleted or disabled"
+		delivery.Status, delivery.Error, delivery.NextAttemptDate = domain.DELIVERY_FAILED, &msg, nil
+		s.saveAttempt(delivery)
+		return
+	}
+
+	delivery.Attempts++
+	delivery.ResponseCode, delivery.Error = nil, nil
+
+	code, err := s.send(webhook, delivery)
+	if code != 0 {
+		delivery.ResponseCode = &code
+	}
+	if err == nil && code >= 200 && code < 300 {
+		now := time.Now()
+		delivery.Status, delivery.DeliveredDate, delivery.NextAttemptDate = domain.DELIVERY_SUCCEEDED, &now, nil
+		s.saveAttempt(delivery)
+		return
+	}
+
+	if err == nil {
+		err = fmt.Errorf("endpoint responded with status %d", code)
+	}
+	msg := err.Error()
+	delivery.Error = &msg
+
+	if delivery.Attempts >= domain.WebhookMaxAttempts {
+		delivery.Status, delivery.NextAttemptDate = domain.DELIVERY_FAILED, nil
+	} else {
+		next := time.Now().Add(webhookRetryBase << (delivery.Attempts - 1))
+		delivery.Status, delivery.NextAttemptDate = domain.DELIVERY_PENDING, &next
+	}
+	s.saveAttempt(delivery)
+}
+
+func (s webhookService) saveAttempt(delivery domain.WebhookDelivery) {
+	_, err := s.webhookRepo.UpdateDelivery(delivery)
+	if err != nil {
+		log.Printf("WebhookService: delivery %d: %s", delivery.Id, err)
+	}
+}
+
+// send posts the delivery to the webhook URL. The request is signed with
+// the webhook secret: the X-Webhook-Signature header holds
+// "t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>">".
+// Only the status code of the response is kept.
+func (s webhookService) send(webhook domain.Webhook, delivery domain.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(webhookEnvelope{
+		Id:          delivery.Id,
+		Event:       string(delivery.Event),
+		CreatedDate: delivery.CreatedDate,
+		Data:        json.RawMessage(delivery.Payload),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequest(http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Horodyna-Webhooks/1.0")
+	req.Header.Set("X-Webhook-Id", strconv.FormatUint(webhook.Id, 10))
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(delivery.Id, 10))
+	req.Header.Set("X-Webhook-Event", string(delivery.Event))
+	req.Header.Set("X-Webhook-Signature", "t="+timestamp+",v1="+signWebhook(webhook.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, webhookResponseDrainSize))
+	return resp.StatusCode, nil
+}
+
+func signWebhook(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+func validateWebhook(webhook domain.Webhook) error {
+	u, err := url.Parse(webhook.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("webhook url must be an absolute http or https url")
+	}
+	err = checkWebhookHost(u.Hostname())
+	if err != nil {
+		return err
+	}
+
+	if len(webhook.Events) == 0 {
+		return errors.New("at least one event is required")
+	}
+	for _, event := range webhook.Events {
+		known := false
+		for _, e := range domain.GetWebhookEvents() {
+			if e == event {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown webhook event %s", event)
+		}
+	}
+
+	return nil
+}
+
+// checkWebhookHost resolves the host and fails if any of its addresses is
+// not public.
+func checkWebhookHost(host string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("webhook host %s can't be resolved", host)
+	}
+	for _, addr := range addrs {
+		if !isPublicIp(addr.IP) {
+			return errWebhookAddress
+		}
+	}
+
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, RFC 6598.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func isPublicIp(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
@@ -0,0 +1,20 @@
+package domain
+
+// AccountExport is everything the user has given us: what they can take
+// with them before deleting the account.
+type AccountExport struct {
+	User      User
+	Addresses []Address
+	Orders    []Order
+	Farms     []Farm
+	Offers    []Offer
+	Images    []Image
+}
+
+type DeleteAccount struct {
+	Password string
+}
+
+// DELETED_USER_NAME replaces the name of a deleted account on the orders
+// and reviews it leaves behind.
+const DELETED_USER_NAME = "Deleted user"
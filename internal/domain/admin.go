@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+// Filters of the admin listings. Zero values mean "any".
+
+type UserFilter struct {
+	Search  string
+	Role    Role
+	Blocked *bool
+}
+
+type FarmFilter struct {
+	Search   string
+	UserId   uint64
+	MemberId uint64
+}
+
+type OfferFilter struct {
+	Search string
+	FarmId uint64
+	UserId uint64
+	Status *bool
+}
+
+type OrderFilter struct {
+	Status OrderStatus
+	UserId uint64
+	FarmId uint64
+	From   *time.Time
+	To     *time.Time
+	// ExcludeDrafts hides the carts the buyers are still filling.
+	ExcludeDrafts bool
+}
+
+type PaymentFilter struct {
+	Status InvoiceStatus
+	From   *time.Time
+	To     *time.Time
+}
+
+type Invoices struct {
+	Items []Invoice
+	Total uint64
+	Pages uint
+}
+
+// AdminAction is a change made by the staff on behalf of a customer.
+// Reason is shown to the affected user and kept in the audit log.
+type AdminAction struct {
+	Reason string
+	Status OrderStatus
+	Role   Role
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"time"
+)
+
+type ApiKeyScope string
+
+const (
+	API_KEY_SCOPE_OFFERS_READ  ApiKeyScope = "offers:read"
+	API_KEY_SCOPE_OFFERS_WRITE ApiKeyScope = "offers:write"
+	API_KEY_SCOPE_ORDERS_READ  ApiKeyScope = "orders:read"
+)
+
+// API_KEY_PREFIX starts every key, so a key is easy to tell from a JWT and
+// to find when it leaks into a repository.
+const API_KEY_PREFIX = "hrd_"
+
+// ApiKey lets scripts of a farm work with its offers and orders without a
+// user session. Only the hash of the key is stored, Key is filled once
+// when the key is created.
+type ApiKey struct {
+	Id           uint64
+	User         User
+	FarmId       uint64
+	Name         string
+	Prefix       string
+	Key          string
+	KeyHash      string
+	Scopes       []ApiKeyScope
+	ExpiresDate  *time.Time
+	LastUsedDate *time.Time
+	RevokedDate  *time.Time
+	CreatedDate  time.Time
+	UpdatedDate  time.Time
+}
+
+func (k ApiKey) GetUserId() uint64 {
+	return k.User.Id
+}
+
+func (k ApiKey) HasScope(scope ApiKeyScope) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (k ApiKey) IsActive(now time.Time) bool {
+	return k.RevokedDate == nil && (k.ExpiresDate == nil || now.Before(*k.ExpiresDate))
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"reflect"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AUDIT_USER_BLOCKED         AuditAction = "USER_BLOCKED"
+	AUDIT_USER_UNBLOCKED       AuditAction = "USER_UNBLOCKED"
+	AUDIT_USER_ROLE_CHANGED    AuditAction = "USER_ROLE_CHANGED"
+	AUDIT_USER_DELETED         AuditAction = "USER_DELETED"
+	AUDIT_OFFER_UNPUBLISHED    AuditAction = "OFFER_UNPUBLISHED"
+	AUDIT_OFFER_PRICE_CHANGED  AuditAction = "OFFER_PRICE_CHANGED"
+	AUDIT_FARM_DELETED         AuditAction = "FARM_DELETED"
+	AUDIT_ORDER_STATUS_CHANGED AuditAction = "ORDER_STATUS_CHANGED"
+	AUDIT_ORDER_STATUS_FORCED  AuditAction = "ORDER_STATUS_FORCED"
+)
+
+type AuditEntity string
+
+const (
+	AUDIT_ENTITY_USER  AuditEntity = "users"
+	AUDIT_ENTITY_FARM  AuditEntity = "farms"
+	AUDIT_ENTITY_OFFER AuditEntity = "offers"
+	AUDIT_ENTITY_ORDER AuditEntity = "orders"
+)
+
+// Actor is whoever triggered an action. A zero UserId means the system
+// itself, e.g. a scheduled price change.
+type Actor struct {
+	UserId    uint64
+	Ip        string
+	RequestId string
+}
+
+type AuditEvent struct {
+	Id          uint64
+	ActorId     uint64
+	Action      AuditAction
+	Entity      AuditEntity
+	EntityId    uint64
+	Reason      string
+	Before      map[string]interface{}
+	After       map[string]interface{}
+	Ip          string
+	RequestId   string
+	CreatedDate time.Time
+}
+
+type AuditEvents struct {
+	Items []AuditEvent
+	Total uint64
+	Pages uint
+}
+
+type AuditEventFilter struct {
+	ActorId  uint64
+	Action   AuditAction
+	Entity   AuditEntity
+	EntityId uint64
+	From     *time.Time
+	To       *time.Time
+}
+
+func NewAuditEvent(actor Actor, action AuditAction, entity AuditEntity, entityId uint64) AuditEvent {
+	return AuditEvent{
+		ActorId:   actor.UserId,
+		Action:    action,
+		Entity:    entity,
+		EntityId:  entityId,
+		Ip:        actor.Ip,
+		RequestId: actor.RequestId,
+	}
+}
+
+// WithChanges keeps only the fields which differ between the two states,
+// so the event shows exactly what was changed.
+func (e AuditEvent) WithChanges(before, after map[string]interface{}) AuditEvent {
+	e.Before = make(map[string]interface{})
+	e.After = make(map[string]interface{})
+	for key, value := range before {
+		if !reflect.DeepEqual(value, after[key]) {
+			e.Before[key] = value
+		}
+	}
+	for key, value := range after {
+		if !reflect.DeepEqual(value, before[key]) {
+			e.After[key] = value
+		}
+	}
+	return e
+}
+
+func (e AuditEvent) WithReason(reason string) AuditEvent {
+	e.Reason = reason
+	return e
+}
@@ -16,17 +16,20 @@ type Points struct {
 }
 
 type Farm struct {
-	Id          uint64
-	Name        *string
-	City        string
-	Address     string
-	User        User
-	Longitude   float64
-	Latitude    float64
-	AllImages   []Image
-	CreatedDate time.Time
-	UpdatedDate time.Time
-	DeletedDate *time.Time
+	Id           uint64
+	Name         *string
+	City         string
+	Address      string
+	User         User
+	Longitude    float64
+	Latitude     float64
+	AllImages    []Image
+	Rating       float64
+	ReviewsCount uint64
+	IsFollowed   bool
+	CreatedDate  time.Time
+	UpdatedDate  time.Time
+	DeletedDate  *time.Time
 }
 
 type Farms struct {
@@ -38,3 +41,7 @@ type Farms struct {
 func (f Farm) GetUserId() uint64 {
 	return f.User.Id
 }
+
+func (f Farm) GetFarmId() uint64 {
+	return f.Id
+}
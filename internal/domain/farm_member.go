@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"time"
+)
+
+type FarmRole string
+
+const (
+	FARM_ROLE_OWNER   FarmRole = "OWNER"
+	FARM_ROLE_MANAGER FarmRole = "MANAGER"
+	FARM_ROLE_PACKER  FarmRole = "PACKER"
+)
+
+type FarmPermission string
+
+const (
+	FARM_PERMISSION_FARM_MANAGE    FarmPermission = "farm.manage"
+	FARM_PERMISSION_MEMBERS_READ   FarmPermission = "members.read"
+	FARM_PERMISSION_MEMBERS_MANAGE FarmPermission = "members.manage"
+	FARM_PERMISSION_OFFERS_MANAGE  FarmPermission = "offers.manage"
+	FARM_PERMISSION_ORDERS_READ    FarmPermission = "orders.read"
+	FARM_PERMISSION_ORDERS_PROCESS FarmPermission = "orders.process"
+)
+
+// FarmInvitationTtl is how long an invitation waits to be accepted.
+const FarmInvitationTtl = 7 * 24 * time.Hour
+
+// farmRolePermissions lists what the staff of a farm may do with it. The
+// owner is the farm user and the only one who can change the farm itself
+// or its staff.
+var farmRolePermissions = map[FarmRole][]FarmPermission{
+	FARM_ROLE_OWNER: {
+		FARM_PERMISSION_FARM_MANAGE,
+		FARM_PERMISSION_MEMBERS_READ,
+		FARM_PERMISSION_MEMBERS_MANAGE,
+		FARM_PERMISSION_OFFERS_MANAGE,
+		FARM_PERMISSION_ORDERS_READ,
+		FARM_PERMISSION_ORDERS_PROCESS,
+	},
+	FARM_ROLE_MANAGER: {
+		FARM_PERMISSION_MEMBERS_READ,
+		FARM_PERMISSION_OFFERS_MANAGE,
+		FARM_PERMISSION_ORDERS_READ,
+		FARM_PERMISSION_ORDERS_PROCESS,
+	},
+	FARM_ROLE_PACKER: {
+		FARM_PERMISSION_MEMBERS_READ,
+		FARM_PERMISSION_ORDERS_READ,
+		FARM_PERMISSION_ORDERS_PROCESS,
+	},
+}
+
+func GetFarmRoles() []FarmRole {
+	return []FarmRole{FARM_ROLE_OWNER, FARM_ROLE_MANAGER, FARM_ROLE_PACKER}
+}
+
+func (r FarmRole) Can(permission FarmPermission) bool {
+	for _, p := range farmRolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+type FarmMember struct {
+	Id          uint64
+	FarmId      uint64
+	User        User
+	Role        FarmRole
+	CreatedDate time.Time
+	UpdatedDate time.Time
+}
+
+func (m FarmMember) GetUserId() uint64 {
+	return m.User.Id
+}
+
+func (m FarmMember) GetFarmId() uint64 {
+	return m.FarmId
+}
+
+// FarmInvitation asks the person with the email or the phone number to
+// join the farm. It shows up for a user once the address is verified.
+type FarmInvitation struct {
+	Id           uint64
+	FarmId       uint64
+	FarmName     *string
+	Role         FarmRole
+	Email        *string
+	PhoneNumber  *string
+	InvitedBy    User
+	ExpiresDate  time.Time
+	AcceptedDate *time.Time
+	DeclinedDate *time.Time
+	RevokedDate  *time.Time
+	CreatedDate  time.Time
+	UpdatedDate  time.Time
+}
+
+func (i FarmInvitation) GetUserId() uint64 {
+	return i.InvitedBy.Id
+}
+
+func (i FarmInvitation) GetFarmId() uint64 {
+	return i.FarmId
+}
+
+func (i FarmInvitation) IsPending(now time.Time) bool {
+	return i.AcceptedDate == nil && i.DeclinedDate == nil && i.RevokedDate == nil && now.Before(i.ExpiresDate)
+}
+
+// IsFor tells whether the invitation was sent to a verified address of
+// the user.
+func (i FarmInvitation) IsFor(user User) bool {
+	if i.Email != nil && user.IsEmailVerified() && *i.Email == user.Email {
+		return true
+	}
+	return i.PhoneNumber != nil && user.IsPhoneVerified() && *i.PhoneNumber == *user.PhoneNumber
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"time"
+)
+
+type Favorite struct {
+	Id          uint64
+	User        User
+	Offer       Offer
+	CreatedDate time.Time
+}
+
+type FarmFollow struct {
+	Id          uint64
+	User        User
+	Farm        Farm
+	CreatedDate time.Time
+}
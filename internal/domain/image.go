@@ -1,5 +1,11 @@
 package domain
 
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
 type Image struct {
 	Id       uint64
 	Name     string
@@ -7,3 +13,88 @@ type Image struct {
 	Entity   string
 	EntityId uint64
 }
+
+// ImageUpload is an image file streamed from a multipart request. The
+// content is read once, by the image processing.
+type ImageUpload struct {
+	Name     string
+	MimeType string
+	Content  io.Reader
+}
+
+type ImageSize string
+
+const (
+	IMAGE_SIZE_THUMB ImageSize = "thumb"
+	IMAGE_SIZE_CARD  ImageSize = "card"
+	IMAGE_SIZE_FULL  ImageSize = "full"
+)
+
+type ImageFormat string
+
+const (
+	IMAGE_FORMAT_JPEG ImageFormat = "jpg"
+	IMAGE_FORMAT_WEBP ImageFormat = "webp"
+)
+
+// imageSizeEdges are the longest sides of the variants in pixels. Smaller
+// images are not scaled up.
+var imageSizeEdges = map[ImageSize]int{
+	IMAGE_SIZE_THUMB: 240,
+	IMAGE_SIZE_CARD:  640,
+	IMAGE_SIZE_FULL:  1600,
+}
+
+var ErrInvalidImage = errors.New("image is damaged or too large to process")
+
+func GetImageSizes() []ImageSize {
+	return []ImageSize{IMAGE_SIZE_THUMB, IMAGE_SIZE_CARD, IMAGE_SIZE_FULL}
+}
+
+func GetImageFormats() []ImageFormat {
+	return []ImageFormat{IMAGE_FORMAT_JPEG, IMAGE_FORMAT_WEBP}
+}
+
+func (s ImageSize) MaxEdge() int {
+	return imageSizeEdges[s]
+}
+
+func (f ImageFormat) MimeType() string {
+	if f == IMAGE_FORMAT_WEBP {
+		return "image/webp"
+	}
+	return "image/jpeg"
+}
+
+type ImageVariant struct {
+	Size   ImageSize
+	Format ImageFormat
+	Name   string
+}
+
+// ImageVariantName names the variants after a common base. The full JPEG
+// is the name of the image itself, the rest are found by it.
+func ImageVariantName(base string, size ImageSize, format ImageFormat) string {
+	return base + "." + string(size) + "." + string(format)
+}
+
+// Variants lists the stored variants of the image. Images stored before
+// they were processed have none.
+func (i Image) Variants() []ImageVariant {
+	base, ok := strings.CutSuffix(i.Name, "."+string(IMAGE_SIZE_FULL)+"."+string(IMAGE_FORMAT_JPEG))
+	if !ok || base == "" {
+		return nil
+	}
+
+	variants := make([]ImageVariant, 0, len(imageSizeEdges)*2)
+	for _, size := range GetImageSizes() {
+		for _, format := range GetImageFormats() {
+			variants = append(variants, ImageVariant{
+				Size:   size,
+				Format: format,
+				Name:   ImageVariantName(base, size, format),
+			})
+		}
+	}
+	return variants
+}
@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"time"
+)
+
+type NotificationChannel string
+
+const (
+	CHANNEL_EMAIL  NotificationChannel = "EMAIL"
+	CHANNEL_SMS    NotificationChannel = "SMS"
+	CHANNEL_IN_APP NotificationChannel = "IN_APP"
+)
+
+type NotificationEvent string
+
+const (
+	EVENT_ORDER_SUBMITTED     NotificationEvent = "ORDER_SUBMITTED"
+	EVENT_ORDER_APPROVED      NotificationEvent = "ORDER_APPROVED"
+	EVENT_ORDER_DECLINED      NotificationEvent = "ORDER_DECLINED"
+	EVENT_ORDER_SHIPPING      NotificationEvent = "ORDER_SHIPPING"
+	EVENT_ORDER_COMPLETED     NotificationEvent = "ORDER_COMPLETED"
+	EVENT_ORDER_MESSAGE       NotificationEvent = "ORDER_MESSAGE"
+	EVENT_OFFER_BACK_IN_STOCK NotificationEvent = "OFFER_BACK_IN_STOCK"
+	EVENT_OFFER_PRICE_DROP    NotificationEvent = "OFFER_PRICE_DROP"
+	EVENT_FARM_INVITATION     NotificationEvent = "FARM_INVITATION"
+	// Security events are always delivered and can't be muted, so they
+	// are left out of GetNotificationEvents.
+	EVENT_PASSWORD_RESET     NotificationEvent = "PASSWORD_RESET"
+	EVENT_PHONE_VERIFICATION NotificationEvent = "PHONE_VERIFICATION"
+	EVENT_EMAIL_VERIFICATION NotificationEvent = "EMAIL_VERIFICATION"
+)
+
+const (
+	LANGUAGE_UK = "uk"
+	LANGUAGE_EN = "en"
+)
+
+type Notification struct {
+	Id          uint64
+	User        User
+	Event       NotificationEvent
+	Subject     string
+	Body        string
+	ReadDate    *time.Time
+	CreatedDate time.Time
+}
+
+type Notifications struct {
+	Items  []Notification
+	Total  uint64
+	Pages  uint
+	Unread uint64
+}
+
+// QueuedNotification is a message sent in the background at SendDate: an
+// email or SMS held back by the quiet hours of the user, or an offer alert.
+type QueuedNotification struct {
+	Id          uint64
+	User        User
+	Event       NotificationEvent
+	Channel     NotificationChannel
+	Subject     string
+	Body        string
+	SendDate    time.Time
+	CreatedDate time.Time
+}
+
+func (n Notification) GetUserId() uint64 {
+	return n.User.Id
+}
+
+func (n Notification) IsRead() bool {
+	return n.ReadDate != nil
+}
+
+func GetNotificationChannels() []NotificationChannel {
+	return []NotificationChannel{CHANNEL_EMAIL, CHANNEL_SMS, CHANNEL_IN_APP}
+}
+
+// OrderStatusEvent returns the notification event sent when an order
+// moves to the given status.
+func OrderStatusEvent(status OrderStatus) (NotificationEvent, bool) {
+	switch status {
+	case SUBMITTED:
+		return EVENT_ORDER_SUBMITTED, true
+	case APPROVED:
+		return EVENT_ORDER_APPROVED, true
+	case DECLINED:
+		return EVENT_ORDER_DECLINED, true
+	case SHIPPING:
+		return EVENT_ORDER_SHIPPING, true
+	case COMPLETED:
+		return EVENT_ORDER_COMPLETED, true
+	}
+	return "", false
+}
+
+// NotificationPreference overrides the default delivery of an event over a
+// channel for a user. Quiet hours are minutes since midnight; the range may
+// wrap past midnight, e.g. 22:00-08:00.
+type NotificationPreference struct {
+	Id              uint64
+	User            User
+	Event           NotificationEvent
+	Channel         NotificationChannel
+	Enabled         bool
+	QuietHoursStart *uint16
+	QuietHoursEnd   *uint16
+	CreatedDate     time.Time
+	UpdatedDate     time.Time
+}
+
+func GetNotificationEvents() []NotificationEvent {
+	return []NotificationEvent{
+		EVENT_ORDER_SUBMITTED,
+		EVENT_ORDER_APPROVED,
+		EVENT_ORDER_DECLINED,
+		EVENT_ORDER_SHIPPING,
+		EVENT_ORDER_COMPLETED,
+		EVENT_ORDER_MESSAGE,
+		EVENT_OFFER_BACK_IN_STOCK,
+		EVENT_OFFER_PRICE_DROP,
+		EVENT_FARM_INVITATION,
+	}
+}
+
+// IsQuietAt reports whether t falls into the quiet hours of the preference.
+func (p NotificationPreference) IsQuietAt(t time.Time) bool {
+	if p.QuietHoursStart == nil || p.QuietHoursEnd == nil || *p.QuietHoursStart == *p.QuietHoursEnd {
+		return false
+	}
+
+	now := uint16(t.Hour()*60 + t.Minute())
+	start, end := *p.QuietHoursStart, *p.QuietHoursEnd
+	if start < end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// QuietUntil returns when the quiet hours t falls into end, or t itself
+// outside of them.
+func (p NotificationPreference) QuietUntil(t time.Time) time.Time {
+	if !p.IsQuietAt(t) {
+		return t
+	}
+
+	end := int(*p.QuietHoursEnd)
+	until := time.Date(t.Year(), t.Month(), t.Day(), end/60, end%60, 0, 0, t.Location())
+	if !until.After(t) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until
+}
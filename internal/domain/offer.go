@@ -17,6 +17,9 @@ type Offer struct {
 	Farm             Farm
 	Cover            Image
 	AdditionalImages []Image
+	Rating           float64
+	ReviewsCount     uint64
+	IsFavorite       bool
 	CreatedDate      time.Time
 	UpdatedDate      time.Time
 	DeletedDate      *time.Time
@@ -31,3 +34,7 @@ type Offers struct {
 func (o Offer) GetUserId() uint64 {
 	return o.User.Id
 }
+
+func (o Offer) GetFarmId() uint64 {
+	return o.Farm.Id
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"time"
+)
+
+type OfferAlertType string
+
+const (
+	ALERT_BACK_IN_STOCK OfferAlertType = "BACK_IN_STOCK"
+	ALERT_PRICE_DROP    OfferAlertType = "PRICE_DROP"
+)
+
+type OfferAlert struct {
+	Id           uint64
+	User         User
+	OfferId      uint64
+	Type         OfferAlertType
+	TargetPrice  *float64
+	Channel      NotificationChannel
+	Notified     bool
+	NotifiedDate *time.Time
+	CreatedDate  time.Time
+	UpdatedDate  time.Time
+}
+
+func (a OfferAlert) GetUserId() uint64 {
+	return a.User.Id
+}
+
+// IsAvailable reports whether the offer can be ordered at the moment.
+func (o Offer) IsAvailable() bool {
+	return o.Status && o.Stock > 0
+}
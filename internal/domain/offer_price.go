@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+)
+
+type OfferPriceScheduleStatus string
+
+const (
+	PRICE_SCHEDULE_PENDING   OfferPriceScheduleStatus = "PENDING"
+	PRICE_SCHEDULE_ACTIVE    OfferPriceScheduleStatus = "ACTIVE"
+	PRICE_SCHEDULE_FINISHED  OfferPriceScheduleStatus = "FINISHED"
+	PRICE_SCHEDULE_CANCELLED OfferPriceScheduleStatus = "CANCELLED"
+)
+
+type OfferPrice struct {
+	Id          uint64
+	OfferId     uint64
+	Price       float64
+	CreatedDate time.Time
+}
+
+type OfferPrices struct {
+	Items []OfferPrice
+	Total uint64
+	Pages uint
+}
+
+type OfferPriceSchedule struct {
+	Id            uint64
+	OfferId       uint64
+	Price         float64
+	PreviousPrice *float64
+	StartDate     time.Time
+	EndDate       *time.Time
+	Status        OfferPriceScheduleStatus
+	CreatedDate   time.Time
+	UpdatedDate   time.Time
+}
+
+func (s OfferPriceSchedule) IsOpen() bool {
+	return s.Status == PRICE_SCHEDULE_PENDING || s.Status == PRICE_SCHEDULE_ACTIVE
+}
+
+// Overlaps reports whether two schedules can be active at the same time.
+// A schedule without an end date lasts forever.
+func (s OfferPriceSchedule) Overlaps(other OfferPriceSchedule) bool {
+	if s.EndDate != nil && !s.EndDate.After(other.StartDate) {
+		return false
+	}
+	if other.EndDate != nil && !other.EndDate.After(s.StartDate) {
+		return false
+	}
+	return true
+}
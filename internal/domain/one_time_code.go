@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+type OneTimeCodePurpose string
+
+const (
+	CODE_PASSWORD_RESET     OneTimeCodePurpose = "PASSWORD_RESET"
+	CODE_PHONE_VERIFICATION OneTimeCodePurpose = "PHONE_VERIFICATION"
+	CODE_EMAIL_VERIFICATION OneTimeCodePurpose = "EMAIL_VERIFICATION"
+)
+
+const (
+	OneTimeCodeLength      = 6
+	OneTimeCodeMaxAttempts = 5
+	OneTimeCodeTTL         = 15 * time.Minute
+	// OneTimeCodeResendDelay is how long a user waits before another code
+	// for the same purpose can be sent.
+	OneTimeCodeResendDelay = time.Minute
+)
+
+type OneTimeCode struct {
+	Id          uint64
+	UserId      uint64
+	Purpose     OneTimeCodePurpose
+	Channel     NotificationChannel
+	CodeHash    string
+	Attempts    uint32
+	UsedDate    *time.Time
+	ExpiresDate time.Time
+	CreatedDate time.Time
+}
+
+type CodeConfirmation struct {
+	Code string
+}
+
+type ResetPassword struct {
+	Email       string
+	PhoneNumber string
+	Code        string
+	NewPassword string
+}
+
+func (c OneTimeCode) IsActive(t time.Time) bool {
+	return c.UsedDate == nil && c.Attempts < OneTimeCodeMaxAttempts && c.ExpiresDate.After(t)
+}
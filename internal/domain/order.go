@@ -24,13 +24,16 @@ type Order struct {
 	OrderItemsCount  uint64
 	ProductsPrice    float64
 	ShippingPrice    float64
+	DiscountPrice    float64
 	TotalPrice       float64
+	Discounts        []OrderDiscount
 	Status           OrderStatus
 	PostOffice       *string
 	PostOfficeCity   *string
 	Ttn              *string
 	Percentage       *float64
 	IsPercentagePaid *bool
+	UnreadMessages   uint64
 	CreatedDate      time.Time
 	UpdatedDate      time.Time
 	DeletedDate      *time.Time
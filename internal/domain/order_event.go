@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"time"
+)
+
+type OrderEventType string
+
+const (
+	ORDER_CREATED        OrderEventType = "ORDER_CREATED"
+	ORDER_STATUS_CHANGED OrderEventType = "ORDER_STATUS_CHANGED"
+	ORDER_ITEM_CHANGED   OrderEventType = "ORDER_ITEM_CHANGED"
+)
+
+// OrderEvent is pushed in real time to the buyer and the farm owners
+// listed in Recipients.
+type OrderEvent struct {
+	Type        OrderEventType
+	OrderId     uint64
+	OrderItemId *uint64
+	Status      OrderStatus
+	Recipients  []uint64
+	CreatedDate time.Time
+}
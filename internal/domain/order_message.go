@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"time"
+)
+
+type OrderMessage struct {
+	Id          uint64
+	OrderId     uint64
+	User        User
+	Text        string
+	Images      []Image
+	ReadDate    *time.Time
+	CreatedDate time.Time
+}
+
+type OrderMessages struct {
+	Items []OrderMessage
+	Total uint64
+	Pages uint
+}
+
+func (m OrderMessage) GetUserId() uint64 {
+	return m.User.Id
+}
@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+type PromotionType string
+
+const (
+	PROMOTION_PERCENTAGE    PromotionType = "PERCENTAGE"
+	PROMOTION_FIXED         PromotionType = "FIXED"
+	PROMOTION_FREE_SHIPPING PromotionType = "FREE_SHIPPING"
+	PROMOTION_BUY_X_GET_Y   PromotionType = "BUY_X_GET_Y"
+)
+
+// ErrPromotionUnavailable is returned on submit when a promotion applied
+// to the order has ended or run out of usages in the meantime.
+var ErrPromotionUnavailable = errors.New("a promotion applied to the order is no longer available, remove it and try again")
+
+type Promotion struct {
+	Id                uint64
+	Code              string
+	Title             string
+	Type              PromotionType
+	Value             float64
+	BuyAmount         uint32
+	GetAmount         uint32
+	MinOrderPrice     float64
+	UsageLimit        *uint64
+	UsageLimitPerUser *uint64
+	StartDate         time.Time
+	EndDate           *time.Time
+	IsActive          bool
+	User              User
+	FarmId            *uint64 // nil for platform-level promotions
+	OfferId           *uint64 // limits the promotion to a single offer
+	CreatedDate       time.Time
+	UpdatedDate       time.Time
+	DeletedDate       *time.Time
+}
+
+type Promotions struct {
+	Items []Promotion
+	Total uint64
+	Pages uint
+}
+
+type OrderDiscount struct {
+	Id          uint64
+	OrderId     uint64
+	Promotion   Promotion
+	Amount      float64
+	CreatedDate time.Time
+	UpdatedDate time.Time
+}
+
+func (p Promotion) GetUserId() uint64 {
+	return p.User.Id
+}
+
+func GetPromotionTypes() []PromotionType {
+	return []PromotionType{PROMOTION_PERCENTAGE, PROMOTION_FIXED, PROMOTION_FREE_SHIPPING, PROMOTION_BUY_X_GET_Y}
+}
+
+func (p Promotion) IsPlatform() bool {
+	return p.FarmId == nil
+}
+
+func (p Promotion) IsValidAt(t time.Time) bool {
+	if !p.IsActive || p.DeletedDate != nil {
+		return false
+	}
+	if t.Before(p.StartDate) {
+		return false
+	}
+	if p.EndDate != nil && !t.Before(*p.EndDate) {
+		return false
+	}
+	return true
+}
+
+// EligibleItems returns the order items the promotion can be applied to.
+func (p Promotion) EligibleItems(items []OrderItem) []OrderItem {
+	eligible := make([]OrderItem, 0, len(items))
+	for _, item := range items {
+		if p.FarmId != nil && item.Farm.Id != *p.FarmId {
+			continue
+		}
+		if p.OfferId != nil && item.Offer.Id != *p.OfferId {
+			continue
+		}
+		eligible = append(eligible, item)
+	}
+	return eligible
+}
+
+// DiscountFor calculates the discount the promotion gives on the given items.
+// The result never exceeds the price of the eligible items (plus shipping for
+// free shipping promotions) and is zero when the minimal order price is not reached.
+func (p Promotion) DiscountFor(items []OrderItem, shippingPrice float64) float64 {
+	eligible := p.EligibleItems(items)
+	if len(eligible) == 0 {
+		return 0
+	}
+
+	var subtotal float64
+	for _, item := range eligible {
+		subtotal += item.TotalPrice
+	}
+	if subtotal < p.MinOrderPrice {
+		return 0
+	}
+
+	var discount float64
+	switch p.Type {
+	case PROMOTION_PERCENTAGE:
+		discount = subtotal * math.Min(p.Value, 100) / 100
+	case PROMOTION_FIXED:
+		discount = math.Min(p.Value, subtotal)
+	case PROMOTION_FREE_SHIPPING:
+		discount = shippingPrice
+	case PROMOTION_BUY_X_GET_Y:
+		if p.BuyAmount == 0 || p.GetAmount == 0 {
+			return 0
+		}
+		for _, item := range eligible {
+			freeAmount := item.Amount / (p.BuyAmount + p.GetAmount) * p.GetAmount
+			discount += float64(freeAmount) * item.Price
+		}
+	}
+
+	return math.Round(discount*100) / 100
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+type ReviewStatus string
+
+const (
+	REVIEW_PUBLISHED ReviewStatus = "PUBLISHED"
+	REVIEW_HIDDEN    ReviewStatus = "HIDDEN"
+)
+
+// ReviewReportsToHide is the number of reports after which a review
+// is hidden from the listings and excluded from the ratings.
+const ReviewReportsToHide = 5
+
+var (
+	ErrOwnReviewReport       = errors.New("you can not report your own review")
+	ErrReviewAlreadyReported = errors.New("you have already reported this review")
+)
+
+type Review struct {
+	Id          uint64
+	User        User
+	OfferId     uint64
+	FarmId      uint64
+	OrderItemId uint64
+	Rating      uint8
+	Text        string
+	Images      []Image
+	Reply       *string
+	ReplyDate   *time.Time
+	Status      ReviewStatus
+	CreatedDate time.Time
+	UpdatedDate time.Time
+	DeletedDate *time.Time
+}
+
+type Reviews struct {
+	Items []Review
+	Total uint64
+	Pages uint
+}
+
+type ReviewReport struct {
+	Id          uint64
+	ReviewId    uint64
+	User        User
+	Reason      string
+	CreatedDate time.Time
+}
+
+func (r Review) GetUserId() uint64 {
+	return r.User.Id
+}
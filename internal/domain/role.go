@@ -0,0 +1,73 @@
+package domain
+
+type Role string
+
+const (
+	ROLE_BUYER     Role = "BUYER"
+	ROLE_FARMER    Role = "FARMER"
+	ROLE_MODERATOR Role = "MODERATOR"
+	ROLE_ADMIN     Role = "ADMIN"
+)
+
+type Permission string
+
+const (
+	PERMISSION_FARMS_CREATE       Permission = "farms.create"
+	PERMISSION_FARMS_MANAGE       Permission = "farms.manage"
+	PERMISSION_OFFERS_MANAGE      Permission = "offers.manage"
+	PERMISSION_ORDERS_MANAGE      Permission = "orders.manage"
+	PERMISSION_COMMISSIONS_READ   Permission = "commissions.read"
+	PERMISSION_COMMISSIONS_MANAGE Permission = "commissions.manage"
+	PERMISSION_INVOICES_READ      Permission = "invoices.read"
+	PERMISSION_INVOICES_MANAGE    Permission = "invoices.manage"
+	PERMISSION_REVIEWS_MODERATE   Permission = "reviews.moderate"
+	PERMISSION_USERS_MANAGE       Permission = "users.manage"
+	PERMISSION_AUDIT_READ         Permission = "audit.read"
+	PERMISSION_PROMOTIONS_MANAGE  Permission = "promotions.manage"
+)
+
+// rolePermissions lists what each role may do on top of the plain
+// ownership checks every user passes for their own objects.
+var rolePermissions = map[Role][]Permission{
+	ROLE_BUYER: {},
+	ROLE_FARMER: {
+		PERMISSION_FARMS_CREATE,
+		PERMISSION_COMMISSIONS_READ,
+	},
+	ROLE_MODERATOR: {
+		PERMISSION_OFFERS_MANAGE,
+		PERMISSION_REVIEWS_MODERATE,
+		PERMISSION_AUDIT_READ,
+	},
+	ROLE_ADMIN: {
+		PERMISSION_FARMS_CREATE,
+		PERMISSION_FARMS_MANAGE,
+		PERMISSION_OFFERS_MANAGE,
+		PERMISSION_ORDERS_MANAGE,
+		PERMISSION_COMMISSIONS_READ,
+		PERMISSION_COMMISSIONS_MANAGE,
+		PERMISSION_INVOICES_READ,
+		PERMISSION_INVOICES_MANAGE,
+		PERMISSION_REVIEWS_MODERATE,
+		PERMISSION_USERS_MANAGE,
+		PERMISSION_AUDIT_READ,
+		PERMISSION_PROMOTIONS_MANAGE,
+	},
+}
+
+func GetRoles() []Role {
+	return []Role{ROLE_BUYER, ROLE_FARMER, ROLE_MODERATOR, ROLE_ADMIN}
+}
+
+func (r Role) Permissions() []Permission {
+	return rolePermissions[r]
+}
+
+func (r Role) Can(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
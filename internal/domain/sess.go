@@ -1,10 +1,45 @@
 package domain
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type Session struct {
-	UserId uint64
-	UUID   uuid.UUID
+	UserId       uint64
+	UUID         uuid.UUID
+	Device       string
+	Ip           string
+	UserAgent    string
+	LastSeenDate time.Time
+	ExpiresDate  time.Time
+	CreatedDate  time.Time
+}
+
+type RefreshToken struct {
+	Id          uint64
+	UserId      uint64
+	SessionUUID uuid.UUID
+	TokenHash   string
+	UsedDate    *time.Time
+	ExpiresDate time.Time
+	CreatedDate time.Time
+}
+
+// AuthTokens holds either the session tokens or, for accounts with 2FA,
+// only the challenge token to exchange for them with a code.
+type AuthTokens struct {
+	AccessToken    string
+	RefreshToken   string
+	ExpiresDate    time.Time
+	ChallengeToken string
+}
+
+func (s Session) IsExpired(t time.Time) bool {
+	return !s.ExpiresDate.IsZero() && s.ExpiresDate.Before(t)
+}
+
+func (t RefreshToken) IsUsed() bool {
+	return t.UsedDate != nil
 }
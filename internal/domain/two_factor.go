@@ -0,0 +1,51 @@
+package domain
+
+import "time"
+
+const (
+	RecoveryCodesCount = 10
+	// TwoFactorChallengeTTL is how long the user has to enter the code
+	// after the password was accepted.
+	TwoFactorChallengeTTL         = 5 * time.Minute
+	TwoFactorChallengeMaxAttempts = 5
+)
+
+// TwoFactorEnrollment is what the authenticator app needs. Url is the
+// otpauth:// link to show as a QR code, Secret is for entering by hand.
+type TwoFactorEnrollment struct {
+	Secret string
+	Url    string
+}
+
+// TwoFactorChallenge is issued instead of the session when the password
+// of an account with 2FA is correct. Only the hash of the token is stored.
+type TwoFactorChallenge struct {
+	Id          uint64
+	UserId      uint64
+	TokenHash   string
+	Attempts    uint32
+	ExpiresDate time.Time
+	CreatedDate time.Time
+}
+
+type RecoveryCode struct {
+	Id          uint64
+	UserId      uint64
+	CodeHash    string
+	UsedDate    *time.Time
+	CreatedDate time.Time
+}
+
+type TwoFactorLogin struct {
+	ChallengeToken string
+	Code           string
+}
+
+type DisableTwoFactor struct {
+	Password string
+	Code     string
+}
+
+func (c TwoFactorChallenge) IsActive(t time.Time) bool {
+	return c.ExpiresDate.After(t) && c.Attempts < TwoFactorChallengeMaxAttempts
+}
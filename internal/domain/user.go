@@ -5,14 +5,24 @@ import (
 )
 
 type User struct {
-	Id          uint64
-	Name        string
-	Email       string
-	Password    string
-	PhoneNumber *string
-	CreatedDate time.Time
-	UpdatedDate time.Time
-	DeletedDate *time.Time
+	Id                uint64
+	Name              string
+	Email             string
+	EmailVerifiedDate *time.Time
+	NewEmail          *string
+	Password          string
+	PhoneNumber       *string
+	PhoneVerifiedDate *time.Time
+	Language          string
+	Role              Role
+	BlockedDate       *time.Time
+	BlockReason       *string
+	TotpSecret        *string
+	TotpEnabledDate   *time.Time
+	TotpLastStep      int64
+	CreatedDate       time.Time
+	UpdatedDate       time.Time
+	DeletedDate       *time.Time
 }
 
 type Users struct {
@@ -21,6 +31,13 @@ type Users struct {
 	Pages uint
 }
 
+// ChangeEmail asks to move the account to another address. The password
+// is needed once the current address is verified.
+type ChangeEmail struct {
+	Email    string
+	Password string
+}
+
 type ChangePassword struct {
 	OldPassword string
 	NewPassword string
@@ -29,3 +46,25 @@ type ChangePassword struct {
 func (u User) GetUserId() uint64 {
 	return u.Id
 }
+
+func (u User) Can(permission Permission) bool {
+	return u.Role.Can(permission)
+}
+
+func (u User) IsBlocked() bool {
+	return u.BlockedDate != nil
+}
+
+func (u User) IsEmailVerified() bool {
+	return u.Email != "" && u.EmailVerifiedDate != nil
+}
+
+func (u User) IsPhoneVerified() bool {
+	return u.PhoneNumber != nil && u.PhoneVerifiedDate != nil
+}
+
+// IsTwoFactorEnabled is true once the user has confirmed the authenticator
+// app with a code, not just requested the secret.
+func (u User) IsTwoFactorEnabled() bool {
+	return u.TotpSecret != nil && u.TotpEnabledDate != nil
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"time"
+)
+
+type WebhookEvent string
+
+const (
+	WEBHOOK_ORDER_CREATED        WebhookEvent = "ORDER_CREATED"
+	WEBHOOK_ORDER_STATUS_CHANGED WebhookEvent = "ORDER_STATUS_CHANGED"
+	WEBHOOK_ORDER_ITEM_CHANGED   WebhookEvent = "ORDER_ITEM_CHANGED"
+	WEBHOOK_OFFER_CREATED        WebhookEvent = "OFFER_CREATED"
+	WEBHOOK_OFFER_UPDATED        WebhookEvent = "OFFER_UPDATED"
+	WEBHOOK_OFFER_DELETED        WebhookEvent = "OFFER_DELETED"
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	DELIVERY_PENDING   WebhookDeliveryStatus = "PENDING"
+	DELIVERY_SENDING   WebhookDeliveryStatus = "SENDING"
+	DELIVERY_SUCCEEDED WebhookDeliveryStatus = "SUCCEEDED"
+	DELIVERY_FAILED    WebhookDeliveryStatus = "FAILED"
+)
+
+// WebhookMaxAttempts is the number of tries after which a delivery is
+// given up and marked as FAILED.
+const WebhookMaxAttempts = 8
+
+type Webhook struct {
+	Id          uint64
+	User        User
+	FarmId      uint64
+	Url         string
+	Secret      string
+	Events      []WebhookEvent
+	IsActive    bool
+	CreatedDate time.Time
+	UpdatedDate time.Time
+	DeletedDate *time.Time
+}
+
+type WebhookDelivery struct {
+	Id              uint64
+	WebhookId       uint64
+	Event           WebhookEvent
+	Payload         string
+	Status          WebhookDeliveryStatus
+	Attempts        uint32
+	NextAttemptDate *time.Time
+	ResponseCode    *int
+	Error           *string
+	DeliveredDate   *time.Time
+	CreatedDate     time.Time
+	UpdatedDate     time.Time
+}
+
+type WebhookDeliveries struct {
+	Items []WebhookDelivery
+	Total uint64
+	Pages uint
+}
+
+func (w Webhook) GetUserId() uint64 {
+	return w.User.Id
+}
+
+// Subscribes reports whether the webhook wants to receive the event.
+func (w Webhook) Subscribes(event WebhookEvent) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func GetWebhookEvents() []WebhookEvent {
+	return []WebhookEvent{
+		WEBHOOK_ORDER_CREATED,
+		WEBHOOK_ORDER_STATUS_CHANGED,
+		WEBHOOK_ORDER_ITEM_CHANGED,
+		WEBHOOK_OFFER_CREATED,
+		WEBHOOK_OFFER_UPDATED,
+		WEBHOOK_OFFER_DELETED,
+	}
+}
+
+// OrderWebhookEvent returns the webhook event matching the order event.
+func OrderWebhookEvent(eventType OrderEventType) WebhookEvent {
+	switch eventType {
+	case ORDER_CREATED:
+		return WEBHOOK_ORDER_CREATED
+	case ORDER_STATUS_CHANGED:
+		return WEBHOOK_ORDER_STATUS_CHANGED
+	default:
+		return WEBHOOK_ORDER_ITEM_CHANGED
+	}
+}
@@ -0,0 +1,252 @@
+package filesystem
+
+import (
+	"boilerplate/internal/domain"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/gen2brain/heic"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	jpegQuality = 85
+	// maxImagePixels keeps decoding within memory, a 24 MP camera photo
+	// still fits and phones save 12 MP ones unless told otherwise.
+	maxImagePixels = 25_000_000
+	// maxConcurrentProcessing is how many images are decoded at once, each
+	// takes up to ~100 MB with the sizes made of it.
+	maxConcurrentProcessing = 2
+	exifOrientationTag      = 0x0112
+)
+
+func init() {
+	// heic registers the files of the "heic" major brand only, phones
+	// write the other HEIF brands too.
+	for _, brand := range []string{"heix", "hevc", "hevx", "heim", "heis", "mif1", "msf1"} {
+		image.RegisterFormat("heic", "????ftyp"+brand, heic.Decode, heic.DecodeConfig)
+	}
+}
+
+type ProcessedImage struct {
+	Size    domain.ImageSize
+	Format  domain.ImageFormat
+	Content []byte
+}
+
+type ImageProcessor interface {
+	Process(content io.Reader) ([]ProcessedImage, error)
+}
+
+type imageProcessor struct {
+	slots chan struct{}
+}
+
+func NewImageProcessor() ImageProcessor {
+	return imageProcessor{
+		slots: make(chan struct{}, maxConcurrentProcessing),
+	}
+}
+
+// Process decodes the image and encodes every size of it in every format.
+// The image is turned upright by its EXIF orientation, the metadata itself
+// is not carried over.
+func (p imageProcessor) Process(content io.Reader) ([]ProcessedImage, error) {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidImage, err)
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels", domain.ErrInvalidImage, cfg.Width, cfg.Height)
+	}
+
+	// Uploads past the limit wait for their turn instead of running the
+	// server out of memory.
+	p.slots <- struct{}{}
+	defer func() { <-p.slots }()
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidImage, err)
+	}
+
+	// The HEIF decoder applies the rotation of the file by itself.
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+	full := orient(scale(src, domain.IMAGE_SIZE_FULL.MaxEdge()), orientation)
+
+	var result []ProcessedImage
+	for _, size := range domain.GetImageSizes() {
+		img := full
+		if size != domain.IMAGE_SIZE_FULL {
+			img = scale(full, size.MaxEdge())
+		}
+
+		var jpegBuf bytes.Buffer
+		err = jpeg.Encode(&jpegBuf, flatten(img), &jpeg.Options{Quality: jpegQuality})
+		if err != nil {
+			return nil, err
+		}
+
+		var webpBuf bytes.Buffer
+		err = nativewebp.Encode(&webpBuf, img, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result,
+			ProcessedImage{Size: size, Format: domain.IMAGE_FORMAT_JPEG, Content: jpegBuf.Bytes()},
+			ProcessedImage{Size: size, Format: domain.IMAGE_FORMAT_WEBP, Content: webpBuf.Bytes()},
+		)
+	}
+	return result, nil
+}
+
+// scale fits the image into a square of maxEdge keeping the proportions.
+func scale(src image.Image, maxEdge int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > maxEdge || h > maxEdge {
+		if w >= h {
+			w, h = maxEdge, max(1, h*maxEdge/w)
+		} else {
+			w, h = max(1, w*maxEdge/h), maxEdge
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if w == b.Dx() && h == b.Dy() {
+		draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	} else {
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	}
+	return dst
+}
+
+// flatten puts a transparent image on white, JPEG has no alpha.
+func flatten(img *image.RGBA) image.Image {
+	if img.Opaque() {
+		return img
+	}
+	dst := image.NewRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Over)
+	return dst
+}
+
+// orient turns the image by an EXIF orientation value.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			si := src.PixOffset(sx, sy)
+			di := dst.PixOffset(x, y)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the orientation from the EXIF segment of a JPEG
+// file, 1 when there is none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		// The image data follows the start of scan, the metadata comes
+		// before it.
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return 1
+		}
+		segment := data[i+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i = end
+	}
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd : ifd+2]))
+	for n := 0; n < count; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:entry+2]) == exifOrientationTag {
+			return int(order.Uint16(tiff[entry+8 : entry+10]))
+		}
+	}
+	return 1
+}
@@ -1,104 +1,84 @@
 package filesystem
 
 import (
-	"bufio"
-	"bytes"
-	stdimg "image"
-	"image/jpeg"
+	"context"
+	"errors"
 	"io"
 	"log"
-	"math/rand"
 	"os"
 	"path"
-	"strconv"
 	"strings"
-	"time"
+)
+
+// maxNameLen caps the readable part of a key taken from a file name.
+const maxNameLen = 100
 
-	"golang.org/x/image/draw"
+var (
+	ErrImageNotFound = errors.New("image not found")
+	ErrInvalidKey    = errors.New("invalid image key")
 )
 
+// ImageStorageService keeps image files as objects under keys, the keys
+// are what images store as their names. Callers pick unique keys, saving
+// under a taken one replaces the object.
 type ImageStorageService interface {
-	SaveImage(filename string, content []byte) (string, error)
-	RemoveImage(filename string) error
-	UpdateImage(oldfilename string, filename string, content []byte) (string, error)
+	// SaveImage stores the content, size is -1 when it isn't known.
+	SaveImage(key string, content io.Reader, size int64, contentType string) error
+	// ReadImage opens the object, the reading stops with ctx.
+	ReadImage(ctx context.Context, key string) (io.ReadCloser, error)
+	RemoveImage(key string) error
+	// ImageUrl is where clients fetch the image from.
+	ImageUrl(key string) string
 }
 
 type imageStorageService struct {
-	loc string
+	loc     string
+	baseUrl string
 }
 
-func NewImageStorageService(location string) ImageStorageService {
+// NewImageStorageService keeps the files in a local directory. They are
+// served by the API under baseUrl, so every instance needs the same
+// directory.
+func NewImageStorageService(location string, baseUrl string) ImageStorageService {
 	return imageStorageService{
-		loc: location,
+		loc:     location,
+		baseUrl: baseUrl,
 	}
 }
 
-func (s imageStorageService) SaveImage(filename string, content []byte) (string, error) {
-	name, err := GenerateFileName(s.loc, filename)
-	if err != nil {
-		return "", err
+func (s imageStorageService) SaveImage(key string, content io.Reader, size int64, contentType string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
 	}
 
-	location := path.Join(s.loc, name)
-	err = writeFileToStorage(location, content)
+	err := writeFileToStorage(path.Join(s.loc, key), content)
 	if err != nil {
 		log.Print(err)
-		return "", err
+		return err
 	}
-
-	/*
-		scaledImage, err := downScaleImage(image.Image)
-		if err != nil {
-			log.Print(err)
-			return err
-		}
-
-		err = writeFileToStorage(s.loc, image.Link+"_thumbnail", scaledImage)
-		if err != nil {
-			log.Print(err)
-			return err
-		}
-	*/
-
-	return name, nil
+	return nil
 }
 
-func (s imageStorageService) UpdateImage(oldfilename string, filename string, content []byte) (string, error) {
-	location := path.Join(s.loc, oldfilename)
-	filer, err := os.Open(location)
-	if err == nil {
-		file_content, err := io.ReadAll(filer)
-		if err != nil {
-			return "", err
-		}
-		filer.Close()
-		if AreBytesEqual(content, file_content) {
-			return oldfilename, nil
-		}
-		err = s.RemoveImage(oldfilename)
-		if err != nil {
-			return "", err
-		}
+func (s imageStorageService) ReadImage(_ context.Context, key string) (io.ReadCloser, error) {
+	if !ValidKey(key) {
+		return nil, ErrInvalidKey
 	}
 
-	name, err := GenerateFileName(s.loc, filename)
-	if err != nil {
-		return "", err
+	file, err := os.Open(path.Join(s.loc, key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrImageNotFound
+	} else if err != nil {
+		return nil, err
 	}
+	return file, nil
+}
 
-	location = path.Join(s.loc, name)
-	err = writeFileToStorage(location, content)
-	if err != nil {
-		log.Print(err)
-		return "", err
+func (s imageStorageService) RemoveImage(key string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
 	}
 
-	return name, nil
-}
-
-func (s imageStorageService) RemoveImage(filename string) error {
-	location := path.Join(s.loc, filename)
-	err := os.Remove(location)
+	err := os.Remove(path.Join(s.loc, key))
 	if err != nil {
 		log.Print(err)
 		return err
@@ -107,62 +87,41 @@ func (s imageStorageService) RemoveImage(filename string) error {
 	return nil
 }
 
-func GenerateFileName(loc string, name string) (string, error) {
-	location := path.Join(loc, name)
-	_, err := os.Stat(location)
-	if !os.IsNotExist(err) {
-		source := rand.NewSource(time.Now().UnixNano())
-		rng := rand.New(source)
-		num := strconv.FormatUint(rng.Uint64(), 10)
-		splited := strings.Split(name, ".")
-		return GenerateFileName(loc, splited[0]+"_"+num+"."+splited[1])
-	}
-
-	return name, nil
+func (s imageStorageService) ImageUrl(key string) string {
+	return s.baseUrl + key
 }
 
-func AreBytesEqual(b1, b2 []byte) bool {
-	if len(b1) != len(b2) {
-		return false
-	}
-	for i := range b1 {
-		if b1[i] != b2[i] {
-			return false
-		}
-	}
-	return true
+// ValidKey keeps the keys inside the storage, a key is a clean relative
+// path.
+func ValidKey(key string) bool {
+	return key != "" && !strings.HasPrefix(key, "/") && path.Clean(key) == key &&
+		key != ".." && !strings.HasPrefix(key, "../")
 }
 
-// nolint
-func downScaleImage(input []byte) ([]byte, error) {
-	src, err := jpeg.Decode(bytes.NewReader(input))
-	if err != nil {
-		log.Print(err)
-		return nil, err
-	}
-
-	if src.Bounds().Max.X <= 500 {
-		return input, nil
-	} else {
-		scaleFactor := src.Bounds().Max.X / 500
-		dst := stdimg.NewRGBA(stdimg.Rect(0, 0, src.Bounds().Max.X/scaleFactor, src.Bounds().Max.Y/scaleFactor))
-		draw.NearestNeighbor.Scale(dst, dst.Rect, src, src.Bounds(), draw.Over, nil)
-
-		var b bytes.Buffer
-		writer := bufio.NewWriter(&b)
-
-		err = jpeg.Encode(writer, dst, nil)
-		if err != nil {
-			log.Print(err)
-			return nil, err
+// SafeName turns the name of an uploaded file into the readable part of a
+// key: the extension is dropped and anything but ASCII letters, digits,
+// "-" and "_" becomes "_", so the key can go into a URL as it is.
+func SafeName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimSuffix(name, path.Ext(name))
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
 		}
-
-		result := b.Bytes()
-		return result, nil
+		return '_'
+	}, name)
+	if len(name) > maxNameLen {
+		name = name[:maxNameLen]
 	}
+	if strings.Trim(name, "_") == "" {
+		name = "image"
+	}
+	return name
 }
 
-func writeFileToStorage(location string, file []byte) error {
+// writeFileToStorage doesn't leave a partial file behind when reading the
+// content fails half way.
+func writeFileToStorage(location string, content io.Reader) error {
 	dirLocation := path.Dir(location)
 	err := os.MkdirAll(dirLocation, os.ModePerm)
 	if err != nil {
@@ -170,10 +129,20 @@ func writeFileToStorage(location string, file []byte) error {
 		return err
 	}
 
-	err = os.WriteFile(location, file, os.ModePerm)
+	file, err := os.OpenFile(location, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
 	if err != nil {
 		log.Print(err)
 		return err
 	}
+
+	_, err = io.Copy(file, content)
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(location)
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,131 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"image.jpg", true},
+		{"offers/1/image.jpg", true},
+		{"..image.jpg", true},
+		{"", false},
+		{"/image.jpg", false},
+		{"..", false},
+		{"../image.jpg", false},
+		{"offers/../../image.jpg", false},
+		{"offers/./image.jpg", false},
+		{"offers//image.jpg", false},
+		{"offers/", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidKey(tt.key); got != tt.want {
+			t.Errorf("ValidKey(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestSafeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"photo.jpg", "photo"},
+		{"my photo.v2.png", "my_photo_v2"},
+		{"C:\\Users\\me\\cat.jpg", "cat"},
+		{"../../etc/passwd", "passwd"},
+		{"a#b?c%d.jpg", "a_b_c_d"},
+		{"фото.jpg", "image"},
+		{"фото-1.jpg", "____-1"},
+		{"...", "image"},
+		{"", "image"},
+		{strings.Repeat("a", 150) + ".jpg", strings.Repeat("a", 100)},
+	}
+
+	for _, tt := range tests {
+		if got := SafeName(tt.name); got != tt.want {
+			t.Errorf("SafeName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestImageStorageServices(t *testing.T) {
+	storages := map[string]ImageStorageService{
+		"memory":     NewMemoryStorageService("/static/"),
+		"filesystem": NewImageStorageService(t.TempDir(), "/static/"),
+	}
+
+	for name, storage := range storages {
+		t.Run(name, func(t *testing.T) {
+			testImageStorageService(t, storage)
+		})
+	}
+}
+
+func testImageStorageService(t *testing.T, storage ImageStorageService) {
+	ctx := context.Background()
+	key := "offers/1/image.jpg"
+
+	_, err := storage.ReadImage(ctx, key)
+	if !errors.Is(err, ErrImageNotFound) {
+		t.Fatalf("ReadImage of a missing key: got %v, want ErrImageNotFound", err)
+	}
+
+	err = storage.SaveImage(key, strings.NewReader("first"), 5, "image/jpeg")
+	if err != nil {
+		t.Fatalf("SaveImage: %s", err)
+	}
+	err = storage.SaveImage(key, strings.NewReader("second"), -1, "image/jpeg")
+	if err != nil {
+		t.Fatalf("SaveImage over a taken key: %s", err)
+	}
+
+	content, err := storage.ReadImage(ctx, key)
+	if err != nil {
+		t.Fatalf("ReadImage: %s", err)
+	}
+	data, err := io.ReadAll(content)
+	_ = content.Close()
+	if err != nil {
+		t.Fatalf("reading the image: %s", err)
+	}
+	if string(data) != "second" {
+		t.Errorf("ReadImage = %q, want %q", data, "second")
+	}
+	if _, ok := content.(io.ReadSeeker); !ok {
+		t.Errorf("ReadImage returned %T, want an io.ReadSeeker", content)
+	}
+
+	if got := storage.ImageUrl(key); got != "/static/"+key {
+		t.Errorf("ImageUrl = %q, want %q", got, "/static/"+key)
+	}
+
+	err = storage.RemoveImage(key)
+	if err != nil {
+		t.Fatalf("RemoveImage: %s", err)
+	}
+	_, err = storage.ReadImage(ctx, key)
+	if !errors.Is(err, ErrImageNotFound) {
+		t.Errorf("ReadImage after RemoveImage: got %v, want ErrImageNotFound", err)
+	}
+
+	for _, bad := range []string{"", "/etc/passwd", "../image.jpg"} {
+		if err := storage.SaveImage(bad, strings.NewReader("x"), 1, "image/jpeg"); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("SaveImage(%q): got %v, want ErrInvalidKey", bad, err)
+		}
+		if _, err := storage.ReadImage(ctx, bad); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("ReadImage(%q): got %v, want ErrInvalidKey", bad, err)
+		}
+		if err := storage.RemoveImage(bad); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("RemoveImage(%q): got %v, want ErrInvalidKey", bad, err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+type memoryStorageService struct {
+	mu      *sync.RWMutex
+	objects map[string][]byte
+	baseUrl string
+}
+
+// NewMemoryStorageService keeps the files in memory, they are lost on
+// restart. It is meant for tests and local runs.
+func NewMemoryStorageService(baseUrl string) ImageStorageService {
+	return memoryStorageService{
+		mu:      &sync.RWMutex{},
+		objects: make(map[string][]byte),
+		baseUrl: baseUrl,
+	}
+}
+
+func (s memoryStorageService) SaveImage(key string, content io.Reader, size int64, contentType string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	return nil
+}
+
+func (s memoryStorageService) ReadImage(_ context.Context, key string) (io.ReadCloser, error) {
+	if !ValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, ErrImageNotFound
+	}
+	// The slice is never written to again, saving replaces it.
+	return memoryObject{bytes.NewReader(data)}, nil
+}
+
+// RemoveImage doesn't fail for a missing object, the same as S3.
+func (s memoryStorageService) RemoveImage(key string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s memoryStorageService) ImageUrl(key string) string {
+	return s.baseUrl + key
+}
+
+// memoryObject can seek like the files and the S3 objects do.
+type memoryObject struct {
+	*bytes.Reader
+}
+
+func (memoryObject) Close() error {
+	return nil
+}
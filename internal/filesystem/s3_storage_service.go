@@ -0,0 +1,160 @@
+package filesystem
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// s3Timeout bounds a single call to the storage.
+const s3Timeout = 30 * time.Second
+
+type S3Config struct {
+	Endpoint  string // host[:port], e.g. s3.eu-central-1.amazonaws.com or localhost:9000 for MinIO
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSsl    bool
+	// PresignTtl is how long the links to the objects work. With zero the
+	// images are served by the API under baseUrl instead.
+	PresignTtl time.Duration
+}
+
+type s3StorageService struct {
+	client  *minio.Client
+	conf    S3Config
+	baseUrl string
+	urls    *presignedUrls
+}
+
+// presignedUrls keeps the links signed in the current half of PresignTtl.
+// A link changes with every signature, reusing it lets the browsers and
+// CDNs cache the image; each link still has at least half of PresignTtl
+// to live when it is handed out.
+type presignedUrls struct {
+	mu     sync.Mutex
+	period time.Time
+	urls   map[string]string
+}
+
+// NewS3StorageService keeps the files in a bucket of an S3-compatible
+// storage, all the instances of the API share it.
+func NewS3StorageService(conf S3Config, baseUrl string) (ImageStorageService, error) {
+	client, err := minio.New(conf.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(conf.AccessKey, conf.SecretKey, ""),
+		Secure: conf.UseSsl,
+		// With the region known presigning makes no requests.
+		Region: conf.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+	exists, err := client.BucketExists(ctx, conf.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("bucket %q does not exist", conf.Bucket)
+	}
+
+	return s3StorageService{
+		client:  client,
+		conf:    conf,
+		baseUrl: baseUrl,
+		urls:    &presignedUrls{},
+	}, nil
+}
+
+func (s s3StorageService) SaveImage(key string, content io.Reader, size int64, contentType string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+	_, err := s.client.PutObject(ctx, s.conf.Bucket, key, content, size, minio.PutObjectOptions{
+		ContentType: contentType,
+		// The keys are never reused for other content.
+		CacheControl: "public, max-age=31536000, immutable",
+	})
+	if err != nil {
+		log.Print(err)
+		return err
+	}
+	return nil
+}
+
+// ReadImage streams the object, the reading is bound by ctx only, not by a
+// timeout.
+func (s s3StorageService) ReadImage(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !ValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+
+	object, err := s.client.GetObject(ctx, s.conf.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// The request is only made on the first read, Stat makes it now to
+	// tell a missing object apart.
+	_, err = object.Stat()
+	if err != nil {
+		_ = object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrImageNotFound
+		}
+		return nil, err
+	}
+	return object, nil
+}
+
+func (s s3StorageService) RemoveImage(key string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+	err := s.client.RemoveObject(ctx, s.conf.Bucket, key, minio.RemoveObjectOptions{})
+	if err != nil {
+		log.Print(err)
+		return err
+	}
+	return nil
+}
+
+func (s s3StorageService) ImageUrl(key string) string {
+	if s.conf.PresignTtl == 0 {
+		return s.baseUrl + key
+	}
+
+	s.urls.mu.Lock()
+	defer s.urls.mu.Unlock()
+
+	period := time.Now().Truncate(s.conf.PresignTtl / 2)
+	if !period.Equal(s.urls.period) {
+		s.urls.period = period
+		s.urls.urls = make(map[string]string)
+	}
+	if u, ok := s.urls.urls[key]; ok {
+		return u
+	}
+
+	u, err := s.client.PresignedGetObject(context.Background(), s.conf.Bucket, key, s.conf.PresignTtl, nil)
+	if err != nil {
+		log.Print(err)
+		return s.baseUrl + key
+	}
+	s.urls.urls[key] = u.String()
+	return u.String()
+}
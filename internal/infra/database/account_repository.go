@@ -0,0 +1,104 @@
+package database
+
+import (
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+type AccountRepository interface {
+	Delete(userId uint64, farmIds []uint64) error
+}
+
+type accountRepository struct {
+	sess db.Session
+}
+
+func NewAccountRepository(dbSession db.Session) AccountRepository {
+	return accountRepository{
+		sess: dbSession,
+	}
+}
+
+// Delete closes the account in one transaction: the farms with their
+// offers, webhooks and members, the addresses, farm memberships, recovery
+// codes, queued notifications and sessions go away, the API keys are
+// revoked, the orders and the user row lose everything personal. Either all
+// of it happens or nothing does, so a failure can't leave a half deleted
+// account that still works.
+func (r accountRepository) Delete(userId uint64, farmIds []uint64) error {
+	return r.sess.Tx(func(tx db.Session) error {
+		offerRepo := NewOfferRepository(tx)
+		farmRepo := NewFarmRepository(tx, offerRepo)
+		orderItemRepo := NewOrderItemRepository(tx, offerRepo, farmRepo)
+		orderRepo := NewOrderRepository(tx, orderItemRepo, NewPromotionRepository(tx))
+		apiKeyRepo := NewApiKeyRepository(tx)
+		farmMemberRepo := NewFarmMemberRepository(tx)
+
+		for _, farmId := range farmIds {
+			err := tx.Collection(OffersTableName).
+				Find(db.Cond{"farm_id": farmId, "deleted_date": nil}).
+				Update(map[string]interface{}{"deleted_date": time.Now()})
+			if err != nil {
+				return err
+			}
+
+			err = farmRepo.Delete(farmId)
+			if err != nil {
+				return err
+			}
+
+			err = apiKeyRepo.RevokeAllByFarmId(farmId)
+			if err != nil {
+				return err
+			}
+
+			err = farmMemberRepo.DeleteAllByFarmId(farmId)
+			if err != nil {
+				return err
+			}
+
+			err = NewWebhookRepository(tx).DeleteAllByFarmId(farmId)
+			if err != nil {
+				return err
+			}
+		}
+
+		err := NewAddressRepository(tx).DeleteAllByUserId(userId)
+		if err != nil {
+			return err
+		}
+
+		err = apiKeyRepo.RevokeAllByUserId(userId)
+		if err != nil {
+			return err
+		}
+
+		err = farmMemberRepo.DeleteAllByUserId(userId)
+		if err != nil {
+			return err
+		}
+
+		err = orderRepo.AnonymizeByUserId(userId)
+		if err != nil {
+			return err
+		}
+
+		err = NewTwoFactorRepository(tx).DeleteRecoveryCodes(userId)
+		if err != nil {
+			return err
+		}
+
+		err = NewQueuedNotificationRepository(tx).DeleteAllByUserId(userId)
+		if err != nil {
+			return err
+		}
+
+		err = NewUserRepository(tx).Anonymize(userId)
+		if err != nil {
+			return err
+		}
+
+		return NewSessRepository(tx).DeleteAllByUserId(userId)
+	})
+}
@@ -28,8 +28,10 @@ type AddressRepository interface {
 	Save(address domain.Address) (domain.Address, error)
 	FindById(id uint64) (domain.Address, error)
 	FindByUserId(userId uint64) (domain.Address, error)
+	FindAllByUserId(userId uint64) ([]domain.Address, error)
 	Update(address domain.Address) (domain.Address, error)
 	Delete(id uint64) error
+	DeleteAllByUserId(userId uint64) error
 }
 
 type addressRepository struct {
@@ -111,6 +113,25 @@ func (r addressRepository) FindByUserId(userId uint64) (domain.Address, error) {
 	return r.mapModelToDomain(addressModel, userModel), nil
 }
 
+func (r addressRepository) FindAllByUserId(userId uint64) ([]domain.Address, error) {
+	var addressModels []address
+	err := r.coll.Find(db.Cond{"user_id": userId, "deleted_date": nil}).OrderBy("id").All(&addressModels)
+	if err != nil {
+		return []domain.Address{}, err
+	}
+
+	addresses := make([]domain.Address, len(addressModels))
+	for i, m := range addressModels {
+		addresses[i] = r.mapModelToDomain(m, user{Id: userId})
+	}
+	return addresses, nil
+}
+
+// DeleteAllByUserId removes the rows for good, soft deleted ones included.
+func (r addressRepository) DeleteAllByUserId(userId uint64) error {
+	return r.coll.Find(db.Cond{"user_id": userId}).Delete()
+}
+
 func (r addressRepository) Delete(id uint64) error {
 	return r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).Update(map[string]interface{}{"deleted_date": time.Now()})
 }
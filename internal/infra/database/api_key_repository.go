@@ -0,0 +1,157 @@
+package database
+
+import (
+	"boilerplate/internal/domain"
+	"strings"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+const ApiKeysTableName = "api_keys"
+
+type apiKey struct {
+	Id           uint64     `db:"id,omitempty"`
+	UserId       uint64     `db:"user_id"`
+	FarmId       uint64     `db:"farm_id"`
+	Name         string     `db:"name"`
+	Prefix       string     `db:"prefix"`
+	KeyHash      string     `db:"key_hash"`
+	Scopes       string     `db:"scopes"`
+	ExpiresDate  *time.Time `db:"expires_date"`
+	LastUsedDate *time.Time `db:"last_used_date"`
+	RevokedDate  *time.Time `db:"revoked_date"`
+	CreatedDate  time.Time  `db:"created_date,omitempty"`
+	UpdatedDate  time.Time  `db:"updated_date,omitempty"`
+}
+
+type ApiKeyRepository interface {
+	Save(k domain.ApiKey) (domain.ApiKey, error)
+	FindById(id uint64) (domain.ApiKey, error)
+	FindByHash(keyHash string) (domain.ApiKey, error)
+	FindAllByFarmId(farmId uint64) ([]domain.ApiKey, error)
+	Revoke(id uint64) error
+	RevokeAllByUserId(userId uint64) error
+	RevokeAllByFarmId(farmId uint64) error
+	Touch(id uint64, date time.Time) error
+}
+
+type apiKeyRepository struct {
+	coll db.Collection
+}
+
+func NewApiKeyRepository(dbSession db.Session) ApiKeyRepository {
+	return apiKeyRepository{
+		coll: dbSession.Collection(ApiKeysTableName),
+	}
+}
+
+func (r apiKeyRepository) Save(k domain.ApiKey) (domain.ApiKey, error) {
+	m := r.mapDomainToModel(k)
+	m.CreatedDate, m.UpdatedDate = time.Now(), time.Now()
+	err := r.coll.InsertReturning(&m)
+	if err != nil {
+		return domain.ApiKey{}, err
+	}
+
+	return r.mapModelToDomain(m), nil
+}
+
+func (r apiKeyRepository) FindById(id uint64) (domain.ApiKey, error) {
+	var m apiKey
+	err := r.coll.Find(db.Cond{"id": id}).One(&m)
+	if err != nil {
+		return domain.ApiKey{}, err
+	}
+
+	return r.mapModelToDomain(m), nil
+}
+
+func (r apiKeyRepository) FindByHash(keyHash string) (domain.ApiKey, error) {
+	var m apiKey
+	err := r.coll.Find(db.Cond{"key_hash": keyHash}).One(&m)
+	if err != nil {
+		return domain.ApiKey{}, err
+	}
+
+	return r.mapModelToDomain(m), nil
+}
+
+func (r apiKeyRepository) FindAllByFarmId(farmId uint64) ([]domain.ApiKey, error) {
+	var data []apiKey
+	err := r.coll.Find(db.Cond{"farm_id": farmId}).OrderBy("-created_date").All(&data)
+	if err != nil {
+		return []domain.ApiKey{}, err
+	}
+
+	keys := make([]domain.ApiKey, len(data))
+	for i, item := range data {
+		keys[i] = r.mapModelToDomain(item)
+	}
+	return keys, nil
+}
+
+func (r apiKeyRepository) Revoke(id uint64) error {
+	now := time.Now()
+	return r.coll.Find(db.Cond{"id": id, "revoked_date": nil}).Update(map[string]interface{}{"revoked_date": now, "updated_date": now})
+}
+
+func (r apiKeyRepository) RevokeAllByUserId(userId uint64) error {
+	now := time.Now()
+	return r.coll.Find(db.Cond{"user_id": userId, "revoked_date": nil}).Update(map[string]interface{}{"revoked_date": now, "updated_date": now})
+}
+
+func (r apiKeyRepository) RevokeAllByFarmId(farmId uint64) error {
+	now := time.Now()
+	return r.coll.Find(db.Cond{"farm_id": farmId, "revoked_date": nil}).Update(map[string]interface{}{"revoked_date": now, "updated_date": now})
+}
+
+func (r apiKeyRepository) Touch(id uint64, date time.Time) error {
+	return r.coll.Find(db.Cond{"id": id}).Update(map[string]interface{}{"last_used_date": date})
+}
+
+func (r apiKeyRepository) mapDomainToModel(d domain.ApiKey) apiKey {
+	scopes := make([]string, len(d.Scopes))
+	for i, s := range d.Scopes {
+		scopes[i] = string(s)
+	}
+
+	return apiKey{
+		Id:           d.Id,
+		UserId:       d.User.Id,
+		FarmId:       d.FarmId,
+		Name:         d.Name,
+		Prefix:       d.Prefix,
+		KeyHash:      d.KeyHash,
+		Scopes:       strings.Join(scopes, ","),
+		ExpiresDate:  d.ExpiresDate,
+		LastUsedDate: d.LastUsedDate,
+		RevokedDate:  d.RevokedDate,
+		CreatedDate:  d.CreatedDate,
+		UpdatedDate:  d.UpdatedDate,
+	}
+}
+
+func (r apiKeyRepository) mapModelToDomain(m apiKey) domain.ApiKey {
+	var scopes []domain.ApiKeyScope
+	for _, s := range strings.Split(m.Scopes, ",") {
+		if s != "" {
+			scopes = append(scopes, domain.ApiKeyScope(s))
+		}
+	}
+
+	return domain.ApiKey{
+		Id:           m.Id,
+		User:         domain.User{Id: m.UserId},
+		FarmId:       m.FarmId,
+		Name:         m.Name,
+		Prefix:       m.Prefix,
+		KeyHash:      m.KeyHash,
+		Scopes:       scopes,
+		ExpiresDate:  m.ExpiresDate,
+		LastUsedDate: m.LastUsedDate,
+		RevokedDate:  m.RevokedDate,
+		CreatedDate:  m.CreatedDate,
+		UpdatedDate:  m.UpdatedDate,
+	}
+}
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"boilerplate/internal/domain"
+	"math"
+	"time"
+
+	"github.com/upper/db/v4"
+	"github.com/upper/db/v4/adapter/postgresql"
+)
+
+const AuditEventsTableName = "audit_events"
+
+type auditEvent struct {
+	Id          uint64              `db:"id,omitempty"`
+	ActorId     *uint64             `db:"actor_id"`
+	Action      string              `db:"action"`
+	Entity      string              `db:"entity"`
+	EntityId    uint64              `db:"entity_id"`
+	Reason      string              `db:"reason"`
+	Before      postgresql.JSONBMap `db:"before"`
+	After       postgresql.JSONBMap `db:"after"`
+	Ip          string              `db:"ip"`
+	RequestId   string              `db:"request_id"`
+	CreatedDate time.Time           `db:"created_date"`
+}
+
+type AuditEventRepository interface {
+	Save(e domain.AuditEvent) (domain.AuditEvent, error)
+	FindAll(filter domain.AuditEventFilter, p domain.Pagination) (domain.AuditEvents, error)
+}
+
+type auditEventRepository struct {
+	coll db.Collection
+}
+
+func NewAuditEventRepository(dbSession db.Session) AuditEventRepository {
+	return auditEventRepository{
+		coll: dbSession.Collection(AuditEventsTableName),
+	}
+}
+
+func (r auditEventRepository) Save(e domain.AuditEvent) (domain.AuditEvent, error) {
+	m := r.mapDomainToModel(e)
+	m.CreatedDate = time.Now()
+	err := r.coll.InsertReturning(&m)
+	if err != nil {
+		return domain.AuditEvent{}, err
+	}
+
+	return r.mapModelToDomain(m), nil
+}
+
+func (r auditEventRepository) FindAll(filter domain.AuditEventFilter, p domain.Pagination) (domain.AuditEvents, error) {
+	cond := db.Cond{}
+	if filter.ActorId != 0 {
+		cond["actor_id"] = filter.ActorId
+	}
+	if filter.Action != "" {
+		cond["action"] = filter.Action
+	}
+	if filter.Entity != "" {
+		cond["entity"] = filter.Entity
+	}
+	if filter.EntityId != 0 {
+		cond["entity_id"] = filter.EntityId
+	}
+	if filter.From != nil {
+		cond["created_date >="] = *filter.From
+	}
+	if filter.To != nil {
+		cond["created_date <="] = *filter.To
+	}
+
+	var data []auditEvent
+	res := r.coll.Find(cond).OrderBy("-created_date", "-id").Paginate(uint(p.CountPerPage))
+	err := res.Page(uint(p.Page)).All(&data)
+	if err != nil {
+		return domain.AuditEvents{}, err
+	}
+
+	events := make([]domain.AuditEvent, len(data))
+	for i := range data {
+		events[i] = r.mapModelToDomain(data[i])
+	}
+
+	total, err := res.TotalEntries()
+	if err != nil {
+		return domain.AuditEvents{}, err
+	}
+
+	return domain.AuditEvents{
+		Items: events,
+		Total: total,
+		Pages: uint(math.Ceil(float64(total) / float64(p.CountPerPage))),
+	}, nil
+}
+
+func (r auditEventRepository) mapDomainToModel(d domain.AuditEvent) auditEvent {
+	var actorId *uint64
+	if d.ActorId != 0 {
+		actorId = &d.ActorId
+	}
+
+	return auditEvent{
+		Id:          d.Id,
+		ActorId:     actorId,
+		Action:      string(d.Action),
+		Entity:      string(d.Entity),
+		EntityId:    d.EntityId,
+		Reason:      d.Reason,
+		Before:      jsonbMap(d.Before),
+		After:       jsonbMap(d.After),
+		Ip:          d.Ip,
+		RequestId:   d.RequestId,
+		CreatedDate: d.CreatedDate,
+	}
+}
+
+func (r auditEventRepository) mapModelToDomain(m auditEvent) domain.AuditEvent {
+	var actorId uint64
+	if m.ActorId != nil {
+		actorId = *m.ActorId
+	}
+
+	return domain.AuditEvent{
+		Id:          m.Id,
+		ActorId:     actorId,
+		Action:      domain.AuditAction(m.Action),
+		Entity:      domain.AuditEntity(m.Entity),
+		EntityId:    m.EntityId,
+		Reason:      m.Reason,
+		Before:      m.Before,
+		After:       m.After,
+		Ip:          m.Ip,
+		RequestId:   m.RequestId,
+		CreatedDate: m.CreatedDate,
+	}
+}
+
+func jsonbMap(m map[string]interface{}) postgresql.JSONBMap {
+	if m == nil {
+		return postgresql.JSONBMap{}
+	}
+	return postgresql.JSONBMap(m)
+}
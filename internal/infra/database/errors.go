@@ -0,0 +1,19 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/jackc/pgconn"
+)
+
+// uniqueViolation is the PostgreSQL error code of a unique constraint or
+// index being broken.
+const uniqueViolation = "23505"
+
+// IsUniqueViolation reports whether the query failed because a row with
+// the same unique key already exists. It lets callers turn a lost race
+// into the same error their own check would have given.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
@@ -0,0 +1,312 @@
+package database
+
+import (
+	"boilerplate/internal/domain"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+const (
+	FarmMembersTableName     = "farm_members"
+	FarmInvitationsTableName = "farm_invitations"
+)
+
+type farmMember struct {
+	Id          uint64    `db:"id,omitempty"`
+	FarmId      uint64    `db:"farm_id"`
+	UserId      uint64    `db:"user_id"`
+	Role        string    `db:"role"`
+	CreatedDate time.Time `db:"created_date,omitempty"`
+	UpdatedDate time.Time `db:"updated_date,omitempty"`
+}
+
+type farmMemberWithUser struct {
+	Member          farmMember
+	UserName        string  `db:"user_name"`
+	UserEmail       string  `db:"user_email"`
+	UserPhoneNumber *string `db:"user_phone_number"`
+}
+
+type farmInvitation struct {
+	Id           uint64     `db:"id,omitempty"`
+	FarmId       uint64     `db:"farm_id"`
+	Role         string     `db:"role"`
+	Email        *string    `db:"email"`
+	PhoneNumber  *string    `db:"phone_number"`
+	InvitedBy    uint64     `db:"invited_by"`
+	ExpiresDate  time.Time  `db:"expires_date"`
+	AcceptedDate *time.Time `db:"accepted_date"`
+	DeclinedDate *time.Time `db:"declined_date"`
+	RevokedDate  *time.Time `db:"revoked_date"`
+	CreatedDate  time.Time  `db:"created_date,omitempty"`
+	UpdatedDate  time.Time  `db:"updated_date,omitempty"`
+}
+
+type farmInvitationWithFarm struct {
+	Invitation farmInvitation
+	FarmName   *string `db:"farm_name"`
+}
+
+type FarmMemberRepository interface {
+	SaveMember(m domain.FarmMember) (domain.FarmMember, error)
+	UpdateMember(m domain.FarmMember) (domain.FarmMember, error)
+	FindMemberById(id uint64) (domain.FarmMember, error)
+	FindMember(farmId uint64, userId uint64) (domain.FarmMember, error)
+	FindMembersByFarmId(farmId uint64) ([]domain.FarmMember, error)
+	FindMembersByFarmIds(farmIds []uint64) ([]domain.FarmMember, error)
+	DeleteMember(id uint64) error
+	DeleteAllByUserId(userId uint64) error
+	DeleteAllByFarmId(farmId uint64) error
+	SaveInvitation(i domain.FarmInvitation) (domain.FarmInvitation, error)
+	UpdateInvitation(i domain.FarmInvitation) (domain.FarmInvitation, error)
+	FindInvitationById(id uint64) (domain.FarmInvitation, error)
+	FindInvitationsByFarmId(farmId uint64) ([]domain.FarmInvitation, error)
+	FindPendingInvitations(email *string, phoneNumber *string, now time.Time) ([]domain.FarmInvitation, error)
+}
+
+type farmMemberRepository struct {
+	members     db.Collection
+	invitations db.Collection
+}
+
+func NewFarmMemberRepository(dbSession db.Session) FarmMemberRepository {
+	return farmMemberRepository{
+		members:     dbSession.Collection(FarmMembersTableName),
+		invitations: dbSession.Collection(FarmInvitationsTableName),
+	}
+}
+
+func (r farmMemberRepository) SaveMember(m domain.FarmMember) (domain.FarmMember, error) {
+	model := r.mapMemberDomainToModel(m)
+	model.CreatedDate, model.UpdatedDate = time.Now(), time.Now()
+	err := r.members.InsertReturning(&model)
+	if err != nil {
+		return domain.FarmMember{}, err
+	}
+
+	return r.FindMemberById(model.Id)
+}
+
+func (r farmMemberRepository) UpdateMember(m domain.FarmMember) (domain.FarmMember, error) {
+	model := r.mapMemberDomainToModel(m)
+	model.UpdatedDate = time.Now()
+	err := r.members.Find(db.Cond{"id": model.Id}).Update(&model)
+	if err != nil {
+		return domain.FarmMember{}, err
+	}
+
+	return r.FindMemberById(model.Id)
+}
+
+func (r farmMemberRepository) FindMemberById(id uint64) (domain.FarmMember, error) {
+	return r.findMember(db.Cond{"fm.id": id})
+}
+
+func (r farmMemberRepository) FindMember(farmId uint64, userId uint64) (domain.FarmMember, error) {
+	return r.findMember(db.Cond{"fm.farm_id": farmId, "fm.user_id": userId})
+}
+
+func (r farmMemberRepository) findMember(cond db.Cond) (domain.FarmMember, error) {
+	var m farmMemberWithUser
+	err := r.membersQuery().Where(cond).One(&m)
+	if err != nil {
+		return domain.FarmMember{}, err
+	}
+
+	return r.mapMemberModelToDomain(m), nil
+}
+
+func (r farmMemberRepository) FindMembersByFarmId(farmId uint64) ([]domain.FarmMember, error) {
+	return r.findMembers(db.Cond{"fm.farm_id": farmId})
+}
+
+func (r farmMemberRepository) FindMembersByFarmIds(farmIds []uint64) ([]domain.FarmMember, error) {
+	if len(farmIds) == 0 {
+		return []domain.FarmMember{}, nil
+	}
+	return r.findMembers(db.Cond{"fm.farm_id IN": farmIds})
+}
+
+func (r farmMemberRepository) findMembers(cond db.Cond) ([]domain.FarmMember, error) {
+	var data []farmMemberWithUser
+	err := r.membersQuery().Where(cond).OrderBy("fm.id").All(&data)
+	if err != nil {
+		return []domain.FarmMember{}, err
+	}
+
+	members := make([]domain.FarmMember, len(data))
+	for i, item := range data {
+		members[i] = r.mapMemberModelToDomain(item)
+	}
+	return members, nil
+}
+
+func (r farmMemberRepository) membersQuery() db.Selector {
+	return r.members.Session().SQL().
+		Select("fm.*", "u.name AS user_name", "u.email AS user_email", "u.phone_number AS user_phone_number").
+		From("farm_members AS fm").
+		Join("users AS u").On("u.id = fm.user_id")
+}
+
+func (r farmMemberRepository) DeleteMember(id uint64) error {
+	return r.members.Find(db.Cond{"id": id}).Delete()
+}
+
+func (r farmMemberRepository) DeleteAllByUserId(userId uint64) error {
+	return r.members.Find(db.Cond{"user_id": userId}).Delete()
+}
+
+// DeleteAllByFarmId drops the members of the farm and its invitations.
+func (r farmMemberRepository) DeleteAllByFarmId(farmId uint64) error {
+	err := r.members.Find(db.Cond{"farm_id": farmId}).Delete()
+	if err != nil {
+		return err
+	}
+	return r.invitations.Find(db.Cond{"farm_id": farmId}).Delete()
+}
+
+func (r farmMemberRepository) SaveInvitation(i domain.FarmInvitation) (domain.FarmInvitation, error) {
+	model := r.mapInvitationDomainToModel(i)
+	model.CreatedDate, model.UpdatedDate = time.Now(), time.Now()
+	err := r.invitations.InsertReturning(&model)
+	if err != nil {
+		return domain.FarmInvitation{}, err
+	}
+
+	return r.FindInvitationById(model.Id)
+}
+
+func (r farmMemberRepository) UpdateInvitation(i domain.FarmInvitation) (domain.FarmInvitation, error) {
+	model := r.mapInvitationDomainToModel(i)
+	model.UpdatedDate = time.Now()
+	err := r.invitations.Find(db.Cond{"id": model.Id}).Update(&model)
+	if err != nil {
+		return domain.FarmInvitation{}, err
+	}
+
+	return r.FindInvitationById(model.Id)
+}
+
+func (r farmMemberRepository) FindInvitationById(id uint64) (domain.FarmInvitation, error) {
+	var m farmInvitationWithFarm
+	err := r.invitationsQuery().Where(db.Cond{"fi.id": id}).One(&m)
+	if err != nil {
+		return domain.FarmInvitation{}, err
+	}
+
+	return r.mapInvitationModelToDomain(m), nil
+}
+
+func (r farmMemberRepository) FindInvitationsByFarmId(farmId uint64) ([]domain.FarmInvitation, error) {
+	return r.findInvitations(db.Cond{"fi.farm_id": farmId})
+}
+
+// FindPendingInvitations returns the invitations to any of the addresses
+// that are still waiting for an answer.
+func (r farmMemberRepository) FindPendingInvitations(email *string, phoneNumber *string, now time.Time) ([]domain.FarmInvitation, error) {
+	var addresses []db.LogicalExpr
+	if email != nil {
+		addresses = append(addresses, db.Cond{"fi.email": *email})
+	}
+	if phoneNumber != nil {
+		addresses = append(addresses, db.Cond{"fi.phone_number": *phoneNumber})
+	}
+	if len(addresses) == 0 {
+		return []domain.FarmInvitation{}, nil
+	}
+
+	return r.findInvitations(db.And(
+		db.Or(addresses...),
+		db.Cond{
+			"fi.accepted_date":  nil,
+			"fi.declined_date":  nil,
+			"fi.revoked_date":   nil,
+			"fi.expires_date >": now,
+		},
+	))
+}
+
+func (r farmMemberRepository) findInvitations(cond db.LogicalExpr) ([]domain.FarmInvitation, error) {
+	var data []farmInvitationWithFarm
+	err := r.invitationsQuery().Where(cond).OrderBy("-fi.created_date").All(&data)
+	if err != nil {
+		return []domain.FarmInvitation{}, err
+	}
+
+	invitations := make([]domain.FarmInvitation, len(data))
+	for i, item := range data {
+		invitations[i] = r.mapInvitationModelToDomain(item)
+	}
+	return invitations, nil
+}
+
+func (r farmMemberRepository) invitationsQuery() db.Selector {
+	return r.invitations.Session().SQL().
+		Select("fi.*", "f.name AS farm_name").
+		From("farm_invitations AS fi").
+		Join("farms AS f").On("f.id = fi.farm_id")
+}
+
+func (r farmMemberRepository) mapMemberDomainToModel(d domain.FarmMember) farmMember {
+	return farmMember{
+		Id:          d.Id,
+		FarmId:      d.FarmId,
+		UserId:      d.User.Id,
+		Role:        string(d.Role),
+		CreatedDate: d.CreatedDate,
+		UpdatedDate: d.UpdatedDate,
+	}
+}
+
+func (r farmMemberRepository) mapMemberModelToDomain(m farmMemberWithUser) domain.FarmMember {
+	return domain.FarmMember{
+		Id:     m.Member.Id,
+		FarmId: m.Member.FarmId,
+		User: domain.User{
+			Id:          m.Member.UserId,
+			Name:        m.UserName,
+			Email:       m.UserEmail,
+			PhoneNumber: m.UserPhoneNumber,
+		},
+		Role:        domain.FarmRole(m.Member.Role),
+		CreatedDate: m.Member.CreatedDate,
+		UpdatedDate: m.Member.UpdatedDate,
+	}
+}
+
+func (r farmMemberRepository) mapInvitationDomainToModel(d domain.FarmInvitation) farmInvitation {
+	return farmInvitation{
+		Id:           d.Id,
+		FarmId:       d.FarmId,
+		Role:         string(d.Role),
+		Email:        d.Email,
+		PhoneNumber:  d.PhoneNumber,
+		InvitedBy:    d.InvitedBy.Id,
+		ExpiresDate:  d.ExpiresDate,
+		AcceptedDate: d.AcceptedDate,
+		DeclinedDate: d.DeclinedDate,
+		RevokedDate:  d.RevokedDate,
+		CreatedDate:  d.CreatedDate,
+		UpdatedDate:  d.UpdatedDate,
+	}
+}
+
+func (r farmMemberRepository) mapInvitationModelToDomain(m farmInvitationWithFarm) domain.FarmInvitation {
+	i := m.Invitation
+	return domain.FarmInvitation{
+		Id:           i.Id,
+		FarmId:       i.FarmId,
+		FarmName:     m.FarmName,
+		Role:         domain.FarmRole(i.Role),
+		Email:        i.Email,
+		PhoneNumber:  i.PhoneNumber,
+		InvitedBy:    domain.User{Id: i.InvitedBy},
+		ExpiresDate:  i.ExpiresDate,
+		AcceptedDate: i.AcceptedDate,
+		DeclinedDate: i.DeclinedDate,
+		RevokedDate:  i.RevokedDate,
+		CreatedDate:  i.CreatedDate,
+		UpdatedDate:  i.UpdatedDate,
+	}
+}
@@ -11,16 +11,18 @@ import (
 const FarmsTableName = "farms"
 
 type farm struct {
-	Id          uint64     `db:"id,omitempty"`
-	Name        *string    `db:"name"`
-	City        string     `db:"city"`
-	Address     string     `db:"address"`
-	UserId      uint64     `db:"user_id"`
-	Longitude   float64    `db:"longitude"`
-	Latitude    float64    `db:"latitude"`
-	CreatedDate time.Time  `db:"created_date,omitempty"`
-	UpdatedDate time.Time  `db:"updated_date,omitempty"`
-	DeletedDate *time.Time `db:"deleted_date,omitempty"`
+	Id           uint64     `db:"id,omitempty"`
+	Name         *string    `db:"name"`
+	City         string     `db:"city"`
+	Address      string     `db:"address"`
+	UserId       uint64     `db:"user_id"`
+	Longitude    float64    `db:"longitude"`
+	Latitude     float64    `db:"latitude"`
+	Rating       float64    `db:"rating,omitempty"`
+	ReviewsCount uint64     `db:"reviews_count,omitempty"`
+	CreatedDate  time.Time  `db:"created_date,omitempty"`
+	UpdatedDate  time.Time  `db:"updated_date,omitempty"`
+	DeletedDate  *time.Time `db:"deleted_date,omitempty"`
 }
 
 type farmWithUser struct {
@@ -29,6 +31,7 @@ type farmWithUser struct {
 	UserName        string `db:"user_name"`
 	UserEmail       string `db:"user_email"`
 	UserPhoneNumber string `db:"user_phone_number"`
+	IsFollowed      bool   `db:"is_followed"`
 }
 
 type FarmRepository interface {
@@ -36,9 +39,10 @@ type FarmRepository interface {
 	FindById(id uint64) (domain.Farm, error)
 	Update(farm domain.Farm) (domain.Farm, error)
 	FindAllByCoords(points domain.Points, p domain.Pagination) (domain.Farms, error)
-	FindAll(pag domain.Pagination) (domain.Farms, error)
+	FindAll(followerId uint64, pag domain.Pagination) (domain.Farms, error)
 	Delete(id uint64) error
 	mapModelToDomainWithoutUser(m farm) domain.Farm
+	FindAllByFilter(filter domain.FarmFilter, p domain.Pagination) (domain.Farms, error)
 }
 
 type farmRepository struct {
@@ -95,8 +99,16 @@ func (r farmRepository) Delete(id uint64) error {
 	return r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).Update(map[string]interface{}{"deleted_date": time.Now()})
 }
 
-func (r farmRepository) FindAll(p domain.Pagination) (domain.Farms, error) {
-	farms, err := r.findFarmsWithUsers(db.Cond{"farms.deleted_date": nil}, p)
+// FindAll returns the farms followed by the user first, then all the others.
+func (r farmRepository) FindAll(followerId uint64, p domain.Pagination) (domain.Farms, error) {
+	query := r.coll.Session().SQL().Select("farms.*", "u.id AS id_user", "u.name AS user_name", "u.email AS user_email", "u.phone_number AS user_phone_number", db.Raw("ff.id IS NOT NULL AS is_followed")).
+		From("farms").
+		Where(db.Cond{"farms.deleted_date": nil}).
+		Join("users as u").On("u.id = farms.user_id").
+		LeftJoin("farm_follows as ff").On("ff.farm_id = farms.id AND ff.user_id = ?", followerId).
+		OrderBy(db.Raw("ff.id IS NULL"), "farms.id")
+
+	farms, err := r.paginateFarmsWithUsers(query, p)
 	if err != nil {
 		return domain.Farms{}, err
 	}
@@ -104,6 +116,32 @@ func (r farmRepository) FindAll(p domain.Pagination) (domain.Farms, error) {
 	return farms, nil
 }
 
+func (r farmRepository) FindAllByFilter(filter domain.FarmFilter, p domain.Pagination) (domain.Farms, error) {
+	cond := db.And(db.Cond{"farms.deleted_date": nil})
+	if filter.Search != "" {
+		search := "%" + filter.Search + "%"
+		cond = cond.And(db.Or(
+			db.Cond{"farms.name ILIKE": search},
+			db.Cond{"farms.city ILIKE": search},
+			db.Cond{"u.name ILIKE": search},
+		))
+	}
+	if filter.UserId != 0 {
+		cond = cond.And(db.Cond{"farms.user_id": filter.UserId})
+	}
+	if filter.MemberId != 0 {
+		cond = cond.And(db.Raw("farms.id IN (SELECT farm_id FROM farm_members WHERE user_id = ?)", filter.MemberId))
+	}
+
+	query := r.coll.Session().SQL().Select("farms.*", "u.id AS id_user", "u.name AS user_name", "u.email AS user_email", "u.phone_number AS user_phone_number").
+		From("farms").
+		Join("users as u").On("u.id = farms.user_id").
+		Where(cond).
+		OrderBy("farms.id")
+
+	return r.paginateFarmsWithUsers(query, p)
+}
+
 func (r farmRepository) FindAllByCoords(points domain.Points, p domain.Pagination) (domain.Farms, error) {
 	offers, err := r.offerRepo.FindByCategory(points.Category)
 	if err != nil {
@@ -129,11 +167,16 @@ func (r farmRepository) FindAllByCoords(points domain.Points, p domain.Paginatio
 }
 
 func (r farmRepository) findFarmsWithUsers(cond db.Cond, p domain.Pagination) (domain.Farms, error) {
-	var farms []farmWithUser
 	query := r.coll.Session().SQL().Select("farms.*", "u.id AS id_user", "u.name AS user_name", "u.email AS user_email", "u.phone_number AS user_phone_number").
 		From("farms").
 		Where(cond).
 		Join("users as u").On("u.id = farms.user_id")
+
+	return r.paginateFarmsWithUsers(query, p)
+}
+
+func (r farmRepository) paginateFarmsWithUsers(query db.Selector, p domain.Pagination) (domain.Farms, error) {
+	var farms []farmWithUser
 	res := query.Paginate(uint(p.CountPerPage))
 	err := res.Page(uint(p.Page)).All(&farms)
 	if err != nil {
@@ -143,6 +186,7 @@ func (r farmRepository) findFarmsWithUsers(cond db.Cond, p domain.Pagination) (d
 	domainFarms := make([]domain.Farm, len(farms))
 	for i, farm := range farms {
 		domainFarms[i] = r.mapModelToDomain(farm.Farm, user{Id: farm.UserId, Name: farm.UserName, Email: farm.UserEmail, PhoneNumber: &farm.UserPhoneNumber})
+		domainFarms[i].IsFollowed = farm.IsFollowed
 	}
 	farmsR := domain.Farms{Items: domainFarms}
 	totalCount, err := res.TotalEntries()
@@ -211,31 +255,35 @@ func (r farmRepository) mapDomainToModel(m domain.Farm) farm {
 
 func (r farmRepository) mapModelToDomain(m farm, u user) domain.Farm {
 	return domain.Farm{
-		Id:          m.Id,
-		Name:        m.Name,
-		City:        m.City,
-		Address:     m.Address,
-		CreatedDate: m.CreatedDate,
-		User:        mapModelToDomainUser(u),
-		Latitude:    m.Latitude,
-		Longitude:   m.Longitude,
-		AllImages:   r.GetAllImages(m.Id),
-		UpdatedDate: m.UpdatedDate,
-		DeletedDate: m.DeletedDate,
+		Id:           m.Id,
+		Name:         m.Name,
+		City:         m.City,
+		Address:      m.Address,
+		CreatedDate:  m.CreatedDate,
+		User:         mapModelToDomainUser(u),
+		Latitude:     m.Latitude,
+		Longitude:    m.Longitude,
+		AllImages:    r.GetAllImages(m.Id),
+		Rating:       m.Rating,
+		ReviewsCount: m.ReviewsCount,
+		UpdatedDate:  m.UpdatedDate,
+		DeletedDate:  m.DeletedDate,
 	}
 }
 
 func (r farmRepository) mapModelToDomainWithoutUser(m farm) domain.Farm {
 	return domain.Farm{
-		Id:          m.Id,
-		Name:        m.Name,
-		City:        m.City,
-		Address:     m.Address,
-		CreatedDate: m.CreatedDate,
-		User:        domain.User{Id: m.UserId},
-		Latitude:    m.Latitude,
-		Longitude:   m.Longitude,
-		UpdatedDate: m.UpdatedDate,
-		DeletedDate: m.DeletedDate,
+		Id:           m.Id,
+		Name:         m.Name,
+		City:         m.City,
+		Address:      m.Address,
+		CreatedDate:  m.CreatedDate,
+		User:         domain.User{Id: m.UserId},
+		Latitude:     m.Latitude,
+		Longitude:    m.Longitude,
+		Rating:       m.Rating,
+		ReviewsCount: m.ReviewsCount,
+		UpdatedDate:  m.UpdatedDate,
+		DeletedDate:  m.DeletedDate,
 	}
 }
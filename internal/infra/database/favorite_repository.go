@@ -0,0 +1,160 @@
+package database
+
+import (
+	"boilerplate/internal/domain"
+	"math"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+const (
+	FavoritesTableName   = "favorites"
+	FarmFollowsTableName = "farm_follows"
+)
+
+type favorite struct {
+	Id          uint64    `db:"id,omitempty"`
+	UserId      uint64    `db:"user_id"`
+	OfferId     uint64    `db:"offer_id"`
+	CreatedDate time.Time `db:"created_date,omitempty"`
+}
+
+type farmFollow struct {
+	Id          uint64    `db:"id,omitempty"`
+	UserId      uint64    `db:"user_id"`
+	FarmId      uint64    `db:"farm_id"`
+	CreatedDate time.Time `db:"created_date,omitempty"`
+}
+
+type FavoriteRepository interface {
+	AddOffer(userId uint64, offerId uint64) error
+	RemoveOffer(userId uint64, offerId uint64) error
+	FindOffers(userId uint64, p domain.Pagination) (domain.Offers, error)
+	FindFavoriteOfferIds(userId uint64, offerIds []uint64) ([]uint64, error)
+	FollowFarm(userId uint64, farmId uint64) error
+	UnfollowFarm(userId uint64, farmId uint64) error
+	FindFollowedFarms(userId uint64, p domain.Pagination) (domain.Farms, error)
+}
+
+type favoriteRepository struct {
+	coll       db.Collection
+	followColl db.Collection
+	offerRepo  OfferRepository
+	farmRepo   FarmRepository
+}
+
+func NewFavoriteRepository(dbSession db.Session, offerR OfferRepository, farmR FarmRepository) FavoriteRepository {
+	return favoriteRepository{
+		coll:       dbSession.Collection(FavoritesTableName),
+		followColl: dbSession.Collection(FarmFollowsTableName),
+		offerRepo:  offerR,
+		farmRepo:   farmR,
+	}
+}
+
+func (r favoriteRepository) AddOffer(userId uint64, offerId uint64) error {
+	exists, err := r.coll.Find(db.Cond{"user_id": userId, "offer_id": offerId}).Exists()
+	if err != nil || exists {
+		return err
+	}
+
+	f := favorite{UserId: userId, OfferId: offerId, CreatedDate: time.Now()}
+	return r.coll.InsertReturning(&f)
+}
+
+func (r favoriteRepository) RemoveOffer(userId uint64, offerId uint64) error {
+	return r.coll.Find(db.Cond{"user_id": userId, "offer_id": offerId}).Delete()
+}
+
+func (r favoriteRepository) FindOffers(userId uint64, p domain.Pagination) (domain.Offers, error) {
+	var data []favorite
+	res := r.coll.Find(db.Cond{
+		"user_id":     userId,
+		"offer_id IN": db.Raw("(SELECT id FROM offers WHERE deleted_date IS NULL)"),
+	}).OrderBy("-created_date").Paginate(uint(p.CountPerPage))
+	err := res.Page(uint(p.Page)).All(&data)
+	if err != nil {
+		return domain.Offers{}, err
+	}
+
+	offers := domain.Offers{Items: make([]domain.Offer, len(data))}
+	for i, item := range data {
+		offers.Items[i], err = r.offerRepo.FindById(item.OfferId)
+		if err != nil {
+			return domain.Offers{}, err
+		}
+		offers.Items[i].IsFavorite = true
+	}
+
+	totalCount, err := res.TotalEntries()
+	if err != nil {
+		return domain.Offers{}, err
+	}
+	offers.Total = totalCount
+	offers.Pages = uint(math.Ceil(float64(offers.Total) / float64(p.CountPerPage)))
+
+	return offers, nil
+}
+
+func (r favoriteRepository) FindFavoriteOfferIds(userId uint64, offerIds []uint64) ([]uint64, error) {
+	if len(offerIds) == 0 {
+		return []uint64{}, nil
+	}
+
+	var data []favorite
+	err := r.coll.Find(db.Cond{"user_id": userId, "offer_id IN": offerIds}).All(&data)
+	if err != nil {
+		return []uint64{}, err
+	}
+
+	ids := make([]uint64, len(data))
+	for i, item := range data {
+		ids[i] = item.OfferId
+	}
+	return ids, nil
+}
+
+func (r favoriteRepository) FollowFarm(userId uint64, farmId uint64) error {
+	exists, err := r.followColl.Find(db.Cond{"user_id": userId, "farm_id": farmId}).Exists()
+	if err != nil || exists {
+		return err
+	}
+
+	f := farmFollow{UserId: userId, FarmId: farmId, CreatedDate: time.Now()}
+	return r.followColl.InsertReturning(&f)
+}
+
+func (r favoriteRepository) UnfollowFarm(userId uint64, farmId uint64) error {
+	return r.followColl.Find(db.Cond{"user_id": userId, "farm_id": farmId}).Delete()
+}
+
+func (r favoriteRepository) FindFollowedFarms(userId uint64, p domain.Pagination) (domain.Farms, error) {
+	var data []farmFollow
+	res := r.followColl.Find(db.Cond{
+		"user_id":    userId,
+		"farm_id IN": db.Raw("(SELECT id FROM farms WHERE deleted_date IS NULL)"),
+	}).OrderBy("-created_date").Paginate(uint(p.CountPerPage))
+	err := res.Page(uint(p.Page)).All(&data)
+	if err != nil {
+		return domain.Farms{}, err
+	}
+
+	farms := domain.Farms{Items: make([]domain.Farm, len(data))}
+	for i, item := range data {
+		farms.Items[i], err = r.farmRepo.FindById(item.FarmId)
+		if err != nil {
+			return domain.Farms{}, err
+		}
+		farms.Items[i].IsFollowed = true
+	}
+
+	totalCount, err := res.TotalEntries()
+	if err != nil {
+		return domain.Farms{}, err
+	}
+	farms.Total = totalCount
+	farms.Pages = uint(math.Ceil(float64(farms.Total) / float64(p.CountPerPage)))
+
+	return farms, nil
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"boilerplate/internal/domain"
+	"math"
 	"time"
 
 	"github.com/upper/db/v4"
@@ -38,6 +39,7 @@ type InvoiceRepository interface {
 	FindOne(invoiceId string) (domain.Invoice, error)
 	FindAll() ([]domain.Invoice, error)
 	FindAllUpdatedWithinOneDay() ([]domain.Invoice, error)
+	FindAllByFilter(filter domain.PaymentFilter, p domain.Pagination) (domain.Invoices, error)
 	Delete(invoiceId string) error
 }
 
@@ -163,6 +165,37 @@ func (r invoiceRepository) FindAllUpdatedWithinOneDay() ([]domain.Invoice, error
 	return r.mapModelToDomainCollection(invoiceModels), nil
 }
 
+func (r invoiceRepository) FindAllByFilter(filter domain.PaymentFilter, p domain.Pagination) (domain.Invoices, error) {
+	cond := db.Cond{}
+	if filter.Status != "" {
+		cond["status"] = filter.Status
+	}
+	if filter.From != nil {
+		cond["created_date >="] = *filter.From
+	}
+	if filter.To != nil {
+		cond["created_date <"] = *filter.To
+	}
+
+	var invoiceModels []invoice
+	res := r.coll.Find(cond).OrderBy("-created_date").Paginate(uint(p.CountPerPage))
+	err := res.Page(uint(p.Page)).All(&invoiceModels)
+	if err != nil {
+		return domain.Invoices{}, err
+	}
+
+	total, err := res.TotalEntries()
+	if err != nil {
+		return domain.Invoices{}, err
+	}
+
+	return domain.Invoices{
+		Items: r.mapModelToDomainCollection(invoiceModels),
+		Total: total,
+		Pages: uint(math.Ceil(float64(total) / float64(p.CountPerPage))),
+	}, nil
+}
+
 func (r invoiceRepository) Delete(invoiceId string) error {
 	return r.coll.Find(db.Cond{"invoice_id": invoiceId}).Delete()
 }
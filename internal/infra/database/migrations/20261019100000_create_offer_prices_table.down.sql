@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS offer_price_schedules;
+DROP TABLE IF EXISTS offer_prices;
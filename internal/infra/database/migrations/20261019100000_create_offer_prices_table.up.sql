@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS offer_prices
+(
+    id           SERIAL PRIMARY KEY,
+    offer_id     INTEGER NOT NULL,
+    price        FLOAT8 NOT NULL,
+    created_date TIMESTAMP,
+    CONSTRAINT fk_offer_id FOREIGN KEY (offer_id) REFERENCES offers(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS offer_prices_offer_id_idx ON offer_prices (offer_id, created_date);
+
+INSERT INTO offer_prices (offer_id, price, created_date)
+SELECT id, price, created_date FROM offers;
+
+CREATE TABLE IF NOT EXISTS offer_price_schedules
+(
+    id             SERIAL PRIMARY KEY,
+    offer_id       INTEGER NOT NULL,
+    price          FLOAT8 NOT NULL,
+    previous_price FLOAT8 NULL,
+    start_date     TIMESTAMP NOT NULL,
+    end_date       TIMESTAMP NULL,
+    status         TEXT NOT NULL,
+    created_date   TIMESTAMP,
+    updated_date   TIMESTAMP,
+    CONSTRAINT fk_offer_id FOREIGN KEY (offer_id) REFERENCES offers(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS offer_price_schedules_status_idx ON offer_price_schedules (status, start_date);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS order_discounts;
+DROP TABLE IF EXISTS promotions;
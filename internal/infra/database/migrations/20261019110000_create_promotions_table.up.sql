@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS promotions
+(
+    id                   SERIAL PRIMARY KEY,
+    code                 TEXT NOT NULL,
+    title                TEXT NOT NULL,
+    type                 TEXT NOT NULL,
+    value                FLOAT8 NOT NULL DEFAULT 0,
+    buy_amount           INTEGER NOT NULL DEFAULT 0,
+    get_amount           INTEGER NOT NULL DEFAULT 0,
+    min_order_price      FLOAT8 NOT NULL DEFAULT 0,
+    usage_limit          INTEGER NULL,
+    usage_limit_per_user INTEGER NULL,
+    start_date           TIMESTAMP NOT NULL,
+    end_date             TIMESTAMP NULL,
+    is_active            BOOLEAN NOT NULL DEFAULT TRUE,
+    user_id              INTEGER NOT NULL,
+    farm_id              INTEGER NULL,
+    offer_id             INTEGER NULL,
+    created_date         TIMESTAMP,
+    updated_date         TIMESTAMP,
+    deleted_date         TIMESTAMP NULL,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_farm_id FOREIGN KEY (farm_id) REFERENCES farms(id) ON DELETE CASCADE,
+    CONSTRAINT fk_offer_id FOREIGN KEY (offer_id) REFERENCES offers(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS promotions_code_idx ON promotions (code) WHERE deleted_date IS NULL;
+
+CREATE TABLE IF NOT EXISTS order_discounts
+(
+    id           SERIAL PRIMARY KEY,
+    order_id     INTEGER NOT NULL,
+    promotion_id INTEGER NOT NULL,
+    amount       FLOAT8 NOT NULL,
+    created_date TIMESTAMP,
+    updated_date TIMESTAMP,
+    CONSTRAINT fk_order_id FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    CONSTRAINT fk_promotion_id FOREIGN KEY (promotion_id) REFERENCES promotions(id) ON DELETE CASCADE,
+    CONSTRAINT order_discounts_order_promotion_key UNIQUE (order_id, promotion_id)
+);
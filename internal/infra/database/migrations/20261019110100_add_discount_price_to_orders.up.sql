@@ -0,0 +1,2 @@
+ALTER TABLE orders
+ADD COLUMN discount_price FLOAT8 NOT NULL DEFAULT 0;
@@ -0,0 +1,10 @@
+ALTER TABLE farms
+DROP COLUMN rating,
+DROP COLUMN reviews_count;
+
+ALTER TABLE offers
+DROP COLUMN rating,
+DROP COLUMN reviews_count;
+
+DROP TABLE IF EXISTS review_reports;
+DROP TABLE IF EXISTS reviews;
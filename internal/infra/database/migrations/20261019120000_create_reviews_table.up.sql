@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS reviews
+(
+    id            SERIAL PRIMARY KEY,
+    user_id       INTEGER NOT NULL,
+    offer_id      INTEGER NOT NULL,
+    farm_id       INTEGER NOT NULL,
+    order_item_id INTEGER NOT NULL,
+    rating        SMALLINT NOT NULL,
+    text          TEXT NOT NULL DEFAULT '',
+    reply         TEXT NULL,
+    reply_date    TIMESTAMP NULL,
+    status        TEXT NOT NULL,
+    created_date  TIMESTAMP,
+    updated_date  TIMESTAMP,
+    deleted_date  TIMESTAMP NULL,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_offer_id FOREIGN KEY (offer_id) REFERENCES offers(id) ON DELETE CASCADE,
+    CONSTRAINT fk_farm_id FOREIGN KEY (farm_id) REFERENCES farms(id) ON DELETE CASCADE,
+    CONSTRAINT fk_order_item_id FOREIGN KEY (order_item_id) REFERENCES order_items(id) ON DELETE CASCADE,
+    CONSTRAINT reviews_rating_check CHECK (rating BETWEEN 1 AND 5)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS reviews_user_offer_idx ON reviews (user_id, offer_id) WHERE deleted_date IS NULL;
+CREATE INDEX IF NOT EXISTS reviews_offer_id_idx ON reviews (offer_id, created_date);
+CREATE INDEX IF NOT EXISTS reviews_farm_id_idx ON reviews (farm_id, created_date);
+
+CREATE TABLE IF NOT EXISTS review_reports
+(
+    id           SERIAL PRIMARY KEY,
+    review_id    INTEGER NOT NULL,
+    user_id      INTEGER NOT NULL,
+    reason       TEXT NOT NULL,
+    created_date TIMESTAMP,
+    CONSTRAINT fk_review_id FOREIGN KEY (review_id) REFERENCES reviews(id) ON DELETE CASCADE,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT review_reports_review_user_key UNIQUE (review_id, user_id)
+);
+
+ALTER TABLE offers
+ADD COLUMN rating FLOAT8 NOT NULL DEFAULT 0,
+ADD COLUMN reviews_count INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE farms
+ADD COLUMN rating FLOAT8 NOT NULL DEFAULT 0,
+ADD COLUMN reviews_count INTEGER NOT NULL DEFAULT 0;
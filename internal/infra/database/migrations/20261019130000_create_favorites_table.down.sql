@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS farm_follows;
+DROP TABLE IF EXISTS favorites;
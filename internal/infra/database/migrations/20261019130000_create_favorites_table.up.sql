@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS favorites
+(
+    id           SERIAL PRIMARY KEY,
+    user_id      INTEGER NOT NULL,
+    offer_id     INTEGER NOT NULL,
+    created_date TIMESTAMP,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_offer_id FOREIGN KEY (offer_id) REFERENCES offers(id) ON DELETE CASCADE,
+    CONSTRAINT favorites_user_offer_key UNIQUE (user_id, offer_id)
+);
+
+CREATE TABLE IF NOT EXISTS farm_follows
+(
+    id           SERIAL PRIMARY KEY,
+    user_id      INTEGER NOT NULL,
+    farm_id      INTEGER NOT NULL,
+    created_date TIMESTAMP,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_farm_id FOREIGN KEY (farm_id) REFERENCES farms(id) ON DELETE CASCADE,
+    CONSTRAINT farm_follows_user_farm_key UNIQUE (user_id, farm_id)
+);
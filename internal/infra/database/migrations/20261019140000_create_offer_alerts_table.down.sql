@@ -0,0 +1 @@
+DROP TABLE IF EXISTS offer_alerts;
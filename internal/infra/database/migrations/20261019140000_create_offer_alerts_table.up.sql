@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS offer_alerts
+(
+    id            SERIAL PRIMARY KEY,
+    user_id       INTEGER NOT NULL,
+    offer_id      INTEGER NOT NULL,
+    type          TEXT NOT NULL,
+    target_price  FLOAT8 NULL,
+    channel       TEXT NOT NULL,
+    notified      BOOLEAN NOT NULL DEFAULT FALSE,
+    notified_date TIMESTAMP NULL,
+    created_date  TIMESTAMP,
+    updated_date  TIMESTAMP,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_offer_id FOREIGN KEY (offer_id) REFERENCES offers(id) ON DELETE CASCADE,
+    CONSTRAINT offer_alerts_user_offer_type_key UNIQUE (user_id, offer_id, type)
+);
+
+CREATE INDEX IF NOT EXISTS offer_alerts_offer_id_idx ON offer_alerts (offer_id, type, notified);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS notifications
+(
+    id           SERIAL PRIMARY KEY,
+    user_id      INTEGER NOT NULL,
+    event        TEXT NOT NULL,
+    subject      TEXT NOT NULL,
+    body         TEXT NOT NULL,
+    read_date    TIMESTAMP NULL,
+    created_date TIMESTAMP,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_id_idx ON notifications (user_id, created_date DESC);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS language TEXT NOT NULL DEFAULT 'uk';
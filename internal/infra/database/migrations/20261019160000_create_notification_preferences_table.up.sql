@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS notification_preferences
+(
+    id                SERIAL PRIMARY KEY,
+    user_id           INTEGER NOT NULL,
+    event             TEXT NOT NULL,
+    channel           TEXT NOT NULL,
+    enabled           BOOLEAN NOT NULL DEFAULT TRUE,
+    quiet_hours_start SMALLINT NULL,
+    quiet_hours_end   SMALLINT NULL,
+    created_date      TIMESTAMP,
+    updated_date      TIMESTAMP,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT notification_preferences_user_event_channel_key UNIQUE (user_id, event, channel)
+);
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS webhooks
+(
+    id           SERIAL PRIMARY KEY,
+    user_id      INTEGER NOT NULL,
+    farm_id      INTEGER NOT NULL,
+    url          TEXT NOT NULL,
+    secret       TEXT NOT NULL,
+    events       TEXT NOT NULL,
+    is_active    BOOLEAN NOT NULL DEFAULT TRUE,
+    created_date TIMESTAMP,
+    updated_date TIMESTAMP,
+    deleted_date TIMESTAMP NULL,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_farm_id FOREIGN KEY (farm_id) REFERENCES farms(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_farm_id_idx ON webhooks (farm_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id                SERIAL PRIMARY KEY,
+    webhook_id        INTEGER NOT NULL,
+    event             TEXT NOT NULL,
+    payload           TEXT NOT NULL,
+    status            TEXT NOT NULL,
+    attempts          INTEGER NOT NULL DEFAULT 0,
+    next_attempt_date TIMESTAMP NULL,
+    response_code     INTEGER NULL,
+    response_body     TEXT NULL,
+    error             TEXT NULL,
+    delivered_date    TIMESTAMP NULL,
+    created_date      TIMESTAMP,
+    updated_date      TIMESTAMP,
+    CONSTRAINT fk_webhook_id FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, created_date DESC);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (status, next_attempt_date);
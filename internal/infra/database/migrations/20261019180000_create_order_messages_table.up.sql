@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS order_messages
+(
+    id           SERIAL PRIMARY KEY,
+    order_id     INTEGER NOT NULL,
+    user_id      INTEGER NOT NULL,
+    text         TEXT NOT NULL,
+    read_date    TIMESTAMP NULL,
+    created_date TIMESTAMP,
+    CONSTRAINT fk_order_id FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS order_messages_order_id_idx ON order_messages (order_id, created_date);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS refresh_tokens;
+
+ALTER TABLE sessions
+    DROP COLUMN IF EXISTS device,
+    DROP COLUMN IF EXISTS ip,
+    DROP COLUMN IF EXISTS user_agent,
+    DROP COLUMN IF EXISTS last_seen_date,
+    DROP COLUMN IF EXISTS expires_date,
+    DROP COLUMN IF EXISTS created_date;
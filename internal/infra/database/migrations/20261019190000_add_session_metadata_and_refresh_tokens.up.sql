@@ -0,0 +1,21 @@
+ALTER TABLE sessions
+    ADD COLUMN IF NOT EXISTS device         TEXT      NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS ip             TEXT      NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS user_agent     TEXT      NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS last_seen_date TIMESTAMP NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS expires_date   TIMESTAMP NOT NULL DEFAULT now() + INTERVAL '30 days',
+    ADD COLUMN IF NOT EXISTS created_date   TIMESTAMP NOT NULL DEFAULT now();
+
+CREATE TABLE IF NOT EXISTS refresh_tokens
+(
+    id           SERIAL PRIMARY KEY,
+    user_id      INTEGER   NOT NULL,
+    session_uuid TEXT      NOT NULL,
+    token_hash   TEXT      NOT NULL UNIQUE,
+    used_date    TIMESTAMP NULL,
+    expires_date TIMESTAMP NOT NULL,
+    created_date TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id, session_uuid) REFERENCES sessions (user_id, uuid) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_session_idx ON refresh_tokens (user_id, session_uuid);
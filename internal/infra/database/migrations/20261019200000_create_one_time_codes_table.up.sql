@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS one_time_codes
+(
+    id           SERIAL PRIMARY KEY,
+    user_id      INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    purpose      VARCHAR(50) NOT NULL,
+    channel      VARCHAR(20) NOT NULL,
+    code_hash    TEXT        NOT NULL,
+    attempts     INTEGER     NOT NULL DEFAULT 0,
+    used_date    TIMESTAMP   NULL,
+    expires_date TIMESTAMP   NOT NULL,
+    created_date TIMESTAMP   NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS one_time_codes_user_purpose_idx ON one_time_codes (user_id, purpose, created_date);
@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verified_date TIMESTAMP NULL;
+
+-- Numbers entered before verification existed are trusted as they are.
+UPDATE users SET phone_verified_date = now() WHERE phone_number IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS users_verified_phone_number_idx ON users (phone_number) WHERE phone_verified_date IS NOT NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'BUYER';
+
+UPDATE users SET role = 'FARMER'
+WHERE role = 'BUYER' AND id IN (SELECT user_id FROM farms WHERE deleted_date IS NULL);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS audit_events;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS blocked_date,
+    DROP COLUMN IF EXISTS block_reason;
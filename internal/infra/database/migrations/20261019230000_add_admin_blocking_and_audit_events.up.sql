@@ -0,0 +1,16 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS blocked_date TIMESTAMP NULL,
+    ADD COLUMN IF NOT EXISTS block_reason TEXT      NULL;
+
+CREATE TABLE IF NOT EXISTS audit_events
+(
+    id           SERIAL PRIMARY KEY,
+    actor_id     INTEGER     NULL REFERENCES users (id) ON DELETE SET NULL,
+    action       VARCHAR(50) NOT NULL,
+    entity       VARCHAR(50) NOT NULL,
+    entity_id    INTEGER     NOT NULL,
+    reason       TEXT        NOT NULL DEFAULT '',
+    created_date TIMESTAMP   NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_entity_idx ON audit_events (entity, entity_id, created_date);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS audit_events_created_date_idx;
+DROP INDEX IF EXISTS audit_events_actor_idx;
+
+ALTER TABLE audit_events
+    DROP COLUMN IF EXISTS before,
+    DROP COLUMN IF EXISTS after,
+    DROP COLUMN IF EXISTS ip,
+    DROP COLUMN IF EXISTS request_id;
@@ -0,0 +1,8 @@
+ALTER TABLE audit_events
+    ADD COLUMN IF NOT EXISTS before     JSONB       NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS after      JSONB       NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS ip         VARCHAR(45) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS request_id VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS audit_events_actor_idx ON audit_events (actor_id, created_date);
+CREATE INDEX IF NOT EXISTS audit_events_created_date_idx ON audit_events (created_date);
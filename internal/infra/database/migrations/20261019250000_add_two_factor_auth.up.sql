@@ -0,0 +1,25 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS totp_secret       TEXT      NULL,
+    ADD COLUMN IF NOT EXISTS totp_enabled_date TIMESTAMP NULL,
+    ADD COLUMN IF NOT EXISTS totp_last_step    BIGINT    NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS recovery_codes
+(
+    id           SERIAL PRIMARY KEY,
+    user_id      INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash    TEXT      NOT NULL,
+    used_date    TIMESTAMP NULL,
+    created_date TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS recovery_codes_user_idx ON recovery_codes (user_id, code_hash);
+
+CREATE TABLE IF NOT EXISTS two_factor_challenges
+(
+    id           SERIAL PRIMARY KEY,
+    user_id      INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash   TEXT      NOT NULL UNIQUE,
+    attempts     INTEGER   NOT NULL DEFAULT 0,
+    expires_date TIMESTAMP NOT NULL,
+    created_date TIMESTAMP NOT NULL
+);
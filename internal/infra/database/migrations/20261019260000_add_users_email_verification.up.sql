@@ -0,0 +1,10 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP NULL,
+    ADD COLUMN IF NOT EXISTS new_email         TEXT      NULL;
+
+-- Addresses of the users registered before verification was introduced
+-- are trusted, so that they keep receiving emails and logging in.
+UPDATE users
+SET email_verified_at = created_date
+WHERE email IS NOT NULL
+  AND email <> '';
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id             SERIAL PRIMARY KEY,
+    user_id        INTEGER NOT NULL,
+    farm_id        INTEGER NOT NULL,
+    name           TEXT NOT NULL,
+    prefix         TEXT NOT NULL,
+    key_hash       TEXT NOT NULL,
+    scopes         TEXT NOT NULL,
+    expires_date   TIMESTAMP NULL,
+    last_used_date TIMESTAMP NULL,
+    revoked_date   TIMESTAMP NULL,
+    created_date   TIMESTAMP,
+    updated_date   TIMESTAMP,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_farm_id FOREIGN KEY (farm_id) REFERENCES farms(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS api_keys_key_hash_idx ON api_keys (key_hash);
+CREATE INDEX IF NOT EXISTS api_keys_farm_id_idx ON api_keys (farm_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS farm_invitations;
+DROP TABLE IF EXISTS farm_members;
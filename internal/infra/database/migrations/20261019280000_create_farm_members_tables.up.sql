@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS farm_members
+(
+    id           SERIAL PRIMARY KEY,
+    farm_id      INTEGER NOT NULL,
+    user_id      INTEGER NOT NULL,
+    role         TEXT NOT NULL,
+    created_date TIMESTAMP,
+    updated_date TIMESTAMP,
+    CONSTRAINT fk_farm_id FOREIGN KEY (farm_id) REFERENCES farms(id) ON DELETE CASCADE,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS farm_members_farm_id_user_id_idx ON farm_members (farm_id, user_id);
+CREATE INDEX IF NOT EXISTS farm_members_user_id_idx ON farm_members (user_id);
+
+INSERT INTO farm_members (farm_id, user_id, role, created_date, updated_date)
+SELECT id, user_id, 'OWNER', created_date, NOW()
+FROM farms
+WHERE deleted_date IS NULL
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS farm_invitations
+(
+    id            SERIAL PRIMARY KEY,
+    farm_id       INTEGER NOT NULL,
+    role          TEXT NOT NULL,
+    email         TEXT NULL,
+    phone_number  TEXT NULL,
+    invited_by    INTEGER NOT NULL,
+    expires_date  TIMESTAMP NOT NULL,
+    accepted_date TIMESTAMP NULL,
+    declined_date TIMESTAMP NULL,
+    revoked_date  TIMESTAMP NULL,
+    created_date  TIMESTAMP,
+    updated_date  TIMESTAMP,
+    CONSTRAINT fk_farm_id FOREIGN KEY (farm_id) REFERENCES farms(id) ON DELETE CASCADE,
+    CONSTRAINT fk_invited_by FOREIGN KEY (invited_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS farm_invitations_farm_id_idx ON farm_invitations (farm_id);
+CREATE INDEX IF NOT EXISTS farm_invitations_email_idx ON farm_invitations (email);
+CREATE INDEX IF NOT EXISTS farm_invitations_phone_number_idx ON farm_invitations (phone_number);
@@ -0,0 +1,3 @@
+-- Response bodies of webhook endpoints are not stored any more, they
+-- could reveal what a registered URL serves.
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS response_body;
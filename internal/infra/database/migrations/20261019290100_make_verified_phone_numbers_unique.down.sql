@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS users_verified_phone_number_idx;
+CREATE INDEX IF NOT EXISTS users_verified_phone_number_idx ON users (phone_number) WHERE phone_verified_date IS NOT NULL;
@@ -0,0 +1,16 @@
+-- A number verified by several accounts stays verified for the first one
+-- only, the others have to verify another number.
+UPDATE users u
+SET phone_verified_date = NULL
+WHERE u.phone_verified_date IS NOT NULL
+  AND u.deleted_date IS NULL
+  AND EXISTS (SELECT 1
+              FROM users o
+              WHERE o.phone_number = u.phone_number
+                AND o.phone_verified_date IS NOT NULL
+                AND o.deleted_date IS NULL
+                AND o.id < u.id);
+
+DROP INDEX IF EXISTS users_verified_phone_number_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS users_verified_phone_number_idx ON users (phone_number)
+    WHERE phone_verified_date IS NOT NULL AND deleted_date IS NULL;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS users_verified_email_idx;
@@ -0,0 +1,15 @@
+-- An address verified by several accounts stays verified for the first
+-- one only, the others have to verify another address.
+UPDATE users u
+SET email_verified_at = NULL
+WHERE u.email_verified_at IS NOT NULL
+  AND u.deleted_date IS NULL
+  AND EXISTS (SELECT 1
+              FROM users o
+              WHERE o.email = u.email
+                AND o.email_verified_at IS NOT NULL
+                AND o.deleted_date IS NULL
+                AND o.id < u.id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_verified_email_idx ON users (email)
+    WHERE email_verified_at IS NOT NULL AND deleted_date IS NULL;
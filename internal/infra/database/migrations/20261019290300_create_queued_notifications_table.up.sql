@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS queued_notifications
+(
+    id           SERIAL PRIMARY KEY,
+    user_id      INTEGER NOT NULL,
+    event        TEXT NOT NULL,
+    channel      TEXT NOT NULL,
+    subject      TEXT NOT NULL,
+    body         TEXT NOT NULL,
+    send_date    TIMESTAMP NOT NULL,
+    created_date TIMESTAMP,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS queued_notifications_send_date_idx ON queued_notifications (send_date);
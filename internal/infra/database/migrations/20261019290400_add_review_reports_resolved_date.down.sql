@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS review_reports_review_user_open_idx;
+
+DELETE FROM review_reports WHERE resolved_date IS NOT NULL;
+
+ALTER TABLE review_reports
+    DROP COLUMN IF EXISTS resolved_date,
+    ADD CONSTRAINT review_reports_review_user_key UNIQUE (review_id, user_id);
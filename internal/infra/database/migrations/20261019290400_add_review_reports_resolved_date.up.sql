@@ -0,0 +1,9 @@
+-- A moderator's decision resolves the reports made before it, only the
+-- reports made after it count towards hiding the review again. A user can
+-- report the review once more after the decision.
+ALTER TABLE review_reports
+    ADD COLUMN IF NOT EXISTS resolved_date TIMESTAMP NULL,
+    DROP CONSTRAINT IF EXISTS review_reports_review_user_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS review_reports_review_user_open_idx
+    ON review_reports (review_id, user_id) WHERE resolved_date IS NULL;
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"boilerplate/internal/domain"
+	"errors"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+const NotificationPreferencesTableName = "notification_preferences"
+
+type notificationPreference struct {
+	Id              uint64    `db:"id,omitempty"`
+	UserId          uint64    `db:"user_id"`
+	Event           string    `db:"event"`
+	Channel         string    `db:"channel"`
+	Enabled         bool      `db:"enabled"`
+	QuietHoursStart *uint16   `db:"quiet_hours_start"`
+	QuietHoursEnd   *uint16   `db:"quiet_hours_end"`
+	CreatedDate     time.Time `db:"created_date,omitempty"`
+	UpdatedDate     time.Time `db:"updated_date,omitempty"`
+}
+
+type NotificationPreferenceRepository interface {
+	FindAllByUserId(userId uint64) ([]domain.NotificationPreference, error)
+	FindByUserEventAndChannel(userId uint64, event domain.NotificationEvent, channel domain.NotificationChannel) (domain.NotificationPreference, error)
+	Upsert(preference domain.NotificationPreference) (domain.NotificationPreference, error)
+}
+
+type notificationPreferenceRepository struct {
+	coll db.Collection
+}
+
+func NewNotificationPreferenceRepository(dbSession db.Session) NotificationPreferenceRepository {
+	return notificationPreferenceRepository{
+		coll: dbSession.Collection(NotificationPreferencesTableName),
+	}
+}
+
+func (r notificationPreferenceRepository) FindAllByUserId(userId uint64) ([]domain.NotificationPreference, error) {
+	var data []notificationPreference
+	err := r.coll.Find(db.Cond{"user_id": userId}).OrderBy("event", "channel").All(&data)
+	if err != nil {
+		return []domain.NotificationPreference{}, err
+	}
+
+	preferences := make([]domain.NotificationPreference, len(data))
+	for i, item := range data {
+		preferences[i] = r.mapModelToDomain(item)
+	}
+	return preferences, nil
+}
+
+func (r notificationPreferenceRepository) FindByUserEventAndChannel(userId uint64, event domain.NotificationEvent, channel domain.NotificationChannel) (domain.NotificationPreference, error) {
+	var m notificationPreference
+	err := r.coll.Find(db.Cond{"user_id": userId, "event": string(event), "channel": string(channel)}).One(&m)
+	if err != nil {
+		return domain.NotificationPreference{}, err
+	}
+
+	return r.mapModelToDomain(m), nil
+}
+
+// Upsert saves the preference or replaces the existing one for the same
+// user, event and channel.
+func (r notificationPreferenceRepository) Upsert(preference domain.NotificationPreference) (domain.NotificationPreference, error) {
+	m := r.mapDomainToModel(preference)
+	m.UpdatedDate = time.Now()
+
+	existing, err := r.FindByUserEventAndChannel(preference.User.Id, preference.Event, preference.Channel)
+	if err == nil {
+		m.Id, m.CreatedDate = existing.Id, existing.CreatedDate
+		err = r.coll.Find(db.Cond{"id": m.Id}).Update(&m)
+		if err != nil {
+			return domain.NotificationPreference{}, err
+		}
+		return r.mapModelToDomain(m), nil
+	} else if !errors.Is(err, db.ErrNoMoreRows) {
+		return domain.NotificationPreference{}, err
+	}
+
+	m.CreatedDate = time.Now()
+	err = r.coll.InsertReturning(&m)
+	if err != nil {
+		return domain.NotificationPreference{}, err
+	}
+	return r.mapModelToDomain(m), nil
+}
+
+func (r notificationPreferenceRepository) mapDomainToModel(d domain.NotificationPreference) notificationPreference {
+	return notificationPreference{
+		Id:              d.Id,
+		UserId:          d.User.Id,
+		Event:           string(d.Event),
+		Channel:         string(d.Channel),
+		Enabled:         d.Enabled,
+		QuietHoursStart: d.QuietHoursStart,
+		QuietHoursEnd:   d.QuietHoursEnd,
+		CreatedDate:     d.CreatedDate,
+		UpdatedDate:     d.UpdatedDate,
+	}
+}
+
+func (r notificationPreferenceRepository) mapModelToDomain(m notificationPreference) domain.NotificationPreference {
+	return domain.NotificationPreference{
+		Id:              m.Id,
+		User:            domain.User{Id: m.UserId},
+		Event:           domain.NotificationEvent(m.Event),
+		Channel:         domain.NotificationChannel(m.Channel),
+		Enabled:         m.Enabled,
+		QuietHoursStart: m.QuietHoursStart,
+		QuietHoursEnd:   m.QuietHoursEnd,
+		CreatedDate:     m.CreatedDate,
+		UpdatedDate:     m.UpdatedDate,
+	}
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"boilerplate/internal/domain"
+	"math"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+const NotificationsTableName = "notifications"
+
+type notification struct {
+	Id          uint64     `db:"id,omitempty"`
+	UserId      uint64     `db:"user_id"`
+	Event       string     `db:"event"`
+	Subject     string     `db:"subject"`
+	Body        string     `db:"body"`
+	ReadDate    *time.Time `db:"read_date"`
+	CreatedDate time.Time  `db:"created_date,omitempty"`
+}
+
+type NotificationRepository interface {
+	Save(n domain.Notification) (domain.Notification, error)
+	FindById(id uint64) (domain.Notification, error)
+	FindAllByUserId(userId uint64, unreadOnly bool, p domain.Pagination) (domain.Notifications, error)
+	CountUnread(userId uint64) (uint64, error)
+	MarkAsRead(id uint64) error
+	MarkAllAsRead(userId uint64) error
+}
+
+type notificationRepository struct {
+	coll db.Collection
+}
+
+func NewNotificationRepository(dbSession db.Session) NotificationRepository {
+	return notificationRepository{
+		coll: dbSession.Collection(NotificationsTableName),
+	}
+}
+
+func (r notificationRepository) Save(n domain.Notification) (domain.Notification, error) {
+	m := r.mapDomainToModel(n)
+	m.CreatedDate = time.Now()
+	err := r.coll.InsertReturning(&m)
+	if err != nil {
+		return domain.Notification{}, err
+	}
+
+	return r.mapModelToDomain(m), nil
+}
+
+func (r notificationRepository) FindById(id uint64) (domain.Notification, error) {
+	var m notification
+	err := r.coll.Find(db.Cond{"id": id}).One(&m)
+	if err != nil {
+		return domain.Notification{}, err
+	}
+
+	return r.mapModelToDomain(m), nil
+}
+
+func (r notificationRepository) FindAllByUserId(userId uint64, unreadOnly bool, p domain.Pagination) (domain.Notifications, error) {
+	cond := db.Cond{"user_id": userId}
+	if unreadOnly {
+		cond["read_date"] = nil
+	}
+
+	var data []notification
+	res := r.coll.Find(cond).OrderBy("-created_date", "-id").Paginate(uint(p.CountPerPage))
+	err := res.Page(uint(p.Page)).All(&data)
+	if err != nil {
+		return domain.Notifications{}, err
+	}
+
+	notifications := domain.Notifications{Items: make([]domain.Notification, len(data))}
+	for i, item := range data {
+		notifications.Items[i] = r.mapModelToDomain(item)
+	}
+
+	totalCount, err := res.TotalEntries()
+	if err != nil {
+		return domain.Notifications{}, err
+	}
+	notifications.Total = totalCount
+	notifications.Pages = uint(math.Ceil(float64(notifications.Total) / float64(p.CountPerPage)))
+
+	notifications.Unread, err = r.CountUnread(userId)
+	if err != nil {
+		return domain.Notifications{}, err
+	}
+
+	return notifications, nil
+}
+
+func (r notificationRepository) CountUnread(userId uint64) (uint64, error) {
+	return r.coll.Find(db.Cond{"user_id": userId, "read_date": nil}).Count()
+}
+
+func (r notificationRepository) MarkAsRead(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id, "read_date": nil}).Update(map[string]interface{}{"read_date": time.Now()})
+}
+
+func (r notificationRepository) MarkAllAsRead(userId uint64) error {
+	return r.coll.Find(db.Cond{"user_id": userId, "read_date": nil}).Update(map[string]interface{}{"read_date": time.Now()})
+}
+
+func (r notificationRepository) mapDomainToModel(d domain.Notification) notification {
+	return notification{
+		Id:          d.Id,
+		UserId:      d.User.Id,
+		Event:       string(d.Event),
+		Subject:     d.Subject,
+		Body:        d.Body,
+		ReadDate:    d.ReadDate,
+		CreatedDate: d.CreatedDate,
+	}
+}
+
+func (r notificationRepository) mapModelToDomain(m notification) domain.Notification {
+	return domain.Notification{
+		Id:          m.Id,
+		User:        domain.User{Id: m.UserId},
+		Event:       domain.NotificationEvent(m.Event),
+		Subject:     m.Subject,
+		Body:        m.Body,
+		ReadDate:    m.ReadDate,
+		CreatedDate: m.CreatedDate,
+	}
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"boilerplate/internal/domain"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+const OfferAlertsTableName = "offer_alerts"
+
+type offerAlert struct {
+	Id           uint64     `db:"id,omitempty"`
+	UserId       uint64     `db:"user_id"`
+	OfferId      uint64     `db:"offer_id"`
+	Type         string     `db:"type"`
+	TargetPrice  *float64   `db:"target_price"`
+	Channel      string     `db:"channel"`
+	Notified     bool       `db:"notified"`
+	NotifiedDate *time.Time `db:"notified_date"`
+	CreatedDate  time.Time  `db:"created_date,omitempty"`
+	UpdatedDate  time.Time  `db:"updated_date,omitempty"`
+}
+
+type OfferAlertRepository interface {
+	Save(alert domain.OfferAlert) (domain.OfferAlert, error)
+	Update(alert domain.OfferAlert) (domain.OfferAlert, error)
+	FindById(id uint64) (domain.OfferAlert, error)
+	FindByUserOfferAndType(userId uint64, offerId uint64, alertType domain.OfferAlertType) (domain.OfferAlert, error)
+	FindAllByUserId(userId uint64) ([]domain.OfferAlert, error)
+	FindAllByOfferId(offerId uint64, alertType domain.OfferAlertType) ([]domain.OfferAlert, error)
+	Delete(id uint64) error
+}
+
+type offerAlertRepository struct {
+	coll db.Collection
+}
+
+func NewOfferAlertRepository(dbSession db.Session) OfferAlertRepository {
+	return offerAlertRepository{
+		coll: dbSession.Collection(OfferAlertsTableName),
+	}
+}
+
+func (r offerAlertRepository) Save(alert domain.OfferAlert) (domain.OfferAlert, error) {
+	a := r.mapDomainToModel(alert)
+	a.CreatedDate, a.UpdatedDate = time.Now(), time.Now()
+	err := r.coll.InsertReturning(&a)
+	if err != nil {
+		return domain.OfferAlert{}, err
+	}
+
+	return r.mapModelToDomain(a), nil
+}
+
+func (r offerAlertRepository) Update(alert domain.OfferAlert) (domain.OfferAlert, error) {
+	a := r.mapDomainToModel(alert)
+	a.UpdatedDate = time.Now()
+	err := r.coll.Find(db.Cond{"id": a.Id}).Update(&a)
+	if err != nil {
+		return domain.OfferAlert{}, err
+	}
+
+	return r.mapModelToDomain(a), nil
+}
+
+func (r offerAlertRepository) FindById(id uint64) (domain.OfferAlert, error) {
+	var a offerAlert
+	err := r.coll.Find(db.Cond{"id": id}).One(&a)
+	if err != nil {
+		return domain.OfferAlert{}, err
+	}
+
+	return r.mapModelToDomain(a), nil
+}
+
+func (r offerAlertRepository) FindByUserOfferAndType(userId uint64, offerId uint64, alertType domain.OfferAlertType) (domain.OfferAlert, error) {
+	var a offerAlert
+	err := r.coll.Find(db.Cond{"user_id": userId, "offer_id": offerId, "type": string(alertType)}).One(&a)
+	if err != nil {
+		return domain.OfferAlert{}, err
+	}
+
+	return r.mapModelToDomain(a), nil
+}
+
+func (r offerAlertRepository) FindAllByUserId(userId uint64) ([]domain.OfferAlert, error) {
+	return r.findAll(db.Cond{"user_id": userId})
+}
+
+func (r offerAlertRepository) FindAllByOfferId(offerId uint64, alertType domain.OfferAlertType) ([]domain.OfferAlert, error) {
+	return r.findAll(db.Cond{"offer_id": offerId, "type": string(alertType)})
+}
+
+func (r offerAlertRepository) Delete(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id}).Delete()
+}
+
+func (r offerAlertRepository) findAll(cond db.Cond) ([]domain.OfferAlert, error) {
+	var data []offerAlert
+	err := r.coll.Find(cond).OrderBy("-created_date").All(&data)
+	if err != nil {
+		return []domain.OfferAlert{}, err
+	}
+
+	alerts := make([]domain.OfferAlert, len(data))
+	for i, item := range data {
+		alerts[i] = r.mapModelToDomain(item)
+	}
+	return alerts, nil
+}
+
+func (r offerAlertRepository) mapDomainToModel(d domain.OfferAlert) offerAlert {
+	return offerAlert{
+		Id:           d.Id,
+		UserId:       d.User.Id,
+		OfferId:      d.OfferId,
+		Type:         string(d.Type),
+		TargetPrice:  d.TargetPrice,
+		Channel:      string(d.Channel),
+		Notified:     d.Notified,
+		NotifiedDate: d.NotifiedDate,
+		CreatedDate:  d.CreatedDate,
+		UpdatedDate:  d.UpdatedDate,
+	}
+}
+
+func (r offerAlertRepository) mapModelToDomain(m offerAlert) domain.OfferAlert {
+	return domain.OfferAlert{
+		Id:           m.Id,
+		User:         domain.User{Id: m.UserId},
+		OfferId:      m.OfferId,
+		Type:         domain.OfferAlertType(m.Type),
+		TargetPrice:  m.TargetPrice,
+		Channel:      domain.NotificationChannel(m.Channel),
+		Notified:     m.Notified,
+		NotifiedDate: m.NotifiedDate,
+		CreatedDate:  m.CreatedDate,
+		UpdatedDate:  m.UpdatedDate,
+	}
+}
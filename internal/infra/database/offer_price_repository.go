@@ -0,0 +1,195 @@
+package database
+
+import (
+	"boilerplate/internal/domain"
+	"math"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+const (
+	OfferPricesTableName         = "offer_prices"
+	OfferPriceSchedulesTableName = "offer_price_schedules"
+)
+
+type offerPrice struct {
+	Id          uint64    `db:"id,omitempty"`
+	OfferId     uint64    `db:"offer_id"`
+	Price       float64   `db:"price"`
+	CreatedDate time.Time `db:"created_date,omitempty"`
+}
+
+type offerPriceSchedule struct {
+	Id            uint64     `db:"id,omitempty"`
+	OfferId       uint64     `db:"offer_id"`
+	Price         float64    `db:"price"`
+	PreviousPrice *float64   `db:"previous_price"`
+	StartDate     time.Time  `db:"start_date"`
+	EndDate       *time.Time `db:"end_date"`
+	Status        string     `db:"status"`
+	CreatedDate   time.Time  `db:"created_date,omitempty"`
+	UpdatedDate   time.Time  `db:"updated_date,omitempty"`
+}
+
+type OfferPriceRepository interface {
+	Save(price domain.OfferPrice) (domain.OfferPrice, error)
+	FindAllByOfferId(offerId uint64, p domain.Pagination) (domain.OfferPrices, error)
+	SaveSchedule(schedule domain.OfferPriceSchedule) (domain.OfferPriceSchedule, error)
+	UpdateSchedule(schedule domain.OfferPriceSchedule) (domain.OfferPriceSchedule, error)
+	FindScheduleById(id uint64) (domain.OfferPriceSchedule, error)
+	FindSchedulesByOfferId(offerId uint64) ([]domain.OfferPriceSchedule, error)
+	FindSchedulesToStart(now time.Time) ([]domain.OfferPriceSchedule, error)
+	FindSchedulesToFinish(now time.Time) ([]domain.OfferPriceSchedule, error)
+}
+
+type offerPriceRepository struct {
+	coll         db.Collection
+	scheduleColl db.Collection
+}
+
+func NewOfferPriceRepository(dbSession db.Session) OfferPriceRepository {
+	return offerPriceRepository{
+		coll:         dbSession.Collection(OfferPricesTableName),
+		scheduleColl: dbSession.Collection(OfferPriceSchedulesTableName),
+	}
+}
+
+func (r offerPriceRepository) Save(price domain.OfferPrice) (domain.OfferPrice, error) {
+	p := r.mapDomainToModel(price)
+	p.CreatedDate = time.Now()
+	err := r.coll.InsertReturning(&p)
+	if err != nil {
+		return domain.OfferPrice{}, err
+	}
+
+	return r.mapModelToDomain(p), nil
+}
+
+func (r offerPriceRepository) FindAllByOfferId(offerId uint64, p domain.Pagination) (domain.OfferPrices, error) {
+	var data []offerPrice
+	query := r.coll.Find(db.Cond{"offer_id": offerId}).OrderBy("-created_date", "-id")
+	res := query.Paginate(uint(p.CountPerPage))
+	err := res.Page(uint(p.Page)).All(&data)
+	if err != nil {
+		return domain.OfferPrices{}, err
+	}
+
+	prices := domain.OfferPrices{Items: make([]domain.OfferPrice, len(data))}
+	for i, item := range data {
+		prices.Items[i] = r.mapModelToDomain(item)
+	}
+
+	totalCount, err := res.TotalEntries()
+	if err != nil {
+		return domain.OfferPrices{}, err
+	}
+	prices.Total = totalCount
+	prices.Pages = uint(math.Ceil(float64(prices.Total) / float64(p.CountPerPage)))
+
+	return prices, nil
+}
+
+func (r offerPriceRepository) SaveSchedule(schedule domain.OfferPriceSchedule) (domain.OfferPriceSchedule, error) {
+	s := r.mapScheduleDomainToModel(schedule)
+	s.CreatedDate, s.UpdatedDate = time.Now(), time.Now()
+	err := r.scheduleColl.InsertReturning(&s)
+	if err != nil {
+		return domain.OfferPriceSchedule{}, err
+	}
+
+	return r.mapScheduleModelToDomain(s), nil
+}
+
+func (r offerPriceRepository) UpdateSchedule(schedule domain.OfferPriceSchedule) (domain.OfferPriceSchedule, error) {
+	s := r.mapScheduleDomainToModel(schedule)
+	s.UpdatedDate = time.Now()
+	err := r.scheduleColl.Find(db.Cond{"id": s.Id}).Update(&s)
+	if err != nil {
+		return domain.OfferPriceSchedule{}, err
+	}
+
+	return r.mapScheduleModelToDomain(s), nil
+}
+
+func (r offerPriceRepository) FindScheduleById(id uint64) (domain.OfferPriceSchedule, error) {
+	var s offerPriceSchedule
+	err := r.scheduleColl.Find(db.Cond{"id": id}).One(&s)
+	if err != nil {
+		return domain.OfferPriceSchedule{}, err
+	}
+
+	return r.mapScheduleModelToDomain(s), nil
+}
+
+func (r offerPriceRepository) FindSchedulesByOfferId(offerId uint64) ([]domain.OfferPriceSchedule, error) {
+	return r.findSchedules(db.Cond{"offer_id": offerId})
+}
+
+func (r offerPriceRepository) FindSchedulesToStart(now time.Time) ([]domain.OfferPriceSchedule, error) {
+	return r.findSchedules(db.Cond{"status": string(domain.PRICE_SCHEDULE_PENDING), "start_date <=": now})
+}
+
+func (r offerPriceRepository) FindSchedulesToFinish(now time.Time) ([]domain.OfferPriceSchedule, error) {
+	return r.findSchedules(db.Cond{"status": string(domain.PRICE_SCHEDULE_ACTIVE), "end_date <=": now})
+}
+
+func (r offerPriceRepository) findSchedules(cond db.Cond) ([]domain.OfferPriceSchedule, error) {
+	var data []offerPriceSchedule
+	err := r.scheduleColl.Find(cond).OrderBy("start_date").All(&data)
+	if err != nil {
+		return []domain.OfferPriceSchedule{}, err
+	}
+
+	schedules := make([]domain.OfferPriceSchedule, len(data))
+	for i, item := range data {
+		schedules[i] = r.mapScheduleModelToDomain(item)
+	}
+	return schedules, nil
+}
+
+func (r offerPriceRepository) mapDomainToModel(d domain.OfferPrice) offerPrice {
+	return offerPrice{
+		Id:          d.Id,
+		OfferId:     d.OfferId,
+		Price:       d.Price,
+		CreatedDate: d.CreatedDate,
+	}
+}
+
+func (r offerPriceRepository) mapModelToDomain(m offerPrice) domain.OfferPrice {
+	return domain.OfferPrice{
+		Id:          m.Id,
+		OfferId:     m.OfferId,
+		Price:       m.Price,
+		CreatedDate: m.CreatedDate,
+	}
+}
+
+func (r offerPriceRepository) mapScheduleDomainToModel(d domain.OfferPriceSchedule) offerPriceSchedule {
+	return offerPriceSchedule{
+		Id:            d.Id,
+		OfferId:       d.OfferId,
+		Price:         d.Price,
+		PreviousPrice: d.PreviousPrice,
+		StartDate:     d.StartDate,
+		EndDate:       d.EndDate,
+		Status:        string(d.Status),
+		CreatedDate:   d.CreatedDate,
+		UpdatedDate:   d.UpdatedDate,
+	}
+}
+
+func (r offerPriceRepository) mapScheduleModelToDomain(m offerPriceSchedule) domain.OfferPriceSchedule {
+	return domain.OfferPriceSchedule{
+		Id:            m.Id,
+		OfferId:       m.OfferId,
+		Price:         m.Price,
+		PreviousPrice: m.PreviousPrice,
+		StartDate:     m.StartDate,
+		EndDate:       m.EndDate,
+		Status:        domain.OfferPriceScheduleStatus(m.Status),
+		CreatedDate:   m.CreatedDate,
+		UpdatedDate:   m.UpdatedDate,
+	}
+}
@@ -11,20 +11,22 @@ import (
 const OffersTableName = "offers"
 
 type offer struct {
-	Id          uint64     `db:"id,omitempty"`
-	Title       string     `db:"title"`
-	Description string     `db:"description"`
-	Category    string     `db:"category"`
-	Price       float64    `db:"price"`
-	Unit        string     `db:"unit"`
-	Stock       uint       `db:"stock"`
-	Cover       string     `db:"cover"`
-	Status      bool       `db:"status"`
-	FarmId      uint64     `db:"farm_id"`
-	UserId      uint64     `db:"user_id"`
-	CreatedDate time.Time  `db:"created_date,omitempty"`
-	UpdatedDate time.Time  `db:"updated_date,omitempty"`
-	DeletedDate *time.Time `db:"deleted_date,omitempty"`
+	Id           uint64     `db:"id,omitempty"`
+	Title        string     `db:"title"`
+	Description  string     `db:"description"`
+	Category     string     `db:"category"`
+	Price        float64    `db:"price"`
+	Unit         string     `db:"unit"`
+	Stock        uint       `db:"stock"`
+	Cover        string     `db:"cover"`
+	Status       bool       `db:"status"`
+	FarmId       uint64     `db:"farm_id"`
+	UserId       uint64     `db:"user_id"`
+	Rating       float64    `db:"rating,omitempty"`
+	ReviewsCount uint64     `db:"reviews_count,omitempty"`
+	CreatedDate  time.Time  `db:"created_date,omitempty"`
+	UpdatedDate  time.Time  `db:"updated_date,omitempty"`
+	DeletedDate  *time.Time `db:"deleted_date,omitempty"`
 }
 
 type offerWithUser struct {
@@ -39,10 +41,12 @@ type OfferRepository interface {
 	Save(offer domain.Offer) (domain.Offer, error)
 	FindById(id uint64) (domain.Offer, error)
 	Update(offer domain.Offer) (domain.Offer, error)
+	UpdatePrice(id uint64, price float64) (domain.Offer, error)
 	FindAll(user domain.User, pag domain.Pagination) (domain.Offers, error)
 	FindAllByFarmId(farmId uint64, p domain.Pagination) (domain.Offers, error)
 	FindOnlyOffersByFarmId(farmId uint64) ([]domain.Offer, error)
 	FindByCategory(category string) ([]domain.Offer, error)
+	FindAllByFilter(filter domain.OfferFilter, p domain.Pagination) (domain.Offers, error)
 	Delete(id uint64) error
 }
 
@@ -109,6 +113,18 @@ func (or offerRepository) Update(offer domain.Offer) (domain.Offer, error) {
 	return offer, nil
 }
 
+// UpdatePrice writes the price only, so a scheduled price change doesn't
+// bring back the rest of an offer edited in the meantime.
+func (r offerRepository) UpdatePrice(id uint64, price float64) (domain.Offer, error) {
+	err := r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).
+		Update(map[string]interface{}{"price": price, "updated_date": time.Now()})
+	if err != nil {
+		return domain.Offer{}, err
+	}
+
+	return r.FindById(id)
+}
+
 func (r offerRepository) Delete(id uint64) error {
 	return r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).Update(map[string]interface{}{"deleted_date": time.Now()})
 }
@@ -177,6 +193,48 @@ func (r offerRepository) FindAll(user domain.User, p domain.Pagination) (domain.
 	return offers, nil
 }
 
+func (r offerRepository) FindAllByFilter(filter domain.OfferFilter, p domain.Pagination) (domain.Offers, error) {
+	cond := db.And(db.Cond{"ofr.deleted_date": nil})
+	if filter.Search != "" {
+		search := "%" + filter.Search + "%"
+		cond = cond.And(db.Or(
+			db.Cond{"ofr.title ILIKE": search},
+			db.Cond{"ofr.description ILIKE": search},
+		))
+	}
+	if filter.FarmId != 0 {
+		cond = cond.And(db.Cond{"ofr.farm_id": filter.FarmId})
+	}
+	if filter.UserId != 0 {
+		cond = cond.And(db.Cond{"ofr.user_id": filter.UserId})
+	}
+	if filter.Status != nil {
+		cond = cond.And(db.Cond{"ofr.status": *filter.Status})
+	}
+
+	var data []offerWithUser
+	query := r.coll.Session().SQL().Select("ofr.*", "u.id AS id_user", "u.name AS user_name", "u.email AS user_email", "u.phone_number AS user_phone_number").
+		From("offers AS ofr").
+		Join("users AS u").On("u.id = ofr.user_id").
+		Where(cond).
+		OrderBy("ofr.id")
+	res := query.Paginate(uint(p.CountPerPage))
+	err := res.Page(uint(p.Page)).All(&data)
+	if err != nil {
+		return domain.Offers{}, err
+	}
+
+	offers := r.mapModelsToDomainsWithFarm(data)
+	totalCount, err := res.TotalEntries()
+	if err != nil {
+		return domain.Offers{}, err
+	}
+	offers.Total = totalCount
+	offers.Pages = uint(math.Ceil(float64(offers.Total) / float64(p.CountPerPage)))
+
+	return offers, nil
+}
+
 func (r offerRepository) FindByCategory(category string) ([]domain.Offer, error) {
 	var data []offer
 	query := r.coll.Find(db.Cond{"deleted_date": nil})
@@ -259,6 +317,8 @@ func (r offerRepository) mapModelToDomain(o offer) domain.Offer {
 		Status:           o.Status,
 		User:             domain.User{Id: o.UserId},
 		Farm:             domain.Farm{Id: o.FarmId},
+		Rating:           o.Rating,
+		ReviewsCount:     o.ReviewsCount,
 		CreatedDate:      o.CreatedDate,
 		UpdatedDate:      o.UpdatedDate,
 		DeletedDate:      o.DeletedDate,
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"boilerplate/internal/domain"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+const OneTimeCodesTableName = "one_time_codes"
+
+type oneTimeCode struct {
+	Id          uint64     `db:"id,omitempty"`
+	UserId      uint64     `db:"user_id"`
+	Purpose     string     `db:"purpose"`
+	Channel     string     `db:"channel"`
+	CodeHash    string     `db:"code_hash"`
+	Attempts    uint32     `db:"attempts"`
+	UsedDate    *time.Time `db:"used_date"`
+	ExpiresDate time.Time  `db:"expires_date"`
+	CreatedDate time.Time  `db:"created_date"`
+}
+
+type OneTimeCodeRepository interface {
+	Save(c domain.OneTimeCode) (domain.OneTimeCode, error)
+	FindLast(userId uint64, purpose domain.OneTimeCodePurpose) (domain.OneTimeCode, error)
+	IncrementAttempts(id uint64) error
+	MarkAsUsed(id uint64) (bool, error)
+	ExpireAll(userId uint64, purpose domain.OneTimeCodePurpose) error
+}
+
+type oneTimeCodeRepository struct {
+	coll db.Collection
+}
+
+func NewOneTimeCodeRepository(dbSession db.Session) OneTimeCodeRepository {
+	return oneTimeCodeRepository{
+		coll: dbSession.Collection(OneTimeCodesTableName),
+	}
+}
+
+func (r oneTimeCodeRepository) Save(c domain.OneTimeCode) (domain.OneTimeCode, error) {
+	m := r.mapDomainToModel(c)
+	m.CreatedDate = time.Now()
+	err := r.coll.InsertReturning(&m)
+	if err != nil {
+		return domain.OneTimeCode{}, err
+	}
+
+	return r.mapModelToDomain(m), nil
+}
+
+func (r oneTimeCodeRepository) FindLast(userId uint64, purpose domain.OneTimeCodePurpose) (domain.OneTimeCode, error) {
+	var m oneTimeCode
+	err := r.coll.
+		Find(db.Cond{"user_id": userId, "purpose": purpose}).
+		OrderBy("-created_date").
+		One(&m)
+	if err != nil {
+		return domain.OneTimeCode{}, err
+	}
+
+	return r.mapModelToDomain(m), nil
+}
+
+func (r oneTimeCodeRepository) IncrementAttempts(id uint64) error {
+	_, err := r.coll.Session().SQL().
+		Update(OneTimeCodesTableName).
+		Set("attempts = attempts + 1").
+		Where(db.Cond{"id": id}).
+		Exec()
+	return err
+}
+
+// MarkAsUsed reports whether the code was still unused, so that one code
+// can't be spent twice by concurrent requests.
+func (r oneTimeCodeRepository) MarkAsUsed(id uint64) (bool, error) {
+	res, err := r.coll.Session().SQL().
+		Update(OneTimeCodesTableName).
+		Set("used_date", time.Now()).
+		Where(db.Cond{"id": id, "used_date IS": nil}).
+		Exec()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r oneTimeCodeRepository) ExpireAll(userId uint64, purpose domain.OneTimeCodePurpose) error {
+	return r.coll.
+		Find(db.Cond{"user_id": userId, "purpose": purpose, "used_date IS": nil}).
+		Update(map[string]interface{}{"expires_date": time.Now()})
+}
+
+func (r oneTimeCodeRepository) mapDomainToModel(d domain.OneTimeCode) oneTimeCode {
+	return oneTimeCode{
+		Id:          d.Id,
+		UserId:      d.UserId,
+		Purpose:     string(d.Purpose),
+		Channel:     string(d.Channel),
+		CodeHash:    d.CodeHash,
+		Attempts:    d.Attempts,
+		UsedDate:    d.UsedDate,
+		ExpiresDate: d.ExpiresDate,
+		CreatedDate: d.CreatedDate,
+	}
+}
+
+func (r oneTimeCodeRepository) mapModelToDomain(m oneTimeCode) domain.OneTimeCode {
+	return domain.OneTimeCode{
+		Id:          m.Id,
+		UserId:      m.UserId,
+		Purpose:     domain.OneTimeCodePurpose(m.Purpose),
+		Channel:     domain.NotificationChannel(m.Channel),
+		CodeHash:    m.CodeHash,
+		Attempts:    m.Attempts,
+		UsedDate:    m.UsedDate,
+		ExpiresDate: m.ExpiresDate,
+		CreatedDate: m.CreatedDate,
+	}
+}
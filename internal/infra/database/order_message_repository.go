@@ -0,0 +1,161 @@
+package database
+
+import (
+	"boilerplate/internal/domain"
+	"math"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+const OrderMessagesTableName = "order_messages"
+
+type orderMessage struct {
+	Id          uint64     `db:"id,omitempty"`
+	OrderId     uint64     `db:"order_id"`
+	UserId      uint64     `db:"user_id"`
+	Text        string     `db:"text"`
+	ReadDate    *time.Time `db:"read_date"`
+	CreatedDate time.Time  `db:"created_date,omitempty"`
+}
+
+type orderMessageWithUser struct {
+	OrderMessage orderMessage
+	UserName     string `db:"user_name"`
+}
+
+type OrderMessageRepository interface {
+	Save(message domain.OrderMessage) (domain.OrderMessage, error)
+	FindById(id uint64) (domain.OrderMessage, error)
+	FindAllByOrderId(orderId uint64, p domain.Pagination) (domain.OrderMessages, error)
+	MarkAsRead(orderId uint64, readerId uint64) error
+	CountUnread(orderIds []uint64, readerId uint64) (map[uint64]uint64, error)
+}
+
+type orderMessageRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewOrderMessageRepository(dbSession db.Session) OrderMessageRepository {
+	return orderMessageRepository{
+		coll: dbSession.Collection(OrderMessagesTableName),
+		sess: dbSession,
+	}
+}
+
+func (r orderMessageRepository) Save(message domain.OrderMessage) (domain.OrderMessage, error) {
+	m := r.mapDomainToModel(message)
+	m.CreatedDate = time.Now()
+	err := r.coll.InsertReturning(&m)
+	if err != nil {
+		return domain.OrderMessage{}, err
+	}
+
+	return r.FindById(m.Id)
+}
+
+func (r orderMessageRepository) FindById(id uint64) (domain.OrderMessage, error) {
+	var m orderMessageWithUser
+	err := r.selectWithUser().Where(db.Cond{"order_messages.id": id}).One(&m)
+	if err != nil {
+		return domain.OrderMessage{}, err
+	}
+
+	return r.mapModelToDomain(m), nil
+}
+
+// FindAllByOrderId returns the thread of the order, the newest messages first.
+func (r orderMessageRepository) FindAllByOrderId(orderId uint64, p domain.Pagination) (domain.OrderMessages, error) {
+	var data []orderMessageWithUser
+	res := r.selectWithUser().
+		Where(db.Cond{"order_messages.order_id": orderId}).
+		OrderBy("-order_messages.created_date", "-order_messages.id").
+		Paginate(uint(p.CountPerPage))
+	err := res.Page(uint(p.Page)).All(&data)
+	if err != nil {
+		return domain.OrderMessages{}, err
+	}
+
+	messages := domain.OrderMessages{Items: make([]domain.OrderMessage, len(data))}
+	for i, item := range data {
+		messages.Items[i] = r.mapModelToDomain(item)
+	}
+
+	totalCount, err := res.TotalEntries()
+	if err != nil {
+		return domain.OrderMessages{}, err
+	}
+	messages.Total = totalCount
+	messages.Pages = uint(math.Ceil(float64(messages.Total) / float64(p.CountPerPage)))
+
+	return messages, nil
+}
+
+// MarkAsRead marks the messages of the other party in the order as read.
+func (r orderMessageRepository) MarkAsRead(orderId uint64, readerId uint64) error {
+	return r.coll.Find(db.Cond{"order_id": orderId, "user_id !=": readerId, "read_date": nil}).
+		Update(map[string]interface{}{"read_date": time.Now()})
+}
+
+// CountUnread returns the number of messages the reader has not read yet
+// for each of the orders. Orders without unread messages are omitted.
+func (r orderMessageRepository) CountUnread(orderIds []uint64, readerId uint64) (map[uint64]uint64, error) {
+	result := make(map[uint64]uint64)
+	if len(orderIds) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		OrderId uint64 `db:"order_id"`
+		Count   uint64 `db:"count"`
+	}
+	err := r.sess.SQL().Select("order_id", db.Raw("COUNT(*) AS count")).
+		From(OrderMessagesTableName).
+		Where(db.Cond{"order_id IN": orderIds, "user_id !=": readerId, "read_date": nil}).
+		GroupBy("order_id").
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.OrderId] = row.Count
+	}
+	return result, nil
+}
+
+func (r orderMessageRepository) selectWithUser() db.Selector {
+	return r.sess.SQL().Select("order_messages.*", "u.name AS user_name").
+		From(OrderMessagesTableName).
+		Join("users AS u").On("u.id = order_messages.user_id")
+}
+
+func (r orderMessageRepository) mapDomainToModel(d domain.OrderMessage) orderMessage {
+	return orderMessage{
+		Id:          d.Id,
+		OrderId:     d.OrderId,
+		UserId:      d.User.Id,
+		Text:        d.Text,
+		ReadDate:    d.ReadDate,
+		CreatedDate: d.CreatedDate,
+	}
+}
+
+func (r orderMessageRepository) mapModelToDomain(m orderMessageWithUser) domain.OrderMessage {
+	var images []image
+	err := r.sess.SQL().Select("*").From("images").Where("entity = ? AND entity_id = ?", "order_messages", m.OrderMessage.Id).All(&images)
+	if err != nil {
+		return domain.OrderMessage{}
+	}
+
+	return domain.OrderMessage{
+		Id:          m.OrderMessage.Id,
+		OrderId:     m.OrderMessage.OrderId,
+		User:        domain.User{Id: m.OrderMessage.UserId, Name: m.UserName},
+		Text:        m.OrderMessage.Text,
+		Images:      mapImageModelToDomainList(images),
+		ReadDate:    m.OrderMessage.ReadDate,
+		CreatedDate: m.OrderMessage.CreatedDate,
+	}
+}
@@ -18,6 +18,7 @@ type order struct {
 	Address          *string    `db:"address"`
 	ProductsPrice    float64    `db:"products_price"`
 	ShippingPrice    float64    `db:"shipping_price"`
+	DiscountPrice    float64    `db:"discount_price"`
 	TotalPrice       float64    `db:"total_price"`
 	Status           string     `db:"status"`
 	PostOffice       *string    `db:"post_office"`
@@ -35,8 +36,10 @@ type OrderRepository interface {
 	Update(order domain.Order) (domain.Order, error)
 	FindAllByUserId(userId uint64, p domain.Pagination) (domain.Orders, error)
 	Delete(order domain.Order) error
+	AnonymizeByUserId(userId uint64) error
 	Recalculate(orderId uint64) error
 	GetOrdersByFarmUserId(farmUserId uint64, p domain.Pagination) (domain.Orders, error)
+	FindAllByFilter(filter domain.OrderFilter, p domain.Pagination) (domain.Orders, error)
 	SplitOrderByFarms(order domain.Order) (map[uint64]domain.Order, error)
 	SubmitSplitedOrder(order domain.Order, farmId uint64) (domain.Order, error)
 	DeleteSplitedOrder(order domain.Order, farmId uint64) error
@@ -46,12 +49,14 @@ type OrderRepository interface {
 
 type orderRepository struct {
 	orderItemRepo OrderItemRepository
+	promotionRepo PromotionRepository
 	coll          db.Collection
 }
 
-func NewOrderRepository(dbSession db.Session, orderItemRepo OrderItemRepository) OrderRepository {
+func NewOrderRepository(dbSession db.Session, orderItemRepo OrderItemRepository, promotionRepo PromotionRepository) OrderRepository {
 	return orderRepository{
 		orderItemRepo: orderItemRepo,
+		promotionRepo: promotionRepo,
 		coll:          dbSession.Collection(OrdersTableName),
 	}
 }
@@ -133,7 +138,27 @@ func (r orderRepository) SplitOrderByFarms(order domain.Order) (map[uint64]domai
 	return splitedOrders, nil
 }
 
+// SubmitSplitedOrder runs in a transaction, the promotions of the order
+// stay locked from the usage check in moveDiscounts until the commit.
 func (r orderRepository) SubmitSplitedOrder(order domain.Order, farmId uint64) (domain.Order, error) {
+	var submitted domain.Order
+	err := r.coll.Session().Tx(func(tx db.Session) error {
+		offerRepo := NewOfferRepository(tx)
+		orderItemRepo := NewOrderItemRepository(tx, offerRepo, NewFarmRepository(tx, offerRepo))
+		txRepo := NewOrderRepository(tx, orderItemRepo, NewPromotionRepository(tx)).(orderRepository)
+
+		var err error
+		submitted, err = txRepo.submitSplitedOrder(order, farmId)
+		return err
+	})
+	if err != nil {
+		return domain.Order{}, err
+	}
+
+	return submitted, nil
+}
+
+func (r orderRepository) submitSplitedOrder(order domain.Order, farmId uint64) (domain.Order, error) {
 	splitedOrders, err := r.SplitOrderByFarms(order)
 	if err != nil {
 		return domain.Order{}, err
@@ -161,6 +186,11 @@ func (r orderRepository) SubmitSplitedOrder(order domain.Order, farmId uint64) (
 		}
 	}
 
+	err = r.moveDiscounts(order.Id, submittedSplitedOrder, splitedOrder.OrderItems)
+	if err != nil {
+		return domain.Order{}, err
+	}
+
 	err = r.Recalculate(submittedSplitedOrder.Id)
 	if err != nil {
 		return domain.Order{}, err
@@ -171,6 +201,11 @@ func (r orderRepository) SubmitSplitedOrder(order domain.Order, farmId uint64) (
 		return domain.Order{}, err
 	}
 
+	submittedSplitedOrder, err = r.FindById(submittedSplitedOrder.Id)
+	if err != nil {
+		return domain.Order{}, err
+	}
+
 	submittedSplitedOrder.OrderItems, err = r.orderItemRepo.FindAllWithoutPagination(submittedSplitedOrder.Id)
 	if err != nil {
 		return domain.Order{}, err
@@ -280,6 +315,18 @@ func (r orderRepository) Delete(order domain.Order) error {
 	return r.coll.Find(db.Cond{"id": order.Id, "deleted_date": nil}).Update(map[string]interface{}{"deleted_date": time.Now()})
 }
 
+// AnonymizeByUserId drops the delivery details and comments of the user's
+// orders. Prices, items and statuses stay for the accounting.
+func (r orderRepository) AnonymizeByUserId(userId uint64) error {
+	return r.coll.Find(db.Cond{"user_id": userId}).Update(map[string]interface{}{
+		"comment":          "",
+		"address":          nil,
+		"post_office":      nil,
+		"post_office_city": nil,
+		"updated_date":     time.Now(),
+	})
+}
+
 func (r orderRepository) Recalculate(orderId uint64) error {
 	var order order
 	result := r.coll.Find(db.Cond{"id": orderId, "deleted_date": nil})
@@ -291,8 +338,13 @@ func (r orderRepository) Recalculate(orderId uint64) error {
 	if err != nil {
 		return err
 	}
+	discountPrice, err := r.recalculateDiscounts(orderId, order.ShippingPrice)
+	if err != nil {
+		return err
+	}
 	order.ProductsPrice = totalPrice
-	order.TotalPrice = math.Round((totalPrice+order.ShippingPrice)*100) / 100
+	order.DiscountPrice = math.Round(math.Min(discountPrice, totalPrice+order.ShippingPrice)*100) / 100
+	order.TotalPrice = math.Round((totalPrice+order.ShippingPrice-order.DiscountPrice)*100) / 100
 	err = result.Update(&order)
 	if err != nil {
 		return err
@@ -301,6 +353,98 @@ func (r orderRepository) Recalculate(orderId uint64) error {
 	return nil
 }
 
+func (r orderRepository) recalculateDiscounts(orderId uint64, shippingPrice float64) (float64, error) {
+	discounts, err := r.promotionRepo.FindDiscountsByOrderId(orderId)
+	if err != nil || len(discounts) == 0 {
+		return 0, err
+	}
+
+	orderItems, err := r.orderItemRepo.FindAllWithoutPagination(orderId)
+	if err != nil {
+		return 0, err
+	}
+
+	var total float64
+	for _, discount := range discounts {
+		amount := discount.Promotion.DiscountFor(orderItems, shippingPrice)
+		if amount != discount.Amount {
+			discount.Amount = amount
+			_, err = r.promotionRepo.UpdateDiscount(discount)
+			if err != nil {
+				return 0, err
+			}
+		}
+		total += amount
+	}
+
+	return total, nil
+}
+
+// moveDiscounts transfers the discount lines of a draft order to the farm order
+// split from it. A discount follows the first submitted farm order that contains
+// items eligible for its promotion, so every coupon is redeemed only once.
+// The promotion is checked again under a lock, it could have ended or run
+// out since it was applied to the draft.
+func (r orderRepository) moveDiscounts(fromOrderId uint64, to domain.Order, orderItems []domain.OrderItem) error {
+	discounts, err := r.promotionRepo.FindDiscountsByOrderId(fromOrderId)
+	if err != nil {
+		return err
+	}
+
+	for _, discount := range discounts {
+		if len(discount.Promotion.EligibleItems(orderItems)) == 0 {
+			continue
+		}
+
+		err = r.checkPromotion(discount.Promotion.Id, to.User.Id)
+		if err != nil {
+			return err
+		}
+
+		discount.OrderId = to.Id
+		_, err = r.promotionRepo.UpdateDiscount(discount)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r orderRepository) checkPromotion(promotionId uint64, userId uint64) error {
+	promotion, err := r.promotionRepo.FindByIdForUpdate(promotionId)
+	if errors.Is(err, db.ErrNoMoreRows) {
+		return domain.ErrPromotionUnavailable
+	} else if err != nil {
+		return err
+	}
+	if !promotion.IsValidAt(time.Now()) {
+		return domain.ErrPromotionUnavailable
+	}
+
+	if promotion.UsageLimit != nil {
+		usages, err := r.promotionRepo.CountUsages(promotion.Id)
+		if err != nil {
+			return err
+		}
+		if usages >= *promotion.UsageLimit {
+			return domain.ErrPromotionUnavailable
+		}
+	}
+
+	if promotion.UsageLimitPerUser != nil {
+		usages, err := r.promotionRepo.CountUserUsages(promotion.Id, userId)
+		if err != nil {
+			return err
+		}
+		if usages >= *promotion.UsageLimitPerUser {
+			return domain.ErrPromotionUnavailable
+		}
+	}
+
+	return nil
+}
+
 func (r orderRepository) GetOrdersByFarmUserId(farmUserId uint64, p domain.Pagination) (domain.Orders, error) {
 	var orders []order
 	query := r.coll.Session().SQL().
@@ -308,8 +452,8 @@ func (r orderRepository) GetOrdersByFarmUserId(farmUserId uint64, p domain.Pagin
 		From("orders").
 		Join("order_items").On("order_items.order_id = orders.id").
 		Join("offers").On("order_items.offer_id = offers.id").
-		Join("farms").On("offers.farm_id = farms.id").
-		Where(db.Cond{"farms.user_id": farmUserId, "orders.deleted_date": nil, "orders.status !=": "DRAFT"}).
+		Join("farm_members").On("offers.farm_id = farm_members.farm_id").
+		Where(db.Cond{"farm_members.user_id": farmUserId, "orders.deleted_date": nil, "orders.status !=": "DRAFT"}).
 		OrderBy("created_date").
 		Distinct()
 
@@ -337,6 +481,56 @@ func (r orderRepository) GetOrdersByFarmUserId(farmUserId uint64, p domain.Pagin
 	return paginatedOrders, nil
 }
 
+func (r orderRepository) FindAllByFilter(filter domain.OrderFilter, p domain.Pagination) (domain.Orders, error) {
+	cond := db.And(db.Cond{"deleted_date": nil})
+	if filter.Status != "" {
+		cond = cond.And(db.Cond{"status": filter.Status})
+	}
+	if filter.ExcludeDrafts {
+		cond = cond.And(db.Cond{"status !=": domain.DRAFT})
+	}
+	if filter.UserId != 0 {
+		cond = cond.And(db.Cond{"user_id": filter.UserId})
+	}
+	if filter.FarmId != 0 {
+		cond = cond.And(db.Raw(
+			"EXISTS (SELECT 1 FROM order_items oi JOIN offers ofr ON ofr.id = oi.offer_id WHERE oi.order_id = orders.id AND ofr.farm_id = ?)",
+			filter.FarmId,
+		))
+	}
+	if filter.From != nil {
+		cond = cond.And(db.Cond{"created_date >=": *filter.From})
+	}
+	if filter.To != nil {
+		cond = cond.And(db.Cond{"created_date <": *filter.To})
+	}
+
+	var data []order
+	res := r.coll.Find(cond).OrderBy("-created_date").Paginate(uint(p.CountPerPage))
+	err := res.Page(uint(p.Page)).All(&data)
+	if err != nil {
+		return domain.Orders{}, err
+	}
+
+	orders := r.mapModelToDomainPagination(data)
+	for i, item := range orders.Items {
+		count, err := r.orderItemRepo.Count(item.Id)
+		if err != nil {
+			return domain.Orders{}, err
+		}
+		orders.Items[i].OrderItemsCount = count
+	}
+
+	totalCount, err := res.TotalEntries()
+	if err != nil {
+		return domain.Orders{}, err
+	}
+
+	orders.Total = totalCount
+	orders.Pages = uint(math.Ceil(float64(orders.Total) / float64(p.CountPerPage)))
+	return orders, nil
+}
+
 func (r orderRepository) GetActiveOrdersByFarmId(farmId uint64) ([]domain.Order, error) {
 	activeStatusses := domain.GetActiveOrderStatuses()
 	stringActiveStatusses := make([]string, len(activeStatusses))
@@ -395,6 +589,7 @@ func (r orderRepository) mapDomainToModel(o domain.Order) order {
 		Address:          o.Address,
 		ProductsPrice:    o.ProductsPrice,
 		ShippingPrice:    o.ShippingPrice,
+		DiscountPrice:    o.DiscountPrice,
 		TotalPrice:       o.TotalPrice,
 		Status:           string(o.Status),
 		PostOffice:       o.PostOffice,
@@ -414,6 +609,11 @@ func (r orderRepository) mapModelToDomain(o order) domain.Order {
 		return domain.Order{}
 	}
 
+	discounts, err := r.promotionRepo.FindDiscountsByOrderId(o.Id)
+	if err != nil {
+		return domain.Order{}
+	}
+
 	return domain.Order{
 		Id:               o.Id,
 		Comment:          o.Comment,
@@ -421,7 +621,9 @@ func (r orderRepository) mapModelToDomain(o order) domain.Order {
 		Address:          o.Address,
 		ProductsPrice:    o.ProductsPrice,
 		ShippingPrice:    o.ShippingPrice,
+		DiscountPrice:    o.DiscountPrice,
 		TotalPrice:       o.TotalPrice,
+		Discounts:        discounts,
 		Status:           domain.OrderStatus(o.Status),
 		PostOffice:       o.PostOffice,
 		PostOfficeCity:   o.PostOfficeCity,
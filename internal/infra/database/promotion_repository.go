@@ -0,0 +1,316 @@
+package database
+
+import (
+	"boilerplate/internal/domain"
+	"math"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+const (
+	PromotionsTableName     = "promotions"
+	OrderDiscountsTableName = "order_discounts"
+)
+
+type promotion struct {
+	Id                uint64     `db:"id,omitempty"`
+	Code              string     `db:"code"`
+	Title             string     `db:"title"`
+	Type              string     `db:"type"`
+	Value             float64    `db:"value"`
+	BuyAmount         uint32     `db:"buy_amount"`
+	GetAmount         uint32     `db:"get_amount"`
+	MinOrderPrice     float64    `db:"min_order_price"`
+	UsageLimit        *uint64    `db:"usage_limit"`
+	UsageLimitPerUser *uint64    `db:"usage_limit_per_user"`
+	StartDate         time.Time  `db:"start_date"`
+	EndDate           *time.Time `db:"end_date"`
+	IsActive          bool       `db:"is_active"`
+	UserId            uint64     `db:"user_id"`
+	FarmId            *uint64    `db:"farm_id"`
+	OfferId           *uint64    `db:"offer_id"`
+	CreatedDate       time.Time  `db:"created_date,omitempty"`
+	UpdatedDate       time.Time  `db:"updated_date,omitempty"`
+	DeletedDate       *time.Time `db:"deleted_date,omitempty"`
+}
+
+type orderDiscount struct {
+	Id          uint64    `db:"id,omitempty"`
+	OrderId     uint64    `db:"order_id"`
+	PromotionId uint64    `db:"promotion_id"`
+	Amount      float64   `db:"amount"`
+	CreatedDate time.Time `db:"created_date,omitempty"`
+	UpdatedDate time.Time `db:"updated_date,omitempty"`
+}
+
+type PromotionRepository interface {
+	Save(promotion domain.Promotion) (domain.Promotion, error)
+	Update(promotion domain.Promotion) (domain.Promotion, error)
+	FindById(id uint64) (domain.Promotion, error)
+	FindByCode(code string) (domain.Promotion, error)
+	FindByIdForUpdate(id uint64) (domain.Promotion, error)
+	FindAllByFarmId(farmId uint64, p domain.Pagination) (domain.Promotions, error)
+	FindAllPlatform(p domain.Pagination) (domain.Promotions, error)
+	Delete(id uint64) error
+	CountUsages(promotionId uint64) (uint64, error)
+	CountUserUsages(promotionId uint64, userId uint64) (uint64, error)
+	SaveDiscount(discount domain.OrderDiscount) (domain.OrderDiscount, error)
+	UpdateDiscount(discount domain.OrderDiscount) (domain.OrderDiscount, error)
+	FindDiscountsByOrderId(orderId uint64) ([]domain.OrderDiscount, error)
+	DeleteDiscount(orderId uint64, promotionId uint64) error
+}
+
+type promotionRepository struct {
+	coll         db.Collection
+	discountColl db.Collection
+	sess         db.Session
+}
+
+func NewPromotionRepository(dbSession db.Session) PromotionRepository {
+	return promotionRepository{
+		coll:         dbSession.Collection(PromotionsTableName),
+		discountColl: dbSession.Collection(OrderDiscountsTableName),
+		sess:         dbSession,
+	}
+}
+
+func (r promotionRepository) Save(promotion domain.Promotion) (domain.Promotion, error) {
+	p := r.mapDomainToModel(promotion)
+	p.CreatedDate, p.UpdatedDate = time.Now(), time.Now()
+	err := r.coll.InsertReturning(&p)
+	if err != nil {
+		return domain.Promotion{}, err
+	}
+
+	return r.mapModelToDomain(p), nil
+}
+
+func (r promotionRepository) Update(promotion domain.Promotion) (domain.Promotion, error) {
+	p := r.mapDomainToModel(promotion)
+	p.UpdatedDate = time.Now()
+	err := r.coll.Find(db.Cond{"id": p.Id}).Update(&p)
+	if err != nil {
+		return domain.Promotion{}, err
+	}
+
+	return r.mapModelToDomain(p), nil
+}
+
+func (r promotionRepository) FindById(id uint64) (domain.Promotion, error) {
+	var p promotion
+	err := r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).One(&p)
+	if err != nil {
+		return domain.Promotion{}, err
+	}
+
+	return r.mapModelToDomain(p), nil
+}
+
+// FindByIdForUpdate locks the promotion until the end of the transaction,
+// the usages of a promotion are counted and added under the lock.
+func (r promotionRepository) FindByIdForUpdate(id uint64) (domain.Promotion, error) {
+	var p promotion
+	err := r.sess.SQL().Select("*").From(PromotionsTableName).
+		Where(db.Cond{"id": id, "deleted_date": nil}).
+		Amend(func(query string) string { return query + " FOR UPDATE" }).
+		One(&p)
+	if err != nil {
+		return domain.Promotion{}, err
+	}
+
+	return r.mapModelToDomain(p), nil
+}
+
+func (r promotionRepository) FindByCode(code string) (domain.Promotion, error) {
+	var p promotion
+	err := r.coll.Find(db.Cond{"code": code, "deleted_date": nil}).One(&p)
+	if err != nil {
+		return domain.Promotion{}, err
+	}
+
+	return r.mapModelToDomain(p), nil
+}
+
+func (r promotionRepository) FindAllByFarmId(farmId uint64, p domain.Pagination) (domain.Promotions, error) {
+	return r.findAll(db.Cond{"farm_id": farmId, "deleted_date": nil}, p)
+}
+
+func (r promotionRepository) FindAllPlatform(p domain.Pagination) (domain.Promotions, error) {
+	return r.findAll(db.Cond{"farm_id": nil, "deleted_date": nil}, p)
+}
+
+func (r promotionRepository) Delete(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).Update(map[string]interface{}{"deleted_date": time.Now()})
+}
+
+// CountUsages counts the submitted orders with the promotion. Drafts only
+// hold the promotion and declined orders give it back.
+func (r promotionRepository) CountUsages(promotionId uint64) (uint64, error) {
+	return r.countUsages(db.Cond{"od.promotion_id": promotionId})
+}
+
+func (r promotionRepository) CountUserUsages(promotionId uint64, userId uint64) (uint64, error) {
+	return r.countUsages(db.Cond{"od.promotion_id": promotionId, "o.user_id": userId})
+}
+
+func (r promotionRepository) SaveDiscount(discount domain.OrderDiscount) (domain.OrderDiscount, error) {
+	d := r.mapDiscountDomainToModel(discount)
+	d.CreatedDate, d.UpdatedDate = time.Now(), time.Now()
+	err := r.discountColl.InsertReturning(&d)
+	if err != nil {
+		return domain.OrderDiscount{}, err
+	}
+
+	discount.Id = d.Id
+	discount.CreatedDate, discount.UpdatedDate = d.CreatedDate, d.UpdatedDate
+	return discount, nil
+}
+
+func (r promotionRepository) UpdateDiscount(discount domain.OrderDiscount) (domain.OrderDiscount, error) {
+	d := r.mapDiscountDomainToModel(discount)
+	d.UpdatedDate = time.Now()
+	err := r.discountColl.Find(db.Cond{"id": d.Id}).Update(&d)
+	if err != nil {
+		return domain.OrderDiscount{}, err
+	}
+
+	discount.UpdatedDate = d.UpdatedDate
+	return discount, nil
+}
+
+func (r promotionRepository) FindDiscountsByOrderId(orderId uint64) ([]domain.OrderDiscount, error) {
+	var data []orderDiscount
+	err := r.discountColl.Find(db.Cond{"order_id": orderId}).OrderBy("id").All(&data)
+	if err != nil {
+		return []domain.OrderDiscount{}, err
+	}
+
+	discounts := make([]domain.OrderDiscount, len(data))
+	for i, item := range data {
+		var p promotion
+		err = r.coll.Find(db.Cond{"id": item.PromotionId}).One(&p)
+		if err != nil {
+			return []domain.OrderDiscount{}, err
+		}
+		discounts[i] = r.mapDiscountModelToDomain(item, p)
+	}
+	return discounts, nil
+}
+
+func (r promotionRepository) DeleteDiscount(orderId uint64, promotionId uint64) error {
+	return r.discountColl.Find(db.Cond{"order_id": orderId, "promotion_id": promotionId}).Delete()
+}
+
+func (r promotionRepository) findAll(cond db.Cond, p domain.Pagination) (domain.Promotions, error) {
+	var data []promotion
+	res := r.coll.Find(cond).OrderBy("-created_date").Paginate(uint(p.CountPerPage))
+	err := res.Page(uint(p.Page)).All(&data)
+	if err != nil {
+		return domain.Promotions{}, err
+	}
+
+	promotions := domain.Promotions{Items: make([]domain.Promotion, len(data))}
+	for i, item := range data {
+		promotions.Items[i] = r.mapModelToDomain(item)
+	}
+
+	totalCount, err := res.TotalEntries()
+	if err != nil {
+		return domain.Promotions{}, err
+	}
+	promotions.Total = totalCount
+	promotions.Pages = uint(math.Ceil(float64(promotions.Total) / float64(p.CountPerPage)))
+
+	return promotions, nil
+}
+
+func (r promotionRepository) countUsages(cond db.Cond) (uint64, error) {
+	var result struct {
+		Count uint64 `db:"count"`
+	}
+	err := r.sess.SQL().Select(db.Raw("COUNT(*) AS count")).
+		From("order_discounts AS od").
+		Join("orders AS o").On("o.id = od.order_id").
+		Where(cond).
+		And(db.Cond{
+			"o.deleted_date":  nil,
+			"o.status NOT IN": []string{string(domain.DRAFT), string(domain.DECLINED)},
+		}).
+		One(&result)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.Count, nil
+}
+
+func (r promotionRepository) mapDomainToModel(d domain.Promotion) promotion {
+	return promotion{
+		Id:                d.Id,
+		Code:              d.Code,
+		Title:             d.Title,
+		Type:              string(d.Type),
+		Value:             d.Value,
+		BuyAmount:         d.BuyAmount,
+		GetAmount:         d.GetAmount,
+		MinOrderPrice:     d.MinOrderPrice,
+		UsageLimit:        d.UsageLimit,
+		UsageLimitPerUser: d.UsageLimitPerUser,
+		StartDate:         d.StartDate,
+		EndDate:           d.EndDate,
+		IsActive:          d.IsActive,
+		UserId:            d.User.Id,
+		FarmId:            d.FarmId,
+		OfferId:           d.OfferId,
+		CreatedDate:       d.CreatedDate,
+		UpdatedDate:       d.UpdatedDate,
+		DeletedDate:       d.DeletedDate,
+	}
+}
+
+func (r promotionRepository) mapModelToDomain(m promotion) domain.Promotion {
+	return domain.Promotion{
+		Id:                m.Id,
+		Code:              m.Code,
+		Title:             m.Title,
+		Type:              domain.PromotionType(m.Type),
+		Value:             m.Value,
+		BuyAmount:         m.BuyAmount,
+		GetAmount:         m.GetAmount,
+		MinOrderPrice:     m.MinOrderPrice,
+		UsageLimit:        m.UsageLimit,
+		UsageLimitPerUser: m.UsageLimitPerUser,
+		StartDate:         m.StartDate,
+		EndDate:           m.EndDate,
+		IsActive:          m.IsActive,
+		User:              domain.User{Id: m.UserId},
+		FarmId:            m.FarmId,
+		OfferId:           m.OfferId,
+		CreatedDate:       m.CreatedDate,
+		UpdatedDate:       m.UpdatedDate,
+		DeletedDate:       m.DeletedDate,
+	}
+}
+
+func (r promotionRepository) mapDiscountDomainToModel(d domain.OrderDiscount) orderDiscount {
+	return orderDiscount{
+		Id:          d.Id,
+		OrderId:     d.OrderId,
+		PromotionId: d.Promotion.Id,
+		Amount:      d.Amount,
+		CreatedDate: d.CreatedDate,
+		UpdatedDate: d.UpdatedDate,
+	}
+}
+
+func (r promotionRepository) mapDiscountModelToDomain(m orderDiscount, p promotion) domain.OrderDiscount {
+	return domain.OrderDiscount{
+		Id:          m.Id,
+		OrderId:     m.OrderId,
+		Promotion:   r.mapModelToDomain(p),
+		Amount:      m.Amount,
+		CreatedDate: m.CreatedDate,
+		UpdatedDate: m.UpdatedDate,
+	}
+}
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"boilerplate/internal/domain"
+	"sort"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+const QueuedNotificationsTableName = "queued_notifications"
+
+type queuedNotification struct {
+	Id          uint64    `db:"id,omitempty"`
+	UserId      uint64    `db:"user_id"`
+	Event       string    `db:"event"`
+	Channel     string    `db:"channel"`
+	Subject     string    `db:"subject"`
+	Body        string    `db:"body"`
+	SendDate    time.Time `db:"send_date"`
+	CreatedDate time.Time `db:"created_date,omitempty"`
+}
+
+type QueuedNotificationRepository interface {
+	Save(n domain.QueuedNotification) (domain.QueuedNotification, error)
+	ClaimDue(now time.Time, leaseUntil time.Time, limit uint) ([]domain.QueuedNotification, error)
+	Delete(id uint64) error
+	DeleteAllByUserId(userId uint64) error
+}
+
+type queuedNotificationRepository struct {
+	coll db.Collection
+}
+
+func NewQueuedNotificationRepository(dbSession db.Session) QueuedNotificationRepository {
+	return queuedNotificationRepository{
+		coll: dbSession.Collection(QueuedNotificationsTableName),
+	}
+}
+
+func (r queuedNotificationRepository) Save(n domain.QueuedNotification) (domain.QueuedNotification, error) {
+	m := r.mapDomainToModel(n)
+	m.CreatedDate = time.Now()
+	err := r.coll.InsertReturning(&m)
+	if err != nil {
+		return domain.QueuedNotification{}, err
+	}
+
+	return r.mapModelToDomain(m), nil
+}
+
+// ClaimDue takes up to limit notifications whose send date has come. A
+// claim is a lease: the send date is moved to leaseUntil, so a notification
+// left behind by a worker that died mid-send is claimed again after it.
+func (r queuedNotificationRepository) ClaimDue(now time.Time, leaseUntil time.Time, limit uint) ([]domain.QueuedNotification, error) {
+	rows, err := r.coll.Session().SQL().Query(`UPDATE queued_notifications
+		SET send_date = ?
+		WHERE id IN (
+			SELECT id FROM queued_notifications
+			WHERE send_date <= ?
+			ORDER BY send_date, id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		leaseUntil, now, int(limit))
+	if err != nil {
+		return []domain.QueuedNotification{}, err
+	}
+
+	var data []queuedNotification
+	err = r.coll.Session().SQL().NewIterator(rows).All(&data)
+	if err != nil {
+		return []domain.QueuedNotification{}, err
+	}
+
+	sort.Slice(data, func(i, j int) bool { return data[i].Id < data[j].Id })
+	notifications := make([]domain.QueuedNotification, len(data))
+	for i, item := range data {
+		notifications[i] = r.mapModelToDomain(item)
+	}
+	return notifications, nil
+}
+
+func (r queuedNotificationRepository) Delete(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id}).Delete()
+}
+
+func (r queuedNotificationRepository) DeleteAllByUserId(userId uint64) error {
+	return r.coll.Find(db.Cond{"user_id": userId}).Delete()
+}
+
+func (r queuedNotificationRepository) mapDomainToModel(d domain.QueuedNotification) queuedNotification {
+	return queuedNotification{
+		Id:          d.Id,
+		UserId:      d.User.Id,
+		Event:       string(d.Event),
+		Channel:     string(d.Channel),
+		Subject:     d.Subject,
+		Body:        d.Body,
+		SendDate:    d.SendDate,
+		CreatedDate: d.CreatedDate,
+	}
+}
+
+func (r queuedNotificationRepository) mapModelToDomain(m queuedNotification) domain.QueuedNotification {
+	return domain.QueuedNotification{
+		Id:          m.Id,
+		User:        domain.User{Id: m.UserId},
+		Event:       domain.NotificationEvent(m.Event),
+		Channel:     domain.NotificationChannel(m.Channel),
+		Subject:     m.Subject,
+		Body:        m.Body,
+		SendDate:    m.SendDate,
+		CreatedDate: m.CreatedDate,
+	}
+}
@@ -0,0 +1,312 @@
+package database
+
+import (
+	"boilerplate/internal/domain"
+	"math"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+const (
+	ReviewsTableName       = "reviews"
+	ReviewReportsTableName = "review_reports"
+)
+
+type review struct {
+	Id          uint64     `db:"id,omitempty"`
+	UserId      uint64     `db:"user_id"`
+	OfferId     uint64     `db:"offer_id"`
+	FarmId      uint64     `db:"farm_id"`
+	OrderItemId uint64     `db:"order_item_id"`
+	Rating      uint8      `db:"rating"`
+	Text        string     `db:"text"`
+	Reply       *string    `db:"reply"`
+	ReplyDate   *time.Time `db:"reply_date"`
+	Status      string     `db:"status"`
+	CreatedDate time.Time  `db:"created_date,omitempty"`
+	UpdatedDate time.Time  `db:"updated_date,omitempty"`
+	DeletedDate *time.Time `db:"deleted_date,omitempty"`
+}
+
+type reviewWithUser struct {
+	Review    review
+	UserName  string `db:"user_name"`
+	UserEmail string `db:"user_email"`
+}
+
+type reviewReport struct {
+	Id           uint64     `db:"id,omitempty"`
+	ReviewId     uint64     `db:"review_id"`
+	UserId       uint64     `db:"user_id"`
+	Reason       string     `db:"reason"`
+	ResolvedDate *time.Time `db:"resolved_date"`
+	CreatedDate  time.Time  `db:"created_date,omitempty"`
+}
+
+type ReviewRepository interface {
+	Save(review domain.Review) (domain.Review, error)
+	Update(review domain.Review) (domain.Review, error)
+	FindById(id uint64) (domain.Review, error)
+	FindByUserAndOffer(userId uint64, offerId uint64) (domain.Review, error)
+	FindAllByOfferId(offerId uint64, p domain.Pagination) (domain.Reviews, error)
+	FindAllByFarmId(farmId uint64, p domain.Pagination) (domain.Reviews, error)
+	FindAllByUserId(userId uint64) ([]domain.Review, error)
+	FindCompletedOrderItem(userId uint64, offerId uint64) (domain.OrderItem, error)
+	Delete(id uint64) error
+	SaveReport(report domain.ReviewReport) (domain.ReviewReport, error)
+	CountReports(reviewId uint64) (uint64, error)
+	ResolveReports(reviewId uint64) error
+	RecalculateRatings(offerId uint64, farmId uint64) error
+}
+
+type reviewRepository struct {
+	coll       db.Collection
+	reportColl db.Collection
+	sess       db.Session
+}
+
+func NewReviewRepository(dbSession db.Session) ReviewRepository {
+	return reviewRepository{
+		coll:       dbSession.Collection(ReviewsTableName),
+		reportColl: dbSession.Collection(ReviewReportsTableName),
+		sess:       dbSession,
+	}
+}
+
+func (r reviewRepository) Save(review domain.Review) (domain.Review, error) {
+	m := r.mapDomainToModel(review)
+	m.CreatedDate, m.UpdatedDate = time.Now(), time.Now()
+	err := r.coll.InsertReturning(&m)
+	if err != nil {
+		return domain.Review{}, err
+	}
+
+	return r.FindById(m.Id)
+}
+
+func (r reviewRepository) Update(review domain.Review) (domain.Review, error) {
+	m := r.mapDomainToModel(review)
+	m.UpdatedDate = time.Now()
+	err := r.coll.Find(db.Cond{"id": m.Id}).Update(&m)
+	if err != nil {
+		return domain.Review{}, err
+	}
+
+	return r.FindById(m.Id)
+}
+
+func (r reviewRepository) FindById(id uint64) (domain.Review, error) {
+	var rv reviewWithUser
+	err := r.selectWithUser().
+		Where(db.Cond{"reviews.id": id, "reviews.deleted_date": nil}).
+		One(&rv)
+	if err != nil {
+		return domain.Review{}, err
+	}
+
+	return r.mapModelToDomain(rv), nil
+}
+
+func (r reviewRepository) FindByUserAndOffer(userId uint64, offerId uint64) (domain.Review, error) {
+	var rv reviewWithUser
+	err := r.selectWithUser().
+		Where(db.Cond{"reviews.user_id": userId, "reviews.offer_id": offerId, "reviews.deleted_date": nil}).
+		One(&rv)
+	if err != nil {
+		return domain.Review{}, err
+	}
+
+	return r.mapModelToDomain(rv), nil
+}
+
+func (r reviewRepository) FindAllByOfferId(offerId uint64, p domain.Pagination) (domain.Reviews, error) {
+	return r.findAll(db.Cond{"reviews.offer_id": offerId}, p)
+}
+
+func (r reviewRepository) FindAllByFarmId(farmId uint64, p domain.Pagination) (domain.Reviews, error) {
+	return r.findAll(db.Cond{"reviews.farm_id": farmId}, p)
+}
+
+// FindAllByUserId returns every review the user has left, hidden ones
+// too.
+func (r reviewRepository) FindAllByUserId(userId uint64) ([]domain.Review, error) {
+	var data []reviewWithUser
+	err := r.selectWithUser().
+		Where(db.Cond{"reviews.user_id": userId, "reviews.deleted_date": nil}).
+		OrderBy("-reviews.created_date").
+		All(&data)
+	if err != nil {
+		return []domain.Review{}, err
+	}
+
+	reviews := make([]domain.Review, len(data))
+	for i, item := range data {
+		reviews[i] = r.mapModelToDomain(item)
+	}
+	return reviews, nil
+}
+
+// FindCompletedOrderItem returns the latest order item of the offer
+// which the user has received in a COMPLETED order.
+func (r reviewRepository) FindCompletedOrderItem(userId uint64, offerId uint64) (domain.OrderItem, error) {
+	var item struct {
+		Id     uint64 `db:"id"`
+		FarmId uint64 `db:"farm_id"`
+	}
+	err := r.sess.SQL().Select("oi.id", "ofr.farm_id").
+		From("order_items AS oi").
+		Join("orders AS o").On("o.id = oi.order_id").
+		Join("offers AS ofr").On("ofr.id = oi.offer_id").
+		Where(db.Cond{
+			"oi.offer_id":     offerId,
+			"oi.deleted_date": nil,
+			"o.user_id":       userId,
+			"o.status":        string(domain.COMPLETED),
+			"o.deleted_date":  nil,
+		}).
+		OrderBy("-o.updated_date").
+		Limit(1).
+		One(&item)
+	if err != nil {
+		return domain.OrderItem{}, err
+	}
+
+	return domain.OrderItem{
+		Id:    item.Id,
+		Offer: domain.Offer{Id: offerId},
+		Farm:  domain.Farm{Id: item.FarmId},
+	}, nil
+}
+
+func (r reviewRepository) Delete(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).Update(map[string]interface{}{"deleted_date": time.Now()})
+}
+
+func (r reviewRepository) SaveReport(report domain.ReviewReport) (domain.ReviewReport, error) {
+	m := reviewReport{
+		ReviewId:    report.ReviewId,
+		UserId:      report.User.Id,
+		Reason:      report.Reason,
+		CreatedDate: time.Now(),
+	}
+	err := r.reportColl.InsertReturning(&m)
+	if err != nil {
+		return domain.ReviewReport{}, err
+	}
+
+	report.Id = m.Id
+	report.CreatedDate = m.CreatedDate
+	return report, nil
+}
+
+// CountReports counts the reports no moderator has looked at yet.
+func (r reviewRepository) CountReports(reviewId uint64) (uint64, error) {
+	return r.reportColl.Find(db.Cond{"review_id": reviewId, "resolved_date": nil}).Count()
+}
+
+func (r reviewRepository) ResolveReports(reviewId uint64) error {
+	return r.reportColl.Find(db.Cond{"review_id": reviewId, "resolved_date": nil}).Update(map[string]interface{}{"resolved_date": time.Now()})
+}
+
+// RecalculateRatings refreshes the average rating and the number of reviews
+// stored on the offer and the farm. Hidden and deleted reviews are not counted.
+func (r reviewRepository) RecalculateRatings(offerId uint64, farmId uint64) error {
+	_, err := r.sess.SQL().Exec(
+		`UPDATE offers SET
+			rating = COALESCE((SELECT ROUND(AVG(rating)::numeric, 2) FROM reviews WHERE offer_id = ? AND status = ? AND deleted_date IS NULL), 0),
+			reviews_count = (SELECT COUNT(*) FROM reviews WHERE offer_id = ? AND status = ? AND deleted_date IS NULL)
+		WHERE id = ?`,
+		offerId, string(domain.REVIEW_PUBLISHED), offerId, string(domain.REVIEW_PUBLISHED), offerId,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.sess.SQL().Exec(
+		`UPDATE farms SET
+			rating = COALESCE((SELECT ROUND(AVG(rating)::numeric, 2) FROM reviews WHERE farm_id = ? AND status = ? AND deleted_date IS NULL), 0),
+			reviews_count = (SELECT COUNT(*) FROM reviews WHERE farm_id = ? AND status = ? AND deleted_date IS NULL)
+		WHERE id = ?`,
+		farmId, string(domain.REVIEW_PUBLISHED), farmId, string(domain.REVIEW_PUBLISHED), farmId,
+	)
+	return err
+}
+
+func (r reviewRepository) findAll(cond db.Cond, p domain.Pagination) (domain.Reviews, error) {
+	cond["reviews.status"] = string(domain.REVIEW_PUBLISHED)
+	cond["reviews.deleted_date"] = nil
+
+	var data []reviewWithUser
+	res := r.selectWithUser().
+		Where(cond).
+		OrderBy("-reviews.created_date").
+		Paginate(uint(p.CountPerPage))
+	err := res.Page(uint(p.Page)).All(&data)
+	if err != nil {
+		return domain.Reviews{}, err
+	}
+
+	reviews := domain.Reviews{Items: make([]domain.Review, len(data))}
+	for i, item := range data {
+		reviews.Items[i] = r.mapModelToDomain(item)
+	}
+
+	totalCount, err := res.TotalEntries()
+	if err != nil {
+		return domain.Reviews{}, err
+	}
+	reviews.Total = totalCount
+	reviews.Pages = uint(math.Ceil(float64(reviews.Total) / float64(p.CountPerPage)))
+
+	return reviews, nil
+}
+
+func (r reviewRepository) selectWithUser() db.Selector {
+	return r.sess.SQL().Select("reviews.*", "u.name AS user_name", "u.email AS user_email").
+		From(ReviewsTableName).
+		Join("users AS u").On("u.id = reviews.user_id")
+}
+
+func (r reviewRepository) mapDomainToModel(d domain.Review) review {
+	return review{
+		Id:          d.Id,
+		UserId:      d.User.Id,
+		OfferId:     d.OfferId,
+		FarmId:      d.FarmId,
+		OrderItemId: d.OrderItemId,
+		Rating:      d.Rating,
+		Text:        d.Text,
+		Reply:       d.Reply,
+		ReplyDate:   d.ReplyDate,
+		Status:      string(d.Status),
+		CreatedDate: d.CreatedDate,
+		UpdatedDate: d.UpdatedDate,
+		DeletedDate: d.DeletedDate,
+	}
+}
+
+func (r reviewRepository) mapModelToDomain(m reviewWithUser) domain.Review {
+	var images []image
+	err := r.sess.SQL().Select("*").From("images").Where("entity = ? AND entity_id = ?", "reviews", m.Review.Id).All(&images)
+	if err != nil {
+		return domain.Review{}
+	}
+
+	return domain.Review{
+		Id:          m.Review.Id,
+		User:        domain.User{Id: m.Review.UserId, Name: m.UserName, Email: m.UserEmail},
+		OfferId:     m.Review.OfferId,
+		FarmId:      m.Review.FarmId,
+		OrderItemId: m.Review.OrderItemId,
+		Rating:      m.Review.Rating,
+		Text:        m.Review.Text,
+		Images:      mapImageModelToDomainList(images),
+		Reply:       m.Review.Reply,
+		ReplyDate:   m.Review.ReplyDate,
+		Status:      domain.ReviewStatus(m.Review.Status),
+		CreatedDate: m.Review.CreatedDate,
+		UpdatedDate: m.Review.UpdatedDate,
+		DeletedDate: m.Review.DeletedDate,
+	}
+}
@@ -3,35 +3,71 @@ package database
 import (
 	"boilerplate/internal/domain"
 	"fmt"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/upper/db/v4"
 )
 
-const SessionsTableName = "sessions"
+const (
+	SessionsTableName      = "sessions"
+	RefreshTokensTableName = "refresh_tokens"
+)
+
+// lastSeenPrecision limits how often an authenticated request updates
+// the last seen date of its session.
+const lastSeenPrecision = time.Minute
 
 type sessions struct {
-	UserId uint64    `db:"user_id"`
-	UUID   uuid.UUID `db:"uuid"`
+	UserId       uint64    `db:"user_id"`
+	UUID         uuid.UUID `db:"uuid"`
+	Device       string    `db:"device"`
+	Ip           string    `db:"ip"`
+	UserAgent    string    `db:"user_agent"`
+	LastSeenDate time.Time `db:"last_seen_date"`
+	ExpiresDate  time.Time `db:"expires_date"`
+	CreatedDate  time.Time `db:"created_date"`
+}
+
+type refreshToken struct {
+	Id          uint64     `db:"id,omitempty"`
+	UserId      uint64     `db:"user_id"`
+	SessionUUID uuid.UUID  `db:"session_uuid"`
+	TokenHash   string     `db:"token_hash"`
+	UsedDate    *time.Time `db:"used_date"`
+	ExpiresDate time.Time  `db:"expires_date"`
+	CreatedDate time.Time  `db:"created_date"`
 }
 
 type SessionRepository interface {
 	Save(sess domain.Session) error
 	Exists(sess domain.Session) error
+	Find(userId uint64, id uuid.UUID) (domain.Session, error)
+	FindAllByUserId(userId uint64) ([]domain.Session, error)
+	Touch(sess domain.Session, expiresDate time.Time) error
 	Delete(sess domain.Session) error
+	DeleteAllByUserId(userId uint64) error
+	DeleteAllByUserIdExcept(userId uint64, except uuid.UUID) error
+	SaveRefreshToken(t domain.RefreshToken) (domain.RefreshToken, error)
+	FindRefreshToken(tokenHash string) (domain.RefreshToken, error)
+	MarkRefreshTokenUsed(t domain.RefreshToken) (bool, error)
 }
 
 type sessionRepository struct {
-	coll db.Collection
+	coll        db.Collection
+	refreshColl db.Collection
 }
 
 func NewSessRepository(dbSession db.Session) SessionRepository {
 	return sessionRepository{
-		coll: dbSession.Collection(SessionsTableName),
+		coll:        dbSession.Collection(SessionsTableName),
+		refreshColl: dbSession.Collection(RefreshTokensTableName),
 	}
 }
 
 func (r sessionRepository) Save(sess domain.Session) error {
 	a := r.mapDomainToModel(sess)
+	a.CreatedDate, a.LastSeenDate = time.Now(), time.Now()
 	err := r.coll.InsertReturning(&a)
 	if err != nil {
 		return err
@@ -41,20 +77,160 @@ func (r sessionRepository) Save(sess domain.Session) error {
 }
 
 func (r sessionRepository) Exists(sess domain.Session) error {
-	exists, err := r.coll.Find(db.Cond{"user_id": sess.UserId, "uuid": sess.UUID}).Exists()
+	exists, err := r.coll.
+		Find(db.Cond{"user_id": sess.UserId, "uuid": sess.UUID, "expires_date >": time.Now()}).
+		Exists()
 	if !exists {
 		err = fmt.Errorf("sess not found")
 	}
 	return err
 }
 
+func (r sessionRepository) Find(userId uint64, id uuid.UUID) (domain.Session, error) {
+	var s sessions
+	err := r.coll.Find(db.Cond{"user_id": userId, "uuid": id}).One(&s)
+	if err != nil {
+		return domain.Session{}, err
+	}
+
+	return r.mapModelToDomain(s), nil
+}
+
+func (r sessionRepository) FindAllByUserId(userId uint64) ([]domain.Session, error) {
+	var s []sessions
+	err := r.coll.
+		Find(db.Cond{"user_id": userId, "expires_date >": time.Now()}).
+		OrderBy("-last_seen_date").
+		All(&s)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapModelToDomainCollection(s), nil
+}
+
+// Touch refreshes the last seen date of the session. When expiresDate is
+// not zero the session lifetime is prolonged as well, otherwise the update
+// is skipped if the session was seen less than lastSeenPrecision ago.
+func (r sessionRepository) Touch(sess domain.Session, expiresDate time.Time) error {
+	now := time.Now()
+	cond := db.Cond{"user_id": sess.UserId, "uuid": sess.UUID}
+	set := map[string]interface{}{"last_seen_date": now}
+	if expiresDate.IsZero() {
+		cond["last_seen_date <"] = now.Add(-lastSeenPrecision)
+	} else {
+		set["expires_date"] = expiresDate
+	}
+
+	return r.coll.Find(cond).Update(set)
+}
+
 func (r sessionRepository) Delete(sess domain.Session) error {
 	return r.coll.Find(db.Cond{"user_id": sess.UserId, "uuid": sess.UUID}).Delete()
 }
 
+func (r sessionRepository) DeleteAllByUserId(userId uint64) error {
+	return r.coll.Find(db.Cond{"user_id": userId}).Delete()
+}
+
+func (r sessionRepository) DeleteAllByUserIdExcept(userId uint64, except uuid.UUID) error {
+	return r.coll.Find(db.Cond{"user_id": userId, "uuid <>": except}).Delete()
+}
+
+func (r sessionRepository) SaveRefreshToken(t domain.RefreshToken) (domain.RefreshToken, error) {
+	m := r.mapRefreshTokenDomainToModel(t)
+	m.CreatedDate = time.Now()
+	err := r.refreshColl.InsertReturning(&m)
+	if err != nil {
+		return domain.RefreshToken{}, err
+	}
+
+	return r.mapRefreshTokenModelToDomain(m), nil
+}
+
+func (r sessionRepository) FindRefreshToken(tokenHash string) (domain.RefreshToken, error) {
+	var m refreshToken
+	err := r.refreshColl.Find(db.Cond{"token_hash": tokenHash}).One(&m)
+	if err != nil {
+		return domain.RefreshToken{}, err
+	}
+
+	return r.mapRefreshTokenModelToDomain(m), nil
+}
+
+// MarkRefreshTokenUsed reports whether the token was unused before the
+// call, so that two concurrent refreshes with one token can't both win.
+func (r sessionRepository) MarkRefreshTokenUsed(t domain.RefreshToken) (bool, error) {
+	res, err := r.refreshColl.Session().SQL().
+		Update(RefreshTokensTableName).
+		Set("used_date", time.Now()).
+		Where(db.Cond{"id": t.Id, "used_date IS": nil}).
+		Exec()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
 func (r sessionRepository) mapDomainToModel(d domain.Session) sessions {
 	return sessions{
-		UserId: d.UserId,
-		UUID:   d.UUID,
+		UserId:       d.UserId,
+		UUID:         d.UUID,
+		Device:       d.Device,
+		Ip:           d.Ip,
+		UserAgent:    d.UserAgent,
+		LastSeenDate: d.LastSeenDate,
+		ExpiresDate:  d.ExpiresDate,
+		CreatedDate:  d.CreatedDate,
+	}
+}
+
+func (r sessionRepository) mapModelToDomain(m sessions) domain.Session {
+	return domain.Session{
+		UserId:       m.UserId,
+		UUID:         m.UUID,
+		Device:       m.Device,
+		Ip:           m.Ip,
+		UserAgent:    m.UserAgent,
+		LastSeenDate: m.LastSeenDate,
+		ExpiresDate:  m.ExpiresDate,
+		CreatedDate:  m.CreatedDate,
+	}
+}
+
+func (r sessionRepository) mapModelToDomainCollection(m []sessions) []domain.Session {
+	result := make([]domain.Session, len(m))
+	for i := range m {
+		result[i] = r.mapModelToDomain(m[i])
+	}
+	return result
+}
+
+func (r sessionRepository) mapRefreshTokenDomainToModel(d domain.RefreshToken) refreshToken {
+	return refreshToken{
+		Id:          d.Id,
+		UserId:      d.UserId,
+		SessionUUID: d.SessionUUID,
+		TokenHash:   d.TokenHash,
+		UsedDate:    d.UsedDate,
+		ExpiresDate: d.ExpiresDate,
+		CreatedDate: d.CreatedDate,
+	}
+}
+
+func (r sessionRepository) mapRefreshTokenModelToDomain(m refreshToken) domain.RefreshToken {
+	return domain.RefreshToken{
+		Id:          m.Id,
+		UserId:      m.UserId,
+		SessionUUID: m.SessionUUID,
+		TokenHash:   m.TokenHash,
+		UsedDate:    m.UsedDate,
+		ExpiresDate: m.ExpiresDate,
+		CreatedDate: m.CreatedDate,
 	}
 }
@@ -0,0 +1,202 @@
+package database
+
+import (
+	"boilerplate/internal/domain"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+const (
+	RecoveryCodesTableName       = "recovery_codes"
+	TwoFactorChallengesTableName = "two_factor_challenges"
+)
+
+type recoveryCode struct {
+	Id          uint64     `db:"id,omitempty"`
+	UserId      uint64     `db:"user_id"`
+	CodeHash    string     `db:"code_hash"`
+	UsedDate    *time.Time `db:"used_date"`
+	CreatedDate time.Time  `db:"created_date"`
+}
+
+type twoFactorChallenge struct {
+	Id          uint64    `db:"id,omitempty"`
+	UserId      uint64    `db:"user_id"`
+	TokenHash   string    `db:"token_hash"`
+	Attempts    uint32    `db:"attempts"`
+	ExpiresDate time.Time `db:"expires_date"`
+	CreatedDate time.Time `db:"created_date"`
+}
+
+type TwoFactorRepository interface {
+	ReplaceRecoveryCodes(userId uint64, codeHashes []string) error
+	UseRecoveryCode(userId uint64, codeHash string) (bool, error)
+	DeleteRecoveryCodes(userId uint64) error
+	SetTotp(userId uint64, secret *string, enabledDate *time.Time) error
+	UseTotpStep(userId uint64, step int64) (bool, error)
+	SaveChallenge(c domain.TwoFactorChallenge) (domain.TwoFactorChallenge, error)
+	FindChallenge(tokenHash string) (domain.TwoFactorChallenge, error)
+	ClaimChallengeAttempt(id uint64, maxAttempts uint32, now time.Time) (bool, error)
+	DeleteChallenge(id uint64) error
+}
+
+type twoFactorRepository struct {
+	sess       db.Session
+	codes      db.Collection
+	challenges db.Collection
+}
+
+func NewTwoFactorRepository(dbSession db.Session) TwoFactorRepository {
+	return twoFactorRepository{
+		sess:       dbSession,
+		codes:      dbSession.Collection(RecoveryCodesTableName),
+		challenges: dbSession.Collection(TwoFactorChallengesTableName),
+	}
+}
+
+// ReplaceRecoveryCodes drops the old codes of the user, used or not, and
+// stores the new ones.
+func (r twoFactorRepository) ReplaceRecoveryCodes(userId uint64, codeHashes []string) error {
+	return r.sess.Tx(func(tx db.Session) error {
+		err := tx.Collection(RecoveryCodesTableName).Find(db.Cond{"user_id": userId}).Delete()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, hash := range codeHashes {
+			_, err = tx.Collection(RecoveryCodesTableName).Insert(recoveryCode{UserId: userId, CodeHash: hash, CreatedDate: now})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UseRecoveryCode reports whether the user had such an unused code. The
+// code is spent in the same statement, so it works only once.
+func (r twoFactorRepository) UseRecoveryCode(userId uint64, codeHash string) (bool, error) {
+	res, err := r.sess.SQL().
+		Update(RecoveryCodesTableName).
+		Set("used_date", time.Now()).
+		Where(db.Cond{"user_id": userId, "code_hash": codeHash, "used_date IS": nil}).
+		Exec()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r twoFactorRepository) DeleteRecoveryCodes(userId uint64) error {
+	return r.codes.Find(db.Cond{"user_id": userId}).Delete()
+}
+
+// SetTotp stores the authenticator secret of the user and whether 2FA is
+// on. The general user update leaves these columns alone.
+func (r twoFactorRepository) SetTotp(userId uint64, secret *string, enabledDate *time.Time) error {
+	_, err := r.sess.SQL().
+		Update(UsersTableName).
+		Set("totp_secret", secret, "totp_enabled_date", enabledDate, "updated_date", time.Now()).
+		Where(db.Cond{"id": userId}).
+		Exec()
+	return err
+}
+
+// UseTotpStep remembers the time step of the last accepted code and
+// reports false if this or a later step was already used, so a code seen
+// by someone else can't be replayed.
+func (r twoFactorRepository) UseTotpStep(userId uint64, step int64) (bool, error) {
+	res, err := r.sess.SQL().
+		Update(UsersTableName).
+		Set("totp_last_step", step).
+		Where(db.Cond{"id": userId, "totp_last_step <": step}).
+		Exec()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r twoFactorRepository) SaveChallenge(c domain.TwoFactorChallenge) (domain.TwoFactorChallenge, error) {
+	m := r.mapChallengeDomainToModel(c)
+	m.CreatedDate = time.Now()
+	err := r.challenges.InsertReturning(&m)
+	if err != nil {
+		return domain.TwoFactorChallenge{}, err
+	}
+
+	return r.mapChallengeModelToDomain(m), nil
+}
+
+func (r twoFactorRepository) FindChallenge(tokenHash string) (domain.TwoFactorChallenge, error) {
+	var m twoFactorChallenge
+	err := r.challenges.Find(db.Cond{"token_hash": tokenHash}).One(&m)
+	if err != nil {
+		return domain.TwoFactorChallenge{}, err
+	}
+
+	return r.mapChallengeModelToDomain(m), nil
+}
+
+// ClaimChallengeAttempt counts an attempt before the code is checked. It
+// fails once the challenge is out of attempts or expired, so requests made
+// in parallel can't check more codes than maxAttempts.
+func (r twoFactorRepository) ClaimChallengeAttempt(id uint64, maxAttempts uint32, now time.Time) (bool, error) {
+	row, err := r.sess.SQL().QueryRow(`UPDATE two_factor_challenges
+		SET attempts = attempts + 1
+		WHERE id = ? AND attempts < ? AND expires_date > ?
+		RETURNING attempts`,
+		id, maxAttempts, now)
+	if err != nil {
+		return false, err
+	}
+
+	var attempts uint32
+	err = row.Scan(&attempts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r twoFactorRepository) DeleteChallenge(id uint64) error {
+	return r.challenges.Find(db.Cond{"id": id}).Delete()
+}
+
+func (r twoFactorRepository) mapChallengeDomainToModel(d domain.TwoFactorChallenge) twoFactorChallenge {
+	return twoFactorChallenge{
+		Id:          d.Id,
+		UserId:      d.UserId,
+		TokenHash:   d.TokenHash,
+		Attempts:    d.Attempts,
+		ExpiresDate: d.ExpiresDate,
+		CreatedDate: d.CreatedDate,
+	}
+}
+
+func (r twoFactorRepository) mapChallengeModelToDomain(m twoFactorChallenge) domain.TwoFactorChallenge {
+	return domain.TwoFactorChallenge{
+		Id:          m.Id,
+		UserId:      m.UserId,
+		TokenHash:   m.TokenHash,
+		Attempts:    m.Attempts,
+		ExpiresDate: m.ExpiresDate,
+		CreatedDate: m.CreatedDate,
+	}
+}
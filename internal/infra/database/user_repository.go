@@ -2,6 +2,7 @@ package database
 
 import (
 	"boilerplate/internal/domain"
+	"math"
 	"time"
 
 	"github.com/upper/db/v4"
@@ -10,14 +11,24 @@ import (
 const UsersTableName = "users"
 
 type user struct {
-	Id          uint64     `db:"id,omitempty"`
-	Name        string     `db:"name"`
-	Email       string     `db:"email"`
-	Password    string     `db:"password"`
-	PhoneNumber *string    `db:"phone_number"`
-	CreatedDate time.Time  `db:"created_date,omitempty"`
-	UpdatedDate time.Time  `db:"updated_date,omitempty"`
-	DeletedDate *time.Time `db:"deleted_date,omitempty"`
+	Id                uint64     `db:"id,omitempty"`
+	Name              string     `db:"name"`
+	Email             string     `db:"email"`
+	EmailVerifiedDate *time.Time `db:"email_verified_at"`
+	NewEmail          *string    `db:"new_email"`
+	Password          string     `db:"password"`
+	PhoneNumber       *string    `db:"phone_number"`
+	PhoneVerifiedDate *time.Time `db:"phone_verified_date"`
+	Language          string     `db:"language,omitempty"`
+	Role              string     `db:"role,omitempty"`
+	BlockedDate       *time.Time `db:"blocked_date"`
+	BlockReason       *string    `db:"block_reason"`
+	TotpSecret        *string    `db:"totp_secret"`
+	TotpEnabledDate   *time.Time `db:"totp_enabled_date"`
+	TotpLastStep      int64      `db:"totp_last_step"`
+	CreatedDate       time.Time  `db:"created_date,omitempty"`
+	UpdatedDate       time.Time  `db:"updated_date,omitempty"`
+	DeletedDate       *time.Time `db:"deleted_date,omitempty"`
 }
 
 type UserRepository interface {
@@ -26,7 +37,11 @@ type UserRepository interface {
 	Save(user domain.User) (domain.User, error)
 	FindById(id uint64) (domain.User, error)
 	Update(user domain.User) (domain.User, error)
+	SetBlocked(id uint64, blockedDate *time.Time, reason *string) (domain.User, error)
+	SetRole(id uint64, role domain.Role) (domain.User, error)
 	Delete(id uint64) error
+	Anonymize(id uint64) error
+	FindAll(filter domain.UserFilter, p domain.Pagination) (domain.Users, error)
 }
 
 type userRepository struct {
@@ -39,9 +54,10 @@ func NewUserRepository(dbSession db.Session) UserRepository {
 	}
 }
 
+// FindByEmail looks among verified addresses only, like FindByPhoneNumber.
 func (r userRepository) FindByEmail(email string) (domain.User, error) {
 	var u user
-	err := r.coll.Find(db.Cond{"email": email, "deleted_date": nil}).One(&u)
+	err := r.coll.Find(db.Cond{"email": email, "email_verified_at IS NOT": nil, "deleted_date": nil}).One(&u)
 	if err != nil {
 		return domain.User{}, err
 	}
@@ -49,9 +65,11 @@ func (r userRepository) FindByEmail(email string) (domain.User, error) {
 	return r.mapModelToDomain(u), nil
 }
 
+// FindByPhoneNumber looks among verified numbers only, as an unverified
+// number does not belong to anyone yet.
 func (r userRepository) FindByPhoneNumber(phoneNumber string) (domain.User, error) {
 	var u user
-	err := r.coll.Find(db.Cond{"phone_number": phoneNumber, "deleted_date": nil}).One(&u)
+	err := r.coll.Find(db.Cond{"phone_number": phoneNumber, "phone_verified_date IS NOT": nil, "deleted_date": nil}).One(&u)
 	if err != nil {
 		return domain.User{}, err
 	}
@@ -78,42 +96,165 @@ func (r userRepository) FindById(id uint64) (domain.User, error) {
 	return r.mapModelToDomain(u), nil
 }
 
+// Update saves the profile, contact and password fields of the user. The
+// role, blocking and 2FA columns are written only by their own methods, so
+// saving a stale copy of the user can't undo them.
 func (r userRepository) Update(user domain.User) (domain.User, error) {
 	u := r.mapDomainToModel(user)
-	u.UpdatedDate = time.Now()
-	err := r.coll.Find(db.Cond{"id": u.Id}).Update(&u)
+	fields := map[string]interface{}{
+		"name":                u.Name,
+		"email":               u.Email,
+		"email_verified_at":   u.EmailVerifiedDate,
+		"new_email":           u.NewEmail,
+		"password":            u.Password,
+		"phone_number":        u.PhoneNumber,
+		"phone_verified_date": u.PhoneVerifiedDate,
+		"updated_date":        time.Now(),
+	}
+	if u.Language != "" {
+		fields["language"] = u.Language
+	}
+
+	err := r.coll.Find(db.Cond{"id": u.Id}).Update(fields)
 	if err != nil {
 		return domain.User{}, err
 	}
-	return r.mapModelToDomain(u), nil
+	return r.FindById(u.Id)
+}
+
+func (r userRepository) SetBlocked(id uint64, blockedDate *time.Time, reason *string) (domain.User, error) {
+	err := r.coll.Find(db.Cond{"id": id}).Update(map[string]interface{}{
+		"blocked_date": blockedDate,
+		"block_reason": reason,
+		"updated_date": time.Now(),
+	})
+	if err != nil {
+		return domain.User{}, err
+	}
+	return r.FindById(id)
+}
+
+func (r userRepository) SetRole(id uint64, role domain.Role) (domain.User, error) {
+	err := r.coll.Find(db.Cond{"id": id}).Update(map[string]interface{}{
+		"role":         string(role),
+		"updated_date": time.Now(),
+	})
+	if err != nil {
+		return domain.User{}, err
+	}
+	return r.FindById(id)
 }
 
 func (r userRepository) Delete(id uint64) error {
 	return r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).Update(map[string]interface{}{"deleted_date": time.Now()})
 }
 
+// Anonymize deletes the account and wipes everything that identifies the
+// person, the row itself stays for the orders and invoices pointing at it.
+func (r userRepository) Anonymize(id uint64) error {
+	now := time.Now()
+	return r.coll.Find(db.Cond{"id": id}).Update(map[string]interface{}{
+		"name":                domain.DELETED_USER_NAME,
+		"email":               "",
+		"email_verified_at":   nil,
+		"new_email":           nil,
+		"password":            "",
+		"phone_number":        nil,
+		"phone_verified_date": nil,
+		"totp_secret":         nil,
+		"totp_enabled_date":   nil,
+		"updated_date":        now,
+		"deleted_date":        now,
+	})
+}
+
+func (r userRepository) FindAll(filter domain.UserFilter, p domain.Pagination) (domain.Users, error) {
+	cond := db.And(db.Cond{"deleted_date": nil})
+	if filter.Search != "" {
+		search := "%" + filter.Search + "%"
+		cond = cond.And(db.Or(
+			db.Cond{"name ILIKE": search},
+			db.Cond{"email ILIKE": search},
+			db.Cond{"phone_number ILIKE": search},
+		))
+	}
+	if filter.Role != "" {
+		cond = cond.And(db.Cond{"role": filter.Role})
+	}
+	if filter.Blocked != nil {
+		if *filter.Blocked {
+			cond = cond.And(db.Cond{"blocked_date IS NOT": nil})
+		} else {
+			cond = cond.And(db.Cond{"blocked_date IS": nil})
+		}
+	}
+
+	var data []user
+	res := r.coll.Find(cond).OrderBy("id").Paginate(uint(p.CountPerPage))
+	err := res.Page(uint(p.Page)).All(&data)
+	if err != nil {
+		return domain.Users{}, err
+	}
+
+	users := make([]domain.User, len(data))
+	for i := range data {
+		users[i] = r.mapModelToDomain(data[i])
+	}
+
+	total, err := res.TotalEntries()
+	if err != nil {
+		return domain.Users{}, err
+	}
+
+	return domain.Users{
+		Items: users,
+		Total: total,
+		Pages: uint(math.Ceil(float64(total) / float64(p.CountPerPage))),
+	}, nil
+}
+
 func (r userRepository) mapDomainToModel(d domain.User) user {
 	return user{
-		Id:          d.Id,
-		Name:        d.Name,
-		Email:       d.Email,
-		Password:    d.Password,
-		PhoneNumber: d.PhoneNumber,
-		CreatedDate: d.CreatedDate,
-		UpdatedDate: d.UpdatedDate,
-		DeletedDate: d.DeletedDate,
+		Id:                d.Id,
+		Name:              d.Name,
+		Email:             d.Email,
+		EmailVerifiedDate: d.EmailVerifiedDate,
+		NewEmail:          d.NewEmail,
+		Password:          d.Password,
+		PhoneNumber:       d.PhoneNumber,
+		PhoneVerifiedDate: d.PhoneVerifiedDate,
+		Language:          d.Language,
+		Role:              string(d.Role),
+		BlockedDate:       d.BlockedDate,
+		BlockReason:       d.BlockReason,
+		TotpSecret:        d.TotpSecret,
+		TotpEnabledDate:   d.TotpEnabledDate,
+		TotpLastStep:      d.TotpLastStep,
+		CreatedDate:       d.CreatedDate,
+		UpdatedDate:       d.UpdatedDate,
+		DeletedDate:       d.DeletedDate,
 	}
 }
 
 func (r userRepository) mapModelToDomain(m user) domain.User {
 	return domain.User{
-		Id:          m.Id,
-		Name:        m.Name,
-		Email:       m.Email,
-		Password:    m.Password,
-		PhoneNumber: m.PhoneNumber,
-		CreatedDate: m.CreatedDate,
-		UpdatedDate: m.UpdatedDate,
-		DeletedDate: m.DeletedDate,
+		Id:                m.Id,
+		Name:              m.Name,
+		Email:             m.Email,
+		EmailVerifiedDate: m.EmailVerifiedDate,
+		NewEmail:          m.NewEmail,
+		Password:          m.Password,
+		PhoneNumber:       m.PhoneNumber,
+		PhoneVerifiedDate: m.PhoneVerifiedDate,
+		Language:          m.Language,
+		Role:              domain.Role(m.Role),
+		BlockedDate:       m.BlockedDate,
+		BlockReason:       m.BlockReason,
+		TotpSecret:        m.TotpSecret,
+		TotpEnabledDate:   m.TotpEnabledDate,
+		TotpLastStep:      m.TotpLastStep,
+		CreatedDate:       m.CreatedDate,
+		UpdatedDate:       m.UpdatedDate,
+		DeletedDate:       m.DeletedDate,
 	}
 }
@@ -0,0 +1,293 @@
+package database
+
+import (
+	"boilerplate/internal/domain"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/upper/db/v4"
+)
+
+const (
+	WebhooksTableName          = "webhooks"
+	WebhookDeliveriesTableName = "webhook_deliveries"
+)
+
+type webhook struct {
+	Id          uint64     `db:"id,omitempty"`
+	UserId      uint64     `db:"user_id"`
+	FarmId      uint64     `db:"farm_id"`
+	Url         string     `db:"url"`
+	Secret      string     `db:"secret"`
+	Events      string     `db:"events"`
+	IsActive    bool       `db:"is_active"`
+	CreatedDate time.Time  `db:"created_date,omitempty"`
+	UpdatedDate time.Time  `db:"updated_date,omitempty"`
+	DeletedDate *time.Time `db:"deleted_date,omitempty"`
+}
+
+type webhookDelivery struct {
+	Id              uint64     `db:"id,omitempty"`
+	WebhookId       uint64     `db:"webhook_id"`
+	Event           string     `db:"event"`
+	Payload         string     `db:"payload"`
+	Status          string     `db:"status"`
+	Attempts        uint32     `db:"attempts"`
+	NextAttemptDate *time.Time `db:"next_attempt_date"`
+	ResponseCode    *int       `db:"response_code"`
+	Error           *string    `db:"error"`
+	DeliveredDate   *time.Time `db:"delivered_date"`
+	CreatedDate     time.Time  `db:"created_date,omitempty"`
+	UpdatedDate     time.Time  `db:"updated_date,omitempty"`
+}
+
+type WebhookRepository interface {
+	Save(w domain.Webhook) (domain.Webhook, error)
+	Update(w domain.Webhook) (domain.Webhook, error)
+	FindById(id uint64) (domain.Webhook, error)
+	FindAllByFarmId(farmId uint64) ([]domain.Webhook, error)
+	Delete(id uint64) error
+	DeleteAllByFarmId(farmId uint64) error
+	SaveDelivery(d domain.WebhookDelivery) (domain.WebhookDelivery, error)
+	UpdateDelivery(d domain.WebhookDelivery) (domain.WebhookDelivery, error)
+	FindDeliveryById(id uint64) (domain.WebhookDelivery, error)
+	FindDeliveriesByWebhookId(webhookId uint64, p domain.Pagination) (domain.WebhookDeliveries, error)
+	ClaimDueDeliveries(now time.Time, leaseUntil time.Time, limit uint) ([]domain.WebhookDelivery, error)
+}
+
+type webhookRepository struct {
+	coll         db.Collection
+	deliveryColl db.Collection
+}
+
+func NewWebhookRepository(dbSession db.Session) WebhookRepository {
+	return webhookRepository{
+		coll:         dbSession.Collection(WebhooksTableName),
+		deliveryColl: dbSession.Collection(WebhookDeliveriesTableName),
+	}
+}
+
+func (r webhookRepository) Save(w domain.Webhook) (domain.Webhook, error) {
+	m := r.mapDomainToModel(w)
+	m.CreatedDate, m.UpdatedDate = time.Now(), time.Now()
+	err := r.coll.InsertReturning(&m)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	return r.mapModelToDomain(m), nil
+}
+
+func (r webhookRepository) Update(w domain.Webhook) (domain.Webhook, error) {
+	m := r.mapDomainToModel(w)
+	m.UpdatedDate = time.Now()
+	err := r.coll.Find(db.Cond{"id": m.Id, "deleted_date": nil}).Update(&m)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	return r.mapModelToDomain(m), nil
+}
+
+func (r webhookRepository) FindById(id uint64) (domain.Webhook, error) {
+	var m webhook
+	err := r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).One(&m)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	return r.mapModelToDomain(m), nil
+}
+
+func (r webhookRepository) FindAllByFarmId(farmId uint64) ([]domain.Webhook, error) {
+	var data []webhook
+	err := r.coll.Find(db.Cond{"farm_id": farmId, "deleted_date": nil}).OrderBy("id").All(&data)
+	if err != nil {
+		return []domain.Webhook{}, err
+	}
+
+	webhooks := make([]domain.Webhook, len(data))
+	for i, item := range data {
+		webhooks[i] = r.mapModelToDomain(item)
+	}
+	return webhooks, nil
+}
+
+func (r webhookRepository) Delete(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).Update(map[string]interface{}{"deleted_date": time.Now()})
+}
+
+func (r webhookRepository) DeleteAllByFarmId(farmId uint64) error {
+	return r.coll.Find(db.Cond{"farm_id": farmId, "deleted_date": nil}).Update(map[string]interface{}{"deleted_date": time.Now()})
+}
+
+func (r webhookRepository) SaveDelivery(d domain.WebhookDelivery) (domain.WebhookDelivery, error) {
+	m := r.mapDeliveryDomainToModel(d)
+	m.CreatedDate, m.UpdatedDate = time.Now(), time.Now()
+	err := r.deliveryColl.InsertReturning(&m)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	return r.mapDeliveryModelToDomain(m), nil
+}
+
+func (r webhookRepository) UpdateDelivery(d domain.WebhookDelivery) (domain.WebhookDelivery, error) {
+	m := r.mapDeliveryDomainToModel(d)
+	m.UpdatedDate = time.Now()
+	err := r.deliveryColl.Find(db.Cond{"id": m.Id}).Update(&m)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	return r.mapDeliveryModelToDomain(m), nil
+}
+
+func (r webhookRepository) FindDeliveryById(id uint64) (domain.WebhookDelivery, error) {
+	var m webhookDelivery
+	err := r.deliveryColl.Find(db.Cond{"id": id}).One(&m)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	return r.mapDeliveryModelToDomain(m), nil
+}
+
+func (r webhookRepository) FindDeliveriesByWebhookId(webhookId uint64, p domain.Pagination) (domain.WebhookDeliveries, error) {
+	var data []webhookDelivery
+	res := r.deliveryColl.Find(db.Cond{"webhook_id": webhookId}).OrderBy("-created_date", "-id").Paginate(uint(p.CountPerPage))
+	err := res.Page(uint(p.Page)).All(&data)
+	if err != nil {
+		return domain.WebhookDeliveries{}, err
+	}
+
+	deliveries := domain.WebhookDeliveries{Items: make([]domain.WebhookDelivery, len(data))}
+	for i, item := range data {
+		deliveries.Items[i] = r.mapDeliveryModelToDomain(item)
+	}
+
+	totalCount, err := res.TotalEntries()
+	if err != nil {
+		return domain.WebhookDeliveries{}, err
+	}
+	deliveries.Total = totalCount
+	deliveries.Pages = uint(math.Ceil(float64(deliveries.Total) / float64(p.CountPerPage)))
+
+	return deliveries, nil
+}
+
+// ClaimDueDeliveries marks up to limit pending deliveries whose next
+// attempt is due as SENDING and returns them, the oldest first. Rows locked
+// by another claim are skipped, so concurrent workers never get the same
+// delivery. A claim is a lease: its next attempt date is moved to leaseUntil,
+// and a delivery still SENDING after that is claimed again, which covers
+// workers that died mid-send.
+func (r webhookRepository) ClaimDueDeliveries(now time.Time, leaseUntil time.Time, limit uint) ([]domain.WebhookDelivery, error) {
+	rows, err := r.deliveryColl.Session().SQL().Query(`UPDATE webhook_deliveries
+		SET status = ?, next_attempt_date = ?, updated_date = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status IN (?, ?) AND next_attempt_date <= ?
+			ORDER BY next_attempt_date, id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		string(domain.DELIVERY_SENDING), leaseUntil, now,
+		string(domain.DELIVERY_PENDING), string(domain.DELIVERY_SENDING), now,
+		int(limit))
+	if err != nil {
+		return []domain.WebhookDelivery{}, err
+	}
+
+	var data []webhookDelivery
+	err = r.deliveryColl.Session().SQL().NewIterator(rows).All(&data)
+	if err != nil {
+		return []domain.WebhookDelivery{}, err
+	}
+
+	sort.Slice(data, func(i, j int) bool { return data[i].Id < data[j].Id })
+	deliveries := make([]domain.WebhookDelivery, len(data))
+	for i, item := range data {
+		deliveries[i] = r.mapDeliveryModelToDomain(item)
+	}
+	return deliveries, nil
+}
+
+func (r webhookRepository) mapDomainToModel(d domain.Webhook) webhook {
+	events := make([]string, len(d.Events))
+	for i, e := range d.Events {
+		events[i] = string(e)
+	}
+
+	return webhook{
+		Id:          d.Id,
+		UserId:      d.User.Id,
+		FarmId:      d.FarmId,
+		Url:         d.Url,
+		Secret:      d.Secret,
+		Events:      strings.Join(events, ","),
+		IsActive:    d.IsActive,
+		CreatedDate: d.CreatedDate,
+		UpdatedDate: d.UpdatedDate,
+		DeletedDate: d.DeletedDate,
+	}
+}
+
+func (r webhookRepository) mapModelToDomain(m webhook) domain.Webhook {
+	var events []domain.WebhookEvent
+	for _, e := range strings.Split(m.Events, ",") {
+		if e != "" {
+			events = append(events, domain.WebhookEvent(e))
+		}
+	}
+
+	return domain.Webhook{
+		Id:          m.Id,
+		User:        domain.User{Id: m.UserId},
+		FarmId:      m.FarmId,
+		Url:         m.Url,
+		Secret:      m.Secret,
+		Events:      events,
+		IsActive:    m.IsActive,
+		CreatedDate: m.CreatedDate,
+		UpdatedDate: m.UpdatedDate,
+		DeletedDate: m.DeletedDate,
+	}
+}
+
+func (r webhookRepository) mapDeliveryDomainToModel(d domain.WebhookDelivery) webhookDelivery {
+	return webhookDelivery{
+		Id:              d.Id,
+		WebhookId:       d.WebhookId,
+		Event:           string(d.Event),
+		Payload:         d.Payload,
+		Status:          string(d.Status),
+		Attempts:        d.Attempts,
+		NextAttemptDate: d.NextAttemptDate,
+		ResponseCode:    d.ResponseCode,
+		Error:           d.Error,
+		DeliveredDate:   d.DeliveredDate,
+		CreatedDate:     d.CreatedDate,
+		UpdatedDate:     d.UpdatedDate,
+	}
+}
+
+func (r webhookRepository) mapDeliveryModelToDomain(m webhookDelivery) domain.WebhookDelivery {
+	return domain.WebhookDelivery{
+		Id:              m.Id,
+		WebhookId:       m.WebhookId,
+		Event:           domain.WebhookEvent(m.Event),
+		Payload:         m.Payload,
+		Status:          domain.WebhookDeliveryStatus(m.Status),
+		Attempts:        m.Attempts,
+		NextAttemptDate: m.NextAttemptDate,
+		ResponseCode:    m.ResponseCode,
+		Error:           m.Error,
+		DeliveredDate:   m.DeliveredDate,
+		CreatedDate:     m.CreatedDate,
+		UpdatedDate:     m.UpdatedDate,
+	}
+}
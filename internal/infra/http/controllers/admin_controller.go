@@ -0,0 +1,284 @@
+package controllers
+
+import (
+	"boilerplate/internal/app"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/http/requests"
+	"boilerplate/internal/infra/http/resources"
+	"log"
+	"net/http"
+)
+
+type AdminController struct {
+	adminService      app.AdminService
+	auditService      app.AuditService
+	imageModelService app.ImageModelService
+}
+
+func NewAdminController(as app.AdminService, aus app.AuditService, ims app.ImageModelService) AdminController {
+	return AdminController{
+		adminService:      as,
+		auditService:      aus,
+		imageModelService: ims,
+	}
+}
+
+func (c AdminController) FindUsers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pagination, err := requests.DecodePaginationQuery(r)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		filter, err := requests.DecodeUserFilterQuery(r)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		users, err := c.adminService.FindUsers(filter, pagination)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.AdminUserDto{}.DomainToDtoCollection(users))
+	}
+}
+
+func (c AdminController) BlockUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.Bind(r, requests.BlockUserRequest{}, domain.AdminAction{})
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		user := r.Context().Value(TargetUserKey).(domain.User)
+		user, err = c.adminService.BlockUser(requestActor(r), user, req.Reason)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.AdminUserDto{}.DomainToDto(user))
+	}
+}
+
+func (c AdminController) UnblockUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(TargetUserKey).(domain.User)
+		user, err := c.adminService.UnblockUser(requestActor(r), user)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.AdminUserDto{}.DomainToDto(user))
+	}
+}
+
+func (c AdminController) SetUserRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.Bind(r, requests.SetUserRoleRequest{}, domain.AdminAction{})
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		user := r.Context().Value(TargetUserKey).(domain.User)
+		user, err = c.adminService.SetUserRole(requestActor(r), user, req.Role)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.AdminUserDto{}.DomainToDto(user))
+	}
+}
+
+func (c AdminController) FindFarms() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pagination, err := requests.DecodePaginationQuery(r)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		filter, err := requests.DecodeFarmFilterQuery(r)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		farms, err := c.adminService.FindFarms(filter, pagination)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.FarmDto{}.DomainToDtoPaginatedCollection(farms, c.imageModelService))
+	}
+}
+
+func (c AdminController) FindOffers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pagination, err := requests.DecodePaginationQuery(r)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		filter, err := requests.DecodeOfferFilterQuery(r)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		offers, err := c.adminService.FindOffers(filter, pagination)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.OfferDto{}.DomainToDtoPaginatedCollection(offers, c.imageModelService))
+	}
+}
+
+func (c AdminController) UnpublishOffer() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.Bind(r, requests.UnpublishOfferRequest{}, domain.AdminAction{})
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		offer := r.Context().Value(OfferKey).(domain.Offer)
+		offer, err = c.adminService.UnpublishOffer(requestActor(r), offer, req.Reason)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.OfferDto{}.DomainToDto(offer, c.imageModelService))
+	}
+}
+
+func (c AdminController) FindOrders() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pagination, err := requests.DecodePaginationQuery(r)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		filter, err := requests.DecodeOrderFilterQuery(r)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		orders, err := c.adminService.FindOrders(filter, pagination)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.OrderDto{}.DomainToDtoPaginatedCollection(orders))
+	}
+}
+
+func (c AdminController) ForceOrderStatus() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.Bind(r, requests.ForceOrderStatusRequest{}, domain.AdminAction{})
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		order := r.Context().Value(OrderKey).(domain.Order)
+		order, err = c.adminService.ForceOrderStatus(requestActor(r), order, req.Status, req.Reason)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.OrderDto{}.DomainToDto(order))
+	}
+}
+
+func (c AdminController) FindPayments() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pagination, err := requests.DecodePaginationQuery(r)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		filter, err := requests.DecodePaymentFilterQuery(r)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		invoices, err := c.adminService.FindPayments(filter, pagination)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.InvoiceDto{}.DomainToDtoPage(invoices))
+	}
+}
+
+func (c AdminController) FindAuditEvents() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pagination, err := requests.DecodePaginationQuery(r)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		filter, err := requests.DecodeAuditEventFilterQuery(r)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		events, err := c.auditService.FindAll(filter, pagination)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.AuditEventDto{}.DomainToDtoPaginatedCollection(events))
+	}
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"boilerplate/internal/app"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/http/requests"
+	"boilerplate/internal/infra/http/resources"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+type ApiKeyController struct {
+	apiKeyService     app.ApiKeyService
+	farmService       app.FarmService
+	farmMemberService app.FarmMemberService
+}
+
+func NewApiKeyController(aks app.ApiKeyService, fs app.FarmService, fms app.FarmMemberService) ApiKeyController {
+	return ApiKeyController{
+		apiKeyService:     aks,
+		farmService:       fs,
+		farmMemberService: fms,
+	}
+}
+
+func (c ApiKeyController) Save() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		key, err := requests.Bind(r, requests.ApiKeyRequest{}, domain.ApiKey{})
+		if err != nil {
+			log.Printf("ApiKeyController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		if key.ExpiresDate != nil && key.ExpiresDate.Before(time.Now()) {
+			BadRequest(w, errors.New("expiration date is in the past"))
+			return
+		}
+
+		farm, err := c.farmService.FindById(key.FarmId)
+		if err != nil {
+			log.Printf("ApiKeyController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		allowed, err := c.farmMemberService.Can(farm.Id, u.Id, domain.FARM_PERMISSION_FARM_MANAGE)
+		if err != nil {
+			log.Printf("ApiKeyController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+		if !allowed {
+			err := errors.New("user can't manage the farm")
+			log.Printf("ApiKeyController: %s", err)
+			Forbidden(w, err)
+			return
+		}
+
+		key.User = u
+		key, err = c.apiKeyService.Save(key)
+		if err != nil {
+			log.Printf("ApiKeyController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Created(w, resources.ApiKeyDto{}.DomainToDto(key))
+	}
+}
+
+func (c ApiKeyController) FindByFarmId() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		farm := r.Context().Value(FarmKey).(domain.Farm)
+		keys, err := c.apiKeyService.FindAllByFarmId(farm.Id)
+		if err != nil {
+			log.Printf("ApiKeyController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.ApiKeyDto{}.DomainToDtoCollection(keys))
+	}
+}
+
+func (c ApiKeyController) Revoke() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Context().Value(ApiKeyKey).(domain.ApiKey)
+		key, err := c.apiKeyService.Revoke(key)
+		if err != nil {
+			log.Printf("ApiKeyController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.ApiKeyDto{}.DomainToDto(key))
+	}
+}
@@ -2,23 +2,31 @@ package controllers
 
 import (
 	"boilerplate/internal/app"
+	"boilerplate/internal/app/ratelimit"
 	"boilerplate/internal/domain"
 	"boilerplate/internal/infra/http/requests"
 	"boilerplate/internal/infra/http/resources"
 	"errors"
 	"log"
 	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/upper/db/v4"
 )
 
 type AuthController struct {
-	authService app.AuthService
-	userService app.UserService
+	authService      app.AuthService
+	userService      app.UserService
+	twoFactorService app.TwoFactorService
 }
 
-func NewAuthController(as app.AuthService, us app.UserService) AuthController {
+func NewAuthController(as app.AuthService, us app.UserService, tfs app.TwoFactorService) AuthController {
 	return AuthController{
-		authService: as,
-		userService: us,
+		authService:      as,
+		userService:      us,
+		twoFactorService: tfs,
 	}
 }
 
@@ -31,7 +39,7 @@ func (c AuthController) Register() http.HandlerFunc {
 			return
 		}
 
-		user, token, err := c.authService.Register(user)
+		user, tokens, err := c.authService.Register(user, clientSession(r))
 		if err != nil {
 			log.Printf("AuthController: %s", err)
 			BadRequest(w, err)
@@ -39,7 +47,7 @@ func (c AuthController) Register() http.HandlerFunc {
 		}
 
 		var authDto resources.AuthDto
-		Success(w, authDto.DomainToDto(token, user))
+		Success(w, authDto.DomainToDto(tokens, user))
 	}
 }
 
@@ -52,14 +60,18 @@ func (c AuthController) Login() http.HandlerFunc {
 			return
 		}
 
-		u, token, err := c.authService.Login(user)
+		u, tokens, err := c.authService.Login(user, clientSession(r))
 		if err != nil {
-			Unauthorized(w, err)
+			loginError(w, err)
+			return
+		}
+		if tokens.ChallengeToken != "" {
+			Success(w, resources.TwoFactorChallengeDto{}.DomainToDto(tokens))
 			return
 		}
 
 		var authDto resources.AuthDto
-		Success(w, authDto.DomainToDto(token, u))
+		Success(w, authDto.DomainToDto(tokens, u))
 	}
 }
 
@@ -72,14 +84,115 @@ func (c AuthController) LoginWithEmail() http.HandlerFunc {
 			return
 		}
 
-		u, token, err := c.authService.LoginWithEmail(user)
+		u, tokens, err := c.authService.LoginWithEmail(user, clientSession(r))
 		if err != nil {
-			Unauthorized(w, err)
+			loginError(w, err)
+			return
+		}
+		if tokens.ChallengeToken != "" {
+			Success(w, resources.TwoFactorChallengeDto{}.DomainToDto(tokens))
 			return
 		}
 
 		var authDto resources.AuthDto
-		Success(w, authDto.DomainToDto(token, u))
+		Success(w, authDto.DomainToDto(tokens, u))
+	}
+}
+
+func (c AuthController) CompleteTwoFactor() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.Bind(r, requests.TwoFactorLoginRequest{}, domain.TwoFactorLogin{})
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		u, tokens, err := c.authService.CompleteTwoFactor(req, clientSession(r))
+		if err != nil {
+			loginError(w, err)
+			return
+		}
+
+		var authDto resources.AuthDto
+		Success(w, authDto.DomainToDto(tokens, u))
+	}
+}
+
+func (c AuthController) EnrollTwoFactor() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		enrollment, err := c.twoFactorService.Enroll(user)
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.TwoFactorEnrollmentDto{}.DomainToDto(enrollment))
+	}
+}
+
+func (c AuthController) ConfirmTwoFactor() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.Bind(r, requests.TwoFactorCodeRequest{}, domain.CodeConfirmation{})
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		user := r.Context().Value(UserKey).(domain.User)
+		_, codes, err := c.twoFactorService.Confirm(user, req.Code)
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.RecoveryCodesDto{RecoveryCodes: codes})
+	}
+}
+
+func (c AuthController) RegenerateRecoveryCodes() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.Bind(r, requests.TwoFactorCodeRequest{}, domain.CodeConfirmation{})
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		user := r.Context().Value(UserKey).(domain.User)
+		codes, err := c.twoFactorService.RegenerateRecoveryCodes(user, req.Code)
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.RecoveryCodesDto{RecoveryCodes: codes})
+	}
+}
+
+func (c AuthController) DisableTwoFactor() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.Bind(r, requests.DisableTwoFactorRequest{}, domain.DisableTwoFactor{})
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		user := r.Context().Value(UserKey).(domain.User)
+		user, err = c.twoFactorService.Disable(user, req)
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.UserDto{}.DomainToDto(user))
 	}
 }
 
@@ -118,3 +231,148 @@ func (c AuthController) ChangePassword() http.HandlerFunc {
 		Ok(w)
 	}
 }
+
+func (c AuthController) ForgotPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := requests.Bind(r, requests.ForgotPasswordRequest{}, domain.User{})
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		err = c.authService.ForgotPassword(user)
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Ok(w)
+	}
+}
+
+func (c AuthController) ResetPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.Bind(r, requests.ResetPasswordRequest{}, domain.ResetPassword{})
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		err = c.authService.ResetPassword(req)
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Ok(w)
+	}
+}
+
+func (c AuthController) Refresh() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.Bind(r, requests.RefreshTokenRequest{}, domain.AuthTokens{})
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		u, tokens, err := c.authService.Refresh(req.RefreshToken)
+		if err != nil {
+			loginError(w, err)
+			return
+		}
+
+		var authDto resources.AuthDto
+		Success(w, authDto.DomainToDto(tokens, u))
+	}
+}
+
+func (c AuthController) FindSessions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sess := r.Context().Value(SessKey).(domain.Session)
+		sessions, err := c.authService.FindSessions(sess.UserId)
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.SessionDto{}.DomainToDtoCollection(sessions, sess))
+	}
+}
+
+func (c AuthController) RevokeSession() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sess := r.Context().Value(SessKey).(domain.Session)
+		id, err := uuid.Parse(chi.URLParam(r, "sessionId"))
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		err = c.authService.RevokeSession(sess.UserId, id)
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			if errors.Is(err, db.ErrNoMoreRows) {
+				NotFound(w, errors.New("session not found"))
+				return
+			}
+			InternalServerError(w, err)
+			return
+		}
+
+		noContent(w)
+	}
+}
+
+func (c AuthController) RevokeOtherSessions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sess := r.Context().Value(SessKey).(domain.Session)
+		err := c.authService.RevokeOtherSessions(sess)
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		noContent(w)
+	}
+}
+
+// loginError answers a failed sign in or refresh, a locked account, address
+// or session gets 429 with the time it unlocks.
+func loginError(w http.ResponseWriter, err error) {
+	var locked ratelimit.LockedError
+	if errors.As(err, &locked) {
+		TooManyRequests(w, err, locked.RetryAfter)
+		return
+	}
+	Unauthorized(w, err)
+}
+
+// clientSession describes the client a new session is started for.
+func clientSession(r *http.Request) domain.Session {
+	return domain.Session{
+		Device:    r.Header.Get("X-Device"),
+		Ip:        ClientIp(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+// requestActor describes who makes the request, for the audit log.
+func requestActor(r *http.Request) domain.Actor {
+	actor := domain.Actor{
+		Ip:        ClientIp(r),
+		RequestId: middleware.GetReqID(r.Context()),
+	}
+	if user, ok := r.Context().Value(UserKey).(domain.User); ok {
+		actor.UserId = user.Id
+	}
+	return actor
+}
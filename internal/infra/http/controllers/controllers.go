@@ -1,11 +1,16 @@
 package controllers
 
 import (
+	"boilerplate/internal/domain"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 /* should not use built-in type string as key for value;
@@ -20,6 +25,10 @@ type Userable interface {
 	GetUserId() uint64
 }
 
+type Farmable interface {
+	GetFarmId() uint64
+}
+
 var (
 	UserKey      = CtxKey{name: "user"}
 	SessKey      = CtxKey{name: "sess"}
@@ -30,12 +39,43 @@ var (
 	AddressKey   = CtxKey{name: "address"}
 	ImageKey     = CtxKey{name: "imageId"}
 	InvoiceKey   = CtxKey{name: "InvoiceId"}
+
+	OfferPriceScheduleKey = CtxKey{name: "scheduleId"}
+	PromotionKey          = CtxKey{name: "promotionId"}
+	ReviewKey             = CtxKey{name: "reviewId"}
+	OfferAlertKey         = CtxKey{name: "alertId"}
+	NotificationKey       = CtxKey{name: "notificationId"}
+	WebhookKey            = CtxKey{name: "webhookId"}
+	TargetUserKey         = CtxKey{name: "targetUserId"}
+	ApiKeyKey             = CtxKey{name: "apiKeyId"}
+	FarmMemberKey         = CtxKey{name: "memberId"}
+	FarmInvitationKey     = CtxKey{name: "invitationId"}
+	// AuthApiKeyKey holds the key the request is signed with, there is
+	// no session then.
+	AuthApiKeyKey = CtxKey{name: "authApiKey"}
 )
 
 func GetUserKey() CtxKey {
 	return UserKey
 }
 
+// ApiKeyAllowsFarm is true for user sessions and for API keys issued for
+// the farm.
+func ApiKeyAllowsFarm(r *http.Request, farmId uint64) bool {
+	key, ok := r.Context().Value(AuthApiKeyKey).(domain.ApiKey)
+	return !ok || key.FarmId == farmId
+}
+
+// ClientIp returns the address the request comes from. Behind a trusted
+// proxy it is the address of the client, see middlewares.RealIp.
+func ClientIp(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
 func GetPathValFromCtx[domainType Userable](ctx context.Context, key CtxKey) Userable {
 	return ctx.Value(key).(Userable)
 }
@@ -130,6 +170,15 @@ func encodeErrorBody(w http.ResponseWriter, err error) {
 	}
 }
 
+// TooManyRequests tells the client to slow down and when it may retry.
+func TooManyRequests(w http.ResponseWriter, err error, retryAfter time.Duration) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	w.WriteHeader(http.StatusTooManyRequests)
+
+	encodeErrorBody(w, err)
+}
+
 func Unauthorized(w http.ResponseWriter, err error) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusUnauthorized)
@@ -10,12 +10,14 @@ import (
 )
 
 type FarmController struct {
-	farmService app.FarmService
+	farmService       app.FarmService
+	imageModelService app.ImageModelService
 }
 
-func NewFarmController(fr app.FarmService) FarmController {
+func NewFarmController(fr app.FarmService, ims app.ImageModelService) FarmController {
 	return FarmController{
-		farmService: fr,
+		farmService:       fr,
+		imageModelService: ims,
 	}
 }
 
@@ -41,7 +43,7 @@ func (c FarmController) FindAllByCoords() http.HandlerFunc {
 			InternalServerError(w, err)
 			return
 		}
-		Success(w, resources.FarmDto{}.DomainToDtoPaginatedCollection(farms, resources.ImageMDto{}))
+		Success(w, resources.FarmDto{}.DomainToDtoPaginatedCollection(farms, c.imageModelService))
 	}
 }
 
@@ -63,14 +65,14 @@ func (c FarmController) Save() http.HandlerFunc {
 			return
 		}
 
-		Created(w, resources.FarmDto{}.DomainToDto(farm, resources.ImageMDto{}))
+		Created(w, resources.FarmDto{}.DomainToDto(farm, c.imageModelService))
 	}
 }
 
 func (c FarmController) FindById() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		f := r.Context().Value(FarmKey).(domain.Farm)
-		Success(w, resources.FarmDto{}.DomainToDto(f, resources.ImageMDto{}))
+		Success(w, resources.FarmDto{}.DomainToDto(f, c.imageModelService))
 	}
 }
 
@@ -93,14 +95,14 @@ func (c FarmController) Update() http.HandlerFunc {
 			return
 		}
 
-		Success(w, resources.FarmDto{}.DomainToDto(newfarm, resources.ImageMDto{}))
+		Success(w, resources.FarmDto{}.DomainToDto(newfarm, c.imageModelService))
 	}
 }
 
 func (c FarmController) Delete() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		f := r.Context().Value(FarmKey).(domain.Farm)
-		err := c.farmService.Delete(f.Id)
+		err := c.farmService.Delete(f, requestActor(r))
 		if err != nil {
 			log.Printf("FarmController: %s", err)
 			InternalServerError(w, err)
@@ -113,6 +115,28 @@ func (c FarmController) Delete() http.HandlerFunc {
 
 func (c FarmController) ListView() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		pagination, err := requests.DecodePaginationQuery(r)
+		if err != nil {
+			log.Printf("FarmController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		farms, err := c.farmService.FindAll(u, pagination)
+		if err != nil {
+			log.Printf("FarmController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.FarmDto{}.DomainToDtoPaginatedCollection(farms, c.imageModelService))
+	}
+}
+
+func (c FarmController) FindMine() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
 		pagination, err := requests.DecodePaginationQuery(r)
 		if err != nil {
 			log.Printf("FarmController: %s", err)
@@ -120,13 +144,13 @@ func (c FarmController) ListView() http.HandlerFunc {
 			return
 		}
 
-		farms, err := c.farmService.FindAll(pagination)
+		farms, err := c.farmService.FindAllByMember(u, pagination)
 		if err != nil {
 			log.Printf("FarmController: %s", err)
 			InternalServerError(w, err)
 			return
 		}
 
-		Success(w, resources.FarmDto{}.DomainToDtoPaginatedCollection(farms, resources.ImageMDto{}))
+		Success(w, resources.FarmDto{}.DomainToDtoPaginatedCollection(farms, c.imageModelService))
 	}
 }
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"boilerplate/internal/app"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/http/requests"
+	"boilerplate/internal/infra/http/resources"
+	"errors"
+	"log"
+	"net/http"
+)
+
+type FarmMemberController struct {
+	farmMemberService     app.FarmMemberService
+	farmInvitationService app.FarmInvitationService
+}
+
+func NewFarmMemberController(fms app.FarmMemberService, fis app.FarmInvitationService) FarmMemberController {
+	return FarmMemberController{
+		farmMemberService:     fms,
+		farmInvitationService: fis,
+	}
+}
+
+func (c FarmMemberController) FindMembers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		farm := r.Context().Value(FarmKey).(domain.Farm)
+		members, err := c.farmMemberService.FindAllByFarmId(farm.Id)
+		if err != nil {
+			log.Printf("FarmMemberController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.FarmMemberDto{}.DomainToDtoCollection(members))
+	}
+}
+
+func (c FarmMemberController) ChangeRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		farm := r.Context().Value(FarmKey).(domain.Farm)
+		member := r.Context().Value(FarmMemberKey).(domain.FarmMember)
+		if member.FarmId != farm.Id {
+			NotFound(w, errors.New("member not found"))
+			return
+		}
+
+		req, err := requests.Bind(r, requests.FarmMemberRoleRequest{}, domain.FarmMember{})
+		if err != nil {
+			log.Printf("FarmMemberController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		member, err = c.farmMemberService.ChangeRole(member, req.Role)
+		if err != nil {
+			log.Printf("FarmMemberController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.FarmMemberDto{}.DomainToDto(member))
+	}
+}
+
+func (c FarmMemberController) RemoveMember() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		farm := r.Context().Value(FarmKey).(domain.Farm)
+		member := r.Context().Value(FarmMemberKey).(domain.FarmMember)
+		if member.FarmId != farm.Id {
+			NotFound(w, errors.New("member not found"))
+			return
+		}
+
+		err := c.farmMemberService.Remove(member)
+		if err != nil {
+			log.Printf("FarmMemberController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Ok(w)
+	}
+}
+
+func (c FarmMemberController) Invite() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		farm := r.Context().Value(FarmKey).(domain.Farm)
+		invitation, err := requests.Bind(r, requests.FarmInvitationRequest{}, domain.FarmInvitation{})
+		if err != nil {
+			log.Printf("FarmMemberController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		invitation, err = c.farmInvitationService.Invite(farm, invitation, u)
+		if err != nil {
+			log.Printf("FarmMemberController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Created(w, resources.FarmInvitationDto{}.DomainToDto(invitation))
+	}
+}
+
+func (c FarmMemberController) FindInvitations() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		farm := r.Context().Value(FarmKey).(domain.Farm)
+		invitations, err := c.farmInvitationService.FindAllByFarmId(farm.Id)
+		if err != nil {
+			log.Printf("FarmMemberController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.FarmInvitationDto{}.DomainToDtoCollection(invitations))
+	}
+}
+
+func (c FarmMemberController) RevokeInvitation() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		farm := r.Context().Value(FarmKey).(domain.Farm)
+		invitation := r.Context().Value(FarmInvitationKey).(domain.FarmInvitation)
+		if invitation.FarmId != farm.Id {
+			NotFound(w, errors.New("invitation not found"))
+			return
+		}
+
+		invitation, err := c.farmInvitationService.Revoke(invitation)
+		if err != nil {
+			log.Printf("FarmMemberController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.FarmInvitationDto{}.DomainToDto(invitation))
+	}
+}
+
+func (c FarmMemberController) FindMyInvitations() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		invitations, err := c.farmInvitationService.FindAllForUser(u)
+		if err != nil {
+			log.Printf("FarmMemberController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.FarmInvitationDto{}.DomainToDtoCollection(invitations))
+	}
+}
+
+func (c FarmMemberController) AcceptInvitation() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		invitation := r.Context().Value(FarmInvitationKey).(domain.FarmInvitation)
+		member, err := c.farmInvitationService.Accept(invitation, u)
+		if err != nil {
+			log.Printf("FarmMemberController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Created(w, resources.FarmMemberDto{}.DomainToDto(member))
+	}
+}
+
+func (c FarmMemberController) DeclineInvitation() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		invitation := r.Context().Value(FarmInvitationKey).(domain.FarmInvitation)
+		invitation, err := c.farmInvitationService.Decline(invitation, u)
+		if err != nil {
+			log.Printf("FarmMemberController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.FarmInvitationDto{}.DomainToDto(invitation))
+	}
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"boilerplate/internal/app"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/http/requests"
+	"boilerplate/internal/infra/http/resources"
+	"log"
+	"net/http"
+)
+
+type FavoriteController struct {
+	favoriteService   app.FavoriteService
+	imageModelService app.ImageModelService
+}
+
+func NewFavoriteController(fs app.FavoriteService, ims app.ImageModelService) FavoriteController {
+	return FavoriteController{
+		favoriteService:   fs,
+		imageModelService: ims,
+	}
+}
+
+func (c FavoriteController) FindOffers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		pagination, err := requests.DecodePaginationQuery(r)
+		if err != nil {
+			log.Printf("FavoriteController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		offers, err := c.favoriteService.FindOffers(u, pagination)
+		if err != nil {
+			log.Printf("FavoriteController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.OfferDto{}.DomainToDtoPaginatedCollection(offers, c.imageModelService))
+	}
+}
+
+func (c FavoriteController) AddOffer() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		offer := r.Context().Value(OfferKey).(domain.Offer)
+		err := c.favoriteService.AddOffer(u, offer)
+		if err != nil {
+			log.Printf("FavoriteController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		offer.IsFavorite = true
+		Success(w, resources.OfferDto{}.DomainToDto(offer, c.imageModelService))
+	}
+}
+
+func (c FavoriteController) RemoveOffer() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		offer := r.Context().Value(OfferKey).(domain.Offer)
+		err := c.favoriteService.RemoveOffer(u, offer)
+		if err != nil {
+			log.Printf("FavoriteController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Ok(w)
+	}
+}
+
+func (c FavoriteController) FindFarms() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		pagination, err := requests.DecodePaginationQuery(r)
+		if err != nil {
+			log.Printf("FavoriteController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		farms, err := c.favoriteService.FindFollowedFarms(u, pagination)
+		if err != nil {
+			log.Printf("FavoriteController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.FarmDto{}.DomainToDtoPaginatedCollection(farms, c.imageModelService))
+	}
+}
+
+func (c FavoriteController) FollowFarm() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		farm := r.Context().Value(FarmKey).(domain.Farm)
+		err := c.favoriteService.FollowFarm(u, farm)
+		if err != nil {
+			log.Printf("FavoriteController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		farm.IsFollowed = true
+		Success(w, resources.FarmDto{}.DomainToDto(farm, c.imageModelService))
+	}
+}
+
+func (c FavoriteController) UnfollowFarm() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		farm := r.Context().Value(FarmKey).(domain.Farm)
+		err := c.favoriteService.UnfollowFarm(u, farm)
+		if err != nil {
+			log.Printf("FavoriteController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Ok(w)
+	}
+}
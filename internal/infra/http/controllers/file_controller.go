@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"boilerplate/internal/filesystem"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type FileController struct {
+	imageStorage filesystem.ImageStorageService
+}
+
+func NewFileController(is filesystem.ImageStorageService) FileController {
+	return FileController{
+		imageStorage: is,
+	}
+}
+
+// Serve proxies the stored files, whatever the storage is.
+func (c FileController) Serve() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := chi.URLParam(r, "*")
+		content, err := c.imageStorage.ReadImage(r.Context(), key)
+		if errors.Is(err, filesystem.ErrImageNotFound) || errors.Is(err, filesystem.ErrInvalidKey) {
+			NotFound(w, errors.New("file not found"))
+			return
+		} else if err != nil {
+			log.Printf("FileController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+		defer content.Close()
+
+		if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		// The keys are never reused for other content.
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+
+		// Both the local files and the S3 objects can seek, which gives
+		// the clients ranges and conditional requests.
+		if rs, ok := content.(io.ReadSeeker); ok {
+			http.ServeContent(w, r, key, time.Time{}, rs)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, err = io.Copy(w, content)
+		if err != nil {
+			log.Printf("FileController: %s", err)
+		}
+	}
+}
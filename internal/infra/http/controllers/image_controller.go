@@ -6,18 +6,29 @@ import (
 	"boilerplate/internal/infra/http/requests"
 	"boilerplate/internal/infra/http/resources"
 	"errors"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
+
+	"github.com/upper/db/v4"
 )
 
 type ImageModelController struct {
 	imageModelService app.ImageModelService
+	offerService      app.OfferService
+	reviewService     app.ReviewService
+	farmMemberService app.FarmMemberService
+	maxImageSize      int64
 }
 
-func NewImageModelController(ir app.ImageModelService) ImageModelController {
+func NewImageModelController(ir app.ImageModelService, os app.OfferService, rs app.ReviewService, fms app.FarmMemberService, maxImageSize int64) ImageModelController {
 	return ImageModelController{
 		imageModelService: ir,
+		offerService:      os,
+		reviewService:     rs,
+		farmMemberService: fms,
+		maxImageSize:      maxImageSize,
 	}
 }
 
@@ -45,7 +56,7 @@ func (c ImageModelController) FindAll() http.HandlerFunc {
 			return
 		}
 
-		Created(w, resources.ImageMDto{}.DomainToDtoMass(images))
+		Created(w, resources.ImageMDto{}.DomainToDtoMass(images, c.imageModelService))
 	}
 }
 
@@ -65,14 +76,66 @@ func (c ImageModelController) Save() http.HandlerFunc {
 			return
 		}
 
-		Created(w, resources.ImageMDto{}.DomainToDto(imageM))
+		Created(w, resources.ImageMDto{}.DomainToDto(imageM, c.imageModelService))
+	}
+}
+
+// Upload takes the image as a multipart/form-data file, the entity it
+// belongs to goes in the query. Only a farm, an offer or a review the user
+// can manage takes images.
+func (c ImageModelController) Upload() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		entity := r.URL.Query().Get("entity")
+		if entity == "" {
+			BadRequest(w, errors.New("parameter entity is required"))
+			return
+		}
+		entityId, err := strconv.ParseUint(r.URL.Query().Get("entity_id"), 10, 64)
+		if err != nil {
+			BadRequest(w, errors.New("parameter entity_id is required"))
+			return
+		}
+
+		allowed, err := c.canManageEntity(u, entity, entityId)
+		if errors.Is(err, errUnknownImageEntity) {
+			BadRequest(w, err)
+			return
+		} else if errors.Is(err, db.ErrNoMoreRows) {
+			NotFound(w, errors.New("entity not found"))
+			return
+		} else if err != nil {
+			log.Printf("ImageModelController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+		if !allowed {
+			Forbidden(w, errors.New("you have no permission to perform this action"))
+			return
+		}
+
+		upload, err := requests.BindImageUpload(w, r, c.maxImageSize)
+		if err != nil {
+			log.Printf("ImageModelController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		image, err := c.imageModelService.Upload(domain.Image{Entity: entity, EntityId: entityId}, upload)
+		if err != nil {
+			log.Printf("ImageModelController: %s", err)
+			uploadError(w, err)
+			return
+		}
+
+		Created(w, resources.ImageMDto{}.DomainToDto(image, c.imageModelService))
 	}
 }
 
 func (c ImageModelController) FindById() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		image := r.Context().Value(ImageKey).(domain.Image)
-		Success(w, resources.ImageMDto{}.DomainToDto(image))
+		Success(w, resources.ImageMDto{}.DomainToDto(image, c.imageModelService))
 	}
 }
 
@@ -90,3 +153,40 @@ func (c ImageModelController) Delete() http.HandlerFunc {
 		Ok(w)
 	}
 }
+
+var errUnknownImageEntity = errors.New("images can be uploaded only for farms, offers and reviews")
+
+// canManageEntity reports whether the user may add images to the entity:
+// farms and offers go by the role at the farm, reviews by their author.
+func (c ImageModelController) canManageEntity(u domain.User, entity string, id uint64) (bool, error) {
+	switch entity {
+	case "farms":
+		return c.farmMemberService.Can(id, u.Id, domain.FARM_PERMISSION_FARM_MANAGE)
+	case "offers":
+		offer, err := c.offerService.FindById(id)
+		if err != nil {
+			return false, err
+		}
+		return c.farmMemberService.Can(offer.GetFarmId(), u.Id, domain.FARM_PERMISSION_OFFERS_MANAGE)
+	case "reviews":
+		review, err := c.reviewService.Find(id)
+		if err != nil {
+			return false, err
+		}
+		return review.(domain.Review).GetUserId() == u.Id, nil
+	default:
+		return false, errUnknownImageEntity
+	}
+}
+
+// uploadError tells the faults of the client apart, they show up only
+// once the storage reads the stream.
+func uploadError(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.Is(err, requests.ErrImageTooLarge) || errors.Is(err, domain.ErrInvalidImage) ||
+		errors.As(err, &maxBytesErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		BadRequest(w, err)
+		return
+	}
+	InternalServerError(w, err)
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"boilerplate/internal/app"
+	"boilerplate/internal/domain"
 	"boilerplate/internal/infra/monobank"
 	"encoding/json"
 	"log"
@@ -51,6 +52,20 @@ func (c MonobankController) CreateInvoice() http.HandlerFunc {
 	}
 }
 
+func (c MonobankController) CreateOrderInvoice() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		order := r.Context().Value(OrderKey).(domain.Order)
+		response, err := c.monobankService.CreateOrderInvoice(order)
+		if err != nil {
+			log.Printf("MonobankController CreateOrderInvoice: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, response)
+	}
+}
+
 func (c MonobankController) GetInvoiceData() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		invoiceId, err := strconv.ParseUint(chi.URLParam(r, "invoiceId"), 10, 64)
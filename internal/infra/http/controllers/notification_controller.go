@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"boilerplate/internal/app/notification"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/http/requests"
+	"boilerplate/internal/infra/http/resources"
+	"log"
+	"net/http"
+)
+
+type NotificationController struct {
+	notificationService notification.Service
+}
+
+func NewNotificationController(ns notification.Service) NotificationController {
+	return NotificationController{
+		notificationService: ns,
+	}
+}
+
+func (c NotificationController) FindAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		pagination, err := requests.DecodePaginationQuery(r)
+		if err != nil {
+			log.Printf("NotificationController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		unreadOnly := r.URL.Query().Get("unread") == "true"
+		notifications, err := c.notificationService.FindAllByUserId(u.Id, unreadOnly, pagination)
+		if err != nil {
+			log.Printf("NotificationController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.NotificationDto{}.DomainToDtoPaginatedCollection(notifications))
+	}
+}
+
+func (c NotificationController) CountUnread() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		count, err := c.notificationService.CountUnread(u.Id)
+		if err != nil {
+			log.Printf("NotificationController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.UnreadNotificationsDto{Unread: count})
+	}
+}
+
+func (c NotificationController) MarkAsRead() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n := r.Context().Value(NotificationKey).(domain.Notification)
+		n, err := c.notificationService.MarkAsRead(n)
+		if err != nil {
+			log.Printf("NotificationController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.NotificationDto{}.DomainToDto(n))
+	}
+}
+
+func (c NotificationController) MarkAllAsRead() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		err := c.notificationService.MarkAllAsRead(u.Id)
+		if err != nil {
+			log.Printf("NotificationController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Ok(w)
+	}
+}
+
+func (c NotificationController) FindPreferences() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		preferences, err := c.notificationService.FindPreferences(u.Id)
+		if err != nil {
+			log.Printf("NotificationController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.NotificationPreferenceDto{}.DomainToDtoCollection(preferences))
+	}
+}
+
+func (c NotificationController) SavePreferences() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		preferences, err := requests.Bind(r, requests.NotificationPreferencesRequest{}, []domain.NotificationPreference{})
+		if err != nil {
+			log.Printf("NotificationController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		preferences, err = c.notificationService.SavePreferences(u.Id, preferences)
+		if err != nil {
+			log.Printf("NotificationController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.NotificationPreferenceDto{}.DomainToDtoCollection(preferences))
+	}
+}
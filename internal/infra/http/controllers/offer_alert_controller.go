@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"boilerplate/internal/app"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/http/requests"
+	"boilerplate/internal/infra/http/resources"
+	"log"
+	"net/http"
+)
+
+type OfferAlertController struct {
+	offerAlertService app.OfferAlertService
+	offerService      app.OfferService
+}
+
+func NewOfferAlertController(oas app.OfferAlertService, os app.OfferService) OfferAlertController {
+	return OfferAlertController{
+		offerAlertService: oas,
+		offerService:      os,
+	}
+}
+
+func (c OfferAlertController) Subscribe() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		alert, err := requests.Bind(r, requests.OfferAlertRequest{}, domain.OfferAlert{})
+		if err != nil {
+			log.Printf("OfferAlertController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		_, err = c.offerService.FindById(alert.OfferId)
+		if err != nil {
+			log.Printf("OfferAlertController: %s", err)
+			NotFound(w, err)
+			return
+		}
+
+		alert.User = u
+		alert, err = c.offerAlertService.Subscribe(alert)
+		if err != nil {
+			log.Printf("OfferAlertController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Created(w, resources.OfferAlertDto{}.DomainToDto(alert))
+	}
+}
+
+func (c OfferAlertController) FindAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		alerts, err := c.offerAlertService.FindAllByUserId(u.Id)
+		if err != nil {
+			log.Printf("OfferAlertController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.OfferAlertDto{}.DomainToDtoCollection(alerts))
+	}
+}
+
+func (c OfferAlertController) Unsubscribe() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		alert := r.Context().Value(OfferAlertKey).(domain.OfferAlert)
+		err := c.offerAlertService.Unsubscribe(alert)
+		if err != nil {
+			log.Printf("OfferAlertController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Ok(w)
+	}
+}
@@ -17,13 +17,19 @@ type OfferController struct {
 	offerService      app.OfferService
 	farmService       app.FarmService
 	imageModelService app.ImageModelService
+	favoriteService   app.FavoriteService
+	farmMemberService app.FarmMemberService
+	maxImageSize      int64
 }
 
-func NewOfferController(os app.OfferService, fr app.FarmService, ims app.ImageModelService) OfferController {
+func NewOfferController(os app.OfferService, fr app.FarmService, ims app.ImageModelService, fs app.FavoriteService, fms app.FarmMemberService, maxImageSize int64) OfferController {
 	return OfferController{
 		offerService:      os,
 		farmService:       fr,
 		imageModelService: ims,
+		favoriteService:   fs,
+		farmMemberService: fms,
+		maxImageSize:      maxImageSize,
 	}
 }
 
@@ -36,7 +42,6 @@ func (c OfferController) Save() http.HandlerFunc {
 			BadRequest(w, err)
 			return
 		}
-		offer.User.Id = u.Id
 		offer.Status = true
 
 		farm, err := c.farmService.FindById(offer.Farm.Id)
@@ -45,13 +50,28 @@ func (c OfferController) Save() http.HandlerFunc {
 			BadRequest(w, err)
 			return
 		}
-		if farm.GetUserId() != u.Id {
-			err := errors.New("user is not a farm owner")
+		allowed, err := c.farmMemberService.Can(farm.Id, u.Id, domain.FARM_PERMISSION_OFFERS_MANAGE)
+		if err != nil {
+			log.Printf("OfferController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+		if !allowed {
+			err := errors.New("user can't manage offers of the farm")
 			log.Printf("OfferController: %s", err)
 			BadRequest(w, err)
 			return
 		}
+		if !ApiKeyAllowsFarm(r, farm.Id) {
+			err := errors.New("api key has no access to this farm")
+			log.Printf("OfferController: %s", err)
+			Forbidden(w, err)
+			return
+		}
 
+		// Offers belong to the owner of the farm whoever of the staff
+		// puts them up.
+		offer.User.Id = farm.User.Id
 		offer.Farm = farm
 		offer, err = c.offerService.Save(offer)
 		if err != nil {
@@ -66,6 +86,7 @@ func (c OfferController) Save() http.HandlerFunc {
 
 func (c OfferController) FindByFarmId() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
 		farmId, err := strconv.ParseUint(chi.URLParam(r, "farmId"), 10, 64)
 		if err != nil {
 			log.Printf("OfferController: %s", err)
@@ -86,13 +107,17 @@ func (c OfferController) FindByFarmId() http.HandlerFunc {
 			BadRequest(w, err)
 			return
 		}
+
+		offers.Items = c.favoriteService.MarkFavorites(u, offers.Items)
 		Success(w, resources.OfferDto{}.DomainToDtoPaginatedCollection(offers, c.imageModelService))
 	}
 }
 
 func (c OfferController) FindById() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
 		o := r.Context().Value(OfferKey).(domain.Offer)
+		o = c.favoriteService.MarkFavorites(u, []domain.Offer{o})[0]
 		Success(w, resources.OfferDto{}.DomainToDto(o, c.imageModelService))
 	}
 }
@@ -107,18 +132,21 @@ func (c OfferController) ListView() http.HandlerFunc {
 			return
 		}
 
+		owner := u
 		countStr := r.URL.Query().Get("all")
 		if countStr == "true" {
-			u.Id = 0
+			owner.Id = 0
 		}
 
-		offers, err := c.offerService.FindAll(u, pagination)
+		offers, err := c.offerService.FindAll(owner, pagination)
 		if err != nil {
 			log.Printf("OfferController: %s", err)
 			InternalServerError(w, err)
 			return
 		}
 
+		offers.Items = c.favoriteService.MarkFavorites(u, offers.Items)
+
 		Success(w, resources.OfferDto{}.DomainToDtoPaginatedCollection(offers, c.imageModelService))
 	}
 }
@@ -133,7 +161,7 @@ func (c OfferController) Update() http.HandlerFunc {
 			return
 		}
 
-		newOffer, err := c.offerService.Update(o, offer)
+		newOffer, err := c.offerService.Update(o, offer, requestActor(r))
 		if err != nil {
 			log.Printf("OfferController: %s", err)
 			InternalServerError(w, err)
@@ -158,26 +186,66 @@ func (c OfferController) Delete() http.HandlerFunc {
 	}
 }
 
+// AddAdditionalImage takes either a multipart/form-data file or the
+// base64 JSON of an ImageRequest.
 func (c OfferController) AddAdditionalImage() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		offer := r.Context().Value(OfferKey).(domain.Offer)
-		image, err := requests.Bind(r, requests.ImageRequest{}, domain.Image{})
+		image := domain.Image{Entity: "offers", EntityId: offer.Id}
+
+		if requests.IsMultipart(r) {
+			upload, err := requests.BindImageUpload(w, r, c.maxImageSize)
+			if err != nil {
+				log.Printf("OfferController: %s", err)
+				BadRequest(w, err)
+				return
+			}
+
+			_, err = c.imageModelService.Upload(image, upload)
+			if err != nil {
+				log.Printf("OfferController: %s", err)
+				uploadError(w, err)
+				return
+			}
+		} else {
+			req, err := requests.Bind(r, requests.ImageRequest{}, domain.Image{})
+			if err != nil {
+				log.Printf("OfferController: %s", err)
+				BadRequest(w, err)
+				return
+			}
+
+			image.Name, image.Data = req.Name, req.Data
+			_, err = c.imageModelService.Save(image)
+			if err != nil {
+				log.Printf("OfferController: %s", err)
+				uploadError(w, err)
+				return
+			}
+		}
+
+		Created(w, resources.OfferDto{}.DomainToDto(offer, c.imageModelService))
+	}
+}
+
+func (c OfferController) UploadCover() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		offer := r.Context().Value(OfferKey).(domain.Offer)
+		upload, err := requests.BindImageUpload(w, r, c.maxImageSize)
 		if err != nil {
 			log.Printf("OfferController: %s", err)
 			BadRequest(w, err)
 			return
 		}
 
-		image.Entity = "offers"
-		image.EntityId = offer.Id
-		image, err = c.imageModelService.Save(image)
+		offer, err = c.offerService.UpdateCover(offer, upload)
 		if err != nil {
 			log.Printf("OfferController: %s", err)
-			InternalServerError(w, err)
+			uploadError(w, err)
 			return
 		}
 
-		Created(w, resources.OfferDto{}.DomainToDto(offer, c.imageModelService))
+		Success(w, resources.OfferDto{}.DomainToDto(offer, c.imageModelService))
 	}
 }
 
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"boilerplate/internal/app"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/http/requests"
+	"boilerplate/internal/infra/http/resources"
+	"errors"
+	"log"
+	"net/http"
+)
+
+type OfferPriceController struct {
+	offerPriceService app.OfferPriceService
+}
+
+func NewOfferPriceController(ops app.OfferPriceService) OfferPriceController {
+	return OfferPriceController{
+		offerPriceService: ops,
+	}
+}
+
+func (c OfferPriceController) FindHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		offer := r.Context().Value(OfferKey).(domain.Offer)
+		pagination, err := requests.DecodePaginationQuery(r)
+		if err != nil {
+			log.Printf("OfferPriceController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		prices, err := c.offerPriceService.FindHistory(offer.Id, pagination)
+		if err != nil {
+			log.Printf("OfferPriceController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.OfferPriceDto{}.DomainToDtoPaginatedCollection(prices))
+	}
+}
+
+func (c OfferPriceController) FindSchedules() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		offer := r.Context().Value(OfferKey).(domain.Offer)
+		schedules, err := c.offerPriceService.FindSchedules(offer.Id)
+		if err != nil {
+			log.Printf("OfferPriceController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.OfferPriceScheduleDto{}.DomainToDtoCollection(schedules))
+	}
+}
+
+func (c OfferPriceController) Schedule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		offer := r.Context().Value(OfferKey).(domain.Offer)
+		schedule, err := requests.Bind(r, requests.OfferPriceScheduleRequest{}, domain.OfferPriceSchedule{})
+		if err != nil {
+			log.Printf("OfferPriceController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		schedule.OfferId = offer.Id
+		schedule, err = c.offerPriceService.Schedule(schedule)
+		if err != nil {
+			log.Printf("OfferPriceController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Created(w, resources.OfferPriceScheduleDto{}.DomainToDto(schedule))
+	}
+}
+
+func (c OfferPriceController) CancelSchedule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		offer := r.Context().Value(OfferKey).(domain.Offer)
+		schedule := r.Context().Value(OfferPriceScheduleKey).(domain.OfferPriceSchedule)
+		if schedule.OfferId != offer.Id {
+			err := errors.New("price schedule does not belong to this offer")
+			log.Printf("OfferPriceController: %s", err)
+			NotFound(w, err)
+			return
+		}
+
+		schedule, err := c.offerPriceService.CancelSchedule(schedule, requestActor(r))
+		if err != nil {
+			log.Printf("OfferPriceController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.OfferPriceScheduleDto{}.DomainToDto(schedule))
+	}
+}
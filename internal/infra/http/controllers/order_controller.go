@@ -17,13 +17,15 @@ type OrderController struct {
 	orderService      app.OrderService
 	orderItemService  app.OrderItemsService
 	imageModelService app.ImageModelService
+	messageService    app.OrderMessageService
 }
 
-func NewOrderController(os app.OrderService, ois app.OrderItemsService, ims app.ImageModelService) OrderController {
+func NewOrderController(os app.OrderService, ois app.OrderItemsService, ims app.ImageModelService, oms app.OrderMessageService) OrderController {
 	return OrderController{
 		orderService:      os,
 		orderItemService:  ois,
 		imageModelService: ims,
+		messageService:    oms,
 	}
 }
 
@@ -51,6 +53,7 @@ func (c OrderController) Save() http.HandlerFunc {
 
 func (c OrderController) FindById() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
 		order := r.Context().Value(OrderKey).(domain.Order)
 		orderItems, err := c.orderItemService.FindAll(order.Id)
 		if err != nil {
@@ -59,6 +62,14 @@ func (c OrderController) FindById() http.HandlerFunc {
 			return
 		}
 
+		orders, err := c.messageService.CountUnread([]domain.Order{order}, u)
+		if err != nil {
+			log.Printf("OrderController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		order = orders[0]
 		order.OrderItems = orderItems
 		Success(w, resources.OrderDtoWithOrderItems{}.DomainToDto(order, c.imageModelService))
 	}
@@ -81,6 +92,13 @@ func (c OrderController) FindAllByUserId() http.HandlerFunc {
 			return
 		}
 
+		orders.Items, err = c.messageService.CountUnread(orders.Items, u)
+		if err != nil {
+			log.Printf("OrderController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
 		Success(w, resources.OrderDtoWithOrderItems{}.DomainToDtoPaginatedCollection(orders, c.imageModelService))
 	}
 }
@@ -120,6 +138,35 @@ func (c OrderController) Delete() http.HandlerFunc {
 	}
 }
 
+func (c OrderController) FindByFarmId() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		farm := r.Context().Value(FarmKey).(domain.Farm)
+		pagination, err := requests.DecodePaginationQuery(r)
+		if err != nil {
+			log.Printf("OrderController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		orders, err := c.orderService.FindAllByFarmId(farm.Id, pagination)
+		if err != nil {
+			log.Printf("OrderController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		orders.Items, err = c.messageService.CountUnread(orders.Items, u)
+		if err != nil {
+			log.Printf("OrderController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.OrderDtoWithOrderItems{}.DomainToDtoPaginatedCollection(orders, c.imageModelService))
+	}
+}
+
 func (c OrderController) FindByFarmUserId() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		u := r.Context().Value(UserKey).(domain.User)
@@ -137,6 +184,13 @@ func (c OrderController) FindByFarmUserId() http.HandlerFunc {
 			return
 		}
 
+		orders.Items, err = c.messageService.CountUnread(orders.Items, u)
+		if err != nil {
+			log.Printf("OrderController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
 		Success(w, resources.OrderDtoWithOrderItems{}.DomainToDtoPaginatedCollection(orders, c.imageModelService))
 	}
 }
@@ -152,8 +206,7 @@ func (c OrderController) SetOrderStatusAsReceiver() http.HandlerFunc {
 
 		orderInstance := r.Context().Value(OrderKey).(domain.Order)
 		if orderInstance.IsReceiverStatus(orderStatus.Status) {
-			orderInstance.Status = orderStatus.Status
-			order, err := c.orderService.NoRequestUpdate(orderInstance)
+			order, err := c.orderService.ChangeStatus(orderInstance, orderStatus.Status, requestActor(r))
 			if err != nil {
 				log.Printf("OrderController: %s", err)
 				InternalServerError(w, err)
@@ -181,9 +234,29 @@ func (c OrderController) SetOrderStatusAsFarmer() http.HandlerFunc {
 		}
 
 		orderInstance := r.Context().Value(OrderKey).(domain.Order)
+		farm := r.Context().Value(FarmKey).(domain.Farm)
+		orderItems, err := c.orderItemService.FindAll(orderInstance.Id)
+		if err != nil {
+			log.Printf("OrderController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+		hasFarmItems := false
+		for _, item := range orderItems {
+			if item.Farm.Id == farm.Id {
+				hasFarmItems = true
+				break
+			}
+		}
+		if !hasFarmItems {
+			err = errors.New("order has no items of the farm")
+			log.Printf("OrderController: %s", err)
+			Forbidden(w, err)
+			return
+		}
+
 		if orderInstance.IsFarmerStatus(orderStatus.Status) {
-			orderInstance.Status = orderStatus.Status
-			order, err := c.orderService.NoRequestUpdate(orderInstance)
+			order, err := c.orderService.ChangeStatus(orderInstance, orderStatus.Status, requestActor(r))
 			if err != nil {
 				log.Printf("OrderController: %s", err)
 				InternalServerError(w, err)
@@ -232,8 +305,11 @@ func (c OrderController) SubmitSplitedOrder() http.HandlerFunc {
 			return
 		}
 
-		submitedOrder, err := c.orderService.SubmitSplitedOrder(order, farmId)
-		if err != nil {
+		submitedOrder, err := c.orderService.SubmitSplitedOrder(order, farmId, requestActor(r))
+		if errors.Is(err, domain.ErrPromotionUnavailable) {
+			BadRequest(w, err)
+			return
+		} else if err != nil {
 			log.Printf("OrderController: %s", err)
 			InternalServerError(w, err)
 			return
@@ -285,3 +361,17 @@ func (c OrderController) GetFarmerOrdersPercentage() http.HandlerFunc {
 		Success(w, resources.OrdersDtoWithPercentage{}.DomainToDto(orders, total))
 	}
 }
+
+func (c OrderController) MarkPercentageAsPaid() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		order := r.Context().Value(OrderKey).(domain.Order)
+		order, err := c.orderService.MarkPercentageAsPaid(order)
+		if err != nil {
+			log.Printf("OrderController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.OrderDto{}.DomainToDto(order))
+	}
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"boilerplate/internal/app"
+	"boilerplate/internal/app/events"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/http/resources"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// heartbeatInterval keeps idle streams open through proxies and lets the
+// server notice clients which have gone away. The session is checked again
+// on every heartbeat.
+const heartbeatInterval = 30 * time.Second
+
+type OrderEventController struct {
+	eventBroker events.Broker
+	authService app.AuthService
+	userService app.UserService
+	upgrader    websocket.Upgrader
+}
+
+func NewOrderEventController(eb events.Broker, as app.AuthService, us app.UserService) OrderEventController {
+	return OrderEventController{
+		eventBroker: eb,
+		authService: as,
+		userService: us,
+		upgrader: websocket.Upgrader{
+			// The stream is authorized by the token, not by cookies,
+			// so connections from any origin are safe to accept.
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// Stream pushes the order events of the user as Server-Sent Events.
+func (c OrderEventController) Stream() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			err := errors.New("streaming is not supported")
+			log.Printf("OrderEventController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		subscription := c.eventBroker.Subscribe(u.Id)
+		defer subscription.Close()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-heartbeat.C:
+				if !c.authorized(r) {
+					return
+				}
+				_, err := fmt.Fprint(w, ": ping\n\n")
+				if err != nil {
+					return
+				}
+				flusher.Flush()
+			case event, ok := <-subscription.Events:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(resources.OrderEventDto{}.DomainToDto(event))
+				if err != nil {
+					log.Printf("OrderEventController: %s", err)
+					continue
+				}
+				_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+				if err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// WebSocket pushes the order events of the user as JSON text messages.
+// Messages from the client are ignored.
+func (c OrderEventController) WebSocket() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		conn, err := c.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// Upgrade has already replied to the client
+			log.Printf("OrderEventController: %s", err)
+			return
+		}
+		defer conn.Close()
+
+		subscription := c.eventBroker.Subscribe(u.Id)
+		defer subscription.Close()
+
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			for {
+				if _, _, err := conn.NextReader(); err != nil {
+					return
+				}
+			}
+		}()
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(time.Second))
+				return
+			case <-closed:
+				return
+			case <-heartbeat.C:
+				if !c.authorized(r) {
+					_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "session has ended"), time.Now().Add(time.Second))
+					return
+				}
+				err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(heartbeatInterval/2))
+				if err != nil {
+					return
+				}
+			case event, ok := <-subscription.Events:
+				if !ok {
+					return
+				}
+				_ = conn.SetWriteDeadline(time.Now().Add(heartbeatInterval / 2))
+				err = conn.WriteJSON(resources.OrderEventDto{}.DomainToDto(event))
+				if err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// authorized repeats the checks of AuthMiddleware. A stream outlives the
+// request that opened it, so a session logged out or a user blocked or
+// deleted since then must stop receiving events.
+func (c OrderEventController) authorized(r *http.Request) bool {
+	sess, ok := r.Context().Value(SessKey).(domain.Session)
+	if !ok {
+		return false
+	}
+
+	err := c.authService.Check(sess)
+	if err != nil {
+		return false
+	}
+
+	user, err := c.userService.FindById(sess.UserId)
+	if err != nil {
+		return false
+	}
+	return !user.IsBlocked() && user.DeletedDate == nil
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"boilerplate/internal/app"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/http/requests"
+	"boilerplate/internal/infra/http/resources"
+	"log"
+	"net/http"
+)
+
+type OrderMessageController struct {
+	messageService    app.OrderMessageService
+	imageModelService app.ImageModelService
+}
+
+func NewOrderMessageController(oms app.OrderMessageService, ims app.ImageModelService) OrderMessageController {
+	return OrderMessageController{
+		messageService:    oms,
+		imageModelService: ims,
+	}
+}
+
+func (c OrderMessageController) Send() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		order := r.Context().Value(OrderKey).(domain.Order)
+		message, err := requests.Bind(r, requests.OrderMessageRequest{}, domain.OrderMessage{})
+		if err != nil {
+			log.Printf("OrderMessageController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		message.User = u
+		message, err = c.messageService.Send(order, message)
+		if err != nil {
+			log.Printf("OrderMessageController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Created(w, resources.OrderMessageDto{}.DomainToDto(message, c.imageModelService))
+	}
+}
+
+func (c OrderMessageController) FindAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		order := r.Context().Value(OrderKey).(domain.Order)
+		pagination, err := requests.DecodePaginationQuery(r)
+		if err != nil {
+			log.Printf("OrderMessageController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		messages, err := c.messageService.FindAll(order, u, pagination)
+		if err != nil {
+			log.Printf("OrderMessageController: %s", err)
+			Forbidden(w, err)
+			return
+		}
+
+		Success(w, resources.OrderMessageDto{}.DomainToDtoPaginatedCollection(messages, c.imageModelService))
+	}
+}
+
+func (c OrderMessageController) MarkAsRead() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		order := r.Context().Value(OrderKey).(domain.Order)
+		err := c.messageService.MarkAsRead(order, u)
+		if err != nil {
+			log.Printf("OrderMessageController: %s", err)
+			Forbidden(w, err)
+			return
+		}
+
+		Ok(w)
+	}
+}
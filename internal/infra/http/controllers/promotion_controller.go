@@ -0,0 +1,219 @@
+package controllers
+
+import (
+	"boilerplate/internal/app"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/http/requests"
+	"boilerplate/internal/infra/http/resources"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type PromotionController struct {
+	promotionService  app.PromotionService
+	farmService       app.FarmService
+	orderItemService  app.OrderItemsService
+	imageModelService app.ImageModelService
+}
+
+func NewPromotionController(ps app.PromotionService, fs app.FarmService, ois app.OrderItemsService, ims app.ImageModelService) PromotionController {
+	return PromotionController{
+		promotionService:  ps,
+		farmService:       fs,
+		orderItemService:  ois,
+		imageModelService: ims,
+	}
+}
+
+func (c PromotionController) Save() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		promotion, err := requests.Bind(r, requests.PromotionRequest{}, domain.Promotion{})
+		if err != nil {
+			log.Printf("PromotionController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		// Without a farm the promotion is platform-wide and applies to the
+		// products of every farm.
+		if promotion.FarmId == nil {
+			if !u.Can(domain.PERMISSION_PROMOTIONS_MANAGE) {
+				err := errors.New("you have no permission to create platform promotions")
+				log.Printf("PromotionController: %s", err)
+				Forbidden(w, err)
+				return
+			}
+		} else {
+			farm, err := c.farmService.FindById(*promotion.FarmId)
+			if err != nil {
+				log.Printf("PromotionController: %s", err)
+				BadRequest(w, err)
+				return
+			}
+			if farm.GetUserId() != u.Id {
+				err := errors.New("user is not a farm owner")
+				log.Printf("PromotionController: %s", err)
+				Forbidden(w, err)
+				return
+			}
+		}
+
+		promotion.User = u
+		promotion, err = c.promotionService.Save(promotion)
+		if err != nil {
+			log.Printf("PromotionController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Created(w, resources.PromotionDto{}.DomainToDto(promotion))
+	}
+}
+
+func (c PromotionController) FindByFarmId() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		farmId, err := strconv.ParseUint(chi.URLParam(r, "farmId"), 10, 64)
+		if err != nil {
+			log.Printf("PromotionController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		pagination, err := requests.DecodePaginationQuery(r)
+		if err != nil {
+			log.Printf("PromotionController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		promotions, err := c.promotionService.FindAllByFarmId(farmId, pagination)
+		if err != nil {
+			log.Printf("PromotionController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.PromotionDto{}.DomainToDtoPaginatedCollection(promotions))
+	}
+}
+
+func (c PromotionController) FindPlatform() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pagination, err := requests.DecodePaginationQuery(r)
+		if err != nil {
+			log.Printf("PromotionController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		promotions, err := c.promotionService.FindAllPlatform(pagination)
+		if err != nil {
+			log.Printf("PromotionController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.PromotionDto{}.DomainToDtoPaginatedCollection(promotions))
+	}
+}
+
+func (c PromotionController) FindById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		promotion := r.Context().Value(PromotionKey).(domain.Promotion)
+		Success(w, resources.PromotionDto{}.DomainToDto(promotion))
+	}
+}
+
+func (c PromotionController) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p := r.Context().Value(PromotionKey).(domain.Promotion)
+		promotion, err := requests.Bind(r, requests.PromotionRequest{}, domain.Promotion{})
+		if err != nil {
+			log.Printf("PromotionController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		promotion, err = c.promotionService.Update(p, promotion)
+		if err != nil {
+			log.Printf("PromotionController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.PromotionDto{}.DomainToDto(promotion))
+	}
+}
+
+func (c PromotionController) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		promotion := r.Context().Value(PromotionKey).(domain.Promotion)
+		err := c.promotionService.Delete(promotion)
+		if err != nil {
+			log.Printf("PromotionController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Ok(w)
+	}
+}
+
+func (c PromotionController) ApplyToOrder() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		order := r.Context().Value(OrderKey).(domain.Order)
+		req, err := requests.Bind(r, requests.ApplyPromotionRequest{}, domain.Promotion{})
+		if err != nil {
+			log.Printf("PromotionController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		order, err = c.promotionService.ApplyToOrder(order, req.Code)
+		if err != nil {
+			log.Printf("PromotionController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		c.orderResponse(w, order)
+	}
+}
+
+func (c PromotionController) RemoveFromOrder() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		order := r.Context().Value(OrderKey).(domain.Order)
+		promotionId, err := strconv.ParseUint(chi.URLParam(r, "promotionId"), 10, 64)
+		if err != nil {
+			log.Printf("PromotionController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		order, err = c.promotionService.RemoveFromOrder(order, promotionId)
+		if err != nil {
+			log.Printf("PromotionController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		c.orderResponse(w, order)
+	}
+}
+
+func (c PromotionController) orderResponse(w http.ResponseWriter, order domain.Order) {
+	orderItems, err := c.orderItemService.FindAll(order.Id)
+	if err != nil {
+		log.Printf("PromotionController: %s", err)
+		InternalServerError(w, err)
+		return
+	}
+
+	order.OrderItems = orderItems
+	Success(w, resources.OrderDtoWithOrderItems{}.DomainToDto(order, c.imageModelService))
+}
@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"boilerplate/internal/app"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/http/requests"
+	"boilerplate/internal/infra/http/resources"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ReviewController struct {
+	reviewService     app.ReviewService
+	farmService       app.FarmService
+	imageModelService app.ImageModelService
+}
+
+func NewReviewController(rs app.ReviewService, fs app.FarmService, ims app.ImageModelService) ReviewController {
+	return ReviewController{
+		reviewService:     rs,
+		farmService:       fs,
+		imageModelService: ims,
+	}
+}
+
+func (c ReviewController) Save() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		review, err := requests.Bind(r, requests.ReviewRequest{}, domain.Review{})
+		if err != nil {
+			log.Printf("ReviewController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		review.User = u
+		review, err = c.reviewService.Save(review)
+		if err != nil {
+			log.Printf("ReviewController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Created(w, resources.ReviewDto{}.DomainToDto(review, c.imageModelService))
+	}
+}
+
+func (c ReviewController) FindById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		review := r.Context().Value(ReviewKey).(domain.Review)
+		Success(w, resources.ReviewDto{}.DomainToDto(review, c.imageModelService))
+	}
+}
+
+func (c ReviewController) FindByOfferId() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		offerId, err := strconv.ParseUint(chi.URLParam(r, "offerId"), 10, 64)
+		if err != nil {
+			log.Printf("ReviewController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		pagination, err := requests.DecodePaginationQuery(r)
+		if err != nil {
+			log.Printf("ReviewController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		reviews, err := c.reviewService.FindAllByOfferId(offerId, pagination)
+		if err != nil {
+			log.Printf("ReviewController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.ReviewDto{}.DomainToDtoPaginatedCollection(reviews, c.imageModelService))
+	}
+}
+
+func (c ReviewController) FindByFarmId() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		farmId, err := strconv.ParseUint(chi.URLParam(r, "farmId"), 10, 64)
+		if err != nil {
+			log.Printf("ReviewController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		pagination, err := requests.DecodePaginationQuery(r)
+		if err != nil {
+			log.Printf("ReviewController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		reviews, err := c.reviewService.FindAllByFarmId(farmId, pagination)
+		if err != nil {
+			log.Printf("ReviewController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.ReviewDto{}.DomainToDtoPaginatedCollection(reviews, c.imageModelService))
+	}
+}
+
+func (c ReviewController) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rv := r.Context().Value(ReviewKey).(domain.Review)
+		review, err := requests.Bind(r, requests.UpdateReviewRequest{}, domain.Review{})
+		if err != nil {
+			log.Printf("ReviewController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		review, err = c.reviewService.Update(rv, review)
+		if err != nil {
+			log.Printf("ReviewController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.ReviewDto{}.DomainToDto(review, c.imageModelService))
+	}
+}
+
+func (c ReviewController) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		review := r.Context().Value(ReviewKey).(domain.Review)
+		err := c.reviewService.Delete(review)
+		if err != nil {
+			log.Printf("ReviewController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Ok(w)
+	}
+}
+
+func (c ReviewController) Reply() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		review := r.Context().Value(ReviewKey).(domain.Review)
+		req, err := requests.Bind(r, requests.ReviewReplyRequest{}, domain.Review{})
+		if err != nil {
+			log.Printf("ReviewController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		farm, err := c.farmService.FindById(review.FarmId)
+		if err != nil {
+			log.Printf("ReviewController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		if farm.GetUserId() != u.Id {
+			err := errors.New("only the farm owner can reply to the review")
+			log.Printf("ReviewController: %s", err)
+			Forbidden(w, err)
+			return
+		}
+
+		review, err = c.reviewService.Reply(review, *req.Reply)
+		if err != nil {
+			log.Printf("ReviewController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.ReviewDto{}.DomainToDto(review, c.imageModelService))
+	}
+}
+
+func (c ReviewController) Report() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		review := r.Context().Value(ReviewKey).(domain.Review)
+		report, err := requests.Bind(r, requests.ReviewReportRequest{}, domain.ReviewReport{})
+		if err != nil {
+			log.Printf("ReviewController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		report.User = u
+		err = c.reviewService.Report(review, report)
+		if errors.Is(err, domain.ErrOwnReviewReport) || errors.Is(err, domain.ErrReviewAlreadyReported) {
+			BadRequest(w, err)
+			return
+		} else if err != nil {
+			log.Printf("ReviewController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Ok(w)
+	}
+}
+
+func (c ReviewController) Moderate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		review := r.Context().Value(ReviewKey).(domain.Review)
+		req, err := requests.Bind(r, requests.ReviewStatusRequest{}, domain.Review{})
+		if err != nil {
+			log.Printf("ReviewController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		review, err = c.reviewService.Moderate(review, req.Status)
+		if err != nil {
+			log.Printf("ReviewController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.ReviewDto{}.DomainToDto(review, c.imageModelService))
+	}
+}
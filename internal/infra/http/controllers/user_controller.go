@@ -1,22 +1,32 @@
 package controllers
 
 import (
+	"archive/zip"
 	"boilerplate/internal/app"
 	"boilerplate/internal/domain"
 	"boilerplate/internal/infra/http/requests"
 	"boilerplate/internal/infra/http/resources"
-	"errors"
+	"encoding/json"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"path"
 )
 
 type UserController struct {
-	userService app.UserService
+	userService       app.UserService
+	authService       app.AuthService
+	accountService    app.AccountService
+	imageModelService app.ImageModelService
 }
 
-func NewUserController(us app.UserService) UserController {
+func NewUserController(us app.UserService, as app.AuthService, acs app.AccountService, ims app.ImageModelService) UserController {
 	return UserController{
-		userService: us,
+		userService:       us,
+		authService:       as,
+		accountService:    acs,
+		imageModelService: ims,
 	}
 }
 
@@ -56,13 +66,119 @@ func (c UserController) SetPhoneNumber() http.HandlerFunc {
 			return
 		}
 		user := r.Context().Value(UserKey).(domain.User)
-		if user.PhoneNumber != nil {
-			err = errors.New("user already have a phone number")
+		user, err = c.authService.SetPhoneNumber(user, *userPhoneNumber.PhoneNumber)
+		if err != nil {
+			log.Printf("UserController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		var userDto resources.UserDto
+		Success(w, userDto.DomainToDto(user))
+	}
+}
+
+func (c UserController) SendPhoneCode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		err := c.authService.SendPhoneCode(user)
+		if err != nil {
+			log.Printf("UserController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Ok(w)
+	}
+}
+
+func (c UserController) VerifyPhone() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.Bind(r, requests.VerifyPhoneRequest{}, domain.CodeConfirmation{})
+		if err != nil {
+			log.Printf("UserController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		user := r.Context().Value(UserKey).(domain.User)
+		user, err = c.authService.VerifyPhone(user, req.Code)
+		if err != nil {
+			log.Printf("UserController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.UserDto{}.DomainToDto(user))
+	}
+}
+
+func (c UserController) ChangeEmail() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.Bind(r, requests.ChangeEmailRequest{}, domain.ChangeEmail{})
+		if err != nil {
+			log.Printf("UserController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		user := r.Context().Value(UserKey).(domain.User)
+		user, err = c.authService.ChangeEmail(user, req)
+		if err != nil {
+			log.Printf("UserController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.UserDto{}.DomainToDto(user))
+	}
+}
+
+func (c UserController) SendEmailCode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		err := c.authService.SendEmailCode(user)
+		if err != nil {
+			log.Printf("UserController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Ok(w)
+	}
+}
+
+func (c UserController) VerifyEmail() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.Bind(r, requests.VerifyEmailRequest{}, domain.CodeConfirmation{})
+		if err != nil {
+			log.Printf("UserController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		user := r.Context().Value(UserKey).(domain.User)
+		user, err = c.authService.VerifyEmail(user, req.Code)
+		if err != nil {
+			log.Printf("UserController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.UserDto{}.DomainToDto(user))
+	}
+}
+
+func (c UserController) SetLanguage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userLanguage, err := requests.Bind(r, requests.SetLanguageRequest{}, domain.User{})
+		if err != nil {
 			log.Printf("UserController: %s", err)
 			BadRequest(w, err)
 			return
 		}
-		user.PhoneNumber = userPhoneNumber.PhoneNumber
+
+		user := r.Context().Value(UserKey).(domain.User)
+		user.Language = userLanguage.Language
 		user, err = c.userService.Update(user)
 		if err != nil {
 			log.Printf("UserController: %s", err)
@@ -74,14 +190,83 @@ func (c UserController) SetPhoneNumber() http.HandlerFunc {
 	}
 }
 
+// Export sends a ZIP with account.json and the offer images, or just the
+// JSON with ?format=json.
+func (c UserController) Export() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		export, err := c.accountService.Export(user)
+		if err != nil {
+			log.Printf("UserController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		exportDto := resources.AccountExportDto{}.DomainToDto(export, c.imageModelService)
+		if r.URL.Query().Get("format") == "json" {
+			Success(w, exportDto)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"account-%d.zip\"", user.Id))
+		w.WriteHeader(http.StatusOK)
+
+		archive := zip.NewWriter(w)
+		defer archive.Close()
+
+		file, err := archive.Create("account.json")
+		if err != nil {
+			log.Printf("UserController: %s", err)
+			return
+		}
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(exportDto)
+		if err != nil {
+			log.Printf("UserController: %s", err)
+			return
+		}
+
+		for _, image := range export.Images {
+			content, err := c.accountService.ReadImage(r.Context(), image)
+			if err != nil {
+				// The file could be gone from the storage, the rest of the
+				// archive is still worth sending.
+				log.Printf("UserController: %s", err)
+				continue
+			}
+
+			file, err := archive.Create(path.Join("images", path.Base(image.Name)))
+			if err != nil {
+				_ = content.Close()
+				log.Printf("UserController: %s", err)
+				return
+			}
+			_, err = io.Copy(file, content)
+			_ = content.Close()
+			if err != nil {
+				log.Printf("UserController: %s", err)
+				return
+			}
+		}
+	}
+}
+
 func (c UserController) Delete() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		u := r.Context().Value(UserKey).(domain.User)
+		req, err := requests.Bind(r, requests.DeleteAccountRequest{}, domain.DeleteAccount{})
+		if err != nil {
+			log.Printf("UserController: %s", err)
+			BadRequest(w, err)
+			return
+		}
 
-		err := c.userService.Delete(u.Id)
+		u := r.Context().Value(UserKey).(domain.User)
+		err = c.accountService.Delete(u, req, requestActor(r))
 		if err != nil {
 			log.Printf("UserController: %s", err)
-			InternalServerError(w, err)
+			BadRequest(w, err)
 			return
 		}
 
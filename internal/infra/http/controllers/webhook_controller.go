@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"boilerplate/internal/app"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/http/requests"
+	"boilerplate/internal/infra/http/resources"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type WebhookController struct {
+	webhookService app.WebhookService
+	farmService    app.FarmService
+}
+
+func NewWebhookController(ws app.WebhookService, fs app.FarmService) WebhookController {
+	return WebhookController{
+		webhookService: ws,
+		farmService:    fs,
+	}
+}
+
+func (c WebhookController) Save() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(UserKey).(domain.User)
+		webhook, err := requests.Bind(r, requests.WebhookRequest{}, domain.Webhook{})
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		farm, err := c.farmService.FindById(webhook.FarmId)
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		if farm.GetUserId() != u.Id {
+			err := errors.New("user is not a farm owner")
+			log.Printf("WebhookController: %s", err)
+			Forbidden(w, err)
+			return
+		}
+
+		webhook.User = u
+		webhook, err = c.webhookService.Save(webhook)
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Created(w, resources.WebhookDto{}.DomainToDtoWithSecret(webhook))
+	}
+}
+
+func (c WebhookController) FindByFarmId() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		farm := r.Context().Value(FarmKey).(domain.Farm)
+		webhooks, err := c.webhookService.FindAllByFarmId(farm.Id)
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.WebhookDto{}.DomainToDtoCollection(webhooks))
+	}
+}
+
+func (c WebhookController) FindById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhook := r.Context().Value(WebhookKey).(domain.Webhook)
+		Success(w, resources.WebhookDto{}.DomainToDto(webhook))
+	}
+}
+
+func (c WebhookController) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhook := r.Context().Value(WebhookKey).(domain.Webhook)
+		req, err := requests.Bind(r, requests.UpdateWebhookRequest{}, domain.Webhook{})
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		webhook, err = c.webhookService.Update(webhook, req)
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		Success(w, resources.WebhookDto{}.DomainToDto(webhook))
+	}
+}
+
+func (c WebhookController) RotateSecret() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhook := r.Context().Value(WebhookKey).(domain.Webhook)
+		webhook, err := c.webhookService.RotateSecret(webhook)
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.WebhookDto{}.DomainToDtoWithSecret(webhook))
+	}
+}
+
+func (c WebhookController) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhook := r.Context().Value(WebhookKey).(domain.Webhook)
+		err := c.webhookService.Delete(webhook)
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Ok(w)
+	}
+}
+
+func (c WebhookController) FindDeliveries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhook := r.Context().Value(WebhookKey).(domain.Webhook)
+		pagination, err := requests.DecodePaginationQuery(r)
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		deliveries, err := c.webhookService.FindDeliveries(webhook, pagination)
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.WebhookDeliveryDto{}.DomainToDtoPaginatedCollection(deliveries))
+	}
+}
+
+func (c WebhookController) Redeliver() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhook := r.Context().Value(WebhookKey).(domain.Webhook)
+		deliveryId, err := strconv.ParseUint(chi.URLParam(r, "deliveryId"), 10, 64)
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		delivery, err := c.webhookService.Redeliver(webhook, deliveryId)
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			NotFound(w, err)
+			return
+		}
+
+		Created(w, resources.WebhookDeliveryDto{}.DomainToDto(delivery))
+	}
+}
@@ -0,0 +1,110 @@
+package middlewares
+
+import (
+	"boilerplate/internal/app"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/http/controllers"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/upper/db/v4"
+)
+
+const apiKeyScheme = "ApiKey "
+
+// RequireApiKeyScope lets API keys with the scope make the request, it is
+// attached to the route in router.go. Routes without it take user sessions
+// only, see LimitApiKeys.
+func RequireApiKeyScope(scope domain.ApiKeyScope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return apiKeyScopeHandler{scope: scope, next: next}
+	}
+}
+
+type apiKeyScopeHandler struct {
+	scope domain.ApiKeyScope
+	next  http.Handler
+}
+
+func (h apiKeyScopeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key, ok := r.Context().Value(controllers.AuthApiKeyKey).(domain.ApiKey)
+	if ok && !key.HasScope(h.scope) {
+		controllers.Forbidden(w, errApiKeyNoAccess)
+		return
+	}
+	h.next.ServeHTTP(w, r)
+}
+
+var errApiKeyNoAccess = errors.New("api key has no access to this endpoint")
+
+// LimitApiKeys turns away the requests signed with an API key before they
+// reach a route registered without RequireApiKeyScope. It takes the router
+// with all the routes already registered.
+func LimitApiKeys(router chi.Router) http.Handler {
+	scoped := make(map[string]bool)
+	_ = chi.Walk(router, func(method, route string, _ http.Handler, mws ...func(http.Handler) http.Handler) error {
+		for _, mw := range mws {
+			if _, ok := mw(http.NotFoundHandler()).(apiKeyScopeHandler); ok {
+				scoped[apiKeyRoute(method, route)] = true
+			}
+		}
+		return nil
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := apiKeyFromHeader(r); ok {
+			rctx := chi.NewRouteContext()
+			if router.Match(rctx, r.Method, r.URL.Path) && !scoped[apiKeyRoute(r.Method, rctx.RoutePattern())] {
+				controllers.Forbidden(w, errApiKeyNoAccess)
+				return
+			}
+		}
+		router.ServeHTTP(w, r)
+	})
+}
+
+func apiKeyRoute(method, pattern string) string {
+	return method + " " + strings.TrimSuffix(pattern, "/")
+}
+
+func apiKeyFromHeader(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, apiKeyScheme) {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(header, apiKeyScheme)), true
+}
+
+// authenticateApiKey puts the owner of the farm into the context, like a
+// session would, together with the key itself.
+func authenticateApiKey(next http.Handler, w http.ResponseWriter, r *http.Request, rawKey string, aks app.ApiKeyService, us app.UserService) {
+	key, err := aks.Authenticate(rawKey)
+	if err != nil {
+		controllers.Unauthorized(w, err)
+		return
+	}
+
+	user, err := us.FindById(key.User.Id)
+	if err != nil {
+		if errors.Is(err, db.ErrNoMoreRows) {
+			err = errors.New("api key is unauthorized")
+		}
+		controllers.Unauthorized(w, err)
+		return
+	}
+	if user.DeletedDate != nil {
+		controllers.Unauthorized(w, errors.New("api key is unauthorized"))
+		return
+	}
+	if user.IsBlocked() {
+		controllers.Unauthorized(w, errors.New("user is blocked"))
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), controllers.UserKey, user)
+	ctx = context.WithValue(ctx, controllers.AuthApiKeyKey, key)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
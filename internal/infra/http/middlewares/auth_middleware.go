@@ -14,9 +14,16 @@ import (
 	"github.com/upper/db/v4"
 )
 
-func AuthMiddleware(ja *jwtauth.JWTAuth, as app.AuthService, us app.UserService) func(http.Handler) http.Handler {
+// AuthMiddleware accepts a JWT of a user session or, for the farm
+// integrations, "Authorization: ApiKey <key>".
+func AuthMiddleware(ja *jwtauth.JWTAuth, as app.AuthService, us app.UserService, aks app.ApiKeyService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		hfn := func(w http.ResponseWriter, r *http.Request) {
+			if rawKey, ok := apiKeyFromHeader(r); ok {
+				authenticateApiKey(next, w, r, rawKey, aks, us)
+				return
+			}
+
 			ctx := r.Context()
 			token, err := jwtauth.VerifyRequest(ja, r, jwtauth.TokenFromHeader)
 
@@ -56,6 +63,10 @@ func AuthMiddleware(ja *jwtauth.JWTAuth, as app.AuthService, us app.UserService)
 				controllers.Unauthorized(w, err)
 				return
 			}
+			if user.IsBlocked() {
+				controllers.Unauthorized(w, errors.New("user is blocked"))
+				return
+			}
 
 			ctx = context.WithValue(ctx, controllers.UserKey, user)
 			ctx = context.WithValue(ctx, controllers.SessKey, auth)
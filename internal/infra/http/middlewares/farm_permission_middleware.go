@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"boilerplate/internal/app"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/http/controllers"
+	"errors"
+	"net/http"
+)
+
+// FarmPermission lets the request through only if the current user works
+// at the farm of the path object in a role that grants the permission.
+func FarmPermission(fms app.FarmMemberService, key controllers.CtxKey, permission domain.FarmPermission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			user := r.Context().Value(controllers.GetUserKey()).(domain.User)
+			obj := r.Context().Value(key).(controllers.Farmable)
+
+			if !controllers.ApiKeyAllowsFarm(r, obj.GetFarmId()) {
+				err := errors.New("api key has no access to this object")
+				controllers.Forbidden(w, err)
+				return
+			}
+
+			allowed, err := fms.Can(obj.GetFarmId(), user.Id, permission)
+			if err != nil {
+				controllers.InternalServerError(w, err)
+				return
+			}
+			if !allowed {
+				err := errors.New("you have no permission to perform this action")
+				controllers.Forbidden(w, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
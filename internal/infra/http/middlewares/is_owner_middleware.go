@@ -19,6 +19,15 @@ func IsOwnerMiddleware[domainType controllers.Userable](key controllers.CtxKey)
 				controllers.Forbidden(w, err)
 				return
 			}
+			// An API key reaches only the objects of its own farm.
+			if key, ok := ctx.Value(controllers.AuthApiKeyKey).(domain.ApiKey); ok {
+				farmObj, isFarmable := obj.(controllers.Farmable)
+				if !isFarmable || farmObj.GetFarmId() != key.FarmId {
+					err := errors.New("api key has no access to this object")
+					controllers.Forbidden(w, err)
+					return
+				}
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 		}
 		return http.HandlerFunc(hfn)
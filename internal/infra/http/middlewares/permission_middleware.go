@@ -0,0 +1,25 @@
+package middlewares
+
+import (
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/http/controllers"
+	"errors"
+	"net/http"
+)
+
+// RequirePermission lets the request through only if the role of the
+// current user grants the permission.
+func RequirePermission(permission domain.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			user := r.Context().Value(controllers.GetUserKey()).(domain.User)
+			if !user.Can(permission) {
+				err := errors.New("you have no permission to perform this action")
+				controllers.Forbidden(w, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
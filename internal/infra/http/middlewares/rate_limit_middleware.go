@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"boilerplate/internal/app/ratelimit"
+	"boilerplate/internal/domain"
+	"boilerplate/internal/infra/http/controllers"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// RateLimit throttles the requests of each client with the limiter. Signed
+// in users are counted by their id, anyone else by IP.
+func RateLimit(limiter ratelimit.Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			key := "ip:" + controllers.ClientIp(r)
+			if user, ok := r.Context().Value(controllers.GetUserKey()).(domain.User); ok {
+				key = fmt.Sprintf("user:%d", user.Id)
+			}
+
+			allowed, retryAfter := limiter.Allow(key)
+			if !allowed {
+				err := errors.New("too many requests")
+				controllers.TooManyRequests(w, err, retryAfter)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
@@ -0,0 +1,71 @@
+package middlewares
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RealIp puts the address of the client into RemoteAddr when the request
+// comes through one of the trusted proxies, so ClientIp, the lockouts and
+// the rate limits see the client and not the load balancer.
+// X-Forwarded-For is read from the right, the first address that isn't a
+// trusted proxy is the client; anything left of it could be made up by
+// the client. Requests from other addresses keep RemoteAddr as it is.
+func RealIp(trustedProxies []string) (func(http.Handler) http.Handler, error) {
+	trusted := make([]*net.IPNet, 0, len(trustedProxies))
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: %w", proxy, err)
+		}
+		trusted = append(trusted, network)
+	}
+
+	isTrusted := func(ip net.IP) bool {
+		for _, network := range trusted {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			host, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				host = r.RemoteAddr
+			}
+			peer := net.ParseIP(host)
+			if peer == nil || !isTrusted(peer) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+			for i := len(hops) - 1; i >= 0; i-- {
+				ip := net.ParseIP(strings.TrimSpace(hops[i]))
+				if ip == nil {
+					break
+				}
+				peer = ip
+				if !isTrusted(ip) {
+					break
+				}
+			}
+
+			r.RemoteAddr = net.JoinHostPort(peer.String(), "0")
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(hfn)
+	}, nil
+}
@@ -0,0 +1,195 @@
+package requests
+
+import (
+	"boilerplate/internal/domain"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type BlockUserRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type SetUserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=BUYER FARMER MODERATOR ADMIN"`
+}
+
+type UnpublishOfferRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type ForceOrderStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=SUBMITTED APPROVED DECLINED SHIPPING COMPLETED"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+func (r BlockUserRequest) ToDomainModel() (interface{}, error) {
+	return domain.AdminAction{Reason: r.Reason}, nil
+}
+
+func (r SetUserRoleRequest) ToDomainModel() (interface{}, error) {
+	return domain.AdminAction{Role: domain.Role(r.Role)}, nil
+}
+
+func (r UnpublishOfferRequest) ToDomainModel() (interface{}, error) {
+	return domain.AdminAction{Reason: r.Reason}, nil
+}
+
+func (r ForceOrderStatusRequest) ToDomainModel() (interface{}, error) {
+	return domain.AdminAction{Status: domain.OrderStatus(r.Status), Reason: r.Reason}, nil
+}
+
+func DecodeUserFilterQuery(r *http.Request) (domain.UserFilter, error) {
+	q := r.URL.Query()
+	filter := domain.UserFilter{
+		Search: q.Get("search"),
+		Role:   domain.Role(q.Get("role")),
+	}
+
+	blocked, err := parseBoolQuery(q.Get("blocked"), "blocked")
+	if err != nil {
+		return domain.UserFilter{}, err
+	}
+	filter.Blocked = blocked
+
+	return filter, nil
+}
+
+func DecodeFarmFilterQuery(r *http.Request) (domain.FarmFilter, error) {
+	q := r.URL.Query()
+	userId, err := parseIdQuery(q.Get("user_id"), "user_id")
+	if err != nil {
+		return domain.FarmFilter{}, err
+	}
+
+	return domain.FarmFilter{Search: q.Get("search"), UserId: userId}, nil
+}
+
+func DecodeOfferFilterQuery(r *http.Request) (domain.OfferFilter, error) {
+	q := r.URL.Query()
+	farmId, err := parseIdQuery(q.Get("farm_id"), "farm_id")
+	if err != nil {
+		return domain.OfferFilter{}, err
+	}
+	userId, err := parseIdQuery(q.Get("user_id"), "user_id")
+	if err != nil {
+		return domain.OfferFilter{}, err
+	}
+	status, err := parseBoolQuery(q.Get("status"), "status")
+	if err != nil {
+		return domain.OfferFilter{}, err
+	}
+
+	return domain.OfferFilter{Search: q.Get("search"), FarmId: farmId, UserId: userId, Status: status}, nil
+}
+
+func DecodeOrderFilterQuery(r *http.Request) (domain.OrderFilter, error) {
+	q := r.URL.Query()
+	userId, err := parseIdQuery(q.Get("user_id"), "user_id")
+	if err != nil {
+		return domain.OrderFilter{}, err
+	}
+	farmId, err := parseIdQuery(q.Get("farm_id"), "farm_id")
+	if err != nil {
+		return domain.OrderFilter{}, err
+	}
+	from, err := parseDateQuery(q.Get("from"), "from")
+	if err != nil {
+		return domain.OrderFilter{}, err
+	}
+	to, err := parseDateQuery(q.Get("to"), "to")
+	if err != nil {
+		return domain.OrderFilter{}, err
+	}
+
+	return domain.OrderFilter{
+		Status: domain.OrderStatus(q.Get("status")),
+		UserId: userId,
+		FarmId: farmId,
+		From:   from,
+		To:     to,
+	}, nil
+}
+
+func DecodePaymentFilterQuery(r *http.Request) (domain.PaymentFilter, error) {
+	q := r.URL.Query()
+	from, err := parseDateQuery(q.Get("from"), "from")
+	if err != nil {
+		return domain.PaymentFilter{}, err
+	}
+	to, err := parseDateQuery(q.Get("to"), "to")
+	if err != nil {
+		return domain.PaymentFilter{}, err
+	}
+
+	return domain.PaymentFilter{Status: domain.InvoiceStatus(q.Get("status")), From: from, To: to}, nil
+}
+
+func parseIdQuery(value string, name string) (uint64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("problems in parsing '%s' query parameter", name)
+	}
+	return id, nil
+}
+
+func parseBoolQuery(value string, name string) (*bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("problems in parsing '%s' query parameter", name)
+	}
+	return &b, nil
+}
+
+// parseDateQuery accepts either a date (2006-01-02) or a full RFC 3339
+// timestamp.
+func parseDateQuery(value string, name string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("problems in parsing '%s' query parameter", name)
+	}
+	return &t, nil
+}
+
+func DecodeAuditEventFilterQuery(r *http.Request) (domain.AuditEventFilter, error) {
+	q := r.URL.Query()
+	actorId, err := parseIdQuery(q.Get("actor_id"), "actor_id")
+	if err != nil {
+		return domain.AuditEventFilter{}, err
+	}
+	entityId, err := parseIdQuery(q.Get("entity_id"), "entity_id")
+	if err != nil {
+		return domain.AuditEventFilter{}, err
+	}
+	from, err := parseDateQuery(q.Get("from"), "from")
+	if err != nil {
+		return domain.AuditEventFilter{}, err
+	}
+	to, err := parseDateQuery(q.Get("to"), "to")
+	if err != nil {
+		return domain.AuditEventFilter{}, err
+	}
+
+	return domain.AuditEventFilter{
+		ActorId:  actorId,
+		Action:   domain.AuditAction(q.Get("action")),
+		Entity:   domain.AuditEntity(q.Get("entity")),
+		EntityId: entityId,
+		From:     from,
+		To:       to,
+	}, nil
+}
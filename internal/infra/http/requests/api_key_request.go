@@ -0,0 +1,32 @@
+package requests
+
+import (
+	"boilerplate/internal/domain"
+	"time"
+)
+
+type ApiKeyRequest struct {
+	FarmId      uint64     `json:"farm_id" validate:"required"`
+	Name        string     `json:"name" validate:"required,max=100"`
+	Scopes      []string   `json:"scopes" validate:"required,min=1,dive,oneof=offers:read offers:write orders:read"`
+	ExpiresDate *time.Time `json:"expires_date"`
+}
+
+func (r ApiKeyRequest) ToDomainModel() (interface{}, error) {
+	scopes := make([]domain.ApiKeyScope, 0, len(r.Scopes))
+	seen := make(map[string]bool, len(r.Scopes))
+	for _, s := range r.Scopes {
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		scopes = append(scopes, domain.ApiKeyScope(s))
+	}
+
+	return domain.ApiKey{
+		FarmId:      r.FarmId,
+		Name:        r.Name,
+		Scopes:      scopes,
+		ExpiresDate: r.ExpiresDate,
+	}, nil
+}
@@ -0,0 +1,32 @@
+package requests
+
+import "boilerplate/internal/domain"
+
+type FarmInvitationRequest struct {
+	Email       string `json:"email" validate:"required_without=PhoneNumber,omitempty,email"`
+	PhoneNumber string `json:"phone_number" validate:"required_without=Email"`
+	Role        string `json:"role" validate:"required,oneof=MANAGER PACKER"`
+}
+
+func (r FarmInvitationRequest) ToDomainModel() (interface{}, error) {
+	invitation := domain.FarmInvitation{
+		Role: domain.FarmRole(r.Role),
+	}
+	if r.Email != "" {
+		invitation.Email = &r.Email
+	}
+	if r.PhoneNumber != "" {
+		invitation.PhoneNumber = &r.PhoneNumber
+	}
+	return invitation, nil
+}
+
+type FarmMemberRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=MANAGER PACKER"`
+}
+
+func (r FarmMemberRoleRequest) ToDomainModel() (interface{}, error) {
+	return domain.FarmMember{
+		Role: domain.FarmRole(r.Role),
+	}, nil
+}
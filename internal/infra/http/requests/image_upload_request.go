@@ -0,0 +1,155 @@
+package requests
+
+import (
+	"boilerplate/internal/domain"
+	"boilerplate/internal/filesystem"
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// ImageUploadField is the form field multipart uploads carry the file in.
+const ImageUploadField = "image"
+
+const (
+	// sniffLen is how much of the file is buffered to tell its type.
+	sniffLen = 512
+	// multipartOverhead leaves room for the boundaries and the headers
+	// of the parts on top of the image itself.
+	multipartOverhead = 64 << 10
+)
+
+var (
+	ErrImageTooLarge = errors.New("image is too large")
+	ErrImageType     = errors.New("image must be a JPEG, PNG, WebP or HEIC file")
+	ErrNoImage       = errors.New("form has no image file")
+)
+
+type imageType struct {
+	mimeType  string
+	extension string
+}
+
+var (
+	imageTypeJpeg = imageType{"image/jpeg", "jpg"}
+	imageTypePng  = imageType{"image/png", "png"}
+	imageTypeWebp = imageType{"image/webp", "webp"}
+	imageTypeHeic = imageType{"image/heic", "heic"}
+)
+
+// heicBrands are the ftyp brands of HEIF files holding HEVC coded images.
+var heicBrands = map[string]bool{
+	"heic": true, "heix": true, "heim": true, "heis": true, "hevc": true, "hevx": true,
+}
+
+func IsMultipart(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+// BindImageUpload finds the image file in a multipart/form-data body
+// without reading it through. The content streams on into the storage
+// and fails once it grows past maxSize. The type is told by the
+// signature of the file, the name and the content type the client sends
+// are not trusted.
+func BindImageUpload(w http.ResponseWriter, r *http.Request, maxSize int64) (domain.ImageUpload, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+multipartOverhead)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return domain.ImageUpload{}, err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return domain.ImageUpload{}, ErrNoImage
+		} else if err != nil {
+			return domain.ImageUpload{}, err
+		}
+		if part.FormName() != ImageUploadField || part.FileName() == "" {
+			continue
+		}
+
+		content := bufio.NewReaderSize(part, sniffLen)
+		head, err := content.Peek(sniffLen)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return domain.ImageUpload{}, err
+		}
+		t, ok := sniffImageType(head)
+		if !ok {
+			return domain.ImageUpload{}, ErrImageType
+		}
+
+		return domain.ImageUpload{
+			Name:     imageFileName(part.FileName(), t),
+			MimeType: t.mimeType,
+			Content:  &sizeLimitedReader{r: content, left: maxSize},
+		}, nil
+	}
+}
+
+func sniffImageType(head []byte) (imageType, bool) {
+	switch {
+	case bytes.HasPrefix(head, []byte{0xFF, 0xD8, 0xFF}):
+		return imageTypeJpeg, true
+	case bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")):
+		return imageTypePng, true
+	case len(head) >= 12 && bytes.Equal(head[:4], []byte("RIFF")) && bytes.Equal(head[8:12], []byte("WEBP")):
+		return imageTypeWebp, true
+	case isHeic(head):
+		return imageTypeHeic, true
+	}
+	return imageType{}, false
+}
+
+// isHeic looks for a HEVC brand in the ftyp box the file starts with,
+// either as the major brand or among the compatible ones.
+func isHeic(head []byte) bool {
+	if len(head) < 12 || !bytes.Equal(head[4:8], []byte("ftyp")) {
+		return false
+	}
+	boxSize := int(binary.BigEndian.Uint32(head[:4]))
+	if boxSize < 16 || boxSize > len(head) {
+		boxSize = len(head)
+	}
+	if heicBrands[string(head[8:12])] {
+		return true
+	}
+	for i := 16; i+4 <= boxSize; i += 4 {
+		if heicBrands[string(head[i:i+4])] {
+			return true
+		}
+	}
+	return false
+}
+
+// imageFileName keeps the readable part of the client's file name and
+// puts the extension of the real type after it.
+func imageFileName(name string, t imageType) string {
+	return filesystem.SafeName(name) + "." + t.extension
+}
+
+type sizeLimitedReader struct {
+	r    io.Reader
+	left int64
+}
+
+func (l *sizeLimitedReader) Read(p []byte) (int, error) {
+	if l.left < 0 {
+		return 0, ErrImageTooLarge
+	}
+	// One byte over the limit is enough to know the file is too large.
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+	n, err := l.r.Read(p)
+	l.left -= int64(n)
+	if l.left < 0 {
+		return n, ErrImageTooLarge
+	}
+	return n, err
+}
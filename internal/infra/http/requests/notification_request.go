@@ -0,0 +1,58 @@
+package requests
+
+import (
+	"boilerplate/internal/domain"
+	"fmt"
+	"time"
+)
+
+type NotificationPreferenceRequest struct {
+	Event           string  `json:"event" validate:"required"`
+	Channel         string  `json:"channel" validate:"required,oneof=EMAIL SMS IN_APP"`
+	Enabled         bool    `json:"enabled"`
+	QuietHoursStart *string `json:"quiet_hours_start" validate:"omitempty,datetime=15:04"`
+	QuietHoursEnd   *string `json:"quiet_hours_end" validate:"omitempty,datetime=15:04"`
+}
+
+type NotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceRequest `json:"preferences" validate:"required,dive"`
+}
+
+func (r NotificationPreferencesRequest) ToDomainModel() (interface{}, error) {
+	preferences := make([]domain.NotificationPreference, len(r.Preferences))
+	for i, p := range r.Preferences {
+		start, err := parseQuietHour(p.QuietHoursStart)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseQuietHour(p.QuietHoursEnd)
+		if err != nil {
+			return nil, err
+		}
+
+		preferences[i] = domain.NotificationPreference{
+			Event:           domain.NotificationEvent(p.Event),
+			Channel:         domain.NotificationChannel(p.Channel),
+			Enabled:         p.Enabled,
+			QuietHoursStart: start,
+			QuietHoursEnd:   end,
+		}
+	}
+
+	return preferences, nil
+}
+
+// parseQuietHour converts "HH:MM" to minutes since midnight.
+func parseQuietHour(value *string) (*uint16, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse("15:04", *value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quiet hours time %s", *value)
+	}
+
+	minutes := uint16(t.Hour()*60 + t.Minute())
+	return &minutes, nil
+}
@@ -0,0 +1,21 @@
+package requests
+
+import (
+	"boilerplate/internal/domain"
+)
+
+type OfferAlertRequest struct {
+	OfferId     uint64   `json:"offer_id" validate:"required"`
+	Type        string   `json:"type" validate:"required,oneof=BACK_IN_STOCK PRICE_DROP"`
+	TargetPrice *float64 `json:"target_price" validate:"omitempty,gt=0"`
+	Channel     string   `json:"channel" validate:"required,oneof=EMAIL SMS IN_APP"`
+}
+
+func (m OfferAlertRequest) ToDomainModel() (interface{}, error) {
+	return domain.OfferAlert{
+		OfferId:     m.OfferId,
+		Type:        domain.OfferAlertType(m.Type),
+		TargetPrice: m.TargetPrice,
+		Channel:     domain.NotificationChannel(m.Channel),
+	}, nil
+}
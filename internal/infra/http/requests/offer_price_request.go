@@ -0,0 +1,20 @@
+package requests
+
+import (
+	"boilerplate/internal/domain"
+	"time"
+)
+
+type OfferPriceScheduleRequest struct {
+	Price     float64    `json:"price" validate:"required,gt=0"`
+	StartDate time.Time  `json:"start_date" validate:"required"`
+	EndDate   *time.Time `json:"end_date"`
+}
+
+func (m OfferPriceScheduleRequest) ToDomainModel() (interface{}, error) {
+	return domain.OfferPriceSchedule{
+		Price:     m.Price,
+		StartDate: m.StartDate,
+		EndDate:   m.EndDate,
+	}, nil
+}
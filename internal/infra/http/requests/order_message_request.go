@@ -0,0 +1,17 @@
+package requests
+
+import (
+	"boilerplate/internal/domain"
+)
+
+type OrderMessageRequest struct {
+	Text   string         `json:"text" validate:"required,max=2000"`
+	Images []ImageRequest `json:"images" validate:"max=5,dive"`
+}
+
+func (m OrderMessageRequest) ToDomainModel() (interface{}, error) {
+	return domain.OrderMessage{
+		Text:   m.Text,
+		Images: mapImageRequests(m.Images),
+	}, nil
+}
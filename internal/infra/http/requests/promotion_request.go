@@ -0,0 +1,50 @@
+package requests
+
+import (
+	"boilerplate/internal/domain"
+	"time"
+)
+
+type PromotionRequest struct {
+	Code              string     `json:"code" validate:"required,gte=3,max=32"`
+	Title             string     `json:"title" validate:"required,max=100"`
+	Type              string     `json:"type" validate:"required,oneof=PERCENTAGE FIXED FREE_SHIPPING BUY_X_GET_Y"`
+	Value             float64    `json:"value" validate:"gte=0"`
+	BuyAmount         uint32     `json:"buy_amount"`
+	GetAmount         uint32     `json:"get_amount"`
+	MinOrderPrice     float64    `json:"min_order_price" validate:"gte=0"`
+	UsageLimit        *uint64    `json:"usage_limit"`
+	UsageLimitPerUser *uint64    `json:"usage_limit_per_user"`
+	StartDate         time.Time  `json:"start_date" validate:"required"`
+	EndDate           *time.Time `json:"end_date"`
+	IsActive          bool       `json:"is_active"`
+	FarmId            *uint64    `json:"farm_id"`
+	OfferId           *uint64    `json:"offer_id"`
+}
+
+type ApplyPromotionRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+func (m PromotionRequest) ToDomainModel() (interface{}, error) {
+	return domain.Promotion{
+		Code:              m.Code,
+		Title:             m.Title,
+		Type:              domain.PromotionType(m.Type),
+		Value:             m.Value,
+		BuyAmount:         m.BuyAmount,
+		GetAmount:         m.GetAmount,
+		MinOrderPrice:     m.MinOrderPrice,
+		UsageLimit:        m.UsageLimit,
+		UsageLimitPerUser: m.UsageLimitPerUser,
+		StartDate:         m.StartDate,
+		EndDate:           m.EndDate,
+		IsActive:          m.IsActive,
+		FarmId:            m.FarmId,
+		OfferId:           m.OfferId,
+	}, nil
+}
+
+func (m ApplyPromotionRequest) ToDomainModel() (interface{}, error) {
+	return domain.Promotion{Code: m.Code}, nil
+}
@@ -0,0 +1,67 @@
+package requests
+
+import (
+	"boilerplate/internal/domain"
+)
+
+type ReviewRequest struct {
+	OfferId uint64         `json:"offer_id" validate:"required"`
+	Rating  uint8          `json:"rating" validate:"required,min=1,max=5"`
+	Text    string         `json:"text" validate:"max=2000"`
+	Images  []ImageRequest `json:"images" validate:"max=5,dive"`
+}
+
+type UpdateReviewRequest struct {
+	Rating uint8          `json:"rating" validate:"required,min=1,max=5"`
+	Text   string         `json:"text" validate:"max=2000"`
+	Images []ImageRequest `json:"images" validate:"max=5,dive"`
+}
+
+type ReviewReplyRequest struct {
+	Reply string `json:"reply" validate:"required,max=2000"`
+}
+
+type ReviewReportRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type ReviewStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=PUBLISHED HIDDEN"`
+}
+
+func (m ReviewRequest) ToDomainModel() (interface{}, error) {
+	return domain.Review{
+		OfferId: m.OfferId,
+		Rating:  m.Rating,
+		Text:    m.Text,
+		Images:  mapImageRequests(m.Images),
+	}, nil
+}
+
+func (m UpdateReviewRequest) ToDomainModel() (interface{}, error) {
+	return domain.Review{
+		Rating: m.Rating,
+		Text:   m.Text,
+		Images: mapImageRequests(m.Images),
+	}, nil
+}
+
+func (m ReviewReplyRequest) ToDomainModel() (interface{}, error) {
+	return domain.Review{Reply: &m.Reply}, nil
+}
+
+func (m ReviewReportRequest) ToDomainModel() (interface{}, error) {
+	return domain.ReviewReport{Reason: m.Reason}, nil
+}
+
+func (m ReviewStatusRequest) ToDomainModel() (interface{}, error) {
+	return domain.Review{Status: domain.ReviewStatus(m.Status)}, nil
+}
+
+func mapImageRequests(images []ImageRequest) []domain.Image {
+	result := make([]domain.Image, len(images))
+	for i, image := range images {
+		result[i] = image.ToDomainModelWithoutInt()
+	}
+	return result
+}
@@ -9,6 +9,7 @@ type RegisterRequest struct {
 	Email       string `json:"email" validate:"email"`
 	Password    string `json:"password" validate:"required,alphanum,gte=4,max=20"`
 	PhoneNumber string `json:"phone_number" validate:"required"`
+	Role        string `json:"role" validate:"omitempty,oneof=BUYER FARMER"`
 }
 
 type AuthRequest struct {
@@ -33,10 +34,61 @@ type ChangePasswordRequest struct {
 	NewPassword string `json:"newPassword" validate:"required,alphanum,gte=4"`
 }
 
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email       string `json:"email" validate:"required_without=PhoneNumber,omitempty,email"`
+	PhoneNumber string `json:"phone_number" validate:"required_without=Email"`
+}
+
+type ResetPasswordRequest struct {
+	Email       string `json:"email" validate:"required_without=PhoneNumber,omitempty,email"`
+	PhoneNumber string `json:"phone_number" validate:"required_without=Email"`
+	Code        string `json:"code" validate:"required,numeric,len=6"`
+	NewPassword string `json:"newPassword" validate:"required,alphanum,gte=4"`
+}
+
 type SetPhoneNumberRequest struct {
 	PhoneNumber string `json:"phone_number" validate:"required"`
 }
 
+type VerifyPhoneRequest struct {
+	Code string `json:"code" validate:"required,numeric,len=6"`
+}
+
+type ChangeEmailRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password"`
+}
+
+type VerifyEmailRequest struct {
+	Code string `json:"code" validate:"required,numeric,len=6"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,numeric,len=6"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required,max=20"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required,max=20"`
+}
+
+type SetLanguageRequest struct {
+	Language string `json:"language" validate:"required,oneof=uk en"`
+}
+
 func (r UpdateUserRequest) ToDomainModel() (interface{}, error) {
 	return domain.User{
 		Name: r.Name,
@@ -49,6 +101,7 @@ func (r RegisterRequest) ToDomainModel() (interface{}, error) {
 		Password:    r.Password,
 		PhoneNumber: &r.PhoneNumber,
 		Name:        r.Name,
+		Role:        domain.Role(r.Role),
 	}, nil
 }
 
@@ -73,8 +126,81 @@ func (r ChangePasswordRequest) ToDomainModel() (interface{}, error) {
 	}, nil
 }
 
+func (r RefreshTokenRequest) ToDomainModel() (interface{}, error) {
+	return domain.AuthTokens{
+		RefreshToken: r.RefreshToken,
+	}, nil
+}
+
+func (r ForgotPasswordRequest) ToDomainModel() (interface{}, error) {
+	return domain.User{
+		Email:       r.Email,
+		PhoneNumber: &r.PhoneNumber,
+	}, nil
+}
+
+func (r ResetPasswordRequest) ToDomainModel() (interface{}, error) {
+	return domain.ResetPassword{
+		Email:       r.Email,
+		PhoneNumber: r.PhoneNumber,
+		Code:        r.Code,
+		NewPassword: r.NewPassword,
+	}, nil
+}
+
 func (r SetPhoneNumberRequest) ToDomainModel() (interface{}, error) {
 	return domain.User{
 		PhoneNumber: &r.PhoneNumber,
 	}, nil
 }
+
+func (r VerifyPhoneRequest) ToDomainModel() (interface{}, error) {
+	return domain.CodeConfirmation{
+		Code: r.Code,
+	}, nil
+}
+
+func (r DeleteAccountRequest) ToDomainModel() (interface{}, error) {
+	return domain.DeleteAccount{
+		Password: r.Password,
+	}, nil
+}
+
+func (r ChangeEmailRequest) ToDomainModel() (interface{}, error) {
+	return domain.ChangeEmail{
+		Email:    r.Email,
+		Password: r.Password,
+	}, nil
+}
+
+func (r VerifyEmailRequest) ToDomainModel() (interface{}, error) {
+	return domain.CodeConfirmation{
+		Code: r.Code,
+	}, nil
+}
+
+func (r TwoFactorCodeRequest) ToDomainModel() (interface{}, error) {
+	return domain.CodeConfirmation{
+		Code: r.Code,
+	}, nil
+}
+
+func (r TwoFactorLoginRequest) ToDomainModel() (interface{}, error) {
+	return domain.TwoFactorLogin{
+		ChallengeToken: r.ChallengeToken,
+		Code:           r.Code,
+	}, nil
+}
+
+func (r DisableTwoFactorRequest) ToDomainModel() (interface{}, error) {
+	return domain.DisableTwoFactor{
+		Password: r.Password,
+		Code:     r.Code,
+	}, nil
+}
+
+func (r SetLanguageRequest) ToDomainModel() (interface{}, error) {
+	return domain.User{
+		Language: r.Language,
+	}, nil
+}
@@ -0,0 +1,46 @@
+package requests
+
+import (
+	"boilerplate/internal/domain"
+)
+
+type WebhookRequest struct {
+	FarmId uint64   `json:"farm_id" validate:"required"`
+	Url    string   `json:"url" validate:"required,url"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=ORDER_CREATED ORDER_STATUS_CHANGED ORDER_ITEM_CHANGED OFFER_CREATED OFFER_UPDATED OFFER_DELETED"`
+}
+
+type UpdateWebhookRequest struct {
+	Url      string   `json:"url" validate:"required,url"`
+	Events   []string `json:"events" validate:"required,min=1,dive,oneof=ORDER_CREATED ORDER_STATUS_CHANGED ORDER_ITEM_CHANGED OFFER_CREATED OFFER_UPDATED OFFER_DELETED"`
+	IsActive bool     `json:"is_active"`
+}
+
+func (r WebhookRequest) ToDomainModel() (interface{}, error) {
+	return domain.Webhook{
+		FarmId: r.FarmId,
+		Url:    r.Url,
+		Events: mapWebhookEvents(r.Events),
+	}, nil
+}
+
+func (r UpdateWebhookRequest) ToDomainModel() (interface{}, error) {
+	return domain.Webhook{
+		Url:      r.Url,
+		Events:   mapWebhookEvents(r.Events),
+		IsActive: r.IsActive,
+	}, nil
+}
+
+func mapWebhookEvents(events []string) []domain.WebhookEvent {
+	result := make([]domain.WebhookEvent, 0, len(events))
+	seen := make(map[string]bool, len(events))
+	for _, e := range events {
+		if seen[e] {
+			continue
+		}
+		seen[e] = true
+		result = append(result, domain.WebhookEvent(e))
+	}
+	return result
+}
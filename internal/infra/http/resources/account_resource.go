@@ -0,0 +1,44 @@
+package resources
+
+import (
+	"boilerplate/internal/app"
+	"boilerplate/internal/domain"
+	"time"
+)
+
+type AccountExportDto struct {
+	User       AdminUserDto             `json:"user"`
+	Addresses  []AddressDto             `json:"addresses"`
+	Orders     []OrderDtoWithOrderItems `json:"orders"`
+	Farms      []FarmWithOutDto         `json:"farms"`
+	Offers     []OfferDto               `json:"offers"`
+	Images     []string                 `json:"images"`
+	ExportDate time.Time                `json:"export_date"`
+}
+
+func (d AccountExportDto) DomainToDto(export domain.AccountExport, imageModelService app.ImageModelService) AccountExportDto {
+	farms := make([]FarmWithOutDto, len(export.Farms))
+	for i, farm := range export.Farms {
+		farms[i] = FarmWithOutDto{}.DomainToDto(farm, imageModelService)
+	}
+
+	offers := make([]OfferDto, len(export.Offers))
+	for i, offer := range export.Offers {
+		offers[i] = OfferDto{}.DomainToDto(offer, imageModelService)
+	}
+
+	images := make([]string, len(export.Images))
+	for i, image := range export.Images {
+		images[i] = image.Name
+	}
+
+	return AccountExportDto{
+		User:       AdminUserDto{}.DomainToDto(export.User),
+		Addresses:  AddressDto{}.DomainToDtoCollection(export.Addresses),
+		Orders:     OrderDtoWithOrderItems{}.DomainToDtoCollection(export.Orders, imageModelService),
+		Farms:      farms,
+		Offers:     offers,
+		Images:     images,
+		ExportDate: time.Now(),
+	}
+}
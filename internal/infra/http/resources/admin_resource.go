@@ -0,0 +1,40 @@
+package resources
+
+import (
+	"boilerplate/internal/domain"
+	"time"
+)
+
+type AdminUserDto struct {
+	UserDto
+	IsBlocked   bool       `json:"is_blocked"`
+	BlockedDate *time.Time `json:"blocked_date"`
+	BlockReason *string    `json:"block_reason"`
+	CreatedDate time.Time  `json:"created_date"`
+}
+
+type AdminUsersDto struct {
+	Items []AdminUserDto `json:"items"`
+	Total uint64         `json:"total"`
+	Pages uint           `json:"pages"`
+}
+
+func (d AdminUserDto) DomainToDto(user domain.User) AdminUserDto {
+	return AdminUserDto{
+		UserDto:     UserDto{}.DomainToDto(user),
+		IsBlocked:   user.IsBlocked(),
+		BlockedDate: user.BlockedDate,
+		BlockReason: user.BlockReason,
+		CreatedDate: user.CreatedDate,
+	}
+}
+
+func (d AdminUserDto) DomainToDtoCollection(users domain.Users) AdminUsersDto {
+	result := make([]AdminUserDto, len(users.Items))
+
+	for i := range users.Items {
+		result[i] = d.DomainToDto(users.Items[i])
+	}
+
+	return AdminUsersDto{Items: result, Pages: users.Pages, Total: users.Total}
+}
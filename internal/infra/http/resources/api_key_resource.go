@@ -0,0 +1,49 @@
+package resources
+
+import (
+	"boilerplate/internal/domain"
+	"time"
+)
+
+type ApiKeyDto struct {
+	Id           uint64     `json:"id"`
+	FarmId       uint64     `json:"farm_id"`
+	Name         string     `json:"name"`
+	Prefix       string     `json:"prefix"`
+	Key          string     `json:"key,omitempty"`
+	Scopes       []string   `json:"scopes"`
+	IsActive     bool       `json:"is_active"`
+	ExpiresDate  *time.Time `json:"expires_date"`
+	LastUsedDate *time.Time `json:"last_used_date"`
+	RevokedDate  *time.Time `json:"revoked_date"`
+	CreatedDate  time.Time  `json:"created_date"`
+}
+
+func (d ApiKeyDto) DomainToDto(key domain.ApiKey) ApiKeyDto {
+	scopes := make([]string, len(key.Scopes))
+	for i, s := range key.Scopes {
+		scopes[i] = string(s)
+	}
+
+	return ApiKeyDto{
+		Id:           key.Id,
+		FarmId:       key.FarmId,
+		Name:         key.Name,
+		Prefix:       key.Prefix,
+		Key:          key.Key,
+		Scopes:       scopes,
+		IsActive:     key.IsActive(time.Now()),
+		ExpiresDate:  key.ExpiresDate,
+		LastUsedDate: key.LastUsedDate,
+		RevokedDate:  key.RevokedDate,
+		CreatedDate:  key.CreatedDate,
+	}
+}
+
+func (d ApiKeyDto) DomainToDtoCollection(keys []domain.ApiKey) []ApiKeyDto {
+	result := make([]ApiKeyDto, len(keys))
+	for i := range keys {
+		result[i] = d.DomainToDto(keys[i])
+	}
+	return result
+}
@@ -0,0 +1,58 @@
+package resources
+
+import (
+	"boilerplate/internal/domain"
+	"time"
+)
+
+type AuditEventDto struct {
+	Id          uint64                 `json:"id"`
+	ActorId     *uint64                `json:"actor_id"`
+	Action      string                 `json:"action"`
+	Entity      string                 `json:"entity"`
+	EntityId    uint64                 `json:"entity_id"`
+	Reason      string                 `json:"reason,omitempty"`
+	Before      map[string]interface{} `json:"before"`
+	After       map[string]interface{} `json:"after"`
+	Ip          string                 `json:"ip,omitempty"`
+	RequestId   string                 `json:"request_id,omitempty"`
+	CreatedDate time.Time              `json:"created_date"`
+}
+
+type AuditEventsDto struct {
+	Items []AuditEventDto `json:"items"`
+	Total uint64          `json:"total"`
+	Pages uint            `json:"pages"`
+}
+
+func (d AuditEventDto) DomainToDto(e domain.AuditEvent) AuditEventDto {
+	// actor_id is null for actions done by the system itself
+	var actorId *uint64
+	if e.ActorId != 0 {
+		actorId = &e.ActorId
+	}
+
+	return AuditEventDto{
+		Id:          e.Id,
+		ActorId:     actorId,
+		Action:      string(e.Action),
+		Entity:      string(e.Entity),
+		EntityId:    e.EntityId,
+		Reason:      e.Reason,
+		Before:      e.Before,
+		After:       e.After,
+		Ip:          e.Ip,
+		RequestId:   e.RequestId,
+		CreatedDate: e.CreatedDate,
+	}
+}
+
+func (d AuditEventDto) DomainToDtoPaginatedCollection(events domain.AuditEvents) AuditEventsDto {
+	result := make([]AuditEventDto, len(events.Items))
+
+	for i := range events.Items {
+		result[i] = d.DomainToDto(events.Items[i])
+	}
+
+	return AuditEventsDto{Items: result, Pages: events.Pages, Total: events.Total}
+}
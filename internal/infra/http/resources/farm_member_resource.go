@@ -0,0 +1,80 @@
+package resources
+
+import (
+	"boilerplate/internal/domain"
+	"time"
+)
+
+type FarmMemberDto struct {
+	Id          uint64    `json:"id"`
+	FarmId      uint64    `json:"farm_id"`
+	UserId      uint64    `json:"user_id"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	PhoneNumber *string   `json:"phone_number"`
+	Role        string    `json:"role"`
+	CreatedDate time.Time `json:"created_date"`
+}
+
+type FarmInvitationDto struct {
+	Id           uint64     `json:"id"`
+	FarmId       uint64     `json:"farm_id"`
+	FarmName     *string    `json:"farm_name"`
+	Role         string     `json:"role"`
+	Email        *string    `json:"email"`
+	PhoneNumber  *string    `json:"phone_number"`
+	InvitedBy    uint64     `json:"invited_by"`
+	IsPending    bool       `json:"is_pending"`
+	ExpiresDate  time.Time  `json:"expires_date"`
+	AcceptedDate *time.Time `json:"accepted_date"`
+	DeclinedDate *time.Time `json:"declined_date"`
+	RevokedDate  *time.Time `json:"revoked_date"`
+	CreatedDate  time.Time  `json:"created_date"`
+}
+
+func (d FarmMemberDto) DomainToDto(member domain.FarmMember) FarmMemberDto {
+	return FarmMemberDto{
+		Id:          member.Id,
+		FarmId:      member.FarmId,
+		UserId:      member.User.Id,
+		Name:        member.User.Name,
+		Email:       member.User.Email,
+		PhoneNumber: member.User.PhoneNumber,
+		Role:        string(member.Role),
+		CreatedDate: member.CreatedDate,
+	}
+}
+
+func (d FarmMemberDto) DomainToDtoCollection(members []domain.FarmMember) []FarmMemberDto {
+	result := make([]FarmMemberDto, len(members))
+	for i := range members {
+		result[i] = d.DomainToDto(members[i])
+	}
+	return result
+}
+
+func (d FarmInvitationDto) DomainToDto(invitation domain.FarmInvitation) FarmInvitationDto {
+	return FarmInvitationDto{
+		Id:           invitation.Id,
+		FarmId:       invitation.FarmId,
+		FarmName:     invitation.FarmName,
+		Role:         string(invitation.Role),
+		Email:        invitation.Email,
+		PhoneNumber:  invitation.PhoneNumber,
+		InvitedBy:    invitation.InvitedBy.Id,
+		IsPending:    invitation.IsPending(time.Now()),
+		ExpiresDate:  invitation.ExpiresDate,
+		AcceptedDate: invitation.AcceptedDate,
+		DeclinedDate: invitation.DeclinedDate,
+		RevokedDate:  invitation.RevokedDate,
+		CreatedDate:  invitation.CreatedDate,
+	}
+}
+
+func (d FarmInvitationDto) DomainToDtoCollection(invitations []domain.FarmInvitation) []FarmInvitationDto {
+	result := make([]FarmInvitationDto, len(invitations))
+	for i := range invitations {
+		result[i] = d.DomainToDto(invitations[i])
+	}
+	return result
+}
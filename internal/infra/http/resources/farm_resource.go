@@ -1,29 +1,35 @@
 package resources
 
 import (
+	"boilerplate/internal/app"
 	"boilerplate/internal/domain"
 )
 
 type FarmDto struct {
-	Id        uint64      `json:"id"`
-	Name      *string     `json:"name"`
-	City      string      `json:"city"`
-	Address   string      `json:"address"`
-	Latitude  float64     `json:"latitude"`
-	Longitude float64     `json:"longitude"`
-	AllImages []ImageMDto `json:"all_images"`
-	User      UserDto     `json:"user"`
+	Id           uint64      `json:"id"`
+	Name         *string     `json:"name"`
+	City         string      `json:"city"`
+	Address      string      `json:"address"`
+	Latitude     float64     `json:"latitude"`
+	Longitude    float64     `json:"longitude"`
+	AllImages    []ImageMDto `json:"all_images"`
+	User         UserDto     `json:"user"`
+	Rating       float64     `json:"rating"`
+	ReviewsCount uint64      `json:"reviews_count"`
+	IsFollowed   bool        `json:"is_followed"`
 }
 
 type FarmWithOutDto struct {
-	Id        uint64      `json:"id"`
-	Name      *string     `json:"name"`
-	City      string      `json:"city"`
-	Address   string      `json:"address"`
-	Latitude  float64     `json:"latitude"`
-	Longitude float64     `json:"longitude"`
-	AllImages []ImageMDto `json:"all_images"`
-	UserId    uint64      `json:"user_id"`
+	Id           uint64      `json:"id"`
+	Name         *string     `json:"name"`
+	City         string      `json:"city"`
+	Address      string      `json:"address"`
+	Latitude     float64     `json:"latitude"`
+	Longitude    float64     `json:"longitude"`
+	AllImages    []ImageMDto `json:"all_images"`
+	UserId       uint64      `json:"user_id"`
+	Rating       float64     `json:"rating"`
+	ReviewsCount uint64      `json:"reviews_count"`
 }
 
 type FarmsDto struct {
@@ -32,38 +38,43 @@ type FarmsDto struct {
 	Total uint64    `json:"total"`
 }
 
-func (d FarmDto) DomainToDto(farm domain.Farm, imageDto ImageMDto) FarmDto {
+func (d FarmDto) DomainToDto(farm domain.Farm, imageModelService app.ImageModelService) FarmDto {
 	return FarmDto{
-		Id:        farm.Id,
-		Name:      farm.Name,
-		City:      farm.City,
-		Address:   farm.Address,
-		Latitude:  farm.Latitude,
-		Longitude: farm.Longitude,
-		AllImages: imageDto.DomainToDtoMass(farm.AllImages).Items,
-		User:      UserDto{}.DomainToDto(farm.User),
+		Id:           farm.Id,
+		Name:         farm.Name,
+		City:         farm.City,
+		Address:      farm.Address,
+		Latitude:     farm.Latitude,
+		Longitude:    farm.Longitude,
+		AllImages:    ImageMDto{}.DomainToDtoMass(farm.AllImages, imageModelService).Items,
+		User:         UserDto{}.DomainToDto(farm.User),
+		Rating:       farm.Rating,
+		ReviewsCount: farm.ReviewsCount,
+		IsFollowed:   farm.IsFollowed,
 	}
 }
 
-func (d FarmWithOutDto) DomainToDto(farm domain.Farm) FarmWithOutDto {
+func (d FarmWithOutDto) DomainToDto(farm domain.Farm, imageModelService app.ImageModelService) FarmWithOutDto {
 
 	return FarmWithOutDto{
-		Id:        farm.Id,
-		Name:      farm.Name,
-		City:      farm.City,
-		Address:   farm.Address,
-		Latitude:  farm.Latitude,
-		Longitude: farm.Longitude,
-		AllImages: ImageMDto{}.DomainToDtoMass(farm.AllImages).Items,
-		UserId:    farm.User.Id,
+		Id:           farm.Id,
+		Name:         farm.Name,
+		City:         farm.City,
+		Address:      farm.Address,
+		Latitude:     farm.Latitude,
+		Longitude:    farm.Longitude,
+		AllImages:    ImageMDto{}.DomainToDtoMass(farm.AllImages, imageModelService).Items,
+		UserId:       farm.User.Id,
+		Rating:       farm.Rating,
+		ReviewsCount: farm.ReviewsCount,
 	}
 }
 
-func (d FarmDto) DomainToDtoPaginatedCollection(farms domain.Farms, imageDto ImageMDto) FarmsDto {
+func (d FarmDto) DomainToDtoPaginatedCollection(farms domain.Farms, imageModelService app.ImageModelService) FarmsDto {
 	result := make([]FarmDto, len(farms.Items))
 
 	for i := range farms.Items {
-		result[i] = d.DomainToDto(farms.Items[i], imageDto)
+		result[i] = d.DomainToDto(farms.Items[i], imageModelService)
 	}
 
 	return FarmsDto{Items: result, Pages: farms.Pages, Total: farms.Total}
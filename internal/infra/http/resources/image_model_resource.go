@@ -1,32 +1,59 @@
 package resources
 
-import "boilerplate/internal/domain"
+import (
+	"boilerplate/internal/app"
+	"boilerplate/internal/domain"
+)
 
 type ImageMDto struct {
-	Id       uint64 `json:"id"`
-	Name     string `json:"name"`
-	Entity   string `json:"entity"`
-	EntityId uint64 `json:"entity_id"`
+	Id       uint64           `json:"id"`
+	Name     string           `json:"name"`
+	Url      string           `json:"url"`
+	Variants ImageVariantsDto `json:"variants,omitempty"`
+	Entity   string           `json:"entity"`
+	EntityId uint64           `json:"entity_id"`
 }
 
 type ImagesMDto struct {
 	Items []ImageMDto `json:"data"`
 }
 
-func (d ImageMDto) DomainToDtoMass(images []domain.Image) ImagesMDto {
+// ImageVariantsDto holds the URLs of the variants of an image by size and
+// format, e.g. variants["thumb"]["webp"].
+type ImageVariantsDto map[domain.ImageSize]map[domain.ImageFormat]string
+
+func (d ImageMDto) DomainToDtoMass(images []domain.Image, imageModelService app.ImageModelService) ImagesMDto {
 	imgsDto := make([]ImageMDto, len(images))
 	for i, item := range images {
-		imgsDto[i] = ImageMDto{}.DomainToDto(item)
+		imgsDto[i] = ImageMDto{}.DomainToDto(item, imageModelService)
 	}
 
 	return ImagesMDto{Items: imgsDto}
 }
 
-func (d ImageMDto) DomainToDto(imageM domain.Image) ImageMDto {
+func (d ImageMDto) DomainToDto(imageM domain.Image, imageModelService app.ImageModelService) ImageMDto {
 	return ImageMDto{
 		Id:       imageM.Id,
 		Name:     imageM.Name,
+		Url:      imageModelService.ImageUrl(imageM.Name),
+		Variants: ImageVariantsDto{}.DomainToDto(imageM, imageModelService),
 		Entity:   imageM.Entity,
 		EntityId: imageM.EntityId,
 	}
 }
+
+func (d ImageVariantsDto) DomainToDto(image domain.Image, imageModelService app.ImageModelService) ImageVariantsDto {
+	variants := image.Variants()
+	if len(variants) == 0 {
+		return nil
+	}
+
+	result := make(ImageVariantsDto, len(domain.GetImageSizes()))
+	for _, v := range variants {
+		if result[v.Size] == nil {
+			result[v.Size] = make(map[domain.ImageFormat]string, len(domain.GetImageFormats()))
+		}
+		result[v.Size][v.Format] = imageModelService.ImageUrl(v.Name)
+	}
+	return result
+}
@@ -27,6 +27,12 @@ type InvoiceDto struct {
 	UpdatedDate     time.Time           `json:"update_date"`
 }
 
+type InvoicesDto struct {
+	Items []InvoiceDto `json:"items"`
+	Pages uint         `json:"pages"`
+	Total uint64       `json:"total"`
+}
+
 func (d InvoiceDto) DomainToDto(invoice domain.Invoice) InvoiceDto {
 	return InvoiceDto{
 		InvoiceId:       invoice.InvoiceId,
@@ -51,6 +57,10 @@ func (d InvoiceDto) DomainToDtoPaginatedCollection(invoices []domain.Invoice) []
 	return result
 }
 
+func (d InvoiceDto) DomainToDtoPage(invoices domain.Invoices) InvoicesDto {
+	return InvoicesDto{Items: d.DomainToDtoPaginatedCollection(invoices.Items), Pages: invoices.Pages, Total: invoices.Total}
+}
+
 func (d CancelListItemDto) DomainToDto(cancelListItem domain.CancelListItem) CancelListItemDto {
 	return CancelListItemDto{
 		InvoiceId:    cancelListItem.InvoiceId,
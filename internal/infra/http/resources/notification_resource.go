@@ -0,0 +1,87 @@
+package resources
+
+import (
+	"boilerplate/internal/domain"
+	"fmt"
+	"time"
+)
+
+type NotificationDto struct {
+	Id          uint64     `json:"id"`
+	Event       string     `json:"event"`
+	Subject     string     `json:"subject"`
+	Body        string     `json:"body"`
+	IsRead      bool       `json:"is_read"`
+	ReadDate    *time.Time `json:"read_date"`
+	CreatedDate time.Time  `json:"created_date"`
+}
+
+type NotificationsDto struct {
+	Items  []NotificationDto `json:"items"`
+	Total  uint64            `json:"total"`
+	Pages  uint              `json:"pages"`
+	Unread uint64            `json:"unread"`
+}
+
+type UnreadNotificationsDto struct {
+	Unread uint64 `json:"unread"`
+}
+
+func (d NotificationDto) DomainToDto(n domain.Notification) NotificationDto {
+	return NotificationDto{
+		Id:          n.Id,
+		Event:       string(n.Event),
+		Subject:     n.Subject,
+		Body:        n.Body,
+		IsRead:      n.IsRead(),
+		ReadDate:    n.ReadDate,
+		CreatedDate: n.CreatedDate,
+	}
+}
+
+func (d NotificationDto) DomainToDtoPaginatedCollection(notifications domain.Notifications) NotificationsDto {
+	result := make([]NotificationDto, len(notifications.Items))
+
+	for i := range notifications.Items {
+		result[i] = d.DomainToDto(notifications.Items[i])
+	}
+
+	return NotificationsDto{Items: result, Total: notifications.Total, Pages: notifications.Pages, Unread: notifications.Unread}
+}
+
+type NotificationPreferenceDto struct {
+	Event           string  `json:"event"`
+	Channel         string  `json:"channel"`
+	Enabled         bool    `json:"enabled"`
+	QuietHoursStart *string `json:"quiet_hours_start"`
+	QuietHoursEnd   *string `json:"quiet_hours_end"`
+}
+
+func (d NotificationPreferenceDto) DomainToDto(p domain.NotificationPreference) NotificationPreferenceDto {
+	return NotificationPreferenceDto{
+		Event:           string(p.Event),
+		Channel:         string(p.Channel),
+		Enabled:         p.Enabled,
+		QuietHoursStart: formatQuietHour(p.QuietHoursStart),
+		QuietHoursEnd:   formatQuietHour(p.QuietHoursEnd),
+	}
+}
+
+func (d NotificationPreferenceDto) DomainToDtoCollection(preferences []domain.NotificationPreference) []NotificationPreferenceDto {
+	result := make([]NotificationPreferenceDto, len(preferences))
+
+	for i := range preferences {
+		result[i] = d.DomainToDto(preferences[i])
+	}
+
+	return result
+}
+
+func formatQuietHour(minutes *uint16) *string {
+	if minutes == nil {
+		return nil
+	}
+
+	value := fmt.Sprintf("%02d:%02d", *minutes/60, *minutes%60)
+	return &value
+}
@@ -0,0 +1,40 @@
+package resources
+
+import (
+	"boilerplate/internal/domain"
+	"time"
+)
+
+type OfferAlertDto struct {
+	Id           uint64     `json:"id"`
+	OfferId      uint64     `json:"offer_id"`
+	Type         string     `json:"type"`
+	TargetPrice  *float64   `json:"target_price"`
+	Channel      string     `json:"channel"`
+	Notified     bool       `json:"notified"`
+	NotifiedDate *time.Time `json:"notified_date"`
+	CreatedDate  time.Time  `json:"created_date"`
+}
+
+func (d OfferAlertDto) DomainToDto(alert domain.OfferAlert) OfferAlertDto {
+	return OfferAlertDto{
+		Id:           alert.Id,
+		OfferId:      alert.OfferId,
+		Type:         string(alert.Type),
+		TargetPrice:  alert.TargetPrice,
+		Channel:      string(alert.Channel),
+		Notified:     alert.Notified,
+		NotifiedDate: alert.NotifiedDate,
+		CreatedDate:  alert.CreatedDate,
+	}
+}
+
+func (d OfferAlertDto) DomainToDtoCollection(alerts []domain.OfferAlert) []OfferAlertDto {
+	result := make([]OfferAlertDto, len(alerts))
+
+	for i := range alerts {
+		result[i] = d.DomainToDto(alerts[i])
+	}
+
+	return result
+}
@@ -0,0 +1,71 @@
+package resources
+
+import (
+	"boilerplate/internal/domain"
+	"math"
+	"time"
+)
+
+type OfferPriceDto struct {
+	Id          uint64    `json:"id"`
+	OfferId     uint64    `json:"offer_id"`
+	Price       float64   `json:"price"`
+	CreatedDate time.Time `json:"created_date"`
+}
+
+type OfferPricesDto struct {
+	Items []OfferPriceDto `json:"items"`
+	Pages uint            `json:"pages"`
+	Total uint64          `json:"total"`
+}
+
+type OfferPriceScheduleDto struct {
+	Id            uint64     `json:"id"`
+	OfferId       uint64     `json:"offer_id"`
+	Price         float64    `json:"price"`
+	PreviousPrice *float64   `json:"previous_price"`
+	StartDate     time.Time  `json:"start_date"`
+	EndDate       *time.Time `json:"end_date"`
+	Status        string     `json:"status"`
+}
+
+func (d OfferPriceDto) DomainToDto(price domain.OfferPrice) OfferPriceDto {
+	return OfferPriceDto{
+		Id:          price.Id,
+		OfferId:     price.OfferId,
+		Price:       math.Round(price.Price*100) / 100,
+		CreatedDate: price.CreatedDate,
+	}
+}
+
+func (d OfferPriceDto) DomainToDtoPaginatedCollection(prices domain.OfferPrices) OfferPricesDto {
+	result := make([]OfferPriceDto, len(prices.Items))
+
+	for i := range prices.Items {
+		result[i] = d.DomainToDto(prices.Items[i])
+	}
+
+	return OfferPricesDto{Items: result, Pages: prices.Pages, Total: prices.Total}
+}
+
+func (d OfferPriceScheduleDto) DomainToDto(schedule domain.OfferPriceSchedule) OfferPriceScheduleDto {
+	return OfferPriceScheduleDto{
+		Id:            schedule.Id,
+		OfferId:       schedule.OfferId,
+		Price:         math.Round(schedule.Price*100) / 100,
+		PreviousPrice: schedule.PreviousPrice,
+		StartDate:     schedule.StartDate,
+		EndDate:       schedule.EndDate,
+		Status:        string(schedule.Status),
+	}
+}
+
+func (d OfferPriceScheduleDto) DomainToDtoCollection(schedules []domain.OfferPriceSchedule) []OfferPriceScheduleDto {
+	result := make([]OfferPriceScheduleDto, len(schedules))
+
+	for i := range schedules {
+		result[i] = d.DomainToDto(schedules[i])
+	}
+
+	return result
+}
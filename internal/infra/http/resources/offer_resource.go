@@ -7,18 +7,23 @@ import (
 )
 
 type OfferDto struct {
-	Id               uint64      `json:"id"`
-	Title            string      `json:"title"`
-	Description      string      `json:"description"`
-	Category         string      `json:"category"`
-	Price            float64     `json:"price"`
-	Unit             string      `json:"unit"`
-	Stock            uint        `json:"stock"`
-	Status           bool        `json:"status"`
-	Cover            string      `json:"image"`
-	AdditionalImages []ImageMDto `json:"additional_images"`
-	User             UserDto     `json:"user"`
-	FarmId           uint64      `json:"farm_id"`
+	Id               uint64           `json:"id"`
+	Title            string           `json:"title"`
+	Description      string           `json:"description"`
+	Category         string           `json:"category"`
+	Price            float64          `json:"price"`
+	Unit             string           `json:"unit"`
+	Stock            uint             `json:"stock"`
+	Status           bool             `json:"status"`
+	Cover            string           `json:"image"`
+	CoverUrl         string           `json:"image_url"`
+	CoverVariants    ImageVariantsDto `json:"image_variants,omitempty"`
+	AdditionalImages []ImageMDto      `json:"additional_images"`
+	User             UserDto          `json:"user"`
+	FarmId           uint64           `json:"farm_id"`
+	Rating           float64          `json:"rating"`
+	ReviewsCount     uint64           `json:"reviews_count"`
+	IsFavorite       bool             `json:"is_favorite"`
 }
 
 type OffersDto struct {
@@ -38,9 +43,14 @@ func (d OfferDto) DomainToDto(offer domain.Offer, imageModelService app.ImageMod
 		Unit:             offer.Unit,
 		Stock:            offer.Stock,
 		Cover:            offer.Cover.Name,
-		AdditionalImages: ImageMDto{}.DomainToDtoMass(additionalImages).Items,
+		CoverUrl:         imageModelService.ImageUrl(offer.Cover.Name),
+		CoverVariants:    ImageVariantsDto{}.DomainToDto(offer.Cover, imageModelService),
+		AdditionalImages: ImageMDto{}.DomainToDtoMass(additionalImages, imageModelService).Items,
 		Status:           offer.Status,
 		FarmId:           offer.Farm.Id,
+		Rating:           offer.Rating,
+		ReviewsCount:     offer.ReviewsCount,
+		IsFavorite:       offer.IsFavorite,
 		User:             UserDto{}.DomainToDto(offer.User),
 	}
 }
@@ -0,0 +1,24 @@
+package resources
+
+import (
+	"boilerplate/internal/domain"
+	"time"
+)
+
+type OrderEventDto struct {
+	Type        string    `json:"type"`
+	OrderId     uint64    `json:"order_id"`
+	OrderItemId *uint64   `json:"order_item_id,omitempty"`
+	Status      string    `json:"status"`
+	CreatedDate time.Time `json:"created_date"`
+}
+
+func (d OrderEventDto) DomainToDto(event domain.OrderEvent) OrderEventDto {
+	return OrderEventDto{
+		Type:        string(event.Type),
+		OrderId:     event.OrderId,
+		OrderItemId: event.OrderItemId,
+		Status:      string(event.Status),
+		CreatedDate: event.CreatedDate,
+	}
+}
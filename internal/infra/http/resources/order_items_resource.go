@@ -25,6 +25,6 @@ func (d OrderItemDto) DomainToDto(o domain.OrderItem, imageModelService app.Imag
 		Price:      o.Price,
 		TotalPrice: o.TotalPrice,
 		Amount:     o.Amount,
-		Farm:       FarmWithOutDto{}.DomainToDto(o.Farm),
+		Farm:       FarmWithOutDto{}.DomainToDto(o.Farm, imageModelService),
 	}
 }
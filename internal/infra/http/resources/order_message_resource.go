@@ -0,0 +1,49 @@
+package resources
+
+import (
+	"boilerplate/internal/app"
+	"boilerplate/internal/domain"
+	"time"
+)
+
+type OrderMessageDto struct {
+	Id          uint64      `json:"id"`
+	OrderId     uint64      `json:"order_id"`
+	UserId      uint64      `json:"user_id"`
+	UserName    string      `json:"user_name"`
+	Text        string      `json:"text"`
+	Images      []ImageMDto `json:"images"`
+	IsRead      bool        `json:"is_read"`
+	ReadDate    *time.Time  `json:"read_date"`
+	CreatedDate time.Time   `json:"created_date"`
+}
+
+type OrderMessagesDto struct {
+	Items []OrderMessageDto `json:"items"`
+	Pages uint              `json:"pages"`
+	Total uint64            `json:"total"`
+}
+
+func (d OrderMessageDto) DomainToDto(message domain.OrderMessage, imageModelService app.ImageModelService) OrderMessageDto {
+	return OrderMessageDto{
+		Id:          message.Id,
+		OrderId:     message.OrderId,
+		UserId:      message.User.Id,
+		UserName:    message.User.Name,
+		Text:        message.Text,
+		Images:      ImageMDto{}.DomainToDtoMass(message.Images, imageModelService).Items,
+		IsRead:      message.ReadDate != nil,
+		ReadDate:    message.ReadDate,
+		CreatedDate: message.CreatedDate,
+	}
+}
+
+func (d OrderMessageDto) DomainToDtoPaginatedCollection(messages domain.OrderMessages, imageModelService app.ImageModelService) OrderMessagesDto {
+	result := make([]OrderMessageDto, len(messages.Items))
+
+	for i := range messages.Items {
+		result[i] = d.DomainToDto(messages.Items[i], imageModelService)
+	}
+
+	return OrderMessagesDto{Items: result, Pages: messages.Pages, Total: messages.Total}
+}
@@ -14,11 +14,13 @@ type OrderDto struct {
 	User             UserDto `json:"user"`
 	ProductPrice     float64 `json:"product_price"`
 	ShippingPrice    float64 `json:"shipping_price"`
+	DiscountPrice    float64 `json:"discount_price"`
 	TotalPrice       float64 `json:"total_price"`
 	PostOffice       *string `json:"post_office"`
 	PostOfficeCity   *string `json:"post_office_city"`
 	Ttn              *string `json:"ttn"`
 	IsPercentagePaid *bool   `json:"is_percentage_paid"`
+	UnreadMessages   uint64  `json:"unread_messages"`
 	CreatedDate      string  `json:"created_data"`
 }
 
@@ -38,11 +40,13 @@ func (d OrderDto) DomainToDto(order domain.Order) OrderDto {
 		User:             UserDto{}.DomainToDto(order.User),
 		ProductPrice:     order.ProductsPrice,
 		ShippingPrice:    order.ShippingPrice,
+		DiscountPrice:    order.DiscountPrice,
 		TotalPrice:       order.TotalPrice,
 		PostOffice:       order.PostOffice,
 		PostOfficeCity:   order.PostOfficeCity,
 		Ttn:              order.Ttn,
 		IsPercentagePaid: order.IsPercentagePaid,
+		UnreadMessages:   order.UnreadMessages,
 		CreatedDate:      order.CreatedDate.Format("2006-01-02T15:04:05Z07:00"),
 	}
 }
@@ -68,20 +72,23 @@ func (d OrderDto) DomainToDtoPaginatedCollection(orders domain.Orders) OrdersDto
 }
 
 type OrderDtoWithOrderItems struct {
-	Id               uint64         `json:"id"`
-	OrderItems       []OrderItemDto `json:"order_items"`
-	Status           string         `json:"status"`
-	Comment          string         `json:"comment"`
-	Address          *string        `json:"address"`
-	User             UserDto        `json:"user"`
-	ProductPrice     float64        `json:"product_price"`
-	ShippingPrice    float64        `json:"shipping_price"`
-	TotalPrice       float64        `json:"total_price"`
-	PostOffice       *string        `json:"post_office"`
-	PostOfficeCity   *string        `json:"post_office_city"`
-	Ttn              *string        `json:"ttn"`
-	IsPercentagePaid *bool          `json:"is_percentage_paid"`
-	CreatedDate      string         `json:"created_data"`
+	Id               uint64             `json:"id"`
+	OrderItems       []OrderItemDto     `json:"order_items"`
+	Status           string             `json:"status"`
+	Comment          string             `json:"comment"`
+	Address          *string            `json:"address"`
+	User             UserDto            `json:"user"`
+	ProductPrice     float64            `json:"product_price"`
+	ShippingPrice    float64            `json:"shipping_price"`
+	DiscountPrice    float64            `json:"discount_price"`
+	Discounts        []OrderDiscountDto `json:"discounts"`
+	TotalPrice       float64            `json:"total_price"`
+	PostOffice       *string            `json:"post_office"`
+	PostOfficeCity   *string            `json:"post_office_city"`
+	Ttn              *string            `json:"ttn"`
+	IsPercentagePaid *bool              `json:"is_percentage_paid"`
+	UnreadMessages   uint64             `json:"unread_messages"`
+	CreatedDate      string             `json:"created_data"`
 }
 
 func (d OrderDtoWithOrderItems) DomainToDto(order domain.Order, imageModelService app.ImageModelService) OrderDtoWithOrderItems {
@@ -99,11 +106,14 @@ func (d OrderDtoWithOrderItems) DomainToDto(order domain.Order, imageModelServic
 		User:             UserDto{}.DomainToDto(order.User),
 		ProductPrice:     order.ProductsPrice,
 		ShippingPrice:    order.ShippingPrice,
+		DiscountPrice:    order.DiscountPrice,
+		Discounts:        OrderDiscountDto{}.DomainToDtoCollection(order.Discounts),
 		TotalPrice:       order.TotalPrice,
 		PostOffice:       order.PostOffice,
 		PostOfficeCity:   order.PostOfficeCity,
 		Ttn:              order.Ttn,
 		IsPercentagePaid: order.IsPercentagePaid,
+		UnreadMessages:   order.UnreadMessages,
 		CreatedDate:      order.CreatedDate.Format("2006-01-02T15:04:05Z07:00"),
 	}
 }
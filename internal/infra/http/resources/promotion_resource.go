@@ -0,0 +1,89 @@
+package resources
+
+import (
+	"boilerplate/internal/domain"
+	"math"
+	"time"
+)
+
+type PromotionDto struct {
+	Id                uint64     `json:"id"`
+	Code              string     `json:"code"`
+	Title             string     `json:"title"`
+	Type              string     `json:"type"`
+	Value             float64    `json:"value"`
+	BuyAmount         uint32     `json:"buy_amount"`
+	GetAmount         uint32     `json:"get_amount"`
+	MinOrderPrice     float64    `json:"min_order_price"`
+	UsageLimit        *uint64    `json:"usage_limit"`
+	UsageLimitPerUser *uint64    `json:"usage_limit_per_user"`
+	StartDate         time.Time  `json:"start_date"`
+	EndDate           *time.Time `json:"end_date"`
+	IsActive          bool       `json:"is_active"`
+	FarmId            *uint64    `json:"farm_id"`
+	OfferId           *uint64    `json:"offer_id"`
+}
+
+type PromotionsDto struct {
+	Items []PromotionDto `json:"items"`
+	Pages uint           `json:"pages"`
+	Total uint64         `json:"total"`
+}
+
+type OrderDiscountDto struct {
+	PromotionId uint64  `json:"promotion_id"`
+	Code        string  `json:"code"`
+	Title       string  `json:"title"`
+	Type        string  `json:"type"`
+	Amount      float64 `json:"amount"`
+}
+
+func (d PromotionDto) DomainToDto(promotion domain.Promotion) PromotionDto {
+	return PromotionDto{
+		Id:                promotion.Id,
+		Code:              promotion.Code,
+		Title:             promotion.Title,
+		Type:              string(promotion.Type),
+		Value:             promotion.Value,
+		BuyAmount:         promotion.BuyAmount,
+		GetAmount:         promotion.GetAmount,
+		MinOrderPrice:     promotion.MinOrderPrice,
+		UsageLimit:        promotion.UsageLimit,
+		UsageLimitPerUser: promotion.UsageLimitPerUser,
+		StartDate:         promotion.StartDate,
+		EndDate:           promotion.EndDate,
+		IsActive:          promotion.IsActive,
+		FarmId:            promotion.FarmId,
+		OfferId:           promotion.OfferId,
+	}
+}
+
+func (d PromotionDto) DomainToDtoPaginatedCollection(promotions domain.Promotions) PromotionsDto {
+	result := make([]PromotionDto, len(promotions.Items))
+
+	for i := range promotions.Items {
+		result[i] = d.DomainToDto(promotions.Items[i])
+	}
+
+	return PromotionsDto{Items: result, Pages: promotions.Pages, Total: promotions.Total}
+}
+
+func (d OrderDiscountDto) DomainToDto(discount domain.OrderDiscount) OrderDiscountDto {
+	return OrderDiscountDto{
+		PromotionId: discount.Promotion.Id,
+		Code:        discount.Promotion.Code,
+		Title:       discount.Promotion.Title,
+		Type:        string(discount.Promotion.Type),
+		Amount:      math.Round(discount.Amount*100) / 100,
+	}
+}
+
+func (d OrderDiscountDto) DomainToDtoCollection(discounts []domain.OrderDiscount) []OrderDiscountDto {
+	result := make([]OrderDiscountDto, len(discounts))
+
+	for i := range discounts {
+		result[i] = d.DomainToDto(discounts[i])
+	}
+
+	return result
+}
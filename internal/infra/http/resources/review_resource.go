@@ -0,0 +1,57 @@
+package resources
+
+import (
+	"boilerplate/internal/app"
+	"boilerplate/internal/domain"
+	"time"
+)
+
+type ReviewDto struct {
+	Id          uint64      `json:"id"`
+	UserId      uint64      `json:"user_id"`
+	UserName    string      `json:"user_name"`
+	OfferId     uint64      `json:"offer_id"`
+	FarmId      uint64      `json:"farm_id"`
+	Rating      uint8       `json:"rating"`
+	Text        string      `json:"text"`
+	Images      []ImageMDto `json:"images"`
+	Reply       *string     `json:"reply"`
+	ReplyDate   *time.Time  `json:"reply_date"`
+	Status      string      `json:"status"`
+	CreatedDate time.Time   `json:"created_date"`
+	UpdatedDate time.Time   `json:"updated_date"`
+}
+
+type ReviewsDto struct {
+	Items []ReviewDto `json:"items"`
+	Pages uint        `json:"pages"`
+	Total uint64      `json:"total"`
+}
+
+func (d ReviewDto) DomainToDto(review domain.Review, imageModelService app.ImageModelService) ReviewDto {
+	return ReviewDto{
+		Id:          review.Id,
+		UserId:      review.User.Id,
+		UserName:    review.User.Name,
+		OfferId:     review.OfferId,
+		FarmId:      review.FarmId,
+		Rating:      review.Rating,
+		Text:        review.Text,
+		Images:      ImageMDto{}.DomainToDtoMass(review.Images, imageModelService).Items,
+		Reply:       review.Reply,
+		ReplyDate:   review.ReplyDate,
+		Status:      string(review.Status),
+		CreatedDate: review.CreatedDate,
+		UpdatedDate: review.UpdatedDate,
+	}
+}
+
+func (d ReviewDto) DomainToDtoPaginatedCollection(reviews domain.Reviews, imageModelService app.ImageModelService) ReviewsDto {
+	result := make([]ReviewDto, len(reviews.Items))
+
+	for i := range reviews.Items {
+		result[i] = d.DomainToDto(reviews.Items[i], imageModelService)
+	}
+
+	return ReviewsDto{Items: result, Pages: reviews.Pages, Total: reviews.Total}
+}
@@ -1,12 +1,21 @@
 package resources
 
-import "boilerplate/internal/domain"
+import (
+	"boilerplate/internal/domain"
+	"time"
+)
 
 type UserDto struct {
-	Id          uint64  `json:"id"`
-	Name        string  `json:"name"`
-	Email       string  `json:"email"`
-	PhoneNumber *string `json:"phone_number"`
+	Id            uint64  `json:"id"`
+	Name          string  `json:"name"`
+	Email         string  `json:"email"`
+	EmailVerified bool    `json:"email_verified"`
+	NewEmail      *string `json:"new_email"`
+	PhoneNumber   *string `json:"phone_number"`
+	PhoneVerified bool    `json:"phone_verified"`
+	Language      string  `json:"language"`
+	Role          string  `json:"role"`
+	TwoFactor     bool    `json:"two_factor_enabled"`
 }
 
 type UsersDto struct {
@@ -16,16 +25,51 @@ type UsersDto struct {
 }
 
 type AuthDto struct {
-	Token string  `json:"token"`
-	User  UserDto `json:"user"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresDate  time.Time `json:"expires_date"`
+	User         UserDto   `json:"user"`
+}
+
+// TwoFactorChallengeDto is the answer to a correct password of an account
+// with 2FA. The session is issued after the code is sent with the token.
+type TwoFactorChallengeDto struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+}
+
+type TwoFactorEnrollmentDto struct {
+	Secret string `json:"secret"`
+	Url    string `json:"otpauth_url"`
+}
+
+type RecoveryCodesDto struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type SessionDto struct {
+	Id           string    `json:"id"`
+	Device       string    `json:"device"`
+	Ip           string    `json:"ip"`
+	UserAgent    string    `json:"user_agent"`
+	IsCurrent    bool      `json:"is_current"`
+	LastSeenDate time.Time `json:"last_seen_date"`
+	ExpiresDate  time.Time `json:"expires_date"`
+	CreatedDate  time.Time `json:"created_date"`
 }
 
 func (d UserDto) DomainToDto(user domain.User) UserDto {
 	return UserDto{
-		Id:          user.Id,
-		Name:        user.Name,
-		Email:       user.Email,
-		PhoneNumber: user.PhoneNumber,
+		Id:            user.Id,
+		Name:          user.Name,
+		Email:         user.Email,
+		EmailVerified: user.IsEmailVerified(),
+		NewEmail:      user.NewEmail,
+		PhoneNumber:   user.PhoneNumber,
+		PhoneVerified: user.IsPhoneVerified(),
+		Language:      user.Language,
+		Role:          string(user.Role),
+		TwoFactor:     user.IsTwoFactorEnabled(),
 	}
 }
 
@@ -39,10 +83,49 @@ func (d UserDto) DomainToDtoCollection(users domain.Users) UsersDto {
 	return UsersDto{Items: result, Pages: users.Pages, Total: users.Total}
 }
 
-func (d AuthDto) DomainToDto(token string, user domain.User) AuthDto {
+func (d AuthDto) DomainToDto(tokens domain.AuthTokens, user domain.User) AuthDto {
 	var userDto UserDto
 	return AuthDto{
-		Token: token,
-		User:  userDto.DomainToDto(user),
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresDate:  tokens.ExpiresDate,
+		User:         userDto.DomainToDto(user),
+	}
+}
+
+func (d SessionDto) DomainToDto(sess domain.Session, current domain.Session) SessionDto {
+	return SessionDto{
+		Id:           sess.UUID.String(),
+		Device:       sess.Device,
+		Ip:           sess.Ip,
+		UserAgent:    sess.UserAgent,
+		IsCurrent:    sess.UUID == current.UUID,
+		LastSeenDate: sess.LastSeenDate,
+		ExpiresDate:  sess.ExpiresDate,
+		CreatedDate:  sess.CreatedDate,
+	}
+}
+
+func (d SessionDto) DomainToDtoCollection(sessions []domain.Session, current domain.Session) []SessionDto {
+	result := make([]SessionDto, len(sessions))
+
+	for i := range sessions {
+		result[i] = d.DomainToDto(sessions[i], current)
+	}
+
+	return result
+}
+
+func (d TwoFactorChallengeDto) DomainToDto(tokens domain.AuthTokens) TwoFactorChallengeDto {
+	return TwoFactorChallengeDto{
+		TwoFactorRequired: true,
+		ChallengeToken:    tokens.ChallengeToken,
+	}
+}
+
+func (d TwoFactorEnrollmentDto) DomainToDto(e domain.TwoFactorEnrollment) TwoFactorEnrollmentDto {
+	return TwoFactorEnrollmentDto{
+		Secret: e.Secret,
+		Url:    e.Url,
 	}
 }
@@ -0,0 +1,108 @@
+package resources
+
+import (
+	"boilerplate/internal/domain"
+	"time"
+)
+
+type WebhookDto struct {
+	Id          uint64    `json:"id"`
+	FarmId      uint64    `json:"farm_id"`
+	Url         string    `json:"url"`
+	Secret      string    `json:"secret"`
+	Events      []string  `json:"events"`
+	IsActive    bool      `json:"is_active"`
+	CreatedDate time.Time `json:"created_date"`
+	UpdatedDate time.Time `json:"updated_date"`
+}
+
+type WebhookDeliveryDto struct {
+	Id              uint64     `json:"id"`
+	WebhookId       uint64     `json:"webhook_id"`
+	Event           string     `json:"event"`
+	Payload         string     `json:"payload"`
+	Status          string     `json:"status"`
+	Attempts        uint32     `json:"attempts"`
+	NextAttemptDate *time.Time `json:"next_attempt_date"`
+	ResponseCode    *int       `json:"response_code"`
+	Error           *string    `json:"error"`
+	DeliveredDate   *time.Time `json:"delivered_date"`
+	CreatedDate     time.Time  `json:"created_date"`
+}
+
+type WebhookDeliveriesDto struct {
+	Items []WebhookDeliveryDto `json:"items"`
+	Total uint64               `json:"total"`
+	Pages uint                 `json:"pages"`
+}
+
+func (d WebhookDto) DomainToDto(webhook domain.Webhook) WebhookDto {
+	events := make([]string, len(webhook.Events))
+	for i, e := range webhook.Events {
+		events[i] = string(e)
+	}
+
+	return WebhookDto{
+		Id:          webhook.Id,
+		FarmId:      webhook.FarmId,
+		Url:         webhook.Url,
+		Secret:      maskWebhookSecret(webhook.Secret),
+		Events:      events,
+		IsActive:    webhook.IsActive,
+		CreatedDate: webhook.CreatedDate,
+		UpdatedDate: webhook.UpdatedDate,
+	}
+}
+
+// DomainToDtoWithSecret shows the signing secret in full. It is meant only
+// for the responses that create the secret, the farmer can't see it later.
+func (d WebhookDto) DomainToDtoWithSecret(webhook domain.Webhook) WebhookDto {
+	dto := d.DomainToDto(webhook)
+	dto.Secret = webhook.Secret
+	return dto
+}
+
+func (d WebhookDto) DomainToDtoCollection(webhooks []domain.Webhook) []WebhookDto {
+	result := make([]WebhookDto, len(webhooks))
+
+	for i := range webhooks {
+		result[i] = d.DomainToDto(webhooks[i])
+	}
+
+	return result
+}
+
+func (d WebhookDeliveryDto) DomainToDto(delivery domain.WebhookDelivery) WebhookDeliveryDto {
+	return WebhookDeliveryDto{
+		Id:              delivery.Id,
+		WebhookId:       delivery.WebhookId,
+		Event:           string(delivery.Event),
+		Payload:         delivery.Payload,
+		Status:          string(delivery.Status),
+		Attempts:        delivery.Attempts,
+		NextAttemptDate: delivery.NextAttemptDate,
+		ResponseCode:    delivery.ResponseCode,
+		Error:           delivery.Error,
+		DeliveredDate:   delivery.DeliveredDate,
+		CreatedDate:     delivery.CreatedDate,
+	}
+}
+
+func (d WebhookDeliveryDto) DomainToDtoPaginatedCollection(deliveries domain.WebhookDeliveries) WebhookDeliveriesDto {
+	result := make([]WebhookDeliveryDto, len(deliveries.Items))
+
+	for i := range deliveries.Items {
+		result[i] = d.DomainToDto(deliveries.Items[i])
+	}
+
+	return WebhookDeliveriesDto{Items: result, Total: deliveries.Total, Pages: deliveries.Pages}
+}
+
+// maskWebhookSecret keeps the last characters of the secret, enough to
+// tell which one a receiver has configured.
+func maskWebhookSecret(secret string) string {
+	if len(secret) <= 4 {
+		return ""
+	}
+	return "whsec_****" + secret[len(secret)-4:]
+}
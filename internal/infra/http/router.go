@@ -4,14 +4,15 @@ import (
 	"boilerplate/config"
 	"boilerplate/config/container"
 	"boilerplate/internal/app"
+	"boilerplate/internal/app/notification"
 	"boilerplate/internal/domain"
 	"boilerplate/internal/infra/http/controllers"
 	"boilerplate/internal/infra/http/middlewares"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
-	"os"
-	"path/filepath"
+	"net/url"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
@@ -19,14 +20,19 @@ import (
 	"github.com/go-chi/cors"
 )
 
-func Router(cont container.Container) http.Handler {
+func Router(cont container.Container, conf config.Configuration) http.Handler {
 
 	router := chi.NewRouter()
 
-	router.Use(middleware.RedirectSlashes, middleware.Logger, cors.Handler(cors.Options{
+	realIp, err := middlewares.RealIp(conf.TrustedProxies)
+	if err != nil {
+		log.Fatalf("TRUSTED_PROXIES: %s", err)
+	}
+
+	router.Use(realIp, middleware.RequestID, middleware.RedirectSlashes, middleware.Logger, cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Device"},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: false,
 		MaxAge:           300,
@@ -43,7 +49,7 @@ func Router(cont container.Container) http.Handler {
 			// Public routes
 			apiRouter.Group(func(apiRouter chi.Router) {
 				apiRouter.Route("/auth", func(apiRouter chi.Router) {
-					AuthRouter(apiRouter, cont.AuthController, cont.AuthMw)
+					AuthRouter(apiRouter, cont.AuthController, cont.AuthMw, cont.AuthRateLimitMw)
 				})
 				CategoryRouter(apiRouter, cont.CategoryController)
 			})
@@ -52,31 +58,105 @@ func Router(cont container.Container) http.Handler {
 			apiRouter.Group(func(apiRouter chi.Router) {
 				apiRouter.Use(cont.AuthMw)
 
-				UserRouter(apiRouter, cont.UserController)
-				FarmRouter(apiRouter, cont.FarmController, cont.FarmService)
-				OfferRouter(apiRouter, cont.OfferController, cont.OfferService, cont.ImageModelService)
-				OrderRouter(apiRouter, cont.OrderController, cont.OrderService, cont.FarmService)
+				UserRouter(apiRouter, cont.UserController, cont.NotificationController)
+				FarmRouter(apiRouter, cont.FarmController, cont.FarmMemberController, cont.FarmService, cont.FarmMemberService, cont.FarmInvitationService, cont.SearchRateLimitMw)
+				OfferRouter(apiRouter, cont.OfferController, cont.OfferPriceController, cont.OfferService, cont.OfferPriceService, cont.ImageModelService, cont.FarmMemberService)
+				OrderRouter(apiRouter, cont.OrderController, cont.PromotionController, cont.OrderEventController, cont.OrderMessageController, cont.OrderService, cont.FarmService, cont.FarmMemberService)
+				PromotionRouter(apiRouter, cont.PromotionController, cont.PromotionService, cont.FarmService)
+				ReviewRouter(apiRouter, cont.ReviewController, cont.ReviewService)
+				FavoriteRouter(apiRouter, cont.FavoriteController, cont.OfferService, cont.FarmService)
+				OfferAlertRouter(apiRouter, cont.OfferAlertController, cont.OfferAlertService)
+				NotificationRouter(apiRouter, cont.NotificationController, cont.NotificationService)
+				WebhookRouter(apiRouter, cont.WebhookController, cont.WebhookService, cont.FarmService)
+				ApiKeyRouter(apiRouter, cont.ApiKeyController, cont.ApiKeyService, cont.FarmService, cont.FarmMemberService)
 				OrderItemRoute(apiRouter, cont.OrderItemController, cont.OrderService, cont.OrderItemsService)
 				ImageRouter(apiRouter, cont.ImageModelController, cont.ImageModelService)
 				AddressRouter(apiRouter, cont.AddressController, cont.AddressService)
 				InvoiceRouter(apiRouter, cont.InvoiceController, cont.InvoiceService)
-				MonobankRouter(apiRouter, cont.MonobankController)
+				MonobankRouter(apiRouter, cont.MonobankController, cont.OrderService)
+				AdminRouter(apiRouter, cont.AdminController, cont.UserService, cont.OfferService, cont.OrderService)
 
 				apiRouter.Handle("/*", NotFoundJSON())
 			})
 		})
 	})
 
-	router.Get("/static/*", func(w http.ResponseWriter, r *http.Request) {
-		workDir, _ := os.Getwd()
-		filesDir := http.Dir(filepath.Join(workDir, config.GetConfiguration().FileStorageLocation))
-		rctx := chi.RouteContext(r.Context())
-		pathPrefix := strings.TrimSuffix(rctx.RoutePattern(), "/*")
-		fs := http.StripPrefix(pathPrefix, http.FileServer(filesDir))
-		fs.ServeHTTP(w, r)
-	})
+	FileRouter(router, cont.FileController, conf.FilesUrl)
+
+	return middlewares.LimitApiKeys(router)
+}
+
+// FileRouter serves the stored files at the path of filesUrl, which can be
+// a full URL when a proxy or a CDN sits in front of the API.
+func FileRouter(r chi.Router, fc controllers.FileController, filesUrl string) {
+	u, err := url.Parse(filesUrl)
+	if err != nil {
+		log.Fatalf("FILES_URL: %s", err)
+	}
 
-	return router
+	r.Get(strings.TrimSuffix(u.Path, "/")+"/*", fc.Serve())
+}
+
+func AdminRouter(r chi.Router, ac controllers.AdminController, us app.UserService, ofs app.OfferService, os app.OrderService) {
+	userPathObjectMiddleware := middlewares.PathObject("userId", controllers.TargetUserKey, us)
+	offerPathObjectMiddleware := middlewares.PathObject("offerId", controllers.OfferKey, ofs)
+	orderPathObjectMiddleware := middlewares.PathObject("orderId", controllers.OrderKey, os)
+
+	r.Route("/admin", func(apiRouter chi.Router) {
+		apiRouter.Group(func(apiRouter chi.Router) {
+			apiRouter.Use(middlewares.RequirePermission(domain.PERMISSION_USERS_MANAGE))
+			apiRouter.Get(
+				"/users",
+				ac.FindUsers(),
+			)
+			apiRouter.With(userPathObjectMiddleware).Put(
+				"/users/{userId}/block",
+				ac.BlockUser(),
+			)
+			apiRouter.With(userPathObjectMiddleware).Put(
+				"/users/{userId}/unblock",
+				ac.UnblockUser(),
+			)
+			apiRouter.With(userPathObjectMiddleware).Put(
+				"/users/{userId}/role",
+				ac.SetUserRole(),
+			)
+		})
+		apiRouter.With(middlewares.RequirePermission(domain.PERMISSION_FARMS_MANAGE)).Get(
+			"/farms",
+			ac.FindFarms(),
+		)
+		apiRouter.Group(func(apiRouter chi.Router) {
+			apiRouter.Use(middlewares.RequirePermission(domain.PERMISSION_OFFERS_MANAGE))
+			apiRouter.Get(
+				"/offers",
+				ac.FindOffers(),
+			)
+			apiRouter.With(offerPathObjectMiddleware).Put(
+				"/offers/{offerId}/unpublish",
+				ac.UnpublishOffer(),
+			)
+		})
+		apiRouter.Group(func(apiRouter chi.Router) {
+			apiRouter.Use(middlewares.RequirePermission(domain.PERMISSION_ORDERS_MANAGE))
+			apiRouter.Get(
+				"/orders",
+				ac.FindOrders(),
+			)
+			apiRouter.With(orderPathObjectMiddleware).Put(
+				"/orders/{orderId}/status",
+				ac.ForceOrderStatus(),
+			)
+		})
+		apiRouter.With(middlewares.RequirePermission(domain.PERMISSION_INVOICES_READ)).Get(
+			"/payments",
+			ac.FindPayments(),
+		)
+		apiRouter.With(middlewares.RequirePermission(domain.PERMISSION_AUDIT_READ)).Get(
+			"/audit-events",
+			ac.FindAuditEvents(),
+		)
+	})
 }
 
 func OrderItemRoute(r chi.Router, oc controllers.OrderItemController, os app.OrderService, o app.OrderItemsService) {
@@ -101,24 +181,37 @@ func OrderItemRoute(r chi.Router, oc controllers.OrderItemController, os app.Ord
 	})
 }
 
-func OrderRouter(r chi.Router, oc controllers.OrderController, os app.OrderService, fs app.FarmService) {
+func OrderRouter(r chi.Router, oc controllers.OrderController, pc controllers.PromotionController, ec controllers.OrderEventController, mc controllers.OrderMessageController, os app.OrderService, fs app.FarmService, fms app.FarmMemberService) {
 	pathObjectMiddleware := middlewares.PathObject("orderId", controllers.OrderKey, os)
 	isOwnerMiddleware := middlewares.IsOwnerMiddleware[domain.Order](controllers.OrderKey)
 	farmPathObjectMiddleware := middlewares.PathObject("farmId", controllers.FarmKey, fs)
-	farmIsOwnerMiddleweare := middlewares.IsOwnerMiddleware[domain.Farm](controllers.FarmKey)
+	canReadOrdersMiddleware := middlewares.FarmPermission(fms, controllers.FarmKey, domain.FARM_PERMISSION_ORDERS_READ)
+	canProcessOrdersMiddleware := middlewares.FarmPermission(fms, controllers.FarmKey, domain.FARM_PERMISSION_ORDERS_PROCESS)
 	r.Route("/orders", func(apiRouter chi.Router) {
 		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Put(
 			"/receiver-status/{orderId}",
 			oc.SetOrderStatusAsReceiver(),
 		)
-		apiRouter.With(pathObjectMiddleware, farmPathObjectMiddleware, farmIsOwnerMiddleweare).Put(
+		apiRouter.With(pathObjectMiddleware, farmPathObjectMiddleware, canProcessOrdersMiddleware).Put(
 			"/farmer-status/{farmId}/{orderId}",
 			oc.SetOrderStatusAsFarmer(),
 		)
-		apiRouter.Get(
+		apiRouter.With(middlewares.RequirePermission(domain.PERMISSION_COMMISSIONS_READ)).Get(
 			"/farmer-percentage",
 			oc.GetFarmerOrdersPercentage(),
 		)
+		apiRouter.With(middlewares.RequirePermission(domain.PERMISSION_COMMISSIONS_MANAGE), pathObjectMiddleware).Put(
+			"/{orderId}/percentage-paid",
+			oc.MarkPercentageAsPaid(),
+		)
+		apiRouter.Get(
+			"/events",
+			ec.Stream(),
+		)
+		apiRouter.Get(
+			"/events/ws",
+			ec.WebSocket(),
+		)
 		apiRouter.With(pathObjectMiddleware).Get(
 			"/split/{orderId}",
 			oc.SplitOrderByFarms(),
@@ -135,6 +228,30 @@ func OrderRouter(r chi.Router, oc controllers.OrderController, os app.OrderServi
 			"/by-farmer",
 			oc.FindByFarmUserId(),
 		)
+		apiRouter.With(middlewares.RequireApiKeyScope(domain.API_KEY_SCOPE_ORDERS_READ), farmPathObjectMiddleware, canReadOrdersMiddleware).Get(
+			"/by-farmid/{farmId}",
+			oc.FindByFarmId(),
+		)
+		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Post(
+			"/{orderId}/promotions",
+			pc.ApplyToOrder(),
+		)
+		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Delete(
+			"/{orderId}/promotions/{promotionId}",
+			pc.RemoveFromOrder(),
+		)
+		apiRouter.With(pathObjectMiddleware).Get(
+			"/{orderId}/messages",
+			mc.FindAll(),
+		)
+		apiRouter.With(pathObjectMiddleware).Post(
+			"/{orderId}/messages",
+			mc.Send(),
+		)
+		apiRouter.With(pathObjectMiddleware).Put(
+			"/{orderId}/messages/read",
+			mc.MarkAsRead(),
+		)
 		apiRouter.With(pathObjectMiddleware).Get(
 			"/{orderId}",
 			oc.FindById(),
@@ -158,6 +275,224 @@ func OrderRouter(r chi.Router, oc controllers.OrderController, os app.OrderServi
 	})
 }
 
+func PromotionRouter(r chi.Router, pc controllers.PromotionController, ps app.PromotionService, fs app.FarmService) {
+	pathObjectMiddleware := middlewares.PathObject("promotionId", controllers.PromotionKey, ps)
+	isOwnerMiddleware := middlewares.IsOwnerMiddleware[domain.Promotion](controllers.PromotionKey)
+	farmPathObjectMiddleware := middlewares.PathObject("farmId", controllers.FarmKey, fs)
+	farmIsOwnerMiddleware := middlewares.IsOwnerMiddleware[domain.Farm](controllers.FarmKey)
+
+	r.Route("/promotions", func(apiRouter chi.Router) {
+		apiRouter.Post(
+			"/",
+			pc.Save(),
+		)
+		apiRouter.With(farmPathObjectMiddleware, farmIsOwnerMiddleware).Get(
+			"/by-farmid/{farmId}",
+			pc.FindByFarmId(),
+		)
+		apiRouter.With(middlewares.RequirePermission(domain.PERMISSION_PROMOTIONS_MANAGE)).Get(
+			"/platform",
+			pc.FindPlatform(),
+		)
+		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Get(
+			"/{promotionId}",
+			pc.FindById(),
+		)
+		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Put(
+			"/{promotionId}",
+			pc.Update(),
+		)
+		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Delete(
+			"/{promotionId}",
+			pc.Delete(),
+		)
+	})
+}
+
+func ReviewRouter(r chi.Router, rc controllers.ReviewController, rs app.ReviewService) {
+	pathObjectMiddleware := middlewares.PathObject("reviewId", controllers.ReviewKey, rs)
+	isOwnerMiddleware := middlewares.IsOwnerMiddleware[domain.Review](controllers.ReviewKey)
+
+	r.Route("/reviews", func(apiRouter chi.Router) {
+		apiRouter.Post(
+			"/",
+			rc.Save(),
+		)
+		apiRouter.Get(
+			"/by-offerid/{offerId}",
+			rc.FindByOfferId(),
+		)
+		apiRouter.Get(
+			"/by-farmid/{farmId}",
+			rc.FindByFarmId(),
+		)
+		apiRouter.With(pathObjectMiddleware).Put(
+			"/{reviewId}/reply",
+			rc.Reply(),
+		)
+		apiRouter.With(pathObjectMiddleware).Post(
+			"/{reviewId}/report",
+			rc.Report(),
+		)
+		apiRouter.With(middlewares.RequirePermission(domain.PERMISSION_REVIEWS_MODERATE), pathObjectMiddleware).Put(
+			"/{reviewId}/status",
+			rc.Moderate(),
+		)
+		apiRouter.With(pathObjectMiddleware).Get(
+			"/{reviewId}",
+			rc.FindById(),
+		)
+		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Put(
+			"/{reviewId}",
+			rc.Update(),
+		)
+		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Delete(
+			"/{reviewId}",
+			rc.Delete(),
+		)
+	})
+}
+
+func FavoriteRouter(r chi.Router, fc controllers.FavoriteController, os app.OfferService, fs app.FarmService) {
+	offerPathObjectMiddleware := middlewares.PathObject("offerId", controllers.OfferKey, os)
+	farmPathObjectMiddleware := middlewares.PathObject("farmId", controllers.FarmKey, fs)
+
+	r.Route("/favorites", func(apiRouter chi.Router) {
+		apiRouter.Get(
+			"/offers",
+			fc.FindOffers(),
+		)
+		apiRouter.With(offerPathObjectMiddleware).Post(
+			"/offers/{offerId}",
+			fc.AddOffer(),
+		)
+		apiRouter.With(offerPathObjectMiddleware).Delete(
+			"/offers/{offerId}",
+			fc.RemoveOffer(),
+		)
+		apiRouter.Get(
+			"/farms",
+			fc.FindFarms(),
+		)
+		apiRouter.With(farmPathObjectMiddleware).Post(
+			"/farms/{farmId}",
+			fc.FollowFarm(),
+		)
+		apiRouter.With(farmPathObjectMiddleware).Delete(
+			"/farms/{farmId}",
+			fc.UnfollowFarm(),
+		)
+	})
+}
+
+func OfferAlertRouter(r chi.Router, oac controllers.OfferAlertController, oas app.OfferAlertService) {
+	pathObjectMiddleware := middlewares.PathObject("alertId", controllers.OfferAlertKey, oas)
+	isOwnerMiddleware := middlewares.IsOwnerMiddleware[domain.OfferAlert](controllers.OfferAlertKey)
+
+	r.Route("/offer-alerts", func(apiRouter chi.Router) {
+		apiRouter.Get(
+			"/",
+			oac.FindAll(),
+		)
+		apiRouter.Post(
+			"/",
+			oac.Subscribe(),
+		)
+		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Delete(
+			"/{alertId}",
+			oac.Unsubscribe(),
+		)
+	})
+}
+
+func NotificationRouter(r chi.Router, nc controllers.NotificationController, ns notification.Service) {
+	pathObjectMiddleware := middlewares.PathObject("notificationId", controllers.NotificationKey, ns)
+	isOwnerMiddleware := middlewares.IsOwnerMiddleware[domain.Notification](controllers.NotificationKey)
+
+	r.Route("/notifications", func(apiRouter chi.Router) {
+		apiRouter.Get(
+			"/",
+			nc.FindAll(),
+		)
+		apiRouter.Get(
+			"/unread-count",
+			nc.CountUnread(),
+		)
+		apiRouter.Put(
+			"/read-all",
+			nc.MarkAllAsRead(),
+		)
+		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Put(
+			"/{notificationId}/read",
+			nc.MarkAsRead(),
+		)
+	})
+}
+
+func WebhookRouter(r chi.Router, wc controllers.WebhookController, ws app.WebhookService, fs app.FarmService) {
+	pathObjectMiddleware := middlewares.PathObject("webhookId", controllers.WebhookKey, ws)
+	isOwnerMiddleware := middlewares.IsOwnerMiddleware[domain.Webhook](controllers.WebhookKey)
+	farmPathObjectMiddleware := middlewares.PathObject("farmId", controllers.FarmKey, fs)
+	farmIsOwnerMiddleware := middlewares.IsOwnerMiddleware[domain.Farm](controllers.FarmKey)
+
+	r.Route("/webhooks", func(apiRouter chi.Router) {
+		apiRouter.Post(
+			"/",
+			wc.Save(),
+		)
+		apiRouter.With(farmPathObjectMiddleware, farmIsOwnerMiddleware).Get(
+			"/by-farmid/{farmId}",
+			wc.FindByFarmId(),
+		)
+		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Get(
+			"/{webhookId}",
+			wc.FindById(),
+		)
+		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Put(
+			"/{webhookId}",
+			wc.Update(),
+		)
+		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Delete(
+			"/{webhookId}",
+			wc.Delete(),
+		)
+		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Post(
+			"/{webhookId}/rotate-secret",
+			wc.RotateSecret(),
+		)
+		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Get(
+			"/{webhookId}/deliveries",
+			wc.FindDeliveries(),
+		)
+		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Post(
+			"/{webhookId}/deliveries/{deliveryId}/redeliver",
+			wc.Redeliver(),
+		)
+	})
+}
+
+func ApiKeyRouter(r chi.Router, akc controllers.ApiKeyController, aks app.ApiKeyService, fs app.FarmService, fms app.FarmMemberService) {
+	pathObjectMiddleware := middlewares.PathObject("apiKeyId", controllers.ApiKeyKey, aks)
+	isOwnerMiddleware := middlewares.IsOwnerMiddleware[domain.ApiKey](controllers.ApiKeyKey)
+	farmPathObjectMiddleware := middlewares.PathObject("farmId", controllers.FarmKey, fs)
+	canManageFarmMiddleware := middlewares.FarmPermission(fms, controllers.FarmKey, domain.FARM_PERMISSION_FARM_MANAGE)
+
+	r.Route("/api-keys", func(apiRouter chi.Router) {
+		apiRouter.Post(
+			"/",
+			akc.Save(),
+		)
+		apiRouter.With(farmPathObjectMiddleware, canManageFarmMiddleware).Get(
+			"/by-farmid/{farmId}",
+			akc.FindByFarmId(),
+		)
+		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Delete(
+			"/{apiKeyId}",
+			akc.Revoke(),
+		)
+	})
+}
+
 func CategoryRouter(r chi.Router, categoryController controllers.CategoryController) {
 	r.Route("/categories", func(apiRouter chi.Router) {
 		apiRouter.Get(
@@ -167,16 +502,24 @@ func CategoryRouter(r chi.Router, categoryController controllers.CategoryControl
 	})
 }
 
-func FarmRouter(r chi.Router, uc controllers.FarmController, fs app.FarmService) {
+func FarmRouter(r chi.Router, uc controllers.FarmController, mc controllers.FarmMemberController, fs app.FarmService, fms app.FarmMemberService, fis app.FarmInvitationService, rlmw func(http.Handler) http.Handler) {
 	pathObjectMiddleware := middlewares.PathObject("farmId", controllers.FarmKey, fs)
-	isOwnerMiddleware := middlewares.IsOwnerMiddleware[domain.Farm](controllers.FarmKey)
+	memberPathObjectMiddleware := middlewares.PathObject("memberId", controllers.FarmMemberKey, fms)
+	invitationPathObjectMiddleware := middlewares.PathObject("invitationId", controllers.FarmInvitationKey, fis)
+	canManageFarmMiddleware := middlewares.FarmPermission(fms, controllers.FarmKey, domain.FARM_PERMISSION_FARM_MANAGE)
+	canReadMembersMiddleware := middlewares.FarmPermission(fms, controllers.FarmKey, domain.FARM_PERMISSION_MEMBERS_READ)
+	canManageMembersMiddleware := middlewares.FarmPermission(fms, controllers.FarmKey, domain.FARM_PERMISSION_MEMBERS_MANAGE)
 
 	r.Route("/farms", func(apiRouter chi.Router) {
 		apiRouter.Get(
 			"/",
 			uc.ListView(),
 		)
-		apiRouter.Post(
+		apiRouter.Get(
+			"/mine",
+			uc.FindMine(),
+		)
+		apiRouter.With(rlmw).Post(
 			"/get-by-coords",
 			uc.FindAllByCoords(),
 		)
@@ -184,29 +527,71 @@ func FarmRouter(r chi.Router, uc controllers.FarmController, fs app.FarmService)
 			"/{farmId}",
 			uc.FindById(),
 		)
-		apiRouter.Post(
+		apiRouter.With(middlewares.RequirePermission(domain.PERMISSION_FARMS_CREATE)).Post(
 			"/",
 			uc.Save(),
 		)
-		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Put(
+		apiRouter.With(pathObjectMiddleware, canManageFarmMiddleware).Put(
 			"/{farmId}",
 			uc.Update(),
 		)
-		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Delete(
+		apiRouter.With(pathObjectMiddleware, canManageFarmMiddleware).Delete(
 			"/{farmId}",
 			uc.Delete(),
 		)
+		apiRouter.With(pathObjectMiddleware, canReadMembersMiddleware).Get(
+			"/{farmId}/members",
+			mc.FindMembers(),
+		)
+		apiRouter.With(pathObjectMiddleware, memberPathObjectMiddleware, canManageMembersMiddleware).Put(
+			"/{farmId}/members/{memberId}",
+			mc.ChangeRole(),
+		)
+		apiRouter.With(pathObjectMiddleware, memberPathObjectMiddleware, canManageMembersMiddleware).Delete(
+			"/{farmId}/members/{memberId}",
+			mc.RemoveMember(),
+		)
+		apiRouter.With(pathObjectMiddleware, canManageMembersMiddleware).Post(
+			"/{farmId}/invitations",
+			mc.Invite(),
+		)
+		apiRouter.With(pathObjectMiddleware, canManageMembersMiddleware).Get(
+			"/{farmId}/invitations",
+			mc.FindInvitations(),
+		)
+		apiRouter.With(pathObjectMiddleware, invitationPathObjectMiddleware, canManageMembersMiddleware).Delete(
+			"/{farmId}/invitations/{invitationId}",
+			mc.RevokeInvitation(),
+		)
+	})
+
+	r.Route("/farm-invitations", func(apiRouter chi.Router) {
+		apiRouter.Get(
+			"/",
+			mc.FindMyInvitations(),
+		)
+		apiRouter.With(invitationPathObjectMiddleware).Post(
+			"/{invitationId}/accept",
+			mc.AcceptInvitation(),
+		)
+		apiRouter.With(invitationPathObjectMiddleware).Post(
+			"/{invitationId}/decline",
+			mc.DeclineInvitation(),
+		)
 	})
 }
 
-func OfferRouter(r chi.Router, oc controllers.OfferController, os app.OfferService, is app.ImageModelService) {
+func OfferRouter(r chi.Router, oc controllers.OfferController, opc controllers.OfferPriceController, os app.OfferService, ops app.OfferPriceService, is app.ImageModelService, fms app.FarmMemberService) {
 
 	pathObjectMiddleware := middlewares.PathObject("offerId", controllers.OfferKey, os)
 	imagePathObjectMiddleware := middlewares.PathObject("imageId", controllers.ImageKey, is)
-	isOwnerMiddleware := middlewares.IsOwnerMiddleware[domain.Offer](controllers.OfferKey)
+	schedulePathObjectMiddleware := middlewares.PathObject("scheduleId", controllers.OfferPriceScheduleKey, ops)
+	canManageOffersMiddleware := middlewares.FarmPermission(fms, controllers.OfferKey, domain.FARM_PERMISSION_OFFERS_MANAGE)
+	offersReadScope := middlewares.RequireApiKeyScope(domain.API_KEY_SCOPE_OFFERS_READ)
+	offersWriteScope := middlewares.RequireApiKeyScope(domain.API_KEY_SCOPE_OFFERS_WRITE)
 
 	r.Route("/offers", func(apiRouter chi.Router) {
-		apiRouter.Post(
+		apiRouter.With(offersWriteScope).Post(
 			"/",
 			oc.Save(),
 		)
@@ -214,30 +599,50 @@ func OfferRouter(r chi.Router, oc controllers.OfferController, os app.OfferServi
 			"/",
 			oc.ListView(),
 		)
-		apiRouter.Get(
+		apiRouter.With(offersReadScope).Get(
 			"/by-farmid/{farmId}",
 			oc.FindByFarmId(),
 		)
-		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Post(
+		apiRouter.With(pathObjectMiddleware, canManageOffersMiddleware).Post(
 			"/additional-image/{offerId}",
 			oc.AddAdditionalImage(),
 		)
-		apiRouter.With(pathObjectMiddleware, imagePathObjectMiddleware, isOwnerMiddleware).Delete(
+		apiRouter.With(pathObjectMiddleware, imagePathObjectMiddleware, canManageOffersMiddleware).Delete(
 			"/additional-image/{offerId}/{imageId}",
 			oc.DeleteAdditionalImage(),
 		)
-		apiRouter.With(pathObjectMiddleware).Get(
+		apiRouter.With(offersReadScope, pathObjectMiddleware).Get(
+			"/{offerId}/price-history",
+			opc.FindHistory(),
+		)
+		apiRouter.With(pathObjectMiddleware, canManageOffersMiddleware).Get(
+			"/{offerId}/price-schedules",
+			opc.FindSchedules(),
+		)
+		apiRouter.With(pathObjectMiddleware, canManageOffersMiddleware).Post(
+			"/{offerId}/price-schedules",
+			opc.Schedule(),
+		)
+		apiRouter.With(pathObjectMiddleware, schedulePathObjectMiddleware, canManageOffersMiddleware).Delete(
+			"/{offerId}/price-schedules/{scheduleId}",
+			opc.CancelSchedule(),
+		)
+		apiRouter.With(offersReadScope, pathObjectMiddleware).Get(
 			"/{offerId}",
 			oc.FindById(),
 		)
-		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Delete(
+		apiRouter.With(offersWriteScope, pathObjectMiddleware, canManageOffersMiddleware).Delete(
 			"/{offerId}",
 			oc.Delete(),
 		)
-		apiRouter.With(pathObjectMiddleware, isOwnerMiddleware).Put(
+		apiRouter.With(offersWriteScope, pathObjectMiddleware, canManageOffersMiddleware).Put(
 			"/{offerId}",
 			oc.Update(),
 		)
+		apiRouter.With(offersWriteScope, pathObjectMiddleware, canManageOffersMiddleware).Put(
+			"/{offerId}/cover",
+			oc.UploadCover(),
+		)
 	})
 }
 
@@ -269,20 +674,52 @@ func AddressRouter(r chi.Router, ac controllers.AddressController, as app.Addres
 	})
 }
 
-func AuthRouter(r chi.Router, ac controllers.AuthController, amw func(http.Handler) http.Handler) {
+func AuthRouter(r chi.Router, ac controllers.AuthController, amw func(http.Handler) http.Handler, rlmw func(http.Handler) http.Handler) {
 	r.Route("/", func(apiRouter chi.Router) {
-		apiRouter.Post(
+		apiRouter.With(rlmw).Post(
 			"/register",
 			ac.Register(),
 		)
-		apiRouter.Post(
+		apiRouter.With(rlmw).Post(
 			"/login",
 			ac.Login(),
 		)
-		apiRouter.Post(
+		apiRouter.With(rlmw).Post(
 			"/login-email",
 			ac.LoginWithEmail(),
 		)
+		apiRouter.Post(
+			"/refresh",
+			ac.Refresh(),
+		)
+		apiRouter.With(rlmw).Post(
+			"/two-factor/verify",
+			ac.CompleteTwoFactor(),
+		)
+		apiRouter.With(amw).Post(
+			"/two-factor",
+			ac.EnrollTwoFactor(),
+		)
+		apiRouter.With(amw).Post(
+			"/two-factor/confirm",
+			ac.ConfirmTwoFactor(),
+		)
+		apiRouter.With(amw).Post(
+			"/two-factor/recovery-codes",
+			ac.RegenerateRecoveryCodes(),
+		)
+		apiRouter.With(amw).Post(
+			"/two-factor/disable",
+			ac.DisableTwoFactor(),
+		)
+		apiRouter.With(rlmw).Post(
+			"/forgot-password",
+			ac.ForgotPassword(),
+		)
+		apiRouter.With(rlmw).Post(
+			"/reset-password",
+			ac.ResetPassword(),
+		)
 		apiRouter.With(amw).Post(
 			"/change-pwd",
 			ac.ChangePassword(),
@@ -291,10 +728,22 @@ func AuthRouter(r chi.Router, ac controllers.AuthController, amw func(http.Handl
 			"/logout",
 			ac.Logout(),
 		)
+		apiRouter.With(amw).Get(
+			"/sessions",
+			ac.FindSessions(),
+		)
+		apiRouter.With(amw).Delete(
+			"/sessions",
+			ac.RevokeOtherSessions(),
+		)
+		apiRouter.With(amw).Delete(
+			"/sessions/{sessionId}",
+			ac.RevokeSession(),
+		)
 	})
 }
 
-func UserRouter(r chi.Router, uc controllers.UserController) {
+func UserRouter(r chi.Router, uc controllers.UserController, nc controllers.NotificationController) {
 	r.Route("/users", func(apiRouter chi.Router) {
 		apiRouter.Get(
 			"/",
@@ -304,6 +753,42 @@ func UserRouter(r chi.Router, uc controllers.UserController) {
 			"/phone-number",
 			uc.SetPhoneNumber(),
 		)
+		apiRouter.Post(
+			"/phone-number/send-code",
+			uc.SendPhoneCode(),
+		)
+		apiRouter.Post(
+			"/phone-number/verify",
+			uc.VerifyPhone(),
+		)
+		apiRouter.Put(
+			"/email",
+			uc.ChangeEmail(),
+		)
+		apiRouter.Post(
+			"/email/send-code",
+			uc.SendEmailCode(),
+		)
+		apiRouter.Post(
+			"/email/verify",
+			uc.VerifyEmail(),
+		)
+		apiRouter.Put(
+			"/language",
+			uc.SetLanguage(),
+		)
+		apiRouter.Get(
+			"/notification-preferences",
+			nc.FindPreferences(),
+		)
+		apiRouter.Put(
+			"/notification-preferences",
+			nc.SavePreferences(),
+		)
+		apiRouter.Get(
+			"/export",
+			uc.Export(),
+		)
 		apiRouter.Delete(
 			"/",
 			uc.Delete(),
@@ -326,6 +811,10 @@ func ImageRouter(r chi.Router, ic controllers.ImageModelController, is app.Image
 			"/",
 			ic.Save(),
 		)
+		apiRouter.Post(
+			"/upload",
+			ic.Upload(),
+		)
 		apiRouter.With(pathObjectMiddleware).Delete(
 			"/{imageId}",
 			ic.Delete(),
@@ -337,6 +826,7 @@ func ImageRouter(r chi.Router, ic controllers.ImageModelController, is app.Image
 func InvoiceRouter(r chi.Router, ic controllers.InvoiceController, is app.InvoiceService) {
 	pathObjectMiddleware := middlewares.PathObject("invoiceId", controllers.InvoiceKey, is)
 	r.Route("/invoice", func(apiRouter chi.Router) {
+		apiRouter.Use(middlewares.RequirePermission(domain.PERMISSION_INVOICES_READ))
 		apiRouter.Get(
 			"/",
 			ic.FindAll(),
@@ -353,17 +843,26 @@ func InvoiceRouter(r chi.Router, ic controllers.InvoiceController, is app.Invoic
 
 }
 
-func MonobankRouter(r chi.Router, mc controllers.MonobankController) {
+func MonobankRouter(r chi.Router, mc controllers.MonobankController, os app.OrderService) {
+	orderPathObjectMiddleware := middlewares.PathObject("orderId", controllers.OrderKey, os)
+	orderIsOwnerMiddleware := middlewares.IsOwnerMiddleware[domain.Order](controllers.OrderKey)
+	canReadInvoices := middlewares.RequirePermission(domain.PERMISSION_INVOICES_READ)
+	canManageInvoices := middlewares.RequirePermission(domain.PERMISSION_INVOICES_MANAGE)
+
 	r.Route("/monobank", func(apiRouter chi.Router) {
-		apiRouter.Post(
+		apiRouter.With(canManageInvoices).Post(
 			"/",
 			mc.CreateInvoice(),
 		)
-		apiRouter.Get(
+		apiRouter.With(orderPathObjectMiddleware, orderIsOwnerMiddleware).Post(
+			"/order/{orderId}",
+			mc.CreateOrderInvoice(),
+		)
+		apiRouter.With(canReadInvoices).Get(
 			"/{invoiceId}",
 			mc.GetInvoiceData(),
 		)
-		apiRouter.Post(
+		apiRouter.With(canManageInvoices).Post(
 			"/cancel",
 			mc.CreateInvoice(),
 		)